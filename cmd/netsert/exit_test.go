@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ndtobs/netsert/pkg/runner"
+)
+
+func TestRunExitError(t *testing.T) {
+	tests := []struct {
+		name        string
+		interrupted bool
+		counts      runner.Counts
+		flaky       []runner.AssertionHistory
+		want        int // 0 for no error
+	}{
+		{name: "passed", counts: runner.Counts{Passed: 3}},
+		{name: "failed", counts: runner.Counts{Passed: 2, Failed: 1}, want: 1},
+		{name: "errors", counts: runner.Counts{Errors: 1}, want: 1},
+		{name: "flaky", counts: runner.Counts{Passed: 3}, flaky: []runner.AssertionHistory{{}}, want: 1},
+		{name: "interrupted", interrupted: true, counts: runner.Counts{Failed: 1}, want: exitInterrupted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runExitError(tt.interrupted, &runner.RunResult{Counts: tt.counts}, tt.flaky, nil, runOptions{})
+			var exit exitError
+			switch {
+			case tt.want == 0 && err != nil:
+				t.Errorf("runExitError() = %v, want nil", err)
+			case tt.want != 0 && (!errors.As(err, &exit) || exit.code != tt.want):
+				t.Errorf("runExitError() = %v, want exit status %d", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/config"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+	"github.com/ndtobs/netsert/pkg/inventory"
+	"github.com/ndtobs/netsert/pkg/runner"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/spf13/cobra"
+)
+
+// getOptions are the flags of netsert get
+type getOptions struct {
+	username string
+	password string
+	insecure bool
+
+	origin   string // gNMI origin, e.g. a vendor-native tree
+	encoding string // Encoding other than JSON_IETF
+	prefix   string // Request path prefix
+	format   string // Subtree rendering: flat, table, or tree
+
+	stream bool          // Subscribe and print updates until interrupted
+	sample time.Duration // With stream, sample instead of on-change
+	once   bool          // Subscribe once
+	poll   time.Duration // Poll at this interval until interrupted
+}
+
+func getCmd() *cobra.Command {
+	var opts getOptions
+
+	cmd := &cobra.Command{
+		Use:   "get <target> <path>...",
+		Short: "Query gNMI paths on a device",
+		Long: `Query gNMI paths on a device to discover available data.
+
+Examples:
+  netsert get spine1:6030 /interfaces/interface[name=Ethernet1]/state/oper-status
+  netsert get spine1:6030 /system/config/hostname /system/state/software-version
+  netsert get spine1:6030 /interfaces/interface --insecure
+
+Subscribe instead of a single Get to watch values while writing assertions:
+  netsert get spine1:6030 /interfaces/interface/state/oper-status --stream
+  netsert get spine1:6030 /interfaces/interface/state/counters --stream --sample 10s
+  netsert get spine1:6030 /network-instances --once
+  netsert get spine1:6030 /system/state/current-datetime --poll 5s
+
+When a path returns nothing, try the device's native tree or another encoding:
+  netsert get spine1:6030 /Sysdb/interface/status --origin eos_native
+  netsert get spine1:6030 /system/state/hostname --encoding json
+  netsert get spine1:6030 /protocols --prefix /network-instances/network-instance[name=default]
+
+Subtrees print as JSON; --format breaks them into leaves, to pick ones to
+assert on:
+  netsert get spine1:6030 /interfaces/interface[name=Ethernet1]/state --format flat
+  netsert get spine1:6030 /network-instances/network-instance[name=default]/protocols --format tree
+
+Streams and polls run until interrupted. With -o json, each update is
+printed as a JSON line.`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.sample > 0 && !opts.stream {
+				return fmt.Errorf("--sample requires --stream")
+			}
+			switch opts.format {
+			case "", "flat":
+			case "table", "tree":
+				if opts.stream || opts.once || opts.poll > 0 {
+					return fmt.Errorf("--format %s needs a Get; use --format flat with subscriptions", opts.format)
+				}
+			default:
+				return fmt.Errorf("unknown format %q (flat, table, or tree)", opts.format)
+			}
+			if opts.format != "" && output == "json" {
+				return fmt.Errorf("--format applies to text output")
+			}
+			return runGet(args[0], args[1:], opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.username, "username", "u", "", "username (or use config file)")
+	cmd.Flags().StringVarP(&opts.password, "password", "P", "", "password (or use config file)")
+	cmd.Flags().BoolVarP(&opts.insecure, "insecure", "k", false, "skip TLS verification")
+	cmd.Flags().StringVar(&opts.origin, "origin", "", "gNMI origin for the paths (e.g., openconfig, eos_native)")
+	cmd.Flags().StringVar(&opts.encoding, "encoding", "", "encoding to request: json_ietf (default), json, proto, ascii, or bytes")
+	cmd.Flags().StringVar(&opts.prefix, "prefix", "", "gNMI path prefix for the request (default: from config)")
+	cmd.Flags().StringVar(&opts.format, "format", "", "break subtrees into leaves: flat (path = value), table, or tree")
+	cmd.Flags().BoolVar(&opts.stream, "stream", false, "subscribe and print updates as they change")
+	cmd.Flags().DurationVar(&opts.sample, "sample", 0, "with --stream, sample at this interval instead of on change")
+	cmd.Flags().BoolVar(&opts.once, "once", false, "subscribe once: print current values via Subscribe")
+	cmd.Flags().DurationVar(&opts.poll, "poll", 0, "poll the subscription at this interval")
+	cmd.MarkFlagsMutuallyExclusive("stream", "once", "poll")
+
+	return cmd
+}
+
+func runGet(target string, paths []string, opts getOptions) error {
+	username, password, insecure := opts.username, opts.password, opts.insecure
+
+	// Load config for credentials if not provided
+	cfg, err := loadConfigOrWarn()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	if err := promptCredentials(cfg); err != nil {
+		return err
+	}
+	if cfg != nil {
+		target = inventory.AddPort(target, cfg.Defaults.Port)
+	}
+	var conn config.Connection
+	if cfg != nil {
+		// Group membership enables per-group settings when an inventory is present
+		var groups []string
+		if inv, _, _ := inventory.AutoDiscover(); inv != nil {
+			groups = inv.GroupsFor(target)
+		}
+		conn = cfg.GetConnection(target, groups...)
+		var cfgInsecure bool
+		username, password, cfgInsecure = cfg.Credentials(target, username, password, groups...)
+		if !insecure {
+			insecure = cfgInsecure
+		}
+	}
+
+	clientCfg, err := runner.ClientConfig(gnmiclient.Config{
+		Address:  target,
+		Username: username,
+		Password: password,
+		Insecure: insecure,
+		Timeout:  timeout,
+	}, conn)
+	if err != nil {
+		return err
+	}
+	clientCfg.Origin = opts.origin
+	clientCfg.Encoding = opts.encoding
+	if opts.prefix != "" {
+		clientCfg.Prefix = opts.prefix
+	}
+
+	client, err := gnmiclient.NewClient(clientCfg)
+	if err != nil {
+		return fmt.Errorf("connect to %s: %w", target, err)
+	}
+	defer client.Close()
+
+	if opts.stream || opts.once || opts.poll > 0 {
+		return subscribeGet(client, target, paths, username, password, opts)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if opts.format != "" {
+		var leaves []gnmiclient.Update
+		for _, path := range paths {
+			updates, err := client.GetAll(ctx, path, username, password)
+			if err != nil {
+				return fmt.Errorf("get %s: %w", path, err)
+			}
+			if len(updates) == 0 {
+				fmt.Fprintf(os.Stderr, "%s: path does not exist\n", path)
+			}
+			for _, u := range updates {
+				leaves = append(leaves, gnmiclient.Flatten(u)...)
+			}
+		}
+		printLeaves(leaves, opts.format)
+		return nil
+	}
+
+	var results []map[string]interface{}
+	for i, path := range paths {
+		value, exists, err := client.Get(ctx, path, username, password)
+		if err != nil {
+			return fmt.Errorf("get %s: %w", path, err)
+		}
+
+		if output == "json" {
+			results = append(results, map[string]interface{}{
+				"target": target,
+				"path":   path,
+				"exists": exists,
+				"value":  value,
+			})
+			continue
+		}
+
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Path: %s\n", path)
+		if !exists {
+			fmt.Printf("Exists: false\n")
+			continue
+		}
+		fmt.Printf("Value: %s\n", value)
+	}
+
+	if output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		// A single path keeps the single-object form
+		if len(results) == 1 {
+			return enc.Encode(results[0])
+		}
+		return enc.Encode(results)
+	}
+	return nil
+}
+
+// subscribeGet prints the updates of a once, stream, or poll subscription.
+// Once subscriptions are bounded by --timeout, the others by an interrupt.
+func subscribeGet(client *gnmiclient.Client, target string, paths []string, username, password string, opts getOptions) error {
+	subOpts := gnmiclient.SubscribeOptions{Mode: gnmiclient.SubscribeStream, SampleInterval: opts.sample}
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	switch {
+	case opts.once:
+		subOpts.Mode = gnmiclient.SubscribeOnce
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	case opts.poll > 0:
+		subOpts.Mode = gnmiclient.SubscribePoll
+		subOpts.PollInterval = opts.poll
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	err := client.Subscribe(ctx, paths, subOpts, username, password, func(u gnmiclient.Update) error {
+		updates := []gnmiclient.Update{u}
+		if opts.format != "" {
+			updates = gnmiclient.Flatten(u)
+		}
+		for _, u := range updates {
+			if err := printUpdate(enc, target, u); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if opts.once && ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("subscribe: no sync from %s within %s", target, timeout)
+	}
+	return nil
+}
+
+// printUpdate prints a subscription update as a line of text or JSON
+func printUpdate(enc *json.Encoder, target string, u gnmiclient.Update) error {
+	// Devices may leave out timestamps
+	ts := u.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	if output == "json" {
+		out := map[string]interface{}{
+			"timestamp": ts.Format(time.RFC3339Nano),
+			"target":    target,
+			"path":      u.Path,
+		}
+		if u.Deleted {
+			out["deleted"] = true
+		} else {
+			out["value"] = u.Value.String()
+		}
+		return enc.Encode(out)
+	}
+	if u.Deleted {
+		fmt.Printf("%s %s (deleted)\n", ts.Format("15:04:05.000"), u.Path)
+		return nil
+	}
+	fmt.Printf("%s %s = %s\n", ts.Format("15:04:05.000"), u.Path, u.Value)
+	return nil
+}
+
+// printLeaves prints flattened leaves as path = value lines, an aligned
+// table, or a tree of path elements
+func printLeaves(leaves []gnmiclient.Update, format string) {
+	switch format {
+	case "flat":
+		for _, l := range leaves {
+			fmt.Printf("%s = %s\n", l.Path, l.Value)
+		}
+	case "table":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PATH\tVALUE")
+		for _, l := range leaves {
+			fmt.Fprintf(w, "%s\t%s\n", l.Path, l.Value)
+		}
+		w.Flush()
+	case "tree":
+		// Print each path element once, under the elements it shares with
+		// the previous leaf
+		var prev []string
+		for _, l := range leaves {
+			elems := pathElems(l.Path)
+			shared := 0
+			for shared < len(prev) && shared < len(elems)-1 && prev[shared] == elems[shared] {
+				shared++
+			}
+			for i := shared; i < len(elems)-1; i++ {
+				fmt.Printf("%s%s\n", strings.Repeat("  ", i), elems[i])
+			}
+			fmt.Printf("%s%s: %s\n", strings.Repeat("  ", len(elems)-1), elems[len(elems)-1], l.Value)
+			prev = elems
+		}
+	}
+}
+
+// pathElems splits a path into its elements, keys included
+func pathElems(path string) []string {
+	p, err := gnmiclient.ParsePath(path)
+	if err != nil || len(p.Elem) == 0 {
+		return []string{path}
+	}
+	elems := make([]string, len(p.Elem))
+	for i, e := range p.Elem {
+		elems[i] = strings.TrimPrefix(gnmiclient.PathString(&gnmi.Path{Elem: []*gnmi.PathElem{e}}), "/")
+	}
+	return elems
+}
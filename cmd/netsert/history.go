@@ -0,0 +1,112 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/history"
+	"github.com/spf13/cobra"
+)
+
+// historyFile returns the run history file: the flag if given, else the
+// configured one, else the default
+func historyFile(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	if cfg, err := loadConfig(); err == nil && cfg.Defaults.History != "" {
+		return cfg.Defaults.History
+	}
+	return history.DefaultFile
+}
+
+func historyCmd() *cobra.Command {
+	var file string
+	var last int
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List recorded runs",
+		Long: `List the runs recorded with run --history (or defaults.history in the
+config), oldest first, with their pass rates.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := historyFile(file)
+			records, err := history.Load(path)
+			if err != nil {
+				return err
+			}
+			if len(records) == 0 {
+				return fmt.Errorf("no runs recorded in %s; record runs with netsert run --history %s", path, path)
+			}
+			if last > 0 && len(records) > last {
+				records = records[len(records)-last:]
+			}
+
+			for _, rec := range records {
+				fmt.Printf("%s  %5.1f%%  %d/%d passed, %d failed, %d errors  %s  %s\n",
+					rec.Time.Local().Format(time.DateTime), rec.PassRate(), rec.Passed, rec.Total,
+					rec.Failed, rec.Errors, rec.Duration.Round(time.Millisecond), rec.File)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "history file (default: defaults.history in the config, or "+history.DefaultFile+")")
+	cmd.Flags().IntVarP(&last, "last", "n", 20, "show only the latest N runs (0 for all)")
+	return cmd
+}
+
+func trendsCmd() *cobra.Command {
+	var file string
+	var top int
+
+	cmd := &cobra.Command{
+		Use:   "trends",
+		Short: "Show pass rate over time and the assertions that regress most",
+		Long: `Summarize the recorded run history: the pass rate per day, and the
+assertions that most often went from passing to failing.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := historyFile(file)
+			records, err := history.Load(path)
+			if err != nil {
+				return err
+			}
+			if len(records) == 0 {
+				return fmt.Errorf("no runs recorded in %s; record runs with netsert run --history %s", path, path)
+			}
+
+			fmt.Println("Pass rate by day:")
+			for _, d := range history.Daily(records) {
+				fmt.Printf("  %s  %5.1f%%  (%d runs)\n", d.Date, d.PassRate(), d.Runs)
+			}
+
+			var regressing []history.Trend
+			for _, t := range history.Trends(records) {
+				if t.Regressions > 0 {
+					regressing = append(regressing, t)
+				}
+			}
+			if len(regressing) == 0 {
+				fmt.Println("\nNo regressions")
+				return nil
+			}
+			if top > 0 && len(regressing) > top {
+				regressing = regressing[:top]
+			}
+			fmt.Println("\nMost regressions:")
+			for _, t := range regressing {
+				fmt.Printf("  %3d  %5.1f%% pass  now %-11s  %s @ %s (last failed %s)\n",
+					t.Regressions, t.PassRate(), cmp.Or(string(t.Last), "-"), t.Name, t.Target,
+					t.LastFailed.Local().Format(time.DateTime))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "history file (default: defaults.history in the config, or "+history.DefaultFile+")")
+	cmd.Flags().IntVarP(&top, "top", "n", 10, "show the N assertions with the most regressions (0 for all)")
+	return cmd
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// junitSuites is a JUnit XML report: a suite per target, a case per assertion
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     float64      `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     float64     `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	Skipped   *junitProblem `xml:"skipped,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnitReport writes the report as JUnit XML for CI test reporting.
+// Failures are failures; skipped and blocked assertions are skipped; any
+// other status is an error.
+func writeJUnitReport(w io.Writer, report JSONOutput) error {
+	suites := junitSuites{Name: "netsert", Time: seconds(report.Summary.Duration)}
+	index := make(map[string]int)
+	for _, t := range report.Targets {
+		index[t.Target] = len(suites.Suites)
+		suites.Suites = append(suites.Suites, junitSuite{Name: t.Target, Time: seconds(t.Duration)})
+	}
+
+	for _, res := range report.Results {
+		i, ok := index[res.Target]
+		if !ok {
+			index[res.Target] = len(suites.Suites)
+			i = len(suites.Suites)
+			suites.Suites = append(suites.Suites, junitSuite{Name: res.Target})
+		}
+		suite := &suites.Suites[i]
+
+		tc := junitCase{Name: res.Name, Classname: res.Target, File: res.File, Time: seconds(res.Duration)}
+		if res.ShortPath != "" && res.Name == res.Path {
+			// Named after the path; the detail keeps it in full
+			tc.Name = res.ShortPath
+		}
+		problem := &junitProblem{Type: res.Status, Message: res.Error, Text: junitDetail(res)}
+		switch res.Status {
+		case "pass":
+		case "fail":
+			if problem.Message == "" {
+				problem.Message = "assertion failed"
+			}
+			tc.Failure = problem
+			suite.Failures++
+		case "skipped", "blocked":
+			tc.Skipped = problem
+			suite.Skipped++
+		default:
+			tc.Error = problem
+			suite.Errors++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	for _, s := range suites.Suites {
+		suites.Tests += s.Tests
+		suites.Failures += s.Failures
+		suites.Errors += s.Errors
+		suites.Skipped += s.Skipped
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitDetail describes what an assertion checked and got
+func junitDetail(res JSONResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "path: %s\n", res.Path)
+	if res.Expected != "" {
+		fmt.Fprintf(&b, "expected: %s\n", res.Expected)
+	}
+	if res.Actual != "" {
+		fmt.Fprintf(&b, "actual: %s\n", res.Actual)
+	}
+	if res.Timestamp != "" {
+		fmt.Fprintf(&b, "reported: %s\n", res.Timestamp)
+	}
+	if res.Diff != "" {
+		fmt.Fprintf(&b, "diff: %s\n", res.Diff)
+	}
+	return b.String()
+}
+
+// seconds converts a report duration to JUnit's fractional seconds
+func seconds(d string) float64 {
+	parsed, err := time.ParseDuration(d)
+	if err != nil {
+		return 0
+	}
+	return parsed.Seconds()
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ndtobs/netsert/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func loginCmd() *cobra.Command {
+	var username string
+
+	cmd := &cobra.Command{
+		Use:   "login <target|@group>",
+		Short: "Save credentials in the OS keyring",
+		Long: `Prompt for credentials and store them in the system keychain
+(macOS Keychain, Windows Credential Manager, or the Secret Service on Linux).
+
+Stored credentials are used at run time for targets without credentials in
+the assertion file or netsert.yaml. A @group entry applies to every member
+of that inventory group.
+
+Examples:
+  netsert login spine1:6030
+  netsert login @spines -u admin`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key := args[0]
+
+			user, err := promptLine("Username", username)
+			if err != nil {
+				return err
+			}
+			if user == "" {
+				return fmt.Errorf("username is required")
+			}
+			pass, err := promptPassword("Password")
+			if err != nil {
+				return err
+			}
+
+			if err := config.SaveKeyringCredentials(key, user, pass); err != nil {
+				return fmt.Errorf("save to keyring: %w", err)
+			}
+			fmt.Printf("✓ Saved credentials for %s\n", key)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&username, "username", "u", "", "username (prompted if not set)")
+
+	return cmd
+}
+
+func logoutCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout <target|@group>",
+		Short: "Remove credentials from the OS keyring",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.DeleteKeyringCredentials(args[0]); err != nil {
+				return fmt.Errorf("remove from keyring: %w", err)
+			}
+			fmt.Printf("✓ Removed credentials for %s\n", args[0])
+			return nil
+		},
+	}
+}
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"maps"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -15,8 +21,11 @@ import (
 	"github.com/ndtobs/netsert/pkg/config"
 	"github.com/ndtobs/netsert/pkg/generate"
 	"github.com/ndtobs/netsert/pkg/gnmiclient"
+	"github.com/ndtobs/netsert/pkg/history"
 	"github.com/ndtobs/netsert/pkg/inventory"
+	"github.com/ndtobs/netsert/pkg/netsert"
 	"github.com/ndtobs/netsert/pkg/runner"
+	"github.com/ndtobs/netsert/pkg/schema"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -27,13 +36,63 @@ var (
 	// Global flags
 	verbose bool
 	timeout time.Duration
-	output  string
+	output  = "text"
+	askPass bool
+	askUser bool
+	profile string
+	cfgFile string // --config, replacing config discovery
+
+	// timeoutSet records whether --timeout was given, so config defaults don't override it
+	timeoutSet bool
 )
 
 // JSONOutput is the structure for JSON output
 type JSONOutput struct {
 	Summary JSONSummary  `json:"summary"`
+	Targets []JSONTarget `json:"targets"`
 	Results []JSONResult `json:"results"`
+	Flaky   []JSONFlaky  `json:"flaky,omitempty"` // With --repeat or --for
+	Soak    []JSONSoak   `json:"soak,omitempty"`  // With --for
+}
+
+// JSONSoak is one assertion's outcome over the runs of a --for soak test
+type JSONSoak struct {
+	Target      string `json:"target"`
+	Name        string `json:"name"`
+	Path        string `json:"path,omitempty"`
+	Runs        int    `json:"runs"`
+	Passed      int    `json:"passed"`
+	Worst       string `json:"worst"`       // Most severe status seen
+	Transitions int    `json:"transitions"` // Status changes between runs
+}
+
+// JSONFlaky is an assertion with inconsistent outcomes across --repeat runs
+type JSONFlaky struct {
+	Target      string  `json:"target"`
+	Name        string  `json:"name"`
+	Path        string  `json:"path,omitempty"`
+	Runs        int     `json:"runs"`
+	Passed      int     `json:"passed"`
+	FailureRate float64 `json:"failure_rate"` // Percent of runs not passing
+}
+
+// JSONTarget is one target's outcome
+type JSONTarget struct {
+	Target    string `json:"target"`
+	Connected bool   `json:"connected"`
+	Error     string `json:"error,omitempty"` // Connection error
+	Duration  string `json:"duration"`
+	Total     int    `json:"total"`
+	Passed    int    `json:"passed"`
+	Failed    int    `json:"failed"`
+	Errors    int    `json:"errors"`
+	Skipped   int    `json:"skipped,omitempty"`
+	Blocked   int    `json:"blocked,omitempty"`
+
+	RPCs       int64  `json:"rpcs"`                  // gNMI requests, including retries
+	RPCLatency string `json:"rpc_latency,omitempty"` // Average per request
+
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 type JSONSummary struct {
@@ -42,18 +101,35 @@ type JSONSummary struct {
 	Passed   int    `json:"passed"`
 	Failed   int    `json:"failed"`
 	Errors   int    `json:"errors"`
+	Skipped  int    `json:"skipped,omitempty"`
+	Blocked  int    `json:"blocked,omitempty"`
 	Duration string `json:"duration"`
-	Success  bool   `json:"success"`
+	Success  bool   `json:"success"` // Also when failures are within --max-fail limits
+
+	FailedTargets []string `json:"failed_targets,omitempty"`
+	Interrupted   bool     `json:"interrupted,omitempty"` // Stopped early; skipped assertions weren't run
 }
 
 type JSONResult struct {
 	Target   string `json:"target"`
 	Name     string `json:"name"`
 	Path     string `json:"path"`
-	Status   string `json:"status"` // "pass", "fail", "error"
+	File     string `json:"file,omitempty"` // Assertion file, when several were run
+	Status   string `json:"status"`         // "pass", "fail", "error", "timeout", "unauthenticated", "unsupported", "skipped"
 	Actual   string `json:"actual,omitempty"`
 	Expected string `json:"expected,omitempty"`
 	Error    string `json:"error,omitempty"`
+	Diff     string `json:"diff,omitempty"`
+	Duration string `json:"duration,omitempty"`
+
+	// The path in short form, with --compact-paths
+	ShortPath string `json:"short_path,omitempty"`
+
+	// When the device reported the actual value (gNMI notification
+	// timestamp, RFC 3339), rather than when netsert fetched it
+	Timestamp string `json:"timestamp,omitempty"`
+
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 func main() {
@@ -61,57 +137,197 @@ func main() {
 		Use:     "netsert",
 		Short:   "Declarative network state assertions using gNMI",
 		Version: version,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			timeoutSet = cmd.Flags().Changed("timeout")
+			if len(outputSinks) > 0 && cmd.Name() != "run" {
+				return fmt.Errorf("-o %s: report files are written by run only", outputSinks[0])
+			}
+			return nil
+		},
 	}
 
+	cobra.AddTemplateFunc("versionText", versionText)
+	rootCmd.SetVersionTemplate(`{{versionText}}`)
+
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
 	rootCmd.PersistentFlags().DurationVarP(&timeout, "timeout", "t", 30*time.Second, "timeout per assertion")
-	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", "text", "output format (text, json)")
+	rootCmd.PersistentFlags().VarP(outputFlag{}, "output", "o", "output format ("+strings.Join(outputFormats, ", ")+"); repeat with format=path to also write reports from run ("+strings.Join(reportFormats(), ", ")+")")
+	rootCmd.PersistentFlags().BoolVar(&askPass, "ask-pass", false, "prompt for a password used by targets without explicit credentials")
+	rootCmd.PersistentFlags().BoolVar(&askUser, "ask-user", false, "prompt for a username used by targets without explicit credentials")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file to use instead of netsert.yaml, ~/.netsert.yaml, ...")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "config profile to use (default: $NETSERT_PROFILE)")
 
 	rootCmd.AddCommand(runCmd())
 	rootCmd.AddCommand(validateCmd())
 	rootCmd.AddCommand(getCmd())
 	rootCmd.AddCommand(generateCmd())
+	rootCmd.AddCommand(loginCmd())
+	rootCmd.AddCommand(logoutCmd())
+	rootCmd.AddCommand(pathsCmd())
+	rootCmd.AddCommand(historyCmd())
+	rootCmd.AddCommand(trendsCmd())
+	rootCmd.AddCommand(versionCmd())
+	rootCmd.AddCommand(schemaCmd())
 
 	if err := rootCmd.Execute(); err != nil {
+		var exit exitError
+		if errors.As(err, &exit) {
+			os.Exit(exit.code)
+		}
 		os.Exit(1)
 	}
 }
 
+// runOptions holds the flags for the run command
+type runOptions struct {
+	workers       int
+	parallel      int
+	failFast      bool
+	inventoryFile string
+	group         string
+	groupBy       string
+	slowest       int
+	rateLimit     float64
+	against       string
+	maxFail       int
+	history       string
+	maxFailPct    float64
+	saveSnapshot  string
+	globalRate    float64
+	rerunFailed   string
+	repeat        int
+	soakFor       time.Duration
+	interval      time.Duration
+	reportFiles   []string
+	compactPaths  bool
+	updateExp     bool
+	record        string
+	replay        string
+
+	// Whether concurrency flags were given explicitly (otherwise config defaults apply)
+	workersSet  bool
+	parallelSet bool
+
+	rerunSet bool // --rerun-failed was given, perhaps empty
+}
+
 func runCmd() *cobra.Command {
-	var (
-		workers       int
-		parallel      int
-		failFast      bool
-		inventoryFile string
-		group         string
-	)
+	var opts runOptions
 
 	cmd := &cobra.Command{
-		Use:   "run <assertions.yaml>",
+		Use:   "run <assertions.yaml|dir>...",
 		Short: "Run assertions against targets",
-		Args:  cobra.ExactArgs(1),
+		Long: `Run assertions against targets.
+
+Several files, or directories of them (every .yaml file beneath with
+targets, all, groups, or templates), run as one suite, e.g. one file per
+protocol. Targets listed in several files are merged; failures name the
+file they came from.`,
+		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runAssertions(args[0], workers, parallel, failFast, inventoryFile, group)
+			opts.workersSet = cmd.Flags().Changed("workers")
+			opts.parallelSet = cmd.Flags().Changed("parallel")
+			opts.rerunSet = cmd.Flags().Changed("rerun-failed")
+			err := runAssertions(args, opts)
+			if errors.As(err, new(exitError)) {
+				// The report already says why
+				cmd.SilenceErrors = true
+				cmd.SilenceUsage = true
+			}
+			return err
 		},
 	}
 
-	cmd.Flags().IntVarP(&workers, "workers", "w", runner.DefaultWorkers, "number of concurrent targets")
-	cmd.Flags().IntVarP(&parallel, "parallel", "p", runner.DefaultParallel, "number of parallel assertions per target")
-	cmd.Flags().BoolVar(&failFast, "fail-fast", false, "stop on first failure")
-	cmd.Flags().StringVarP(&inventoryFile, "inventory", "i", "", "inventory file (YAML or INI format)")
-	cmd.Flags().StringVarP(&group, "group", "g", "", "run only against hosts in this group")
+	cmd.Flags().IntVarP(&opts.workers, "workers", "w", runner.DefaultWorkers, "number of concurrent targets")
+	cmd.Flags().IntVarP(&opts.parallel, "parallel", "p", runner.DefaultParallel, "number of parallel assertions per target")
+	cmd.Flags().BoolVar(&opts.failFast, "fail-fast", false, "stop at the first failure, reporting the remaining assertions as skipped")
+	cmd.Flags().StringVarP(&opts.inventoryFile, "inventory", "i", "", "inventory file (YAML or INI format)")
+	cmd.Flags().StringVarP(&opts.group, "group", "g", "", "run only against hosts in this group")
+	cmd.Flags().StringVar(&opts.groupBy, "group-by", "", "summarize results by a target metadata key (e.g., site)")
+	cmd.Flags().StringVar(&opts.rerunFailed, "rerun-failed", "", "run only the assertions that didn't pass in the run recorded in this failure manifest, then record this run there (empty: the last run of the same files)")
+	cmd.Flags().IntVar(&opts.repeat, "repeat", 1, "run every assertion N times and report those with inconsistent outcomes")
+	cmd.Flags().DurationVar(&opts.soakFor, "for", 0, "soak test: rerun the suite for this long (e.g., 1h), then report worst results and transitions")
+	cmd.Flags().DurationVar(&opts.interval, "interval", 30*time.Second, "time between runs with --for")
+	cmd.Flags().StringVar(&opts.history, "history", "", "record this run to a history file, for netsert history and trends")
+	cmd.Flags().IntVar(&opts.maxFail, "max-fail", 0, "exit zero if no more than N targets fail (failures are still reported)")
+	cmd.Flags().Float64Var(&opts.maxFailPct, "max-fail-percent", 0, "exit zero if no more than this percentage of targets fail")
+	cmd.Flags().StringVar(&opts.saveSnapshot, "save-snapshot", "", "record the value of every assertion path to this file, for a later --against")
+	cmd.Flags().StringVar(&opts.against, "against", "", "compare every assertion path with a --save-snapshot file instead of its expected value, reporting drift")
+	cmd.Flags().Float64Var(&opts.rateLimit, "rate-limit", 0, "max RPCs per second to each target address (overrides config rate_limit)")
+	cmd.Flags().Float64Var(&opts.globalRate, "global-rate-limit", 0, "max RPCs per second across all targets")
+	cmd.Flags().StringArrayVar(&opts.reportFiles, "report-file", nil, "also write a report to this file, as [format=]path (format from the extension, e.g. out.json); repeatable")
+	cmd.Flags().IntVar(&opts.slowest, "slowest", 0, "list the N slowest assertions and targets (by average gNMI latency)")
+	cmd.Flags().StringVar(&opts.record, "record", "", "record every gNMI response to this directory, for a later --replay")
+	cmd.Flags().StringVar(&opts.replay, "replay", "", "answer assertions from responses recorded with --record, without connecting to devices")
+	cmd.MarkFlagsMutuallyExclusive("record", "replay")
+	cmd.Flags().BoolVar(&opts.updateExp, "update-expected", false, "offer to rewrite failed equals: assertions in their files with the values observed")
+	cmd.Flags().BoolVar(&opts.compactPaths, "compact-paths", false, "show paths in short form (e.g., interface[Ethernet1]/...) in results; verbose output and reports keep the full path")
 
 	return cmd
 }
 
 func validateCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "validate <assertions.yaml>",
+	var (
+		models        string
+		showPaths     bool
+		inventoryFile string
+		group         string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "validate <assertions.yaml|dir>...",
 		Short: "Validate assertion file syntax",
-		Args:  cobra.ExactArgs(1),
+		Long: `Validate assertion file syntax.
+
+Unknown keys, assertions with no check or checks that don't combine,
+invalid regexes, non-numeric thresholds, and missing golden files
+(equals_file, equals_json_file) are errors, as they are for run.
+
+@group targets are resolved against the inventory (-i, the config's, or
+one discovered), reporting groups it lacks as errors, and empty groups and
+targets -g would skip as warnings.
+
+With --show-paths, each assertion's path is listed as written and as
+expanded for its target's platform, flagging short paths that match no
+known prefix and were only given a leading slash.
+
+With --models, each expanded path is also checked against a directory of
+YANG models (e.g., a checkout of openconfig/public), warning about typos,
+wrong list keys, and config-vs-state mistakes without contacting a device.
+Paths under native origins (eos_native:/, srl_nokia:/) aren't checked.`,
+		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			af, err := assertion.LoadFile(args[0])
+			cfg, cfgErr := loadConfig()
+			af, err := assertion.LoadFiles(args...)
+			if err != nil {
+				return err
+			}
+
+			var warnings []string
+			if cfgErr != nil {
+				warnings = append(warnings, fmt.Sprintf("config not used: %v", cfgErr))
+			} else if inventoryFile == "" {
+				inventoryFile = cfg.Defaults.Inventory
+			}
+			inv, err := resolveInventory(af, inventoryFile, group)
 			if err != nil {
+				if inventoryFile != "" || group != "" {
+					return err
+				}
+				// Without an inventory, count what applies to every target
+				warnings = append(warnings, fmt.Sprintf("%v; @group targets were not checked", err))
+			}
+			if inv != nil {
+				errs, inventoryWarnings := netsert.CheckInventory(af, inv, group)
+				warnings = append(warnings, inventoryWarnings...)
+				if len(errs) > 0 {
+					for _, e := range errs {
+						fmt.Fprintf(os.Stderr, "✗ %s\n", e)
+					}
+					return fmt.Errorf("%d inventory reference(s) can't be resolved", len(errs))
+				}
+			}
+			if af, err = netsert.Prepare(af, inv, group); err != nil {
 				return err
 			}
 
@@ -120,84 +336,152 @@ func validateCmd() *cobra.Command {
 				totalAssertions += len(t.Assertions)
 			}
 
+			if models != "" {
+				s, err := schema.Load(models)
+				if err != nil {
+					return err
+				}
+				for _, t := range af.Targets {
+					for _, a := range t.Assertions {
+						if a.Path == "" {
+							continue
+						}
+						if err := s.Check(a.Path); err != nil {
+							warnings = append(warnings, fmt.Sprintf("%s @ %s: %v", a.GetName(), t.GetHost(), err))
+						}
+					}
+				}
+			}
+
+			var previews []pathPreview
+			if showPaths {
+				previews = previewPaths(af)
+			}
+
 			if output == "json" {
 				out := map[string]interface{}{
 					"valid":      true,
 					"targets":    len(af.Targets),
 					"assertions": totalAssertions,
 				}
+				if len(warnings) > 0 {
+					out["warnings"] = warnings
+				}
+				if showPaths {
+					out["paths"] = previews
+				}
 				enc := json.NewEncoder(os.Stdout)
 				enc.SetIndent("", "  ")
 				return enc.Encode(out)
 			}
 
+			printPathPreview(previews)
+			for _, w := range warnings {
+				fmt.Printf("⚠ %s\n", w)
+			}
 			fmt.Printf("✓ Valid: %d targets, %d assertions\n", len(af.Targets), totalAssertions)
 			return nil
 		},
 	}
+
+	cmd.Flags().StringVar(&models, "models", "", "directory of YANG models to check paths against")
+	cmd.Flags().StringVarP(&inventoryFile, "inventory", "i", "", "inventory file to resolve @group targets against")
+	cmd.Flags().StringVarP(&group, "group", "g", "", "check as run -g would: only hosts in this group")
+	cmd.Flags().BoolVar(&showPaths, "show-paths", false, "list each assertion's path as written and as expanded")
+
+	return cmd
 }
 
-func runAssertions(path string, workers, parallel int, failFast bool, inventoryFile, group string) error {
-	af, err := assertion.LoadFile(path)
+func runAssertions(paths []string, opts runOptions) error {
+	inventoryFile, group := opts.inventoryFile, opts.group
+	path := strings.Join(paths, ", ")
+
+	if opts.soakFor > 0 && opts.repeat > 1 {
+		return fmt.Errorf("--for and --repeat can't be combined")
+	}
+	if opts.soakFor > 0 && opts.interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	if opts.updateExp && (opts.repeat > 1 || opts.soakFor > 0 || opts.against != "") {
+		return fmt.Errorf("--update-expected can't be combined with --repeat, --for, or --against")
+	}
+	if opts.updateExp && output == "json" {
+		return fmt.Errorf("--update-expected applies to text output")
+	}
+
+	reportFiles, err := parseReportFiles(append(slices.Clone(opts.reportFiles), outputSinks...))
+	if err != nil {
+		return err
+	}
+
+	// Load config (credentials, defaults, short paths) before the
+	// assertions that may use its short paths
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	if err := promptCredentials(cfg); err != nil {
+		return err
+	}
+
+	af, err := assertion.LoadFiles(paths...)
 	if err != nil {
 		return fmt.Errorf("load assertions: %w", err)
 	}
+	if inventoryFile == "" {
+		inventoryFile = cfg.Defaults.Inventory
+	}
+	workers, parallel := opts.workers, opts.parallel
+	if !opts.workersSet && cfg.Defaults.Workers > 0 {
+		workers = cfg.Defaults.Workers
+	}
+	if !opts.parallelSet && cfg.Defaults.Parallel > 0 {
+		parallel = cfg.Defaults.Parallel
+	}
 
 	// Normalize group name (strip @ prefix if present)
 	group = strings.TrimPrefix(group, "@")
 
-	// Check if assertion file contains @group references
-	hasGroupRefs := false
-	for _, target := range af.Targets {
-		if strings.HasPrefix(target.GetHost(), "@") {
-			hasGroupRefs = true
-			break
-		}
+	inv, err := resolveInventory(af, inventoryFile, group)
+	if err != nil {
+		return err
 	}
 
-	// Load inventory
-	var inv *inventory.Inventory
-	if inventoryFile != "" {
-		// Explicit inventory file provided
-		inv, err = inventory.Load(inventoryFile)
-		if err != nil {
-			return fmt.Errorf("load inventory: %w", err)
-		}
-	} else if hasGroupRefs || group != "" {
-		// Auto-discover inventory if @group refs found or -g flag used
-		var invPath string
-		inv, invPath, err = inventory.AutoDiscover()
-		if err != nil {
-			return fmt.Errorf("auto-discover inventory: %w", err)
-		}
-		if inv == nil {
-			if hasGroupRefs {
-				return fmt.Errorf("assertion file contains @group references but no inventory found - create inventory.yaml or pass -i")
-			}
-			return fmt.Errorf("--group/-g requires an inventory file - create inventory.yaml or pass -i")
-		}
-		if output != "json" {
-			fmt.Printf("Using inventory: %s\n", invPath)
-		}
+	// Expand group references if inventory is available, then shared
+	// assertions, templates and when: conditions
+	af, err = netsert.Prepare(af, inv, group)
+	if err != nil {
+		return err
 	}
 
-	// Expand group references if inventory is available
-	if inv != nil {
-		af = expandInventoryGroups(af, inv, group)
-
-		// Check if filtering resulted in no targets
-		if len(af.Targets) == 0 {
-			if group != "" {
-				return fmt.Errorf("no targets match group %q - check that assertion file uses @group syntax or hosts are in the group", group)
+	// Each run records what didn't pass, by default in the user cache
+	// directory under a name for these files
+	manifest := opts.rerunFailed
+	if manifest == "" {
+		if manifest, err = lastRunPath(paths); err != nil {
+			if opts.rerunSet {
+				return fmt.Errorf("--rerun-failed: %w", err)
 			}
-			return fmt.Errorf("no targets found after expanding inventory groups")
+			fmt.Fprintf(os.Stderr, "Warning: record failures for --rerun-failed: %v\n", err)
 		}
 	}
-
-	// Load config (credentials, defaults)
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("load config: %w", err)
+	if opts.rerunSet {
+		m, err := loadFailureManifest(manifest)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			// The first run of an iterate-and-fix loop, e.g. in CI
+			fmt.Fprintf(os.Stderr, "No previous run recorded in %s; running every assertion\n", manifest)
+		case err != nil:
+			return err
+		default:
+			keepFailed(af, m, cfg.Defaults.Port)
+			if len(af.Targets) == 0 {
+				if output != "json" {
+					fmt.Printf("No failed assertions in %s to rerun\n", manifest)
+				}
+				return nil
+			}
+		}
 	}
 
 	// Apply inventory defaults to config if available
@@ -216,39 +500,130 @@ func runAssertions(path string, workers, parallel int, failFast bool, inventoryF
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Handle interrupt
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigCh
-		fmt.Fprintln(os.Stderr, "\nInterrupted, stopping...")
-		cancel()
-	}()
-
 	// For JSON output, suppress text output from runner
 	var runnerOutput io.Writer = os.Stdout
 	if output == "json" {
 		runnerOutput = io.Discard
 	}
 
-	r := runner.NewRunner(runnerOutput)
-	r.Timeout = timeout
-	r.Workers = workers
-	r.Parallel = parallel
-	r.Verbose = verbose
-	r.Config = cfg
+	r := netsert.NewRunner(netsert.Options{
+		Config:          cfg,
+		Timeout:         timeout,
+		Workers:         workers,
+		Parallel:        parallel,
+		FailFast:        opts.failFast,
+		RateLimit:       opts.rateLimit,
+		GlobalRateLimit: cmp.Or(opts.globalRate, cfg.Defaults.GlobalRateLimit),
+		Output:          runnerOutput,
+		Verbose:         verbose,
+		CompactPaths:    opts.compactPaths,
+	})
+	if opts.saveSnapshot != "" {
+		r.Capture = runner.NewSnapshot()
+	}
+	if opts.record != "" {
+		r.Fixtures = gnmiclient.RecordFixtures(opts.record)
+	}
+	if opts.replay != "" {
+		if r.Fixtures, err = gnmiclient.ReplayFixtures(opts.replay); err != nil {
+			return err
+		}
+	}
+	if opts.against != "" {
+		if r.Against, err = runner.LoadSnapshot(opts.against); err != nil {
+			return err
+		}
+	}
+
+	// The first interrupt lets assertions in flight finish and reports the
+	// rest as skipped; a second abandons them
+	interrupted := make(chan struct{})
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		fmt.Fprintln(os.Stderr, "\nInterrupted, finishing assertions in flight (interrupt again to abort)...")
+		r.Stop()
+		close(interrupted)
+		<-sigCh
+		fmt.Fprintln(os.Stderr, "\nAborting...")
+		cancel()
+	}()
 
 	if output != "json" {
-		fmt.Printf("Running assertions from %s\n\n", path)
+		if r.Against != nil {
+			fmt.Printf("Comparing assertion paths from %s with %s (taken %s)\n\n", path, opts.against, r.Against.Taken.Format(time.DateTime))
+		} else if opts.replay != "" {
+			fmt.Printf("Running assertions from %s against the responses in %s\n\n", path, opts.replay)
+		} else {
+			fmt.Printf("Running assertions from %s\n\n", path)
+		}
 	}
 
-	result, err := r.Run(ctx, af)
+	// Repeated runs print a line per run rather than every result, and
+	// share connections
+	repeated := opts.repeat > 1 || opts.soakFor > 0
+	if repeated {
+		r.Output = io.Discard
+		r.Pool = gnmiclient.NewPool(0)
+		defer r.Pool.Close()
+	}
+	runs, err := repeatRuns(ctx, interrupted, r, af, opts)
 	if err != nil {
 		return err
 	}
+	result := runs[len(runs)-1]
+	if r.Capture != nil {
+		if err := r.Capture.Save(opts.saveSnapshot); err != nil {
+			return fmt.Errorf("save snapshot: %w", err)
+		}
+		if output != "json" {
+			fmt.Printf("Saved %d values to %s\n", len(r.Capture.Values), opts.saveSnapshot)
+		}
+	}
+	if opts.record != "" {
+		if err := r.Fixtures.Save(); err != nil {
+			return fmt.Errorf("save fixtures: %w", err)
+		}
+		if output != "json" {
+			fmt.Printf("Recorded %d gNMI responses to %s\n", r.Fixtures.Len(), opts.record)
+		}
+	}
+	var soak, flaky []runner.AssertionHistory
+	if opts.soakFor > 0 {
+		soak = runner.History(runs)
+	}
+	if repeated {
+		flaky = runner.Flaky(runs)
+	}
+	for _, run := range runs {
+		for _, err := range run.HookErrors {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	if manifest != "" {
+		if err := writeFailureManifest(manifest, suiteFiles(paths), result); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: record failures for --rerun-failed: %v\n", err)
+		}
+	}
+	if historyPath := cmp.Or(opts.history, cfg.Defaults.History); historyPath != "" {
+		for _, run := range runs {
+			if err := history.Append(historyPath, history.NewRecord(path, run)); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: record history: %v\n", err)
+				break
+			}
+		}
+	}
+
+	report := jsonReport(path, result, flaky, soak, opts, r.Stopped())
+	for _, rf := range reportFiles {
+		if err := rf.write(report); err != nil {
+			return fmt.Errorf("write report: %w", err)
+		}
+	}
 
 	if output == "json" {
-		return outputJSON(path, result)
+		return outputJSON(report, result, flaky, soak, opts)
 	}
 
 	// Text output
@@ -260,91 +635,476 @@ func runAssertions(path string, workers, parallel int, failFast bool, inventoryF
 	if result.Errors > 0 {
 		fmt.Printf("  Errors: %d\n", result.Errors)
 	}
+	if result.Skipped > 0 {
+		reason := "--fail-fast"
+		if r.Stopped() {
+			reason = "interrupted"
+		}
+		fmt.Printf("  Skipped: %d (%s)\n", result.Skipped, reason)
+	}
+	if result.Blocked > 0 {
+		fmt.Printf("  Blocked: %d (depends_on didn't pass)\n", result.Blocked)
+	}
+	var unreachable []string
+	for _, tr := range result.Targets {
+		if !tr.Connected() {
+			unreachable = append(unreachable, tr.Target)
+		}
+	}
+	if len(unreachable) > 0 {
+		fmt.Printf("  Unreachable: %s\n", strings.Join(unreachable, ", "))
+	}
+	if r.Against != nil {
+		printDrift(result)
+	}
+	if opts.groupBy != "" {
+		printGroupSummary(result.Results, opts.groupBy)
+	}
+	if opts.slowest > 0 {
+		printSlowest(result, opts.slowest)
+	}
+	if opts.soakFor > 0 {
+		printSoak(soak, len(runs))
+	}
+	if repeated {
+		printFlaky(flaky, len(runs))
+	}
 
-	if result.Failed > 0 || result.Errors > 0 {
-		os.Exit(1)
+	if opts.updateExp && !r.Stopped() {
+		if err := updateExpected(paths, af, result, cfg.Defaults.Port); err != nil {
+			return err
+		}
 	}
 
-	return nil
-}
+	if tolerated(result, opts) {
+		fmt.Printf("\nTolerated %d failed of %d targets: %s\n", len(result.FailedTargets()), len(result.Targets), strings.Join(result.FailedTargets(), ", "))
+	}
 
-// expandInventoryGroups expands group references in assertion file targets
-func expandInventoryGroups(af *assertion.AssertionFile, inv *inventory.Inventory, filterGroup string) *assertion.AssertionFile {
-	var newTargets []assertion.Target
+	return runExitError(r.Stopped(), result, flaky, soak, opts)
+}
 
+// resolveInventory loads the inventory given with -i, or discovers one when
+// the file references groups or -g is used. It returns nil if there is none
+// to use.
+func resolveInventory(af *assertion.AssertionFile, inventoryFile, group string) (*inventory.Inventory, error) {
+	// Check if assertion file contains @group references or groups: sections
+	hasGroupRefs := len(af.Groups) > 0
 	for _, target := range af.Targets {
-		// Check if this target references a group (starts with @)
 		if strings.HasPrefix(target.GetHost(), "@") {
-			groupName := strings.TrimPrefix(target.GetHost(), "@")
-			hosts, ok := inv.GetGroup(groupName)
-			if !ok {
-				// Group not found, keep as-is (will fail later with connection error)
-				newTargets = append(newTargets, target)
-				continue
-			}
+			hasGroupRefs = true
+			break
+		}
+	}
+
+	if inventoryFile != "" {
+		// Explicit inventory file provided
+		inv, err := inventory.Load(inventoryFile)
+		if err != nil {
+			return nil, fmt.Errorf("load inventory: %w", err)
+		}
+		return inv, nil
+	}
+	if !hasGroupRefs && group == "" {
+		return nil, nil
+	}
+
+	// Auto-discover inventory if @group refs found or -g flag used
+	inv, invPath, err := inventory.AutoDiscover()
+	if err != nil {
+		return nil, fmt.Errorf("auto-discover inventory: %w", err)
+	}
+	if inv == nil {
+		if hasGroupRefs {
+			return nil, fmt.Errorf("assertion file contains @group references or groups: sections but no inventory found - create inventory.yaml or pass -i")
+		}
+		return nil, fmt.Errorf("--group/-g requires an inventory file - create inventory.yaml or pass -i")
+	}
+	if output != "json" {
+		fmt.Printf("Using inventory: %s\n", invPath)
+	}
+	return inv, nil
+}
 
-			// Create a target for each host in the group
-			for _, host := range hosts {
-				newTarget := target
-				newTarget.Host = inv.ResolveHost(host) // Resolve to address:port
-				newTarget.Address = ""                 // Clear deprecated field
-				newTargets = append(newTargets, newTarget)
+// passed reports whether a run succeeded: nothing failed, or no more
+// targets failed than --max-fail and --max-fail-percent allow
+func passed(result *runner.RunResult, opts runOptions) bool {
+	return (result.Failed == 0 && result.Errors == 0) || tolerated(result, opts)
+}
+
+// tolerated reports whether some targets failed, but within the limits
+// given. With both limits, both must hold.
+func tolerated(result *runner.RunResult, opts runOptions) bool {
+	if opts.maxFail <= 0 && opts.maxFailPct <= 0 {
+		return false
+	}
+	failed := len(result.FailedTargets())
+	if failed == 0 {
+		return false
+	}
+	if opts.maxFail > 0 && failed > opts.maxFail {
+		return false
+	}
+	if opts.maxFailPct > 0 && 100*float64(failed) > opts.maxFailPct*float64(len(result.Targets)) {
+		return false
+	}
+	return true
+}
+
+// printDrift lists the paths whose values changed since the --against snapshot
+func printDrift(result *runner.RunResult) {
+	var drifted []*assertion.Result
+	for _, tr := range result.Targets {
+		for _, res := range tr.Results {
+			if res.Diff != "" && res.GetStatus() == assertion.StatusFail {
+				drifted = append(drifted, res)
 			}
-		} else {
-			// Non-group target - still resolve through inventory if available
-			newTarget := target
-			newTarget.Host = inv.ResolveHost(target.GetHost())
-			newTarget.Address = ""
-			newTargets = append(newTargets, newTarget)
 		}
 	}
+	if len(drifted) == 0 {
+		fmt.Println("\nNo drift from snapshot")
+		return
+	}
+	fmt.Printf("\nDrift from snapshot (%d):\n", len(drifted))
+	for _, res := range drifted {
+		fmt.Printf("  %s %s: %s\n", res.Target, cmp.Or(res.ShortPath, res.Assertion.Path), res.Diff)
+	}
+}
+
+// exitInterrupted is the exit status of a run stopped by an interrupt,
+// after its partial report
+const exitInterrupted = 130
+
+// exitError makes main exit with code once the command has returned and
+// its deferred cleanup has run
+type exitError struct{ code int }
+
+func (e exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// runExitError returns the exit status for a finished run: interrupted,
+// failed (including flaky or ever-failing assertions), or nil if it passed
+func runExitError(interrupted bool, result *runner.RunResult, flaky, soak []runner.AssertionHistory, opts runOptions) error {
+	if interrupted {
+		return exitError{exitInterrupted}
+	}
+	if !passed(result, opts) || len(flaky) > 0 || everFailed(soak) {
+		return exitError{1}
+	}
+	return nil
+}
 
-	// Filter by group if specified
-	if filterGroup != "" {
-		hosts, ok := inv.GetGroup(filterGroup)
-		if ok {
-			// Build set of resolved addresses for hosts in the filter group
-			hostSet := make(map[string]bool)
-			for _, h := range hosts {
-				hostSet[inv.ResolveHost(h)] = true
+// repeatRuns runs the suite once, --repeat times, or every --interval
+// until --for has passed. An interrupt ends repeats early; the run it cut
+// short is dropped.
+func repeatRuns(ctx context.Context, interrupted <-chan struct{}, r *runner.Runner, af *assertion.AssertionFile, opts runOptions) ([]*runner.RunResult, error) {
+	start := time.Now()
+	var runs []*runner.RunResult
+	for i := 1; ; i++ {
+		run, err := r.Run(ctx, af)
+		if err != nil {
+			return nil, err
+		}
+		if r.Stopped() && len(runs) > 0 {
+			return runs, nil
+		}
+		runs = append(runs, run)
+
+		if output != "json" {
+			switch {
+			case opts.soakFor > 0:
+				fmt.Printf("Run %d (%s): %d passed, %d failed, %d errors\n", i, time.Since(start).Round(time.Second), run.Passed, run.Failed, run.Errors)
+			case opts.repeat > 1:
+				fmt.Printf("Run %d/%d: %d passed, %d failed, %d errors\n", i, opts.repeat, run.Passed, run.Failed, run.Errors)
 			}
+		}
 
-			var filtered []assertion.Target
-			for _, t := range newTargets {
-				if hostSet[t.GetHost()] {
-					filtered = append(filtered, t)
-				}
+		if opts.soakFor <= 0 {
+			if i >= opts.repeat || r.Stopped() {
+				return runs, nil
 			}
-			newTargets = filtered
+			continue
 		}
+		if time.Since(start)+opts.interval > opts.soakFor {
+			return runs, nil
+		}
+		select {
+		case <-interrupted:
+			return runs, nil
+		case <-time.After(opts.interval):
+		}
+	}
+}
+
+// printSoak reports the assertions that didn't pass every soak run
+func printSoak(history []runner.AssertionHistory, runs int) {
+	fmt.Printf("\nSoak summary (%d runs):\n", runs)
+	clean := true
+	for _, h := range history {
+		if h.Worst == assertion.StatusPass {
+			continue
+		}
+		clean = false
+		fmt.Printf("  worst %-15s passed %d/%d, %d transitions  %s @ %s\n",
+			strings.ToUpper(string(h.Worst)), h.Passed, h.Runs, h.Transitions, h.Name, h.Target)
+	}
+	if clean {
+		fmt.Println("  Every assertion passed every run")
 	}
+}
 
-	return &assertion.AssertionFile{Targets: newTargets}
+// everFailed reports whether any assertion failed in any run
+func everFailed(history []runner.AssertionHistory) bool {
+	for _, h := range history {
+		if h.Worst != assertion.StatusPass {
+			return true
+		}
+	}
+	return false
+}
+
+// printFlaky lists assertions whose outcome changed between repeated runs
+func printFlaky(flaky []runner.AssertionHistory, runs int) {
+	if len(flaky) == 0 {
+		fmt.Printf("\nNo flaky assertions in %d runs\n", runs)
+		return
+	}
+	fmt.Printf("\nFlaky assertions (%d runs):\n", runs)
+	for _, f := range flaky {
+		fmt.Printf("  %3.0f%% failing  %s @ %s (passed %d of %d)\n", f.FailureRate(), f.Name, f.Target, f.Passed, f.Runs)
+	}
+}
+
+// loadConfig loads netsert.yaml
+func loadConfig() (*config.Config, error) {
+	var cfg *config.Config
+	var err error
+	if cfgFile != "" {
+		// An explicit config must exist, unlike the discovered ones
+		cfg, err = config.LoadFile(cfgFile)
+	} else {
+		cfg, err = config.Load()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return setupConfig(cfg)
+}
+
+// loadConfigOrWarn is loadConfig for commands that can do without a config,
+// as validate does: a discovered config that can't be loaded is reported
+// and skipped. A --config file must still load.
+func loadConfigOrWarn() (*config.Config, error) {
+	if cfgFile != "" {
+		return loadConfig()
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: config not used: %v\n", err)
+		cfg = &config.Config{}
+	}
+	return setupConfig(cfg)
+}
+
+// setupConfig applies a loaded config's profile, platform profiles and
+// short paths
+func setupConfig(cfg *config.Config) (*config.Config, error) {
+	// Select a named profile (--profile wins over $NETSERT_PROFILE)
+	name := profile
+	if name == "" {
+		name = os.Getenv(config.EnvProfile)
+	}
+	if name != "" {
+		if err := cfg.ApplyProfile(name); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyPlatformProfiles(cfg.Platforms); err != nil {
+		return nil, err
+	}
+	for name, template := range cfg.ShortPaths {
+		if err := assertion.SetPathPrefix(name, template); err != nil {
+			return nil, fmt.Errorf("short_paths: %w", err)
+		}
+	}
+
+	// Config timeout applies unless --timeout was given
+	if !timeoutSet {
+		t, err := cfg.Defaults.TimeoutDuration()
+		if err != nil {
+			return nil, err
+		}
+		if t > 0 {
+			timeout = t
+		}
+	}
+
+	return cfg, nil
+}
+
+// promptCredentials applies --ask-user/--ask-pass for commands that
+// connect. Targets without credentials of their own, from the assertion
+// file or the config's targets: and groups:, use the answers ahead of the
+// environment, keyring, and defaults.
+func promptCredentials(cfg *config.Config) error {
+	if askUser {
+		user, err := promptLine("Username", cfg.Defaults.Username)
+		if err != nil {
+			return err
+		}
+		cfg.PromptedUsername = user
+	}
+	if askPass {
+		pass, err := promptPassword("Password")
+		if err != nil {
+			return err
+		}
+		cfg.PromptedPassword = pass
+	}
+	return nil
+}
+
+// printGroupSummary prints pass/fail counts per value of a metadata key
+func printGroupSummary(results []*assertion.Result, key string) {
+	type counts struct{ passed, failed int }
+	byValue := make(map[string]*counts)
+	for _, res := range results {
+		value := res.Metadata[key]
+		if value == "" {
+			value = "(none)"
+		}
+		c, ok := byValue[value]
+		if !ok {
+			c = &counts{}
+			byValue[value] = c
+		}
+		switch res.GetStatus() {
+		case assertion.StatusPass:
+			c.passed++
+		case assertion.StatusSkipped, assertion.StatusBlocked:
+		default:
+			c.failed++
+		}
+	}
+
+	fmt.Printf("\nBy %s:\n", key)
+	for _, value := range slices.Sorted(maps.Keys(byValue)) {
+		c := byValue[value]
+		fmt.Printf("  %s: %d passed, %d failed\n", value, c.passed, c.failed)
+	}
+}
+
+// printSlowest lists the n slowest assertions, and the n targets with the
+// highest average gNMI latency
+func printSlowest(result *runner.RunResult, n int) {
+	results := slices.Clone(result.Results)
+	slices.SortStableFunc(results, func(a, b *assertion.Result) int {
+		return cmp.Compare(b.Duration, a.Duration)
+	})
+	fmt.Printf("\nSlowest assertions:\n")
+	for _, res := range results[:min(n, len(results))] {
+		fmt.Printf("  %8s  %s @ %s\n", res.Duration.Round(time.Millisecond), res.DisplayName(), res.Target)
+	}
+
+	var targets []*runner.TargetResult
+	for _, tr := range result.Targets {
+		if tr.RPCs.Count > 0 {
+			targets = append(targets, tr)
+		}
+	}
+	slices.SortStableFunc(targets, func(a, b *runner.TargetResult) int {
+		return cmp.Compare(b.RPCs.Average(), a.RPCs.Average())
+	})
+	fmt.Printf("\nSlowest targets (average gNMI latency):\n")
+	for _, tr := range targets[:min(n, len(targets))] {
+		fmt.Printf("  %8s  %s (%d RPCs)\n", tr.RPCs.Average().Round(time.Millisecond), tr.Target, tr.RPCs.Count)
+	}
+}
+
+// generateOptions holds the flags for the generate command
+type generateOptions struct {
+	username      string
+	password      string
+	insecure      bool
+	generators    []string
+	outFile       string
+	inventoryFile string
+	platform      string
+	fromConfig    string
+	format        string
+	limit         int
+	vlans         []int
+	vrfs          []string
+	includeIfaces string
+	excludeIfaces string
+	genArgs       []string
+	ntpServers    []string
+	maxNTPOffset  time.Duration
+	domMargin     float64
+	maxCPU        int
+	minMemFree    int
+	maxPTPOffset  time.Duration
+	workers       int
+	workersSet    bool
+	merge         string
+	compactPaths  bool
+	mode          string
 }
 
 func generateCmd() *cobra.Command {
-	var (
-		username      string
-		password      string
-		insecure      bool
-		generators    []string
-		outFile       string
-		inventoryFile string
-	)
+	var opts generateOptions
 
 	cmd := &cobra.Command{
-		Use:   "generate <target>",
+		Use:   "generate [target]",
 		Short: "Generate assertions from current device state",
 		Long: `Query a device and generate assertion YAML from its current state.
 
 Target can be a single host or @group to generate for all hosts in a group.
+Group hosts are queried concurrently (--workers, or workers in netsert.yaml).
+
+With --from-config, assertions are derived from an intended configuration
+instead of live state: enabled interfaces should be up and configured BGP
+peers should be established. The target is only used as the host in the
+output (default: the configured hostname).
+
+With --mode exists, assertions check what is configured rather than its
+current state: BGP peers exist with their peer AS, interfaces are enabled,
+and other generators' values become exists checks on the same paths. Such
+baselines keep passing when an intentionally down peer comes back.
 
 Available generators:
-  bgp         - BGP neighbor session states
-  interfaces  - Interface oper-status
-  lldp        - LLDP neighbor relationships
-  ospf        - OSPF neighbor states
-  system      - Hostname and software version
+  acl          - ACL sets, entries, and interface/control-plane bindings
+  bgp          - BGP neighbor session states in every VRF (--vrf to filter)
+  counters     - Interface error/discard counters not increasing (counters.mode=zero for 0)
+  components   - Linecard, supervisor, and fabric module state and serials
+  environment  - PSU and fan status, temperature alarms
+  eos-native   - Arista state only in Sysdb, e.g. Vxlan oper-status (platform arista_eos)
+  gnoi         - Clock skew and running OS version (via gNOI)
+  interfaces   - Interface oper-status
+  lldp         - LLDP neighbor relationships
+  mac          - MAC table entries and their interface or VTEP (--vlan to filter)
+  multicast    - PIM neighbors and IGMP group membership
+  ntp          - NTP sync to an approved server and offset (--ntp-server, --max-ntp-offset)
+  ospf         - OSPF neighbor states
+  ptp          - PTP port states, grandmaster, and offset from master (--max-ptp-offset)
+  resources    - CPU and free memory thresholds (--max-cpu, --min-memory-free), busiest processes running
+  routes       - Default and loopback routes in the AFT (capped by --limit)
+  system       - Hostname and software version
+  transceivers - Optic presence, part numbers, and DOM levels (--dom-margin)
+  vlans        - VLAN names and status, trunk VLAN sets (--vlan to filter)
+  vrf          - VRF type, route distinguisher, and running protocols
+
+Plugins: executables named netsert-gen-<name> in $NETSERT_PLUGIN_DIR or
+~/.config/netsert/plugins run as generator <name>.
+
+Generator arguments (--gen-arg <generator>.<key>=<value>):
+  <gen>.include       - Regex of interfaces to check for interfaces, lldp, counters
+  <gen>.exclude       - Regex of interfaces to skip for interfaces, lldp, counters
+  bgp.vrfs            - Comma-separated VRFs, or all (overrides --vrf)
+  counters.mode       - delta (default): no increase; zero: counters must be 0
+  eos-native.paths    - Comma-separated extra Sysdb leaf paths to check
+  <gen>.limit         - Entry cap for acl, mac, multicast, routes (overrides --limit)
 
 Examples:
   netsert generate spine1:6030 --gen bgp
@@ -352,38 +1112,166 @@ Examples:
   netsert generate spine1:6030 -f assertions.yaml
   netsert generate spine1:6030  # All generators
   netsert generate @spines      # All hosts in spines group
-  netsert generate @all -f baseline.yaml`,
-		Args: cobra.ExactArgs(1),
+  netsert generate @all -f baseline.yaml
+  netsert generate spine1:6030 --gen-arg interfaces.exclude='^(Management|Port-Channel)'
+  netsert generate spine1:6030 --include-interfaces '^(Ethernet|Loopback|Management)'
+  netsert generate @all --merge baseline.yaml
+  netsert generate @all --mode exists -f intent.yaml
+  netsert generate spine1:6030 --from-config configs/spine1.cfg
+  netsert generate --from-config spine1.json --format openconfig-json`,
+		Args: cobra.RangeArgs(0, 1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runGenerate(args[0], generators, username, password, insecure, outFile, inventoryFile)
+			var target string
+			if len(args) > 0 {
+				target = args[0]
+			}
+			if opts.fromConfig != "" {
+				return runGenerateFromConfig(target, opts)
+			}
+			if target == "" {
+				return fmt.Errorf("target is required (or use --from-config)")
+			}
+			opts.workersSet = cmd.Flags().Changed("workers")
+			return runGenerate(target, opts)
 		},
 	}
 
-	cmd.Flags().StringVarP(&username, "username", "u", "", "username (or use config file)")
-	cmd.Flags().StringVarP(&password, "password", "P", "", "password (or use config file)")
-	cmd.Flags().BoolVarP(&insecure, "insecure", "k", false, "skip TLS verification")
-	cmd.Flags().StringArrayVar(&generators, "gen", nil, "generators to run (bgp, interfaces). Default: all")
-	cmd.Flags().StringVarP(&outFile, "file", "f", "", "output file (default: stdout)")
-	cmd.Flags().StringVarP(&inventoryFile, "inventory", "i", "", "inventory file (for @group targets)")
+	cmd.Flags().StringVarP(&opts.username, "username", "u", "", "username (or use config file)")
+	cmd.Flags().StringVarP(&opts.password, "password", "P", "", "password (or use config file)")
+	cmd.Flags().BoolVarP(&opts.insecure, "insecure", "k", false, "skip TLS verification")
+	cmd.Flags().StringArrayVar(&opts.generators, "gen", nil, "generators to run (bgp, interfaces). Default: all")
+	cmd.Flags().StringVarP(&opts.outFile, "file", "f", "", "output file (default: stdout)")
+	cmd.Flags().StringVarP(&opts.inventoryFile, "inventory", "i", "", "inventory file (for @group targets)")
+	cmd.Flags().StringVar(&opts.platform, "platform", "", "device platform (arista_eos, nokia_srlinux, cisco_xr, juniper). Default: from inventory")
+	cmd.Flags().IntVar(&opts.limit, "limit", 0, "max assertions per table-based generator such as routes, mac, or acl entries (default: 100)")
+	cmd.Flags().IntSliceVar(&opts.vlans, "vlan", nil, "VLANs for VLAN-scoped generators such as mac and vlans (default: all)")
+	cmd.Flags().StringSliceVar(&opts.vrfs, "vrf", nil, "VRFs for per-instance generators such as bgp (default: all)")
+	cmd.Flags().StringVar(&opts.includeIfaces, "include-interfaces", "", "regex of interfaces to check in interfaces, lldp, and counters (replaces the built-in skip list)")
+	cmd.Flags().StringVar(&opts.excludeIfaces, "exclude-interfaces", "", "regex of interfaces to skip in interfaces, lldp, and counters (replaces the built-in skip list)")
+	cmd.Flags().StringArrayVar(&opts.genArgs, "gen-arg", nil, "generator argument as <generator>.<key>=<value> (repeatable)")
+	cmd.Flags().StringArrayVar(&opts.ntpServers, "ntp-server", nil, "approved NTP server for the ntp generator (default: configured servers)")
+	cmd.Flags().DurationVar(&opts.maxNTPOffset, "max-ntp-offset", generate.DefaultMaxNTPOffset, "max NTP offset for the ntp generator")
+	cmd.Flags().Float64Var(&opts.domMargin, "dom-margin", generate.DefaultDOMMargin, "dB band around current optical power for the transceivers generator")
+	cmd.Flags().DurationVar(&opts.maxPTPOffset, "max-ptp-offset", generate.DefaultMaxPTPOffset, "max PTP offset from master for the ptp generator")
+	cmd.Flags().IntVar(&opts.maxCPU, "max-cpu", generate.DefaultMaxCPU, "max CPU utilization percent for the resources generator")
+	cmd.Flags().IntVar(&opts.minMemFree, "min-memory-free", generate.DefaultMinMemoryFreePct, "min free memory percent for the resources generator")
+	cmd.Flags().StringVar(&opts.fromConfig, "from-config", "", "generate from an intended config file instead of live state")
+	cmd.Flags().StringVar(&opts.format, "format", "", "intended config format (openconfig-json, eos). Default: from file extension")
+	cmd.Flags().IntVarP(&opts.workers, "workers", "w", runner.DefaultWorkers, "number of targets to generate from concurrently")
+	cmd.Flags().StringVar(&opts.merge, "merge", "", "refresh values in an existing assertion file, keeping hand-written assertions, names, and comments")
+	cmd.Flags().BoolVar(&opts.compactPaths, "compact-paths", false, "write every path in short form (e.g., interface[Ethernet1]/...) where one exists")
+	cmd.Flags().StringVar(&opts.mode, "mode", "", "snapshot (current values) or exists (configured peers, enabled interfaces). Default: snapshot")
 
 	return cmd
 }
 
-func runGenerate(target string, generators []string, username, password string, insecure bool, outFile, inventoryFile string) error {
+func runGenerateFromConfig(target string, opts generateOptions) error {
+	platform, err := assertion.ParsePlatform(opts.platform)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(opts.fromConfig)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	format := opts.format
+	if format == "" {
+		format = generate.DetectFormat(opts.fromConfig)
+	}
+
+	intent, err := generate.ParseIntent(data, format)
+	if err != nil {
+		return fmt.Errorf("%s: %w", opts.fromConfig, err)
+	}
+	if target == "" {
+		target = intent.Hostname
+	}
+	if target == "" {
+		return fmt.Errorf("%s has no hostname; pass a target", opts.fromConfig)
+	}
+
+	af := &assertion.AssertionFile{
+		Targets: []assertion.Target{
+			{
+				Host:       target,
+				Platform:   string(platform),
+				Assertions: intent.Assertions(opts.generators),
+			},
+		},
+	}
+
+	yamlData, err := yaml.Marshal(af)
+	if err != nil {
+		return fmt.Errorf("marshal YAML: %w", err)
+	}
+	result := fmt.Sprintf("# Generated by netsert from intended config %s\n# Review and edit as needed\n\n", opts.fromConfig) + string(yamlData)
+
+	if opts.outFile != "" {
+		if err := os.WriteFile(opts.outFile, []byte(result), 0644); err != nil {
+			return fmt.Errorf("write file: %w", err)
+		}
+		fmt.Printf("Generated %d assertions to %s\n", len(af.Targets[0].Assertions), opts.outFile)
+	} else {
+		fmt.Print(result)
+	}
+
+	return nil
+}
+
+func runGenerate(target string, opts generateOptions) error {
+	platformFlag, err := assertion.ParsePlatform(opts.platform)
+	if err != nil {
+		return err
+	}
+
+	// Load config for credentials and defaults
+	cfg, err := loadConfigOrWarn()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	if err := promptCredentials(cfg); err != nil {
+		return err
+	}
+	warnings, err := generate.LoadPlugins(context.Background(), generate.PluginDirs())
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+	if err != nil {
+		return err
+	}
+	genCfg := cfg.Generate
+	if err := applyGenerateConfig(&opts, genCfg); err != nil {
+		return err
+	}
+	// Config arguments first so --gen-arg overrides them
+	genArgs, err := generate.ParseArgs(append(genCfg.ArgSpecs(), opts.genArgs...))
+	if err != nil {
+		return err
+	}
+
+	generators := opts.generators
+	username, password, insecure := opts.username, opts.password, opts.insecure
+	outFile, inventoryFile := opts.outFile, opts.inventoryFile
+	if inventoryFile == "" {
+		inventoryFile = cfg.Defaults.Inventory
+	}
+
 	// Expand group targets (with or without @ prefix)
 	var targets []string
+	platforms := make(map[string]string) // resolved address -> inventory platform
+	groups := make(map[string][]string)  // resolved address -> inventory groups
 
 	// Strip @ prefix if present
 	groupName := strings.TrimPrefix(target, "@")
 	hasAtPrefix := strings.HasPrefix(target, "@")
 
 	// Check if this could be a group (has @ prefix OR no port)
-	couldBeGroup := hasAtPrefix || !strings.Contains(target, ":")
+	couldBeGroup := hasAtPrefix || !inventory.HasPort(target)
 
 	if couldBeGroup {
 		// Try to load inventory and look up group
 		var inv *inventory.Inventory
-		var err error
 		if inventoryFile != "" {
 			inv, err = inventory.Load(inventoryFile)
 			if err != nil {
@@ -400,7 +1288,15 @@ func runGenerate(target string, generators []string, username, password string,
 		if inv != nil {
 			hosts, ok := inv.GetGroup(groupName)
 			if ok && len(hosts) > 0 {
-				targets = hosts
+				for _, h := range hosts {
+					address := inv.ResolveHost(h)
+					targets = append(targets, address)
+					platforms[address] = inv.GetPlatform(h)
+					groups[address] = inv.GroupsFor(h)
+				}
+			} else if !hasAtPrefix {
+				platforms[inv.ResolveHost(target)] = inv.GetPlatform(target)
+				groups[inv.ResolveHost(target)] = inv.GroupsFor(target)
 			}
 		}
 
@@ -413,7 +1309,10 @@ func runGenerate(target string, generators []string, username, password string,
 				}
 				return fmt.Errorf("group %q not found in inventory", groupName)
 			}
-			// No @ prefix, treat as host
+			// No @ prefix, treat as host (resolved through inventory if present)
+			if inv != nil {
+				target = inv.ResolveHost(target)
+			}
 			targets = []string{target}
 		}
 	} else {
@@ -421,73 +1320,189 @@ func runGenerate(target string, generators []string, username, password string,
 		targets = []string{target}
 	}
 
-	// Load config for credentials
-	cfg, _ := config.Load()
-
 	// Default to all generators
 	if len(generators) == 0 {
 		generators = generate.List()
 	}
 
-	// Generate for all targets
-	var allTargets []assertion.Target
-	var totalAssertions int
+	// Generate from one target; safe to run concurrently
+	generateTarget := func(t string) (*assertion.Target, error) {
+		// Platform: --platform flag wins over inventory
+		platform := platformFlag
+		if platform == assertion.PlatformGeneric {
+			p, err := assertion.ParsePlatform(platforms[t])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", t, err)
+			}
+			platform = p
+		}
+
+		hostGroups := groups[t]
+
+		// Apply the configured default port to hosts without one
+		if cfg != nil {
+			t = inventory.AddPort(t, cfg.Defaults.Port)
+		}
 
-	for _, t := range targets {
 		// Get credentials for this target
 		u, p, ins := username, password, insecure
+		var conn config.Connection
 		if cfg != nil {
-			cfgUser, cfgPass, cfgInsecure := cfg.GetCredentials(t)
-			if u == "" {
-				u = cfgUser
-			}
-			if p == "" {
-				p = cfgPass
-			}
+			conn = cfg.GetConnection(t, hostGroups...)
+			var cfgInsecure bool
+			u, p, cfgInsecure = cfg.Credentials(t, u, p, hostGroups...)
 			if !ins {
 				ins = cfgInsecure
 			}
 		}
 
 		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
 
-		client, err := gnmiclient.NewClient(gnmiclient.Config{
+		clientCfg, err := runner.ClientConfig(gnmiclient.Config{
 			Address:  t,
 			Username: u,
 			Password: p,
 			Insecure: ins,
 			Timeout:  timeout,
-		})
+			Origin:   platform.Origin(),
+
+			PathMapper: platform,
+		}, conn)
 		if err != nil {
-			cancel()
-			return fmt.Errorf("connect to %s: %w", t, err)
+			return nil, err
 		}
 
+		client, err := gnmiclient.NewClient(clientCfg)
+		if err != nil {
+			return nil, fmt.Errorf("connect to %s: %w", t, err)
+		}
+		defer client.Close()
+
 		af, err := generate.GenerateFile(ctx, client, generators, generate.Options{
 			Target:   t,
 			Username: u,
 			Password: p,
+			Platform: platform,
+			Limit:    opts.limit,
+			VLANs:    opts.vlans,
+			VRFs:     opts.vrfs,
+
+			IncludeInterfaces: opts.includeIfaces,
+			ExcludeInterfaces: opts.excludeIfaces,
+
+			NTPServers:    opts.ntpServers,
+			MaxNTPOffset:  opts.maxNTPOffset,
+			DOMMargin:     opts.domMargin,
+			MaxPTPOffset:  opts.maxPTPOffset,
+			MaxCPU:        opts.maxCPU,
+			MinMemoryFree: opts.minMemFree,
+			Args:          genArgs,
+			Mode:          opts.mode,
 		})
-		client.Close()
-		cancel()
-
 		if err != nil {
-			return fmt.Errorf("generate from %s: %w", t, err)
+			return nil, fmt.Errorf("generate from %s: %w", t, err)
+		}
+		if len(af.Targets) == 0 {
+			return nil, nil
 		}
+		return &af.Targets[0], nil
+	}
+
+	// Generate for all targets, a worker pool wide, keeping inventory order
+	workers := opts.workers
+	if !opts.workersSet && cfg.Defaults.Workers > 0 {
+		workers = cfg.Defaults.Workers
+	}
+	results := make([]*assertion.Target, len(targets))
+	errs := make([]error, len(targets))
+	sem := make(chan struct{}, max(workers, 1))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+
+	for i, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i], errs[i] = generateTarget(t)
+
+			// Per-host progress, as hosts finish
+			if output == "json" || len(targets) < 2 {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			if errs[i] != nil {
+				fmt.Fprintf(os.Stderr, "[%d/%d] Failed %s: %v\n", done, len(targets), t, errs[i])
+			} else if results[i] != nil {
+				fmt.Fprintf(os.Stderr, "[%d/%d] Generated from %s (%d assertions)\n", done, len(targets), t, len(results[i].Assertions))
+			}
+		}()
+	}
+	wg.Wait()
 
-		if len(af.Targets) > 0 {
-			allTargets = append(allTargets, af.Targets[0])
-			totalAssertions += len(af.Targets[0].Assertions)
+	var allTargets []assertion.Target
+	var totalAssertions int
+	for i := range targets {
+		if errs[i] != nil {
+			return errs[i]
 		}
+		if results[i] != nil {
+			allTargets = append(allTargets, *results[i])
+			totalAssertions += len(results[i].Assertions)
+		}
+	}
 
-		if output != "json" && len(targets) > 1 {
-			fmt.Fprintf(os.Stderr, "Generated from %s (%d assertions)\n", t, len(af.Targets[0].Assertions))
+	// Put every path in one style: --compact-paths or paths: short compacts,
+	// paths: full expands; otherwise paths stay as the generators wrote them
+	pathStyle := genCfg.Output.Paths
+	if opts.compactPaths {
+		pathStyle = config.PathsShort
+	}
+	if pathStyle != "" {
+		for i := range allTargets {
+			platform := assertion.Platform(allTargets[i].Platform)
+			for j := range allTargets[i].Assertions {
+				a := &allTargets[i].Assertions[j]
+				switch {
+				case a.Path == "":
+				case pathStyle == config.PathsFull:
+					a.Path = assertion.ExpandPathFor(a.Path, platform)
+				default:
+					a.Path = assertion.CompactPathFor(a.Path, platform)
+				}
+			}
 		}
 	}
 
 	// Combine into single file
 	combined := &assertion.AssertionFile{Targets: allTargets}
 
+	// Refresh an existing baseline in place (or into -f) instead of replacing it
+	if opts.merge != "" {
+		existing, err := os.ReadFile(opts.merge)
+		if err != nil {
+			return fmt.Errorf("read merge file: %w", err)
+		}
+		merged, result, err := generate.Merge(existing, combined)
+		if err != nil {
+			return fmt.Errorf("merge into %s: %w", opts.merge, err)
+		}
+		if outFile == "" {
+			outFile = opts.merge
+		}
+		if err := os.WriteFile(outFile, merged, 0644); err != nil {
+			return fmt.Errorf("write file: %w", err)
+		}
+		fmt.Printf("Merged %d assertions (%d targets) into %s: %d updated, %d added\n", totalAssertions, len(allTargets), outFile, result.Updated, result.Added)
+		return nil
+	}
+
 	// Convert to YAML
 	yamlData, err := yaml.Marshal(combined)
 	if err != nil {
@@ -495,8 +1510,10 @@ func runGenerate(target string, generators []string, username, password string,
 	}
 
 	// Add header comment
-	header := fmt.Sprintf("# Generated by netsert from %s\n# Review and edit as needed\n\n", target)
-	result := header + string(yamlData)
+	result := string(yamlData)
+	if genCfg.Output.Header == nil || *genCfg.Output.Header {
+		result = fmt.Sprintf("# Generated by netsert from %s\n# Review and edit as needed\n\n", target) + result
+	}
 
 	// Write to file or stdout
 	if outFile != "" {
@@ -511,98 +1528,89 @@ func runGenerate(target string, generators []string, username, password string,
 	return nil
 }
 
-func getCmd() *cobra.Command {
-	var (
-		username string
-		password string
-		insecure bool
-	)
-
-	cmd := &cobra.Command{
-		Use:   "get <target> <path>",
-		Short: "Query a gNMI path on a device",
-		Long: `Query a single gNMI path on a device to discover available data.
-
-Examples:
-  netsert get spine1:6030 /interfaces/interface[name=Ethernet1]/state/oper-status
-  netsert get spine1:6030 /system/config/hostname
-  netsert get spine1:6030 /interfaces/interface --insecure
-
-Use this to explore what paths are available and what values they return.`,
-		Args: cobra.ExactArgs(2),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return runGet(args[0], args[1], username, password, insecure)
-		},
-	}
-
-	cmd.Flags().StringVarP(&username, "username", "u", "", "username (or use config file)")
-	cmd.Flags().StringVarP(&password, "password", "P", "", "password (or use config file)")
-	cmd.Flags().BoolVarP(&insecure, "insecure", "k", false, "skip TLS verification")
-
-	return cmd
-}
-
-func runGet(target, path, username, password string, insecure bool) error {
-	// Load config for credentials if not provided
-	cfg, _ := config.Load()
-	if cfg != nil && (username == "" || password == "") {
-		cfgUser, cfgPass, cfgInsecure := cfg.GetCredentials(target)
-		if username == "" {
-			username = cfgUser
+// applyPlatformProfiles merges configured platform overrides onto the
+// built-in path profiles
+func applyPlatformProfiles(platforms map[string]config.PlatformProfile) error {
+	for name, pc := range platforms {
+		platform, err := assertion.ParsePlatform(name)
+		if err != nil {
+			return fmt.Errorf("platforms: %w", err)
 		}
-		if password == "" {
-			password = cfgPass
+		profile := platform.Profile()
+		if pc.Origin != "" {
+			profile.Origin = pc.Origin
 		}
-		if !insecure {
-			insecure = cfgInsecure
+		if pc.ProtocolName != "" {
+			profile.ProtocolName = pc.ProtocolName
 		}
+		for _, r := range pc.Rewrites {
+			if r.From == "" || r.To == "" {
+				return fmt.Errorf("platforms.%s.rewrites: from and to are required", name)
+			}
+			profile.Rewrites = append(profile.Rewrites, assertion.PathRewrite{From: r.From, To: r.To})
+		}
+		profile.Origins = append(slices.Clone(profile.Origins), pc.Origins...)
+		profile.Unsupported = append(profile.Unsupported, pc.Unsupported...)
+		assertion.SetProfile(platform, profile)
 	}
+	return nil
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	client, err := gnmiclient.NewClient(gnmiclient.Config{
-		Address:  target,
-		Username: username,
-		Password: password,
-		Insecure: insecure,
-		Timeout:  timeout,
-	})
-	if err != nil {
-		return fmt.Errorf("connect to %s: %w", target, err)
-	}
-	defer client.Close()
-
-	value, exists, err := client.Get(ctx, path, username, password)
-	if err != nil {
-		return fmt.Errorf("get %s: %w", path, err)
+// applyGenerateConfig fills options not given on the command line from the
+// config's generate: section
+func applyGenerateConfig(opts *generateOptions, gc config.Generate) error {
+	if err := gc.Output.Validate(); err != nil {
+		return err
 	}
-
-	if output == "json" {
-		out := map[string]interface{}{
-			"target": target,
-			"path":   path,
-			"exists": exists,
-			"value":  value,
+	for _, name := range gc.Generators {
+		if _, ok := generate.Get(name); !ok {
+			return fmt.Errorf("generate.generators: unknown generator %s", name)
 		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(out)
 	}
 
-	if !exists {
-		fmt.Printf("Path: %s\n", path)
-		fmt.Printf("Exists: false\n")
-		return nil
+	if len(opts.generators) == 0 {
+		opts.generators = gc.Generators
 	}
-
-	fmt.Printf("Path: %s\n", path)
-	fmt.Printf("Value: %s\n", value)
-
+	if opts.outFile == "" {
+		opts.outFile = gc.Output.File
+	}
+	if opts.limit == 0 {
+		opts.limit = gc.Limit
+	}
+	if len(opts.vlans) == 0 {
+		opts.vlans = gc.VLANs
+	}
+	if len(opts.vrfs) == 0 {
+		opts.vrfs = gc.VRFs
+	}
+	if opts.includeIfaces == "" {
+		opts.includeIfaces = gc.IncludeInterfaces
+	}
+	if opts.excludeIfaces == "" {
+		opts.excludeIfaces = gc.ExcludePattern()
+	}
+	if opts.mode == "" {
+		opts.mode = gc.Mode
+	}
+	mode, err := generate.ParseMode(opts.mode)
+	if err != nil {
+		return err
+	}
+	opts.mode = mode
 	return nil
 }
 
-func outputJSON(path string, result *runner.RunResult) error {
+// outputJSON prints the JSON report, returning an exitError unless the run
+// passed
+func outputJSON(report JSONOutput, result *runner.RunResult, flaky, soak []runner.AssertionHistory, opts runOptions) error {
+	if err := writeJSONReport(os.Stdout, report); err != nil {
+		return err
+	}
+	return runExitError(report.Summary.Interrupted, result, flaky, soak, opts)
+}
+
+// jsonReport builds the JSON report of a run
+func jsonReport(path string, result *runner.RunResult, flaky, soak []runner.AssertionHistory, opts runOptions, interrupted bool) JSONOutput {
 	out := JSONOutput{
 		Summary: JSONSummary{
 			File:     path,
@@ -610,46 +1618,98 @@ func outputJSON(path string, result *runner.RunResult) error {
 			Passed:   result.Passed,
 			Failed:   result.Failed,
 			Errors:   result.Errors,
+			Skipped:  result.Skipped,
+			Blocked:  result.Blocked,
 			Duration: result.Duration.Round(time.Millisecond).String(),
-			Success:  result.Failed == 0 && result.Errors == 0,
+			Success:  passed(result, opts) && !interrupted,
+
+			Interrupted: interrupted,
+
+			FailedTargets: result.FailedTargets(),
 		},
+		Targets: make([]JSONTarget, 0, len(result.Targets)),
 		Results: make([]JSONResult, 0, len(result.Results)),
 	}
 
+	for _, tr := range result.Targets {
+		jt := JSONTarget{
+			Target:    tr.Target,
+			Connected: tr.Connected(),
+			Duration:  tr.Duration.Round(time.Millisecond).String(),
+			Total:     len(tr.Results),
+			Passed:    tr.Passed,
+			Failed:    tr.Failed,
+			Errors:    tr.Errors,
+			Skipped:   tr.Skipped,
+			Blocked:   tr.Blocked,
+			RPCs:      tr.RPCs.Count,
+			Metadata:  tr.Metadata,
+		}
+		if tr.RPCs.Count > 0 {
+			jt.RPCLatency = tr.RPCs.Average().Round(time.Microsecond).String()
+		}
+		if tr.ConnectError != nil {
+			jt.Error = tr.ConnectError.Error()
+		}
+		out.Targets = append(out.Targets, jt)
+	}
+
 	for _, res := range result.Results {
 		jr := JSONResult{
 			Target: res.Target,
 			Name:   res.Assertion.GetName(),
 			Path:   res.Assertion.Path,
+			File:   res.Assertion.File,
 			Actual: res.ActualValue,
 		}
+		jr.ShortPath = res.ShortPath
 
+		jr.Status = string(res.GetStatus())
+		jr.Metadata = res.Metadata
+		if res.Duration > 0 {
+			jr.Duration = res.Duration.Round(time.Microsecond).String()
+		}
+		if !res.Timestamp.IsZero() {
+			jr.Timestamp = res.Timestamp.UTC().Format(time.RFC3339Nano)
+		}
+		jr.Diff = res.Diff
 		if res.Error != nil {
-			jr.Status = "error"
 			jr.Error = res.Error.Error()
-		} else if res.Passed {
-			jr.Status = "pass"
-		} else {
-			jr.Status = "fail"
 		}
 
 		// Add expected value if it was an equals assertion
 		if res.Assertion.Equals != nil {
 			jr.Expected = *res.Assertion.Equals
+			if res.Assertion.Not {
+				jr.Expected = "not " + jr.Expected
+			}
 		}
 
 		out.Results = append(out.Results, jr)
 	}
 
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	if err := enc.Encode(out); err != nil {
-		return err
+	for _, f := range flaky {
+		out.Flaky = append(out.Flaky, JSONFlaky{
+			Target:      f.Target,
+			Name:        f.Name,
+			Path:        f.Path,
+			Runs:        f.Runs,
+			Passed:      f.Passed,
+			FailureRate: f.FailureRate(),
+		})
 	}
 
-	if result.Failed > 0 || result.Errors > 0 {
-		os.Exit(1)
+	for _, h := range soak {
+		out.Soak = append(out.Soak, JSONSoak{
+			Target:      h.Target,
+			Name:        h.Name,
+			Path:        h.Path,
+			Runs:        h.Runs,
+			Passed:      h.Passed,
+			Worst:       string(h.Worst),
+			Transitions: h.Transitions,
+		})
 	}
 
-	return nil
+	return out
 }
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/paths"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func pathsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "paths",
+		Short: "Browse common OpenConfig paths",
+	}
+	cmd.AddCommand(pathsSearchCmd())
+	return cmd
+}
+
+func pathsSearchCmd() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "search <keyword>...",
+		Short: "Search the bundled OpenConfig path index",
+		Long: `Search a bundled index of commonly used OpenConfig paths by keyword and
+print an assertion stub for the best match. Every keyword must appear in the
+path or its description. Key values in the results are examples to replace.
+
+Examples:
+  netsert paths search transceiver rx power
+  netsert paths search bgp prefixes received
+  netsert paths search lldp neighbor`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := strings.Join(args, " ")
+			matches := paths.Search(query)
+			if len(matches) == 0 {
+				return fmt.Errorf("no paths match %q", query)
+			}
+			if limit > 0 && len(matches) > limit {
+				matches = matches[:limit]
+			}
+
+			for _, e := range matches {
+				fmt.Printf("%s\n    %s (%s)\n", e.Path, e.Description, e.Type)
+			}
+
+			stub, err := yaml.Marshal([]assertion.Assertion{matches[0].Stub()})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("\n# Assertion stub for the top match:\n%s", stub)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "maximum results to show (0 for all)")
+
+	return cmd
+}
+
+// pathPreview is an assertion's path as written and as expanded, for
+// validate --show-paths
+type pathPreview struct {
+	Target   string `json:"target"`
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Expanded string `json:"expanded"`
+	Fallback bool   `json:"fallback,omitempty"` // No known prefix; only a leading slash was added
+}
+
+// previewPaths lists the path of every assertion that reads one
+func previewPaths(af *assertion.AssertionFile) []pathPreview {
+	var previews []pathPreview
+	for _, t := range af.Targets {
+		for _, a := range t.Assertions {
+			if a.Path == "" {
+				continue
+			}
+			raw := cmp.Or(a.RawPath, a.Path)
+			previews = append(previews, pathPreview{
+				Target:   t.GetHost(),
+				Name:     a.GetName(),
+				Path:     raw,
+				Expanded: a.Path,
+				Fallback: assertion.IsFallbackPath(raw),
+			})
+		}
+	}
+	return previews
+}
+
+// printPathPreview prints paths grouped by target
+func printPathPreview(previews []pathPreview) {
+	target := ""
+	for _, p := range previews {
+		if p.Target != target {
+			target = p.Target
+			fmt.Printf("%s\n", target)
+		}
+		if p.Path == p.Expanded {
+			fmt.Printf("  %s\n    %s\n", p.Name, p.Path)
+		} else {
+			fmt.Printf("  %s\n    %s\n    → %s\n", p.Name, p.Path, p.Expanded)
+		}
+		if p.Fallback {
+			fmt.Printf("    ⚠ no known short-path prefix; only a leading slash was added\n")
+		}
+	}
+	if len(previews) > 0 {
+		fmt.Println()
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// reportWriters write the reports --report-file and -o format=path
+// support, by format
+var reportWriters = map[string]func(w io.Writer, report JSONOutput) error{
+	"json":  writeJSONReport,
+	"junit": writeJUnitReport,
+}
+
+// reportExtensions name the format of report files given without one
+var reportExtensions = map[string]string{
+	".json": "json",
+	".xml":  "junit",
+}
+
+// outputSinks are the format=path values of -o, written as --report-file
+var outputSinks []string
+
+// outputFlag is -o: the console format, or with format=path a report file
+// written alongside it. It can be repeated.
+type outputFlag struct{}
+
+func (outputFlag) String() string { return output }
+func (outputFlag) Type() string   { return "format" }
+
+func (outputFlag) Set(v string) error {
+	if strings.Contains(v, "=") {
+		outputSinks = append(outputSinks, v)
+		return nil
+	}
+	if !slices.Contains(outputFormats, v) {
+		return fmt.Errorf("unknown output format %q (%s, or format=path for a report file)", v, strings.Join(outputFormats, ", "))
+	}
+	output = v
+	return nil
+}
+
+// reportFile is a --report-file destination
+type reportFile struct {
+	format string
+	path   string
+}
+
+// parseReportFiles parses --report-file values, [format=]path, taking the
+// format from the file extension when it isn't given
+func parseReportFiles(specs []string) ([]reportFile, error) {
+	var files []reportFile
+	for _, spec := range specs {
+		rf := reportFile{path: spec}
+		if format, path, ok := strings.Cut(spec, "="); ok && reportWriters[format] != nil {
+			rf = reportFile{format: format, path: path}
+		} else {
+			rf.format = reportExtensions[strings.ToLower(filepath.Ext(spec))]
+		}
+		if reportWriters[rf.format] == nil {
+			return nil, fmt.Errorf("--report-file %s: unknown format; name it <format>=%s (%s)", spec, rf.path, strings.Join(reportFormats(), ", "))
+		}
+		files = append(files, rf)
+	}
+	return files, nil
+}
+
+// reportFormats lists the --report-file formats, sorted
+func reportFormats() []string {
+	var formats []string
+	for format := range reportWriters {
+		formats = append(formats, format)
+	}
+	slices.Sort(formats)
+	return formats
+}
+
+// write writes the report to the file
+func (rf reportFile) write(report JSONOutput) error {
+	f, err := os.Create(rf.path)
+	if err != nil {
+		return err
+	}
+	if err := reportWriters[rf.format](f, report); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeJSONReport writes the report as indented JSON, as -o json prints it
+func writeJSONReport(w io.Writer, report JSONOutput) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+	"github.com/ndtobs/netsert/pkg/runner"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden report files in testdata")
+
+// sampleRun is a run with a result of each kind: passed, failed (and
+// negated), errored, skipped, and a target that couldn't be reached
+func sampleRun() *runner.RunResult {
+	up, mtu := "UP", "9214"
+	meta := map[string]string{"site": "dc1"}
+	spine1 := []*assertion.Result{
+		{
+			Target: "spine1:6030", Metadata: meta, Duration: 1200 * time.Microsecond, Passed: true, ActualValue: "UP",
+			Assertion: assertion.Assertion{Name: "Ethernet1 up", Path: "/interfaces/interface[name=Ethernet1]/state/oper-status", Equals: &up, File: "fabric.yaml"},
+			ShortPath: "interface[Ethernet1]/state/oper-status",
+			Timestamp: time.Date(2026, 3, 1, 12, 0, 0, 500, time.UTC),
+		},
+		{
+			Target: "spine1:6030", Metadata: meta, Duration: 800 * time.Microsecond, ActualValue: "9214",
+			Assertion: assertion.Assertion{Path: "/interfaces/interface[name=Ethernet2]/state/mtu", Equals: &mtu, Not: true},
+			ShortPath: "interface[Ethernet2]/state/mtu",
+		},
+		{
+			Target: "spine1:6030", Metadata: meta,
+			Assertion: assertion.Assertion{Name: "BGP <peer> & \"neighbor\"", Path: "/network-instances/network-instance[name=default]/protocols/protocol[identifier=BGP][name=BGP]/bgp/neighbors"},
+			Error:     errors.New("get: rpc error: code = Unavailable"),
+		},
+		{
+			Target: "spine1:6030", Metadata: meta, Status: assertion.StatusSkipped,
+			Assertion: assertion.Assertion{Name: "hostname", Path: "/system/state/hostname"},
+		},
+	}
+	connErr := errors.New("connect: connection refused")
+	leaf1 := []*assertion.Result{{
+		Target: "leaf1:6030", Error: connErr, Status: assertion.StatusError,
+		Assertion: assertion.Assertion{Name: "hostname", Path: "/system/state/hostname"},
+	}}
+
+	result := &runner.RunResult{
+		Duration: 1500 * time.Millisecond,
+		Targets: []*runner.TargetResult{
+			{Target: "spine1:6030", Metadata: meta, Results: spine1, Duration: time.Second, RPCs: gnmiclient.RPCStats{Count: 3, Total: 3 * time.Millisecond}},
+			{Target: "leaf1:6030", ConnectError: connErr, Results: leaf1, Duration: 20 * time.Millisecond},
+		},
+	}
+	for _, tr := range result.Targets {
+		for _, res := range tr.Results {
+			if res.Status == "" {
+				res.Status = res.GetStatus()
+			}
+			tr.Counts = addCount(tr.Counts, res)
+			result.Counts = addCount(result.Counts, res)
+			result.Results = append(result.Results, res)
+			result.TotalAssertions++
+		}
+	}
+	return result
+}
+
+// addCount tallies a result as the runner does
+func addCount(c runner.Counts, res *assertion.Result) runner.Counts {
+	switch res.GetStatus() {
+	case assertion.StatusPass:
+		c.Passed++
+	case assertion.StatusFail:
+		c.Failed++
+	case assertion.StatusSkipped:
+		c.Skipped++
+	case assertion.StatusBlocked:
+		c.Blocked++
+	default:
+		c.Errors++
+	}
+	return c
+}
+
+// checkGolden compares got to testdata/name, or rewrites it with -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from the golden file (rerun with -update to accept):\n%s", name, got)
+	}
+}
+
+func TestReportWriters(t *testing.T) {
+	report := jsonReport("fabric.yaml", sampleRun(), nil, nil, runOptions{}, false)
+	tests := []struct {
+		golden string
+		write  func(*bytes.Buffer, JSONOutput) error
+	}{
+		{"report.json", func(b *bytes.Buffer, r JSONOutput) error { return writeJSONReport(b, r) }},
+		{"report.xml", func(b *bytes.Buffer, r JSONOutput) error { return writeJUnitReport(b, r) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.write(&buf, report); err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
+
+func TestParseReportFiles(t *testing.T) {
+	tests := []struct {
+		specs   []string
+		want    []reportFile
+		wantErr string
+	}{
+		{specs: nil},
+		{
+			specs: []string{"results.json", "out/report.XML", "junit=report.txt", "json=a=b.out"},
+			want: []reportFile{
+				{format: "json", path: "results.json"},
+				{format: "junit", path: "out/report.XML"},
+				{format: "junit", path: "report.txt"},
+				{format: "json", path: "a=b.out"},
+			},
+		},
+		{
+			// Not a known format, so the whole value is the path
+			specs: []string{"build=report.xml"},
+			want:  []reportFile{{format: "junit", path: "build=report.xml"}},
+		},
+		{specs: []string{"report.txt"}, wantErr: "--report-file report.txt: unknown format"},
+		{specs: []string{"yaml=report.yaml"}, wantErr: "unknown format"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.specs, ","), func(t *testing.T) {
+			got, err := parseReportFiles(tt.specs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseReportFiles() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseReportFiles() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportFileWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.xml")
+	rf := reportFile{format: "junit", path: path}
+	if err := rf.write(jsonReport("fabric.yaml", sampleRun(), nil, nil, runOptions{}, false)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "report.xml", data)
+
+	bad := reportFile{format: "json", path: filepath.Join(t.TempDir(), "missing", "report.json")}
+	if err := bad.write(JSONOutput{}); err == nil {
+		t.Error("write() into a missing directory succeeded")
+	}
+}
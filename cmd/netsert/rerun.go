@@ -0,0 +1,128 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/inventory"
+	"github.com/ndtobs/netsert/pkg/runner"
+)
+
+// lastRunPath is where runs of the assertion files at paths record the
+// assertions that didn't pass, for --rerun-failed: a file named for them
+// under netsert/last-run in the user's cache directory, so runs of other
+// suites don't replace it
+func lastRunPath(paths []string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(suiteFiles(paths)))
+	return filepath.Join(dir, "netsert", "last-run", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// failureManifest lists the assertions of a run that didn't pass
+type failureManifest struct {
+	File   string            `json:"file"` // The assertion files run; see suiteFiles
+	Failed []failedAssertion `json:"failed"`
+}
+
+// failedAssertion identifies an assertion by target, name, and path
+type failedAssertion struct {
+	Target string `json:"target"`
+	Name   string `json:"name"`
+	Path   string `json:"path,omitempty"`
+	Status string `json:"status"`
+}
+
+// key drops the status, leaving what identifies the assertion
+func (f failedAssertion) key() failedAssertion {
+	f.Status = ""
+	return f
+}
+
+// writeFailureManifest records the assertions that failed, errored, or were
+// skipped in result
+func writeFailureManifest(path, file string, result *runner.RunResult) error {
+	m := failureManifest{File: file, Failed: []failedAssertion{}}
+	for _, tr := range result.Targets {
+		for _, res := range tr.Results {
+			if res.GetStatus() == assertion.StatusPass {
+				continue
+			}
+			m.Failed = append(m.Failed, failedAssertion{
+				Target: res.Target,
+				Name:   res.Assertion.GetName(),
+				Path:   res.Assertion.Path,
+				Status: string(res.GetStatus()),
+			})
+		}
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// loadFailureManifest reads a manifest written by writeFailureManifest. A
+// missing one is an fs.ErrNotExist error.
+func loadFailureManifest(path string) (*failureManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read failure manifest: %w", err)
+	}
+	var m failureManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse failure manifest %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// keepFailed narrows af to the assertions in the manifest, dropping targets
+// left with none. Hosts get the default port as the runner gives them.
+func keepFailed(af *assertion.AssertionFile, m *failureManifest, port int) {
+	failed := make(map[failedAssertion]bool, len(m.Failed))
+	for _, f := range m.Failed {
+		failed[f.key()] = true
+	}
+
+	var targets []assertion.Target
+	for _, t := range af.Targets {
+		host := inventory.AddPort(t.GetHost(), port)
+		var assertions []assertion.Assertion
+		for _, a := range t.Assertions {
+			if failed[failedAssertion{Target: host, Name: a.GetName(), Path: a.Path}] {
+				assertions = append(assertions, a)
+			}
+		}
+		if len(assertions) > 0 {
+			t.Assertions = assertions
+			targets = append(targets, t)
+		}
+	}
+	af.Targets = targets
+}
+
+// suiteFiles names the assertion files of a run by absolute path, so a
+// manifest is matched to the same files from any directory
+func suiteFiles(paths []string) string {
+	abs := make([]string, len(paths))
+	for i, p := range paths {
+		if a, err := filepath.Abs(p); err == nil {
+			p = a
+		}
+		abs[i] = p
+	}
+	return strings.Join(abs, ", ")
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/runner"
+)
+
+func TestFailureManifestRoundTrip(t *testing.T) {
+	result := &runner.RunResult{Targets: []*runner.TargetResult{{Results: []*assertion.Result{
+		{Target: "spine1:6030", Assertion: assertion.Assertion{Name: "up", Path: "/a"}, Passed: true, Status: assertion.StatusPass},
+		{Target: "spine1:6030", Assertion: assertion.Assertion{Name: "mtu", Path: "/b"}, Status: assertion.StatusFail},
+		{Target: "spine1:6030", Assertion: assertion.Assertion{Name: "bgp", Path: "/c"}, Error: errors.New("boom"), Status: assertion.StatusError},
+	}}}}
+
+	// The cache directory is created on first write
+	path := filepath.Join(t.TempDir(), "netsert", "last-run.json")
+	if err := writeFailureManifest(path, "/suite/a.yaml", result); err != nil {
+		t.Fatal(err)
+	}
+	m, err := loadFailureManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &failureManifest{File: "/suite/a.yaml", Failed: []failedAssertion{
+		{Target: "spine1:6030", Name: "mtu", Path: "/b", Status: "fail"},
+		{Target: "spine1:6030", Name: "bgp", Path: "/c", Status: "error"},
+	}}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("loadFailureManifest() = %+v, want %+v", m, want)
+	}
+
+	if _, err := loadFailureManifest(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("loadFailureManifest(missing) error = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestWriteFailureManifestError(t *testing.T) {
+	// A file where the cache directory should be
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "netsert"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFailureManifest(filepath.Join(dir, "netsert", "last-run.json"), "a.yaml", &runner.RunResult{}); err == nil {
+		t.Error("writeFailureManifest() under a file succeeded, want an error")
+	}
+}
+
+func TestKeepFailed(t *testing.T) {
+	af := &assertion.AssertionFile{Targets: []assertion.Target{
+		{Host: "spine1", Assertions: []assertion.Assertion{
+			{Name: "up", Path: "/a"},
+			{Name: "mtu", Path: "/b"},
+			{Name: "mtu", Path: "/c"},
+		}},
+		{Host: "spine2:57400", Assertions: []assertion.Assertion{{Name: "up", Path: "/a"}}},
+		{Host: "spine3", Assertions: []assertion.Assertion{{Name: "up", Path: "/a"}}},
+	}}
+	m := &failureManifest{Failed: []failedAssertion{
+		{Target: "spine1:6030", Name: "mtu", Path: "/b", Status: "fail"},
+		{Target: "spine2:57400", Name: "up", Path: "/a", Status: "error"},
+		{Target: "spine3", Name: "up", Path: "/a", Status: "fail"}, // Without the port the runner adds, so not this target
+	}}
+	keepFailed(af, m, 6030)
+
+	var got []string
+	for _, tgt := range af.Targets {
+		for _, a := range tgt.Assertions {
+			got = append(got, tgt.Host+" "+a.Name+" "+a.Path)
+		}
+	}
+	want := []string{"spine1 mtu /b", "spine2:57400 up /a"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("keepFailed() kept %v, want %v", got, want)
+	}
+}
+
+func TestSuiteFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	want := filepath.Join(dir, "a.yaml") + ", /abs/b.yaml"
+	if got := suiteFiles([]string{"a.yaml", "/abs/b.yaml"}); got != want {
+		t.Errorf("suiteFiles() = %q, want %q", got, want)
+	}
+}
+
+func TestLastRunPath(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+	t.Setenv("HOME", cache)
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	suite, err := lastRunPath([]string{"suite/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(suite, cache) || !strings.Contains(suite, filepath.Join("netsert", "last-run")) {
+		t.Errorf("lastRunPath() = %s, want it under the cache directory %s", suite, cache)
+	}
+	same, _ := lastRunPath([]string{filepath.Join(dir, "suite")})
+	other, _ := lastRunPath([]string{"other.yaml"})
+	if same != suite || other == suite {
+		t.Errorf("lastRunPath() = %s for the same suite by absolute path and %s for another, want %s and a different one", same, other, suite)
+	}
+}
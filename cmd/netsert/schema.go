@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/ndtobs/netsert/pkg/jsonschema"
+	"github.com/spf13/cobra"
+)
+
+func schemaCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema <assertions|inventory|config>",
+		Short: "Print the JSON Schema of a file format",
+		Long: `Print a JSON Schema document describing assertion, inventory, or config
+files, for editors to complete and check them as you write.
+
+Examples:
+  netsert schema assertions > .netsert/assertions.schema.json
+  netsert schema inventory > .netsert/inventory.schema.json
+
+With the VS Code YAML extension, point a file at its schema with a comment
+on its first line:
+  # yaml-language-server: $schema=.netsert/assertions.schema.json
+
+or map files to schemas in settings.json:
+  "yaml.schemas": {".netsert/assertions.schema.json": "tests/*.yaml"}`,
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: jsonschema.Formats(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := jsonschema.For(args[0])
+			if err != nil {
+				return err
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(s)
+		},
+	}
+}
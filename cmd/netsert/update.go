@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/generate"
+	"github.com/ndtobs/netsert/pkg/inventory"
+	"github.com/ndtobs/netsert/pkg/runner"
+)
+
+// updateExpected offers, file by file, to rewrite the equals: values of
+// failed assertions with the values the devices returned. Hosts get the
+// default port as the runner gives them.
+func updateExpected(paths []string, af *assertion.AssertionFile, result *runner.RunResult, port int) error {
+	hosts := make(map[string]string)
+	for _, t := range af.Targets {
+		hosts[inventory.AddPort(t.GetHost(), port)] = t.GetHost()
+	}
+
+	var files []string
+	byFile := make(map[string][]generate.ExpectedUpdate)
+	for _, res := range result.Results {
+		a := res.Assertion
+		if res.GetStatus() != assertion.StatusFail || a.Equals == nil || a.Not || a.EqualsFile != "" || res.ActualValue == *a.Equals {
+			continue
+		}
+		file := a.File
+		if file == "" {
+			// A single file doesn't name itself
+			all, err := assertion.Files(paths...)
+			if err != nil {
+				return err
+			}
+			file = all[0]
+		}
+		if _, ok := byFile[file]; !ok {
+			files = append(files, file)
+		}
+		byFile[file] = append(byFile[file], generate.ExpectedUpdate{
+			Host: hosts[res.Target],
+			Name: a.Name,
+			Path: a.Path,
+			Old:  *a.Equals,
+			New:  res.ActualValue,
+		})
+	}
+	if len(files) == 0 {
+		fmt.Println("\nNo failed equals assertions to update")
+		return nil
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("update expected values: %w", err)
+		}
+		out, applied, err := generate.UpdateExpected(data, byFile[file])
+		if err != nil {
+			return fmt.Errorf("update expected values in %s: %w", file, err)
+		}
+		if skipped := len(byFile[file]) - len(applied); skipped > 0 {
+			fmt.Printf("\n%s: %d failed assertion(s) not written as literal values under their target; update them by hand\n", file, skipped)
+		}
+		if len(applied) == 0 {
+			continue
+		}
+
+		fmt.Printf("\nExpected values to update in %s:\n", file)
+		for _, u := range applied {
+			fmt.Printf("  %s %s: %q → %q\n", u.Host, updateName(u), u.Old, u.New)
+		}
+		answer, err := promptLine(fmt.Sprintf("Update %d expected value(s) in %s? [y/N]", len(applied), file), "")
+		if err != nil {
+			return fmt.Errorf("update expected values: %w", err)
+		}
+		if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			fmt.Printf("Left %s unchanged\n", file)
+			continue
+		}
+		if err := os.WriteFile(file, out, 0o644); err != nil {
+			return fmt.Errorf("update expected values: %w", err)
+		}
+		fmt.Printf("Updated %d expected value(s) in %s\n", len(applied), file)
+	}
+	return nil
+}
+
+// updateName names an update's assertion as results do: by name, else path
+func updateName(u generate.ExpectedUpdate) string {
+	if u.Name != "" {
+		return u.Name
+	}
+	return u.Path
+}
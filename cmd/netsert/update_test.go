@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/runner"
+)
+
+func TestUpdateExpected(t *testing.T) {
+	const suite = `targets:
+  - host: spine1
+    assertions:
+      - name: version
+        path: /system/state/software-version
+        equals: 4.30.1F
+
+      - name: hostname
+        path: /system/state/hostname
+        equals: spine1
+`
+	tests := []struct {
+		name   string
+		answer string
+		file   bool // Results name their file, as with a directory of files
+		want   string
+	}{
+		{
+			name:   "accepted",
+			answer: "y\n",
+			want:   strings.Replace(suite, "equals: 4.30.1F", "equals: 4.31.0F", 1),
+		},
+		{
+			name:   "accepted for a named file",
+			answer: "yes\n",
+			file:   true,
+			want:   strings.Replace(suite, "equals: 4.30.1F", "equals: 4.31.0F", 1),
+		},
+		{name: "declined", answer: "n\n", want: suite},
+		{name: "no answer", answer: "", want: suite},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "suite.yaml")
+			if err := os.WriteFile(path, []byte(suite), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			af, err := assertion.LoadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			old, now := "4.30.1F", "spine1"
+			version := assertion.Assertion{Name: "version", Path: "/system/state/software-version", Equals: &old}
+			hostname := assertion.Assertion{Name: "hostname", Path: "/system/state/hostname", Equals: &now}
+			if tt.file {
+				version.File, hostname.File = path, path
+			}
+			result := &runner.RunResult{Results: []*assertion.Result{
+				{Target: "spine1:6030", Assertion: version, ActualValue: "4.31.0F", Status: assertion.StatusFail},
+				{Target: "spine1:6030", Assertion: hostname, ActualValue: "spine1", Passed: true, Status: assertion.StatusPass},
+			}}
+
+			saved := stdinReader
+			stdinReader = bufio.NewReader(strings.NewReader(tt.answer))
+			t.Cleanup(func() { stdinReader = saved })
+
+			err = updateExpected([]string{path}, af, result, 6030)
+			if tt.answer == "" {
+				if err == nil {
+					t.Fatal("updateExpected() without an answer succeeded, want an error")
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("file after updateExpected() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
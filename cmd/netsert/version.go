@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/generate"
+	"github.com/spf13/cobra"
+)
+
+// Build metadata, set with -ldflags "-X main.version=... -X main.commit=...
+// -X main.date=..."; go install builds fill commit and date from VCS info
+var (
+	commit = ""
+	date   = ""
+)
+
+// outputFormats are the values --output accepts
+var outputFormats = []string{"text", "json"}
+
+// versionInfo is what netsert --version reports
+type versionInfo struct {
+	Version       string   `json:"version"`
+	Commit        string   `json:"commit,omitempty"`
+	BuildDate     string   `json:"build_date,omitempty"`
+	GoVersion     string   `json:"go_version"`
+	Platform      string   `json:"platform"`
+	OutputFormats []string `json:"output_formats"`
+	Generators    []string `json:"generators"`
+}
+
+// buildInfo collects the version, filling gaps from the binary's build info
+func buildInfo() versionInfo {
+	info := versionInfo{
+		Version:       version,
+		Commit:        commit,
+		BuildDate:     date,
+		GoVersion:     runtime.Version(),
+		Platform:      runtime.GOOS + "/" + runtime.GOARCH,
+		OutputFormats: outputFormats,
+		Generators:    generate.List(),
+	}
+	slices.Sort(info.Generators)
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "dev" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = s.Value
+			case s.Key == "vcs.modified" && s.Value == "true" && info.Commit != "" && !strings.HasSuffix(info.Commit, "-dirty"):
+				info.Commit += "-dirty"
+			}
+		}
+	}
+	return info
+}
+
+// versionText renders the version for --version, as JSON with -o json
+func versionText() string {
+	info := buildInfo()
+	if output == "json" {
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return err.Error() + "\n"
+		}
+		return string(data) + "\n"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "netsert %s\n", info.Version)
+	if info.Commit != "" {
+		fmt.Fprintf(&b, "  commit:     %s\n", info.Commit)
+	}
+	if info.BuildDate != "" {
+		fmt.Fprintf(&b, "  built:      %s\n", info.BuildDate)
+	}
+	fmt.Fprintf(&b, "  go:         %s (%s)\n", info.GoVersion, info.Platform)
+	fmt.Fprintf(&b, "  outputs:    %s\n", strings.Join(info.OutputFormats, ", "))
+	fmt.Fprintf(&b, "  generators: %s\n", strings.Join(info.Generators, ", "))
+	return b.String()
+}
+
+func versionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Show version and build information",
+		Long: `Show the version, commit, build date, Go version, and the output formats
+and generators this build supports, as --version does. With -o json, for
+bug reports and for CI pinning behavior.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Print(versionText())
+		},
+	}
+}
@@ -0,0 +1,232 @@
+package assertion
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadFiles loads assertion files, and directories of them (every .yaml and
+// .yml file beneath with targets, all, groups, or templates, in lexical
+// order), as one suite. Targets with the same
+// host are merged. The same assertion name on a host in two files, or the
+// same template name, is an error. When there are several files, each
+// assertion's File records the one it came from.
+func LoadFiles(paths ...string) (*AssertionFile, error) {
+	files, err := Files(paths...)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 1 {
+		return LoadFile(files[0])
+	}
+
+	merged := &AssertionFile{}
+	m := merger{hosts: make(map[string]int), hostFiles: make(map[string]string), names: make(map[string]string), templates: make(map[string]string)}
+	for _, file := range files {
+		af, err := LoadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		af.setFile(file)
+		if err := m.merge(merged, af, file); err != nil {
+			return nil, err
+		}
+	}
+	return merged, nil
+}
+
+// Files expands directories into the assertion files beneath them, as
+// LoadFiles loads them
+func Files(paths ...string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading file: %w", err)
+		}
+		if !info.IsDir() {
+			files = append(files, filepath.Clean(path))
+			continue
+		}
+
+		found := false
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			ext := strings.ToLower(filepath.Ext(p))
+			if d.IsDir() || (ext != ".yaml" && ext != ".yml") {
+				return nil
+			}
+			ok, err := isAssertionFile(p)
+			if err != nil {
+				return err
+			}
+			if ok {
+				files = append(files, p)
+				found = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("no .yaml assertion files in %s", path)
+		}
+	}
+
+	// A file named twice, directly or through its directory, loads once
+	var unique []string
+	for _, f := range files {
+		if !slices.Contains(unique, f) {
+			unique = append(unique, f)
+		}
+	}
+	return unique, nil
+}
+
+// suiteKeys are the top-level keys of an assertion file; other YAML in a
+// directory (golden files, inventories, netsert.yaml) is left alone
+var suiteKeys = []string{"targets", "all", "groups", "templates"}
+
+// isAssertionFile reports whether a YAML file found in a directory is an
+// assertion file. Files that don't parse are, so their errors are shown.
+func isAssertionFile(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("reading file: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return true, nil
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return false, nil
+	}
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if slices.Contains(suiteKeys, root.Content[i].Value) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// setFile records file as the source of every assertion in af
+func (af *AssertionFile) setFile(file string) {
+	set := func(assertions []Assertion) {
+		for i := range assertions {
+			assertions[i].File = file
+		}
+	}
+	for i := range af.Targets {
+		set(af.Targets[i].Assertions)
+	}
+	set(af.All)
+	for _, assertions := range af.Groups {
+		set(assertions)
+	}
+	for _, tmpl := range af.Templates {
+		set(tmpl.Assertions)
+	}
+}
+
+// merger combines assertion files, remembering where names were defined
+type merger struct {
+	hosts     map[string]int    // Host -> index of its first merged target
+	hostFiles map[string]string // Host -> file it was first listed in
+	names     map[string]string // Host and assertion name -> file
+	templates map[string]string // Template name -> file
+}
+
+// merge adds af, loaded from file, to merged
+func (m *merger) merge(merged, af *AssertionFile, file string) error {
+	for name, tmpl := range af.Templates {
+		if first, ok := m.templates[name]; ok {
+			return fmt.Errorf("template %s is defined in both %s and %s", name, first, file)
+		}
+		m.templates[name] = file
+		if merged.Templates == nil {
+			merged.Templates = make(map[string]Template)
+		}
+		merged.Templates[name] = tmpl
+	}
+
+	for _, t := range af.Targets {
+		host := t.GetHost()
+		for _, a := range t.Assertions {
+			key := host + "\x00" + a.GetName()
+			if first, ok := m.names[key]; ok && first != file {
+				return fmt.Errorf("assertion %q on %s is in both %s and %s", a.GetName(), host, first, file)
+			}
+			m.names[key] = file
+		}
+
+		// Targets are merged across files only; a file listing a host
+		// twice runs it as written
+		i, ok := m.hosts[host]
+		if !ok || m.hostFiles[host] == file {
+			if !ok {
+				m.hosts[host] = len(merged.Targets)
+				m.hostFiles[host] = file
+			}
+			merged.Targets = append(merged.Targets, t)
+			continue
+		}
+		if err := mergeTarget(&merged.Targets[i], t, file); err != nil {
+			return err
+		}
+	}
+
+	merged.All = append(merged.All, af.All...)
+	for group, assertions := range af.Groups {
+		if merged.Groups == nil {
+			merged.Groups = make(map[string][]Assertion)
+		}
+		merged.Groups[group] = append(merged.Groups[group], assertions...)
+	}
+	merged.Hooks = merged.Hooks.Merge(af.Hooks)
+	return nil
+}
+
+// mergeTarget adds the assertions and settings of a target from another
+// file to one already merged. Settings may be repeated but not changed.
+func mergeTarget(dst *Target, src Target, file string) error {
+	settings := []struct {
+		name     string
+		dst, src *string
+	}{
+		{"username", &dst.Username, &src.Username},
+		{"password", &dst.Password, &src.Password},
+		{"platform", &dst.Platform, &src.Platform},
+	}
+	for _, s := range settings {
+		switch {
+		case *s.src == "":
+		case *s.dst == "":
+			*s.dst = *s.src
+		case *s.dst != *s.src:
+			return fmt.Errorf("target %s: %s in %s differs from earlier files", dst.GetHost(), s.name, file)
+		}
+	}
+	dst.Insecure = dst.Insecure || src.Insecure
+	for key, value := range src.Metadata {
+		if existing, ok := dst.Metadata[key]; ok && existing != value {
+			return fmt.Errorf("target %s: metadata %s in %s differs from earlier files", dst.GetHost(), key, file)
+		}
+		if dst.Metadata == nil {
+			dst.Metadata = make(map[string]string)
+		}
+		dst.Metadata[key] = value
+	}
+	dst.Assertions = append(slices.Clone(dst.Assertions), src.Assertions...)
+	dst.Use = append(dst.Use, src.Use...)
+	return nil
+}
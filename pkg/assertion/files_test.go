@@ -0,0 +1,166 @@
+package assertion
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"suite/bgp.yaml": `
+targets:
+  - host: spine1:6030
+    username: admin
+    assertions:
+      - name: BGP AS
+        path: bgp[default]/global/state/as
+        equals: "65000"
+`,
+		"suite/system/basics.yml": `
+all:
+  - name: Hostname set
+    path: system/state/hostname
+    exists: true
+targets:
+  - host: spine1:6030
+    metadata: {role: spine}
+    assertions:
+      - name: Version
+        path: system/state/software-version
+        exists: true
+  - host: leaf1:6030
+    assertions:
+      - name: Version
+        path: system/state/software-version
+        exists: true
+`,
+		"suite/README.md": "not an assertion file",
+	})
+
+	af, err := LoadFiles(filepath.Join(dir, "suite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(af.Targets) != 2 {
+		t.Fatalf("Targets = %+v, want spine1 merged and leaf1", af.Targets)
+	}
+	spine := af.Targets[0]
+	if spine.GetHost() != "spine1:6030" || spine.Username != "admin" || spine.Metadata["role"] != "spine" || len(spine.Assertions) != 2 {
+		t.Errorf("spine1 = %+v, want settings and assertions from both files", spine)
+	}
+	if !strings.HasSuffix(spine.Assertions[0].File, "bgp.yaml") || !strings.HasSuffix(spine.Assertions[1].File, "basics.yml") {
+		t.Errorf("assertion files = %s, %s, want bgp.yaml, basics.yml", spine.Assertions[0].File, spine.Assertions[1].File)
+	}
+	if len(af.All) != 1 || af.All[0].File == "" {
+		t.Errorf("All = %+v, want the shared assertion with its file", af.All)
+	}
+
+	// A single file loads as before, without file names
+	single, err := LoadFiles(filepath.Join(dir, "suite", "bgp.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if single.Targets[0].Assertions[0].File != "" {
+		t.Errorf("single file assertion File = %q, want empty", single.Targets[0].Assertions[0].File)
+	}
+}
+
+func TestLoadFilesSkipsOtherYAML(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"suite/bgp.yaml": `
+targets:
+  - host: spine1:6030
+    assertions:
+      - name: BGP global
+        path: bgp[default]/global/state
+        equals_json_file: expected/bgp.yaml
+`,
+		"suite/expected/bgp.yaml": "protocol: BGP\nas: 65000\n",
+		"suite/inventory.yaml":    "hosts:\n  spine1: {}\n",
+		"suite/netsert.yaml":      "defaults:\n  username: admin\n",
+		"suite/empty.yaml":        "",
+		"other/netsert.yaml":      "defaults:\n  username: admin\n",
+	})
+
+	files, err := Files(filepath.Join(dir, "suite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{filepath.Join(dir, "suite", "bgp.yaml")}; !slices.Equal(files, want) {
+		t.Errorf("Files() = %v, want %v", files, want)
+	}
+	if _, err := LoadFiles(filepath.Join(dir, "suite")); err != nil {
+		t.Errorf("LoadFiles() error = %v", err)
+	}
+	if _, err := Files(filepath.Join(dir, "other")); err == nil || !strings.Contains(err.Error(), "no .yaml assertion files") {
+		t.Errorf("Files() of a directory without assertion files error = %v", err)
+	}
+}
+
+func TestLoadFilesConflicts(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.yaml": `
+targets:
+  - host: spine1:6030
+    platform: arista_eos
+    assertions:
+      - name: Hostname
+        path: system/state/hostname
+        exists: true
+`,
+		"b.yaml": `
+targets:
+  - host: spine1:6030
+    assertions:
+      - name: Hostname
+        path: system/config/hostname
+        exists: true
+`,
+		"c.yaml": `
+targets:
+  - host: spine1:6030
+    platform: nokia_srlinux
+    assertions:
+      - name: Version
+        path: system/state/software-version
+        exists: true
+`,
+	})
+
+	tests := []struct {
+		files   []string
+		wantErr string
+	}{
+		{[]string{"a.yaml", "b.yaml"}, `assertion "Hostname" on spine1:6030 is in both`},
+		{[]string{"a.yaml", "c.yaml"}, "platform in"},
+		{[]string{"a.yaml", "missing.yaml"}, "no such file"},
+	}
+	for _, tt := range tests {
+		var paths []string
+		for _, f := range tt.files {
+			paths = append(paths, filepath.Join(dir, f))
+		}
+		_, err := LoadFiles(paths...)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("LoadFiles(%v) error = %v, want %q", tt.files, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,435 @@
+package assertion
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/value"
+	"gopkg.in/yaml.v3"
+)
+
+// loadJSONFiles reads equals_json_file documents into EqualsJSON.
+// Relative paths are resolved against baseDir (the assertion file's directory).
+func loadJSONFiles(a *Assertion, baseDir string) error {
+	if a.EqualsJSONFile == "" {
+		return nil
+	}
+
+	path := a.EqualsJSONFile
+	if !filepath.IsAbs(path) && baseDir != "" {
+		path = filepath.Join(baseDir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("equals_json_file: %w", err)
+	}
+
+	// YAML is a superset of JSON, so either format works
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("equals_json_file %s: %w", a.EqualsJSONFile, err)
+	}
+	a.EqualsJSON = doc
+	return nil
+}
+
+// canonicalJSON converts a decoded YAML/JSON document, or a string holding
+// JSON text, into a generic JSON tree with json.Number leaves
+func canonicalJSON(doc any) (any, error) {
+	var data []byte
+	if s, ok := doc.(string); ok {
+		data = []byte(s)
+	} else {
+		var err error
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	var out any
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SameJSON reports whether two JSON texts hold the same document, whatever
+// the order of object keys and of list entries (as in a keyed list read
+// twice). Texts that aren't JSON are never the same.
+func SameJSON(a, b string) bool {
+	ca, err := canonicalJSON(a)
+	if err != nil {
+		return false
+	}
+	cb, err := canonicalJSON(b)
+	if err != nil {
+		return false
+	}
+	return compactJSON(sortLists(ca)) == compactJSON(sortLists(cb))
+}
+
+// sortLists orders the entries of every list in a JSON tree by their
+// encoding; objects encode with sorted keys already
+func sortLists(node any) any {
+	switch n := node.(type) {
+	case map[string]any:
+		for key, child := range n {
+			n[key] = sortLists(child)
+		}
+	case []any:
+		for i, child := range n {
+			n[i] = sortLists(child)
+		}
+		sort.Slice(n, func(i, j int) bool { return compactJSON(n[i]) < compactJSON(n[j]) })
+	}
+	return node
+}
+
+// jsonDiff compares expected and actual JSON trees and describes the first
+// difference ("" when equal). Module prefixes on keys are ignored, numbers
+// compare by value (including numbers encoded as strings per JSON_IETF),
+// and ignore paths are removed from both sides first.
+func jsonDiff(expected, actual any, ignore []string) string {
+	var ignorePaths [][]string
+	for _, p := range ignore {
+		ignorePaths = append(ignorePaths, strings.Split(strings.Trim(p, "/"), "/"))
+	}
+	expected = stripJSON(expected, ignorePaths)
+	actual = stripJSON(actual, ignorePaths)
+
+	// A single-leaf container may already have been unwrapped to its scalar
+	if obj, ok := expected.(map[string]any); ok && len(obj) == 1 {
+		if _, isObj := actual.(map[string]any); !isObj {
+			for _, inner := range obj {
+				expected = inner
+			}
+		}
+	}
+
+	return diffJSON("", expected, actual)
+}
+
+// stripJSON removes module prefixes from keys and drops ignored paths.
+// Lists are transparent: a path applies to every element.
+func stripJSON(node any, ignore [][]string) any {
+	switch n := node.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(n))
+		for key, child := range n {
+			key = stripModule(key)
+			var rest [][]string
+			dropped := false
+			for _, p := range ignore {
+				if p[0] != key && p[0] != "*" {
+					continue
+				}
+				if len(p) == 1 {
+					dropped = true
+					break
+				}
+				rest = append(rest, p[1:])
+			}
+			if !dropped {
+				out[key] = stripJSON(child, rest)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(n))
+		for i, child := range n {
+			out[i] = stripJSON(child, ignore)
+		}
+		return out
+	default:
+		return node
+	}
+}
+
+func stripModule(key string) string {
+	if i := strings.Index(key, ":"); i >= 0 {
+		return key[i+1:]
+	}
+	return key
+}
+
+func diffJSON(at string, expected, actual any) string {
+	where := at
+	if where == "" {
+		where = "/"
+	}
+
+	switch e := expected.(type) {
+	case map[string]any:
+		a, ok := actual.(map[string]any)
+		if !ok {
+			return fmt.Sprintf("at %s: expected object, got %s", where, compactJSON(actual))
+		}
+		keys := make([]string, 0, len(e)+len(a))
+		for k := range e {
+			keys = append(keys, k)
+		}
+		for k := range a {
+			if _, ok := e[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			ev, inExpected := e[k]
+			av, inActual := a[k]
+			switch {
+			case !inActual:
+				return fmt.Sprintf("at %s/%s: missing, expected %s", at, k, compactJSON(ev))
+			case !inExpected:
+				return fmt.Sprintf("at %s/%s: unexpected %s", at, k, compactJSON(av))
+			}
+			if d := diffJSON(at+"/"+k, ev, av); d != "" {
+				return d
+			}
+		}
+		return ""
+
+	case []any:
+		a, ok := actual.([]any)
+		if !ok {
+			return fmt.Sprintf("at %s: expected list, got %s", where, compactJSON(actual))
+		}
+		if len(e) != len(a) {
+			return fmt.Sprintf("at %s: expected %d items, got %d", where, len(e), len(a))
+		}
+		for i := range e {
+			if d := diffJSON(fmt.Sprintf("%s[%d]", at, i), e[i], a[i]); d != "" {
+				return d
+			}
+		}
+		return ""
+
+	default:
+		if !scalarsEqual(expected, actual) {
+			return fmt.Sprintf("at %s: expected %s, got %s", where, compactJSON(expected), compactJSON(actual))
+		}
+		return ""
+	}
+}
+
+// scalarsEqual compares JSON scalars, treating numbers and numeric strings by value
+func scalarsEqual(expected, actual any) bool {
+	switch e := expected.(type) {
+	case json.Number:
+		return numericEqual(e.String(), scalarString(actual))
+	case string:
+		if a, ok := actual.(string); ok {
+			return a == e
+		}
+		_, isNum := actual.(json.Number)
+		return isNum && numericEqual(e, scalarString(actual))
+	default:
+		return expected == actual
+	}
+}
+
+func scalarString(v any) string {
+	switch x := v.(type) {
+	case json.Number:
+		return x.String()
+	case string:
+		return x
+	default:
+		return ""
+	}
+}
+
+// numericEqual reports whether two strings hold the same number
+func numericEqual(a, b string) bool {
+	c, err := value.String(a).Compare(b)
+	return err == nil && c == 0
+}
+
+func compactJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// validateJSON implements equals_json
+func (a *Assertion) validateJSON(result *Result, v value.Value) {
+	expected, err := canonicalJSON(a.EqualsJSON)
+	if err != nil {
+		result.Error = fmt.Errorf("invalid equals_json: %w", err)
+		return
+	}
+
+	var actualDoc any = v.String()
+	if v.Kind != value.KindJSON {
+		// Scalars were already unwrapped; re-encode them as JSON
+		actualDoc = compactJSON(scalarJSON(v))
+	}
+	actual, err := canonicalJSON(actualDoc)
+	if err != nil {
+		result.Error = fmt.Errorf("value is not JSON: %w", err)
+		return
+	}
+
+	result.Diff = jsonDiff(expected, actual, a.IgnorePaths)
+	result.Passed = result.Diff == ""
+}
+
+func scalarJSON(v value.Value) any {
+	switch v.Kind {
+	case value.KindInt, value.KindUint, value.KindFloat:
+		return json.Number(v.String())
+	case value.KindBool:
+		return v.Equal("true")
+	default:
+		return v.String()
+	}
+}
+
+// validateContainsItem implements contains_item
+func (a *Assertion) validateContainsItem(result *Result, v value.Value) {
+	pattern, err := canonicalJSON(a.ContainsItem)
+	if err != nil {
+		result.Error = fmt.Errorf("invalid contains_item: %w", err)
+		return
+	}
+	actual, err := canonicalJSON(v.String())
+	if err != nil {
+		result.Error = fmt.Errorf("value is not JSON: %w", err)
+		return
+	}
+	pattern = stripJSON(pattern, nil)
+	actual = stripJSON(actual, nil)
+
+	var lists [][]any
+	if a.ListPath != "" {
+		node, ok := lookupJSON(actual, strings.Split(strings.Trim(a.ListPath, "/"), "/"))
+		list, isList := node.([]any)
+		if !ok || !isList {
+			result.Diff = fmt.Sprintf("no list at %s", a.ListPath)
+			return
+		}
+		lists = append(lists, list)
+	} else {
+		lists = collectLists(actual, nil)
+	}
+
+	for _, list := range lists {
+		for _, item := range list {
+			if matchPartial(pattern, item) {
+				result.Passed = true
+				return
+			}
+		}
+	}
+	result.Diff = fmt.Sprintf("no list item matches %s", compactJSON(pattern))
+}
+
+// validateCount implements count
+func (a *Assertion) validateCount(result *Result, v value.Value) {
+	actual, err := canonicalJSON(v.String())
+	if err != nil {
+		result.Error = fmt.Errorf("value is not JSON: %w", err)
+		return
+	}
+	actual = stripJSON(actual, nil)
+
+	var list []any
+	if a.ListPath != "" {
+		node, ok := lookupJSON(actual, strings.Split(strings.Trim(a.ListPath, "/"), "/"))
+		if list, ok = node.([]any); !ok {
+			result.Diff = fmt.Sprintf("no list at %s", a.ListPath)
+			return
+		}
+	} else if lists := collectLists(actual, nil); len(lists) > 0 {
+		list = lists[0]
+	}
+
+	result.ActualValue = fmt.Sprintf("%d entries", len(list))
+	result.Passed = len(list) == *a.Count
+	if !result.Passed {
+		result.Diff = fmt.Sprintf("expected %d entries, got %d", *a.Count, len(list))
+	}
+}
+
+// lookupJSON walks object keys (module prefixes already stripped)
+func lookupJSON(node any, path []string) (any, bool) {
+	for _, key := range path {
+		obj, ok := node.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if node, ok = obj[key]; !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// collectLists returns every list in the tree, outermost first
+func collectLists(node any, lists [][]any) [][]any {
+	switch n := node.(type) {
+	case []any:
+		lists = append(lists, n)
+		for _, child := range n {
+			lists = collectLists(child, lists)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(n))
+		for k := range n {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			lists = collectLists(n[k], lists)
+		}
+	}
+	return lists
+}
+
+// matchPartial reports whether actual contains everything in pattern:
+// objects match when each pattern key matches, lists when each pattern
+// element matches some actual element
+func matchPartial(pattern, actual any) bool {
+	switch p := pattern.(type) {
+	case map[string]any:
+		a, ok := actual.(map[string]any)
+		if !ok {
+			return false
+		}
+		for k, pv := range p {
+			av, ok := a[k]
+			if !ok || !matchPartial(pv, av) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		a, ok := actual.([]any)
+		if !ok {
+			return false
+		}
+		for _, pv := range p {
+			found := false
+			for _, av := range a {
+				if matchPartial(pv, av) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	default:
+		return scalarsEqual(pattern, actual)
+	}
+}
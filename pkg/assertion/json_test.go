@@ -0,0 +1,157 @@
+package assertion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ndtobs/netsert/pkg/value"
+)
+
+func TestValidate_EqualsJSON(t *testing.T) {
+	actual := `{"openconfig-interfaces:description":"uplink","mtu":9214,"counters":{"in-pkts":"12345"},"state":{"last-change":"1700000000"}}`
+
+	tests := []struct {
+		name     string
+		expected any
+		ignore   []string
+		want     bool
+	}{
+		{"match ignoring volatile fields", map[string]any{"description": "uplink", "mtu": 9214}, []string{"counters", "state/last-change", "state"}, true},
+		{"value mismatch", map[string]any{"description": "downlink", "mtu": 9214}, []string{"counters", "state"}, false},
+		{"unexpected field without ignore", map[string]any{"description": "uplink", "mtu": 9214}, nil, false},
+		{"json string document", `{"description":"uplink","mtu":9214,"counters":{"in-pkts":12345},"state":{"last-change":"1700000000"}}`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := Assertion{EqualsJSON: tt.expected, IgnorePaths: tt.ignore}
+			result := a.ValidateValue(value.JSON([]byte(actual)), true)
+			if result.Error != nil {
+				t.Fatalf("ValidateValue() error = %v", result.Error)
+			}
+			if result.Passed != tt.want {
+				t.Errorf("ValidateValue() passed = %v, want %v (diff: %s)", result.Passed, tt.want, result.Diff)
+			}
+			if !result.Passed && result.Diff == "" {
+				t.Error("failed comparison should describe the difference")
+			}
+		})
+	}
+}
+
+func TestJSONDiff_ListIgnore(t *testing.T) {
+	expected, _ := canonicalJSON(`{"neighbor":[{"address":"10.0.0.1","state":{"session-state":"ESTABLISHED"}}]}`)
+	actual, _ := canonicalJSON(`{"neighbor":[{"address":"10.0.0.1","state":{"session-state":"ESTABLISHED","messages":{"received":99}}}]}`)
+
+	if d := jsonDiff(expected, actual, []string{"neighbor/state/messages"}); d != "" {
+		t.Errorf("jsonDiff() = %q, want no difference", d)
+	}
+	if d := jsonDiff(expected, actual, nil); d == "" {
+		t.Error("jsonDiff() found no difference without ignore paths")
+	}
+}
+
+func TestLoadFile_EqualsJSONFile(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "golden.json"), []byte(`{"mtu": 9214}`), 0o644)
+	os.WriteFile(filepath.Join(dir, "assertions.yaml"), []byte(`
+targets:
+  - host: leaf1:6030
+    assertions:
+      - path: /interfaces/interface[name=Ethernet1]/config
+        equals_json_file: golden.json
+`), 0o644)
+
+	af, err := LoadFile(filepath.Join(dir, "assertions.yaml"))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	a := af.Targets[0].Assertions[0]
+	if result := a.ValidateValue(value.JSON([]byte(`{"mtu":9214}`)), true); !result.Passed {
+		t.Errorf("ValidateValue() failed: %s %v", result.Diff, result.Error)
+	}
+}
+
+func TestValidate_ContainsItem(t *testing.T) {
+	route := `{"openconfig-network-instance:next-hops":{"next-hop":[
+		{"index":"0","state":{"next-hop":"10.1.1.1","metric":10}},
+		{"index":"1","state":{"next-hop":"10.1.1.2","metric":10}}]}}`
+
+	tests := []struct {
+		name     string
+		item     any
+		listPath string
+		want     bool
+	}{
+		{"partial match", map[string]any{"state": map[string]any{"next-hop": "10.1.1.2"}}, "", true},
+		{"numeric field", map[string]any{"state": map[string]any{"metric": 10}}, "next-hops/next-hop", true},
+		{"no match", map[string]any{"state": map[string]any{"next-hop": "10.9.9.9"}}, "", false},
+		{"wrong list path", map[string]any{"index": "0"}, "next-hops/missing", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := Assertion{ContainsItem: tt.item, ListPath: tt.listPath}
+			result := a.ValidateValue(value.JSON([]byte(route)), true)
+			if result.Error != nil {
+				t.Fatalf("ValidateValue() error = %v", result.Error)
+			}
+			if result.Passed != tt.want {
+				t.Errorf("ValidateValue() passed = %v, want %v (diff: %s)", result.Passed, tt.want, result.Diff)
+			}
+		})
+	}
+}
+
+func TestValidate_Count(t *testing.T) {
+	entries := `{"openconfig-acl:acl-entry":[
+		{"sequence-id":10,"state":{"sequence-id":10}},
+		{"sequence-id":20,"state":{"sequence-id":20}}]}`
+	two, three := 2, 3
+
+	tests := []struct {
+		name     string
+		count    *int
+		listPath string
+		value    string
+		want     bool
+	}{
+		{"outermost list", &two, "", entries, true},
+		{"list path", &two, "acl-entry", entries, true},
+		{"wrong count", &three, "acl-entry", entries, false},
+		{"wrong list path", &two, "missing", entries, false},
+		{"no list", &two, "", `{"name":"PROTECT"}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := Assertion{Count: tt.count, ListPath: tt.listPath}
+			result := a.ValidateValue(value.JSON([]byte(tt.value)), true)
+			if result.Error != nil {
+				t.Fatalf("ValidateValue() error = %v", result.Error)
+			}
+			if result.Passed != tt.want {
+				t.Errorf("ValidateValue() passed = %v, want %v (diff: %s)", result.Passed, tt.want, result.Diff)
+			}
+		})
+	}
+}
+
+func TestSameJSON(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{`{"a": 1, "b": [1, 2]}`, `{"b": [2, 1], "a": 1}`, true},
+		{`[{"name": "x", "v": 1}, {"name": "y"}]`, `[{"name": "y"}, {"v": 1, "name": "x"}]`, true},
+		{`{"a": 1}`, `{"a": 2}`, false},
+		{`[1, 1, 2]`, `[1, 2, 2]`, false},
+		{`not json`, `not json`, false},
+	}
+	for _, tt := range tests {
+		if got := SameJSON(tt.a, tt.b); got != tt.want {
+			t.Errorf("SameJSON(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -1,8 +1,16 @@
 package assertion
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -14,29 +22,261 @@ func LoadFile(path string) (*AssertionFile, error) {
 		return nil, fmt.Errorf("reading file: %w", err)
 	}
 
-	return Parse(data)
+	return parse(data, filepath.Dir(path))
 }
 
-// Parse parses assertion YAML data
+// Parse parses assertion YAML data. Referenced files are resolved against
+// the working directory.
 func Parse(data []byte) (*AssertionFile, error) {
+	return parse(data, "")
+}
+
+func parse(data []byte, baseDir string) (*AssertionFile, error) {
+	// Unknown keys are usually misspelled checks, which would otherwise
+	// be silently ignored
 	var af AssertionFile
-	if err := yaml.Unmarshal(data, &af); err != nil {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&af); err != nil && !errors.Is(err, io.EOF) {
 		return nil, fmt.Errorf("parsing YAML: %w", err)
 	}
 
+	if err := af.Hooks.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Templates are validated before targets copy their assertions
+	for name, tmpl := range af.Templates {
+		for j := range tmpl.Assertions {
+			if err := validate(&tmpl.Assertions[j], baseDir); err != nil {
+				return nil, fmt.Errorf("template %s, assertion %d: %w", name, j, err)
+			}
+		}
+	}
+
 	// Validate and expand paths
 	for i, target := range af.Targets {
 		if target.GetHost() == "" {
 			return nil, fmt.Errorf("target %d: host is required", i)
 		}
-		for j, assertion := range target.Assertions {
-			if assertion.Path == "" {
-				return nil, fmt.Errorf("target %d, assertion %d: path is required", i, j)
+		platform, err := ParsePlatform(target.Platform)
+		if err != nil {
+			return nil, fmt.Errorf("target %d: %w", i, err)
+		}
+		af.Targets[i].Platform = string(platform)
+		for _, use := range target.Use {
+			assertions, err := af.instantiate(use)
+			if err != nil {
+				return nil, fmt.Errorf("target %d: %w", i, err)
+			}
+			af.Targets[i].Assertions = append(af.Targets[i].Assertions, assertions...)
+		}
+		for j := range target.Assertions {
+			if err := validate(&af.Targets[i].Assertions[j], baseDir); err != nil {
+				return nil, fmt.Errorf("target %d, assertion %d: %w", i, j, err)
+			}
+		}
+		// Expand short paths to full OpenConfig paths
+		af.Targets[i].ExpandPaths()
+	}
+
+	for j := range af.All {
+		if err := validate(&af.All[j], baseDir); err != nil {
+			return nil, fmt.Errorf("all, assertion %d: %w", j, err)
+		}
+	}
+	for group, assertions := range af.Groups {
+		for j := range assertions {
+			if err := validate(&assertions[j], baseDir); err != nil {
+				return nil, fmt.Errorf("group %s, assertion %d: %w", group, j, err)
 			}
-			// Expand short paths to full OpenConfig paths
-			af.Targets[i].Assertions[j].Path = ExpandPath(assertion.Path)
 		}
 	}
 
 	return &af, nil
 }
+
+// validate checks an assertion has what its kind of check needs and loads
+// any referenced files
+func validate(a *Assertion, baseDir string) error {
+	if err := expandMacro(a); err != nil {
+		return err
+	}
+	switch {
+	case a.Ping != nil:
+		if a.Ping.Destination == "" {
+			return fmt.Errorf("ping destination is required")
+		}
+	case a.Traceroute != nil:
+		if a.Traceroute.Destination == "" {
+			return fmt.Errorf("traceroute destination is required")
+		}
+	case a.MaxClockSkew != nil, a.OSVersion != nil:
+	case a.Path == "":
+		return fmt.Errorf("path is required")
+	}
+	if err := checkOperators(a); err != nil {
+		return err
+	}
+	if err := loadEqualsFile(a, baseDir); err != nil {
+		return err
+	}
+	return loadJSONFiles(a, baseDir)
+}
+
+// loadEqualsFile reads an equals_file into Equals. Relative paths are
+// resolved against baseDir; templated ones are read once rendered, by
+// ApplyMetadata.
+func loadEqualsFile(a *Assertion, baseDir string) error {
+	if a.EqualsFile == "" {
+		return nil
+	}
+	if !filepath.IsAbs(a.EqualsFile) && baseDir != "" {
+		a.EqualsFile = filepath.Join(baseDir, a.EqualsFile)
+	}
+	if strings.Contains(a.EqualsFile, "{{") {
+		return nil
+	}
+	return a.readEqualsFile()
+}
+
+// readEqualsFile sets Equals to the contents of EqualsFile
+func (a *Assertion) readEqualsFile() error {
+	data, err := os.ReadFile(a.EqualsFile)
+	if err != nil {
+		return fmt.Errorf("equals_file: %w", err)
+	}
+	expected := strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+	a.Equals = &expected
+	return nil
+}
+
+// checkOperators checks an assertion sets a check, or checks that combine,
+// and that its regex, numeric thresholds, and custom operator are valid. Templated
+// values are checked once rendered, at run time.
+func checkOperators(a *Assertion) error {
+	// Value checks combine, each having to pass; the others decide alone
+	var set, alone []string
+	switch {
+	case a.Consistent == "":
+	case a.Consistent != "identical" && a.Consistent != "unique":
+		return fmt.Errorf("consistent: %q must be identical or unique", a.Consistent)
+	case a.Absent != nil && *a.Absent, a.IsGNOI():
+		return fmt.Errorf("consistent needs a gNMI value to compare")
+	case a.Not:
+		return fmt.Errorf("not can't be combined with consistent")
+	}
+	if a.FreshWithin != nil {
+		switch {
+		case a.Absent != nil && *a.Absent:
+			return fmt.Errorf("fresh_within can't be combined with absent")
+		case a.IsGNOI():
+			return fmt.Errorf("fresh_within needs a gNMI path")
+		}
+		if !strings.Contains(*a.FreshWithin, "{{") {
+			if d, err := time.ParseDuration(*a.FreshWithin); err != nil || d <= 0 {
+				return fmt.Errorf("fresh_within: %q is not a positive duration", *a.FreshWithin)
+			}
+		}
+	}
+	for _, check := range []struct {
+		name    string
+		set     bool
+		combine bool
+	}{
+		{"equals", a.Equals != nil, false},
+		{"equals_file", a.EqualsFile != "", false},
+		{"contains", a.Contains != nil, true},
+		{"matches", a.Matches != nil, true},
+		{"exists", a.Exists != nil && *a.Exists, false},
+		{"absent", a.Absent != nil && *a.Absent, false},
+		{"gt", a.GT != nil, true},
+		{"lt", a.LT != nil, true},
+		{"gte", a.GTE != nil, true},
+		{"lte", a.LTE != nil, true},
+		{"equals_json", a.EqualsJSON != nil || a.EqualsJSONFile != "", false},
+		{"contains_item", a.ContainsItem != nil, false},
+		{"count", a.Count != nil, false},
+		{"operator", a.Operator != "", true},
+		{"ping", a.Ping != nil, false},
+		{"traceroute", a.Traceroute != nil, false},
+		{"max_clock_skew", a.MaxClockSkew != nil, false},
+		{"os_version", a.OSVersion != nil, false},
+	} {
+		if check.set {
+			set = append(set, check.name)
+			if !check.combine {
+				alone = append(alone, check.name)
+			}
+		}
+	}
+	switch {
+	case len(set) == 0 && (a.FreshWithin != nil || a.Consistent != ""):
+		// Alone, these check the value exists (and is recent, or consistent)
+	case len(set) == 0:
+		return fmt.Errorf("no check: set one of equals, contains, matches, exists, absent, gt/lt/gte/lte, equals_json, contains_item, count, or operator")
+	case len(alone) > 0 && len(set) > 1:
+		return fmt.Errorf("%s can't be combined with other checks (%s); only contains, matches, gt/lt/gte/lte, and operator combine", alone[0], strings.Join(set, ", "))
+	case a.GT != nil && a.GTE != nil:
+		return fmt.Errorf("gt and gte both set a lower bound: set one")
+	case a.LT != nil && a.LTE != nil:
+		return fmt.Errorf("lt and lte both set an upper bound: set one")
+	case a.Not && (a.Exists != nil && *a.Exists || a.Absent != nil && *a.Absent):
+		return fmt.Errorf("not can't invert %s: use exists or absent instead", alone[0])
+	case a.Not && a.IsGNOI():
+		return fmt.Errorf("not can't invert %s", alone[0])
+	}
+
+	if a.Matches != nil && !strings.Contains(*a.Matches, "{{") {
+		if _, err := regexp.Compile(*a.Matches); err != nil {
+			return fmt.Errorf("matches: invalid regex: %w", err)
+		}
+	}
+	for _, t := range []struct {
+		name      string
+		threshold *string
+	}{{"gt", a.GT}, {"lt", a.LT}, {"gte", a.GTE}, {"lte", a.LTE}} {
+		if t.threshold == nil || strings.Contains(*t.threshold, "{{") {
+			continue
+		}
+		if _, err := strconv.ParseFloat(*t.threshold, 64); err != nil {
+			return fmt.Errorf("%s: %q is not a number", t.name, *t.threshold)
+		}
+	}
+	if err := checkRange(a); err != nil {
+		return err
+	}
+	if a.Operator != "" {
+		if _, err := lookupOperator(a.Operator); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRange rejects lower and upper bounds no value can satisfy
+func checkRange(a *Assertion) error {
+	lowerName, lower, lowerStrict := "gte", a.GTE, false
+	if a.GT != nil {
+		lowerName, lower, lowerStrict = "gt", a.GT, true
+	}
+	upperName, upper, upperStrict := "lte", a.LTE, false
+	if a.LT != nil {
+		upperName, upper, upperStrict = "lt", a.LT, true
+	}
+	if lower == nil || upper == nil {
+		return nil
+	}
+	lo, err := strconv.ParseFloat(*lower, 64)
+	if err != nil {
+		return nil // Templated
+	}
+	hi, err := strconv.ParseFloat(*upper, 64)
+	if err != nil {
+		return nil
+	}
+	if lo > hi || (lo == hi && (lowerStrict || upperStrict)) {
+		return fmt.Errorf("%s %s and %s %s: no value is in range", lowerName, *lower, upperName, *upper)
+	}
+	return nil
+}
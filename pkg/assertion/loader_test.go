@@ -1,6 +1,10 @@
 package assertion
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -74,6 +78,84 @@ targets:
 	}
 }
 
+func TestParse_Strict(t *testing.T) {
+	tests := []struct {
+		name      string
+		assertion string
+		wantErr   string
+	}{
+		{"unknown key", "equal: UP", "field equal not found"},
+		{"no check", "name: just a path", "no check"},
+		{"equals with another check", "equals: UP\n        contains: U", "equals can't be combined with other checks (equals, contains)"},
+		{"exists with a value check", "exists: true\n        matches: UP", "exists can't be combined"},
+		{"contains and matches", "contains: Ethernet\n        matches: '\\d+$'", ""},
+		{"two lower bounds", "gt: \"1\"\n        gte: \"2\"", "gt and gte both set a lower bound"},
+		{"empty range", "gte: \"9214\"\n        lte: \"1500\"", "gte 9214 and lte 1500: no value is in range"},
+		{"empty strict range", "gt: \"5\"\n        lte: \"5\"", "no value is in range"},
+		{"single-value range", "gte: \"5\"\n        lte: \"5\"", ""},
+		{"not", "matches: '^Ethernet'\n        not: true", ""},
+		{"not exists", "exists: true\n        not: true", "use exists or absent instead"},
+		{"fresh_within alone", "fresh_within: 5m", ""},
+		{"fresh_within with equals", "equals: \"9214\"\n        fresh_within: 30s", ""},
+		{"fresh_within with absent", "absent: true\n        fresh_within: 5m", "can't be combined with absent"},
+		{"consistent alone", "consistent: identical", ""},
+		{"consistent mode", "consistent: same", `"same" must be identical or unique`},
+		{"not consistent", "consistent: unique\n        not: true", "not can't be combined with consistent"},
+		{"fresh_within not a duration", "fresh_within: soon", `"soon" is not a positive duration`},
+		{"invalid regex", "matches: '[a-'", "invalid regex"},
+		{"non-numeric threshold", "gt: lots", `gt: "lots" is not a number`},
+		{"range", "gte: \"1\"\n        lte: \"9214\"", ""},
+		{"templated threshold", "gt: '{{ .min }}'", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(`
+targets:
+  - host: spine1:6030
+    assertions:
+      - path: /interfaces/interface[name=Ethernet1]/state/mtu
+        ` + tt.assertion + `
+`))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Parse() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParse_Ping(t *testing.T) {
+	yaml := `
+targets:
+  - host: leaf1:6030
+    assertions:
+      - ping:
+          destination: 10.0.0.2
+          network_instance: PROD
+          max_rtt: 20ms
+`
+	af, err := Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	a := af.Targets[0].Assertions[0]
+	if a.Ping == nil || a.Ping.Destination != "10.0.0.2" || a.Path != "" {
+		t.Errorf("ping assertion = %+v", a)
+	}
+	if a.GetName() != "ping 10.0.0.2" {
+		t.Errorf("GetName() = %q, want %q", a.GetName(), "ping 10.0.0.2")
+	}
+
+	if _, err := Parse([]byte("targets:\n  - host: leaf1\n    assertions:\n      - ping: {count: 3}\n")); err == nil {
+		t.Error("expected error for ping without destination")
+	}
+}
+
 func TestParse_InvalidYAML(t *testing.T) {
 	yaml := `
 this is not valid yaml: [
@@ -114,3 +196,132 @@ targets:
 		t.Errorf("got %d targets, want 2", len(af.Targets))
 	}
 }
+
+func TestParse_SharedSections(t *testing.T) {
+	yaml := `
+all:
+  - path: system/state/hostname
+    exists: true
+groups:
+  spines:
+    - path: bgp[default]/global/state/as
+      equals: "65000"
+  leaves:
+    - path: /leaf
+      exists: true
+targets:
+  - host: spine1:6030
+    assertions:
+      - path: /own
+        equals: "a"
+  - host: leaf1:6030
+    platform: cisco_xr
+`
+	af, err := Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	af.Targets[0].Groups = []string{"spines", "fabric"}
+	af.Targets[1].Groups = []string{"leaves"}
+	af.ApplyShared()
+
+	want := [][]string{
+		{"/own", "/network-instances/network-instance[name=default]/protocols/protocol[identifier=BGP][name=BGP]/bgp/global/state/as", "/system/state/hostname"},
+		{"/leaf", "/system/state/hostname"},
+	}
+	for i, target := range af.Targets {
+		var paths []string
+		for _, a := range target.Assertions {
+			paths = append(paths, a.Path)
+		}
+		if !reflect.DeepEqual(paths, want[i]) {
+			t.Errorf("target %s paths = %v, want %v", target.GetHost(), paths, want[i])
+		}
+	}
+}
+
+func TestParse_SharedSectionMissingPath(t *testing.T) {
+	yaml := `
+groups:
+  spines:
+    - equals: "a"
+`
+	if _, err := Parse([]byte(yaml)); err == nil || !strings.Contains(err.Error(), "group spines, assertion 0") {
+		t.Errorf("Parse() error = %v, want group spines error", err)
+	}
+}
+
+func TestParse_Hooks(t *testing.T) {
+	yaml := `
+hooks:
+  before_target:
+    - set:
+        path: interface[Ethernet1]/config/description
+        value: under test
+  after:
+    - exec: echo done
+targets:
+  - host: spine1:6030
+    assertions: []
+`
+	af, err := Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(af.Hooks.BeforeTarget) != 1 || af.Hooks.BeforeTarget[0].Set.Value != "under test" || len(af.Hooks.After) != 1 {
+		t.Errorf("Hooks = %+v", af.Hooks)
+	}
+
+	invalid := `
+hooks:
+  before:
+    - set: {path: /system/config/hostname, value: x}
+targets: []
+`
+	if _, err := Parse([]byte(invalid)); err == nil || !strings.Contains(err.Error(), "set needs a target") {
+		t.Errorf("Parse() error = %v, want set needs a target", err)
+	}
+}
+
+func TestLoadFile_EqualsFile(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "golden"), 0o755)
+	os.WriteFile(filepath.Join(dir, "golden", "banner.txt"), []byte("Authorized {{ use }} only\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "golden", "spine1-as.txt"), []byte("65001\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "assertions.yaml"), []byte(`
+targets:
+  - host: spine1
+    assertions:
+      - path: /system/config/login-banner
+        equals_file: golden/banner.txt
+      - path: /bgp/global/state/as
+        equals_file: golden/{{ .host }}-as.txt
+`), 0o644)
+
+	af, err := LoadFile(filepath.Join(dir, "assertions.yaml"))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	target := af.Targets[0]
+	if err := target.ApplyMetadata(); err != nil {
+		t.Fatalf("ApplyMetadata() error = %v", err)
+	}
+	banner, as := target.Assertions[0], target.Assertions[1]
+	if !banner.Validate("Authorized {{ use }} only", true).Passed {
+		t.Errorf("banner Equals = %q, want the file contents as written", *banner.Equals)
+	}
+	if !as.Validate("65001", true).Passed {
+		t.Errorf("templated equals_file Equals = %v, want spine1's golden value", as.Equals)
+	}
+
+	os.WriteFile(filepath.Join(dir, "missing.yaml"), []byte(`
+targets:
+  - host: spine1
+    assertions:
+      - path: /system/config/login-banner
+        equals_file: golden/motd.txt
+`), 0o644)
+	if _, err := LoadFile(filepath.Join(dir, "missing.yaml")); err == nil || !strings.Contains(err.Error(), "equals_file") {
+		t.Errorf("LoadFile() with a missing golden file error = %v", err)
+	}
+}
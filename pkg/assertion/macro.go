@@ -0,0 +1,88 @@
+package assertion
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LLDPNeighbor is the lldp_neighbor macro: the device on the far side of a
+// local interface
+type LLDPNeighbor struct {
+	Local  string `yaml:"local"`
+	Remote string `yaml:"remote"` // System name, bare or as the host part of an FQDN
+}
+
+// expandMacro replaces a bgp_established, interface_up, or lldp_neighbor
+// shorthand with the path and check it stands for, naming the assertion if
+// it has no name. Paths are short paths, expanded per platform.
+func expandMacro(a *Assertion) error {
+	var macros []string
+	if a.BGPEstablished != "" {
+		macros = append(macros, "bgp_established")
+	}
+	if a.InterfaceUp != "" {
+		macros = append(macros, "interface_up")
+	}
+	if a.LLDPNeighbor != nil {
+		macros = append(macros, "lldp_neighbor")
+	}
+	switch {
+	case len(macros) == 0:
+		return nil
+	case len(macros) > 1:
+		return fmt.Errorf("%s and %s: set one", macros[0], macros[1])
+	case a.Path != "":
+		return fmt.Errorf("%s sets its own path: drop path", macros[0])
+	case a.Equals != nil || a.Contains != nil || a.Matches != nil:
+		return fmt.Errorf("%s sets its own check", macros[0])
+	}
+
+	var name, path, want string
+	switch {
+	case a.BGPEstablished != "":
+		name = fmt.Sprintf("BGP peer %s established", a.BGPEstablished)
+		path = fmt.Sprintf("bgp[default]/neighbors/neighbor[neighbor-address=%s]/state/session-state", a.BGPEstablished)
+		want = "ESTABLISHED"
+		a.Equals = &want
+	case a.InterfaceUp != "":
+		name = fmt.Sprintf("%s is up", a.InterfaceUp)
+		path = fmt.Sprintf("interface[%s]/state/oper-status", a.InterfaceUp)
+		want = "UP"
+		a.Equals = &want
+	default:
+		n := a.LLDPNeighbor
+		if n.Local == "" || n.Remote == "" {
+			return fmt.Errorf("lldp_neighbor needs local and remote")
+		}
+		name = fmt.Sprintf("LLDP %s connects to %s", n.Local, n.Remote)
+		path = fmt.Sprintf("lldp/interfaces/interface[name=%s]/neighbors/neighbor/state/system-name", n.Local)
+		// Devices report bare or fully qualified names: the remote, then
+		// nothing or a domain, so spine1 doesn't match spine10
+		want = "^" + quoteTemplate(n.Remote) + `(\.|$)`
+		a.Matches = &want
+	}
+	if a.Name == "" {
+		a.Name = name
+	}
+	a.Path = path
+	a.BGPEstablished, a.InterfaceUp, a.LLDPNeighbor = "", "", nil
+	return nil
+}
+
+// templateAction matches a {{ ... }} template action
+var templateAction = regexp.MustCompile(`\{\{-?\s*(.*?)\s*-?\}\}`)
+
+// quoteTemplate quotes s for a regexp, leaving template actions to quote
+// what they render
+func quoteTemplate(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range templateAction.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(regexp.QuoteMeta(s[last:m[0]]))
+		b.WriteString("{{ " + s[m[2]:m[3]] + " | quotemeta }}")
+		last = m[1]
+	}
+	b.WriteString(regexp.QuoteMeta(s[last:]))
+	return b.String()
+}
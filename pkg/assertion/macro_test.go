@@ -0,0 +1,80 @@
+package assertion
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandMacro(t *testing.T) {
+	af, err := Parse([]byte(`
+targets:
+  - host: spine1:6030
+    metadata: {peer: spine2.dc1}
+    assertions:
+      - bgp_established: 10.0.0.1
+      - interface_up: "{{ item }}"
+        for_each: [Ethernet1]
+      - name: Uplink to spine1
+        lldp_neighbor: {local: Ethernet49/1, remote: spine1}
+      - lldp_neighbor: {local: Ethernet50/1, remote: "{{ .peer }}"}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := af.Targets[0]
+	if err := target.ApplyMetadata(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, path, value string
+		wrong             []string // Values that must fail
+	}{
+		{"BGP peer 10.0.0.1 established", "/network-instances/network-instance[name=default]/protocols/protocol[identifier=BGP][name=BGP]/bgp/neighbors/neighbor[neighbor-address=10.0.0.1]/state/session-state", "ESTABLISHED", nil},
+		{"Ethernet1 is up", "/interfaces/interface[name=Ethernet1]/state/oper-status", "UP", []string{"DOWN"}},
+		{"Uplink to spine1", "/lldp/interfaces/interface[name=Ethernet49/1]/neighbors/neighbor/state/system-name", "spine1.example.com",
+			[]string{"spine10", "spine11.example.com", "myspine1", "spine1-old"}},
+		{"LLDP Ethernet50/1 connects to spine2.dc1", "/lldp/interfaces/interface[name=Ethernet50/1]/neighbors/neighbor/state/system-name", "spine2.dc1",
+			[]string{"spine2xdc1", "spine2.dc10"}},
+	}
+	for i, tt := range tests {
+		a := target.Assertions[i]
+		if a.GetName() != tt.name || a.Path != tt.path {
+			t.Errorf("assertion %d = %s @ %s, want %s @ %s", i, a.GetName(), a.Path, tt.name, tt.path)
+		}
+		if !a.Validate(tt.value, true).Passed {
+			t.Errorf("%s failed for %q", tt.name, tt.value)
+		}
+		for _, wrong := range tt.wrong {
+			if a.Validate(wrong, true).Passed {
+				t.Errorf("%s passed for %q", tt.name, wrong)
+			}
+		}
+	}
+	if bare := target.Assertions[2]; !bare.Validate("spine1", true).Passed {
+		t.Errorf("%s failed for a bare name", bare.GetName())
+	}
+}
+
+func TestExpandMacroErrors(t *testing.T) {
+	tests := []struct {
+		assertion string
+		wantErr   string
+	}{
+		{"interface_up: Ethernet1\n        path: /interfaces", "sets its own path"},
+		{"interface_up: Ethernet1\n        equals: DOWN", "sets its own check"},
+		{"interface_up: Ethernet1\n        bgp_established: 10.0.0.1", "bgp_established and interface_up: set one"},
+		{"lldp_neighbor: {local: Ethernet1}", "needs local and remote"},
+	}
+	for _, tt := range tests {
+		_, err := Parse([]byte(`
+targets:
+  - host: spine1:6030
+    assertions:
+      - ` + tt.assertion + `
+`))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Parse(%q) error = %v, want %q", tt.assertion, err, tt.wantErr)
+		}
+	}
+}
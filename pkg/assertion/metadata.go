@@ -0,0 +1,179 @@
+package assertion
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// Applies reports whether target metadata satisfies a when: condition.
+// Every key must be set to the given value, or to one of several
+// comma-separated values.
+func (a *Assertion) Applies(metadata map[string]string) bool {
+	for key, want := range a.When {
+		actual, ok := metadata[key]
+		if !ok || !containsValue(want, actual) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsValue reports whether value is one of the comma-separated values in list
+func containsValue(list, value string) bool {
+	for _, v := range strings.Split(list, ",") {
+		if strings.TrimSpace(v) == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyMetadata drops assertions whose when: condition the target doesn't
+// match (host and platform can be matched too) and renders {{ .key }}
+// templates in names, paths, and expected values from the target's
+// metadata, host, and platform, and template arguments. Assertions with
+// for_each are repeated per item, available as {{ item }}. Call it once
+// inventory metadata and shared assertions have been applied.
+func (t *Target) ApplyMetadata() error {
+	targetData := map[string]string{
+		"host":     t.GetHost(),
+		"platform": t.Platform,
+	}
+	maps.Copy(targetData, t.Metadata)
+
+	var assertions []Assertion
+	for _, a := range t.Assertions {
+		data := targetData
+		if len(a.Args) > 0 {
+			data = maps.Clone(targetData)
+			maps.Copy(data, a.Args)
+		}
+
+		// for_each makes one assertion per item
+		instances := []map[string]string{data}
+		if len(a.ForEach) > 0 {
+			instances = nil
+			for _, item := range a.ForEach {
+				d := maps.Clone(data)
+				d["item"] = item
+				instances = append(instances, d)
+			}
+		}
+
+		for _, data := range instances {
+			instance := a
+			instance.ForEach = nil
+			if !instance.Applies(data) {
+				continue
+			}
+			if err := instance.render(data); err != nil {
+				return fmt.Errorf("assertion %s: %w", a.GetName(), err)
+			}
+			assertions = append(assertions, instance)
+		}
+	}
+	t.Assertions = assertions
+	t.ExpandPaths()
+	return nil
+}
+
+// render fills in the templates in an assertion's name, path, expected
+// values and thresholds, operator argument, ping or traceroute
+// destination, and dependencies, and reads an equals_file
+// whose path is templated. Golden file contents aren't templates.
+func (a *Assertion) render(data map[string]string) error {
+	if a.RawPath == "" {
+		a.RawPath = a.Path
+	}
+	if a.EqualsFile != "" && a.Equals == nil {
+		path, err := render(a.EqualsFile, data)
+		if err != nil {
+			return err
+		}
+		a.EqualsFile = path
+		if err := a.readEqualsFile(); err != nil {
+			return err
+		}
+	}
+	for _, s := range []*string{&a.Name, &a.Description, &a.RawPath, &a.Arg} {
+		rendered, err := render(*s, data)
+		if err != nil {
+			return err
+		}
+		*s = rendered
+	}
+	// Expected values are pointers shared by every target the
+	// assertion was copied to, so rendered values get their own
+	for _, p := range []**string{&a.Equals, &a.Contains, &a.Matches, &a.GT, &a.LT, &a.GTE, &a.LTE, &a.FreshWithin} {
+		if *p == nil || (p == &a.Equals && a.EqualsFile != "") {
+			continue
+		}
+		rendered, err := render(**p, data)
+		if err != nil {
+			return err
+		}
+		*p = &rendered
+	}
+	// As are ping and traceroute checks
+	if a.Ping != nil {
+		destination, err := render(a.Ping.Destination, data)
+		if err != nil {
+			return err
+		}
+		ping := *a.Ping
+		ping.Destination = destination
+		a.Ping = &ping
+	}
+	if a.Traceroute != nil {
+		destination, err := render(a.Traceroute.Destination, data)
+		if err != nil {
+			return err
+		}
+		traceroute := *a.Traceroute
+		traceroute.Destination = destination
+		a.Traceroute = &traceroute
+	}
+	if len(a.DependsOn) > 0 {
+		dependsOn := make([]string, len(a.DependsOn))
+		for i, name := range a.DependsOn {
+			rendered, err := render(name, data)
+			if err != nil {
+				return err
+			}
+			dependsOn[i] = rendered
+		}
+		a.DependsOn = dependsOn
+	}
+	return nil
+}
+
+// render executes text as a template over data, failing on unknown keys.
+// The for_each item is available as {{ item }} as well as {{ .item }}, and
+// quotemeta quotes a value for use in a regexp.
+func render(text string, data map[string]string) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	funcs := template.FuncMap{
+		"item": func() (string, error) {
+			item, ok := data["item"]
+			if !ok {
+				return "", fmt.Errorf("item is only set in for_each assertions")
+			}
+			return item, nil
+		},
+		"quotemeta": regexp.QuoteMeta,
+	}
+	tmpl, err := template.New("").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,114 @@
+package assertion
+
+import (
+	"testing"
+
+	"github.com/ndtobs/netsert/pkg/value"
+)
+
+func TestApplyMetadata(t *testing.T) {
+	shared := ptr("{{ .site }}-spine1")
+	target := Target{
+		Host:     "spine1:6030",
+		Metadata: map[string]string{"site": "dc1", "role": "spine"},
+		Assertions: []Assertion{
+			{Name: "Hostname is {{ .site }}-spine1", Path: "system/state/hostname", Equals: shared},
+			{Name: "Leaf only", Path: "/leaf", Exists: boolPtr(true), When: map[string]string{"role": "leaf"}},
+			{Name: "Spine or border", Path: "bgp[{{ .site }}]/global/state/as", Exists: boolPtr(true), When: map[string]string{"role": "spine, border"}},
+			{Name: "Unknown key", Path: "/x", Exists: boolPtr(true), When: map[string]string{"pod": "1"}},
+		},
+	}
+
+	if err := target.ApplyMetadata(); err != nil {
+		t.Fatalf("ApplyMetadata() error = %v", err)
+	}
+	if len(target.Assertions) != 2 {
+		t.Fatalf("got %d assertions, want 2", len(target.Assertions))
+	}
+
+	a := target.Assertions[0]
+	if a.Name != "Hostname is dc1-spine1" || a.Path != "/system/state/hostname" || *a.Equals != "dc1-spine1" {
+		t.Errorf("assertion 0 = %q %s %s", a.Name, a.Path, *a.Equals)
+	}
+	if *shared != "{{ .site }}-spine1" {
+		t.Errorf("shared expected value rendered in place: %s", *shared)
+	}
+	want := "/network-instances/network-instance[name=dc1]/protocols/protocol[identifier=BGP][name=BGP]/bgp/global/state/as"
+	if got := target.Assertions[1].Path; got != want {
+		t.Errorf("assertion 1 path = %s, want %s", got, want)
+	}
+}
+
+func TestApplyMetadataMissingKey(t *testing.T) {
+	target := Target{
+		Host:       "spine1:6030",
+		Assertions: []Assertion{{Name: "{{ .site }}", Path: "/x", Exists: boolPtr(true)}},
+	}
+	if err := target.ApplyMetadata(); err == nil {
+		t.Error("expected error for a template key with no metadata")
+	}
+}
+
+func TestApplyMetadataForEach(t *testing.T) {
+	target := Target{
+		Host: "leaf1:6030",
+		Assertions: []Assertion{{
+			Name:    "{{ item }} is UP",
+			Path:    "interface[{{ item }}]/state/oper-status",
+			Equals:  ptr("UP"),
+			ForEach: []string{"Ethernet1", "Ethernet2", "Ethernet49/1"},
+		}},
+	}
+	if err := target.ApplyMetadata(); err != nil {
+		t.Fatalf("ApplyMetadata() error = %v", err)
+	}
+
+	want := []string{"Ethernet1", "Ethernet2", "Ethernet49/1"}
+	if len(target.Assertions) != len(want) {
+		t.Fatalf("got %d assertions, want %d", len(target.Assertions), len(want))
+	}
+	for i, a := range target.Assertions {
+		if a.Name != want[i]+" is UP" || a.Path != "/interfaces/interface[name="+want[i]+"]/state/oper-status" || a.ForEach != nil {
+			t.Errorf("assertion %d = %q %s", i, a.Name, a.Path)
+		}
+	}
+
+	outside := Target{Host: "leaf1:6030", Assertions: []Assertion{{Name: "{{ item }}", Path: "/x", Exists: boolPtr(true)}}}
+	if err := outside.ApplyMetadata(); err == nil {
+		t.Error("expected error for item outside for_each")
+	}
+}
+
+func TestApplyMetadataThresholdsAndDestinations(t *testing.T) {
+	ping := &PingCheck{Destination: "{{ item }}"}
+	target := Target{
+		Host:     "leaf1:6030",
+		Metadata: map[string]string{"min": "2"},
+		Assertions: []Assertion{
+			{Name: "Enough peers", Path: "/x", GTE: ptr("{{ .min }}")},
+			{Name: "Reach {{ item }}", Ping: ping, ForEach: []string{"10.0.0.1", "10.0.0.2"}},
+			{Name: "Trace", Traceroute: &TracerouteCheck{Destination: "{{ .min }}.0.0.9"}},
+		},
+	}
+	if err := target.ApplyMetadata(); err != nil {
+		t.Fatalf("ApplyMetadata() error = %v", err)
+	}
+	if len(target.Assertions) != 4 {
+		t.Fatalf("got %d assertions, want 4", len(target.Assertions))
+	}
+
+	if got := target.Assertions[0].ValidateValue(value.String("3"), true); !got.Passed || got.Error != nil {
+		t.Errorf("gte {{ .min }} against 3 = %v, %v; want a pass", got.Passed, got.Error)
+	}
+	for i, want := range []string{"10.0.0.1", "10.0.0.2"} {
+		if got := target.Assertions[i+1].Ping.Destination; got != want {
+			t.Errorf("ping %d destination = %s, want %s", i, got, want)
+		}
+	}
+	if ping.Destination != "{{ item }}" {
+		t.Errorf("shared ping check rendered in place: %s", ping.Destination)
+	}
+	if got := target.Assertions[3].Traceroute.Destination; got != "2.0.0.9" {
+		t.Errorf("traceroute destination = %s, want 2.0.0.9", got)
+	}
+}
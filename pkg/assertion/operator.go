@@ -0,0 +1,55 @@
+package assertion
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// OperatorFunc reports whether an actual value satisfies a custom operator,
+// given the assertion's arg (empty if none was written)
+type OperatorFunc func(actual, arg string) (bool, error)
+
+var (
+	operatorsMu sync.RWMutex
+	operators   = map[string]OperatorFunc{}
+)
+
+// RegisterOperator adds a custom operator, used in assertions as
+// operator: <name> with an optional arg:. Register operators before loading
+// the assertion files that use them.
+func RegisterOperator(name string, fn OperatorFunc) error {
+	if name == "" || fn == nil {
+		return fmt.Errorf("operator needs a name and a function")
+	}
+	operatorsMu.Lock()
+	defer operatorsMu.Unlock()
+	if _, ok := operators[name]; ok {
+		return fmt.Errorf("operator %s is already registered", name)
+	}
+	operators[name] = fn
+	return nil
+}
+
+// Operators lists the registered custom operators, sorted
+func Operators() []string {
+	operatorsMu.RLock()
+	defer operatorsMu.RUnlock()
+	names := make([]string, 0, len(operators))
+	for name := range operators {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// lookupOperator returns a registered operator
+func lookupOperator(name string) (OperatorFunc, error) {
+	operatorsMu.RLock()
+	defer operatorsMu.RUnlock()
+	fn, ok := operators[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown operator %s", name)
+	}
+	return fn, nil
+}
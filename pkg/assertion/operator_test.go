@@ -0,0 +1,61 @@
+package assertion
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestRegisterOperator(t *testing.T) {
+	err := RegisterOperator("test-valid-mac", func(actual, _ string) (bool, error) {
+		_, err := net.ParseMAC(actual)
+		return err == nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = RegisterOperator("test-has-prefix", func(actual, arg string) (bool, error) {
+		return strings.HasPrefix(actual, arg), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterOperator("test-valid-mac", func(string, string) (bool, error) { return true, nil }); err == nil {
+		t.Error("RegisterOperator() accepted a duplicate name")
+	}
+
+	tests := []struct {
+		name      string
+		assertion Assertion
+		actual    string
+		want      bool
+		wantErr   bool
+	}{
+		{"valid mac", Assertion{Operator: "test-valid-mac"}, "00:1c:73:aa:bb:cc", true, false},
+		{"invalid mac", Assertion{Operator: "test-valid-mac"}, "Ethernet1", false, false},
+		{"with arg", Assertion{Operator: "test-has-prefix", Arg: "10."}, "10.0.0.1", true, false},
+		{"unknown", Assertion{Operator: "test-missing"}, "x", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.assertion.Validate(tt.actual, true)
+			if (result.Error != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", result.Error, tt.wantErr)
+			}
+			if result.Passed != tt.want {
+				t.Errorf("Validate() = %v, want %v", result.Passed, tt.want)
+			}
+		})
+	}
+
+	_, err = Parse([]byte(`
+targets:
+  - host: spine1:6030
+    assertions:
+      - path: /system/state/hostname
+        operator: test-missing
+`))
+	if err == nil || !strings.Contains(err.Error(), "unknown operator test-missing") {
+		t.Errorf("Parse() error = %v, want unknown operator", err)
+	}
+}
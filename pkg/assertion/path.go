@@ -1,8 +1,11 @@
 package assertion
 
 import (
+	"fmt"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 )
 
 // PathPrefix defines a short path prefix and its expansion
@@ -13,6 +16,105 @@ type PathPrefix struct {
 	Regex *regexp.Regexp
 	// Template for expansion, use {instance} for the captured value
 	Template string
+	// Protocol identifier substituted for {protocol} using the platform's instance naming
+	Protocol string
+
+	name    string         // Set for prefixes added with SetPathPrefix
+	compact *regexp.Regexp // Matches expanded paths, for CompactPathFor
+}
+
+var (
+	customPrefixesMu sync.RWMutex
+	customPrefixes   []PathPrefix
+)
+
+// keyValue captures a key value up to its closing bracket, keeping any \]
+// or \\ escapes in it
+const keyValue = `((?:[^\]\\]|\\.)+)`
+
+// prefixNameRegex matches the names SetPathPrefix accepts
+var prefixNameRegex = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// SetPathPrefix adds a short path prefix of your own, replacing one of the
+// same name and shadowing a built-in one. The template is the expanded
+// path, ending in {rest}; with {instance} in it, the short form is
+// name[<instance>]/<rest>, otherwise name/<rest>. Set prefixes before
+// loading the assertion files using them.
+func SetPathPrefix(name, template string) error {
+	if !prefixNameRegex.MatchString(name) {
+		return fmt.Errorf("short path %q: names are lowercase letters, digits, and dashes", name)
+	}
+	rest, ok := strings.CutSuffix(template, "{rest}")
+	if !ok || strings.Contains(rest, "{rest}") || strings.Count(rest, "{instance}") > 1 {
+		return fmt.Errorf("short path %s: template must end in {rest}, with {instance} at most once before it", name)
+	}
+	if IsShortPath(template) {
+		return fmt.Errorf("short path %s: template must be an absolute path", name)
+	}
+
+	prefix := PathPrefix{name: name}
+	compact := regexp.QuoteMeta(rest)
+	if strings.Contains(rest, "{instance}") {
+		prefix.Pattern = name + "["
+		prefix.Regex = regexp.MustCompile(`^` + regexp.QuoteMeta(name) + `\[` + keyValue + `\]/(.*)$`)
+		prefix.Template = template
+		compact = strings.Replace(compact, regexp.QuoteMeta("{instance}"), keyValue, 1)
+	} else {
+		// As with the built-in lldp/ and system/, the one capture is {instance}
+		prefix.Pattern = name + "/"
+		prefix.Regex = regexp.MustCompile(`^` + regexp.QuoteMeta(name) + `/(.*)$`)
+		prefix.Template = rest + "{instance}"
+	}
+	prefix.compact = regexp.MustCompile(`^` + compact + `(.*)$`)
+
+	customPrefixesMu.Lock()
+	defer customPrefixesMu.Unlock()
+	customPrefixes = slices.DeleteFunc(customPrefixes, func(p PathPrefix) bool { return p.name == name })
+	customPrefixes = append(customPrefixes, prefix)
+	return nil
+}
+
+// allPathPrefixes returns the prefixes set with SetPathPrefix, then the
+// built-in ones they don't shadow
+func allPathPrefixes() []PathPrefix {
+	customPrefixesMu.RLock()
+	defer customPrefixesMu.RUnlock()
+	prefixes := slices.Clone(customPrefixes)
+	for _, p := range pathPrefixes {
+		if !shadowed(p.Pattern) {
+			prefixes = append(prefixes, p)
+		}
+	}
+	return prefixes
+}
+
+// shadowed reports whether a prefix set with SetPathPrefix has the name
+// that a short path (or prefix pattern) starts with. Callers hold
+// customPrefixesMu.
+func shadowed(short string) bool {
+	name := short
+	if i := strings.IndexAny(short, "[/"); i >= 0 {
+		name = short[:i]
+	}
+	return slices.ContainsFunc(customPrefixes, func(p PathPrefix) bool { return p.name == name })
+}
+
+// compactCustom converts a path to the short form of a prefix set with
+// SetPathPrefix, if one matches
+func compactCustom(path string) (string, bool) {
+	customPrefixesMu.RLock()
+	defer customPrefixesMu.RUnlock()
+	for _, p := range customPrefixes {
+		matches := p.compact.FindStringSubmatch(path)
+		switch {
+		case matches == nil:
+		case len(matches) == 3:
+			return p.name + "[" + matches[1] + "]/" + matches[2], true
+		default:
+			return p.name + "/" + matches[1], true
+		}
+	}
+	return "", false
 }
 
 // pathPrefixes defines the known short path prefixes and their expansions
@@ -20,27 +122,80 @@ var pathPrefixes = []PathPrefix{
 	{
 		// bgp[<network-instance>]/... -> /network-instances/network-instance[name=<ni>]/protocols/protocol[identifier=BGP][name=BGP]/bgp/...
 		Pattern:  "bgp[",
-		Regex:    regexp.MustCompile(`^bgp\[([^\]]+)\]/(.*)$`),
-		Template: "/network-instances/network-instance[name={instance}]/protocols/protocol[identifier=BGP][name=BGP]/bgp/{rest}",
+		Regex:    regexp.MustCompile(`^bgp\[` + keyValue + `\]/(.*)$`),
+		Template: "/network-instances/network-instance[name={instance}]/protocols/protocol[identifier=BGP][name={protocol}]/bgp/{rest}",
+		Protocol: "BGP",
 	},
 	{
 		// ospf[<network-instance>]/... -> /network-instances/network-instance[name=<ni>]/protocols/protocol[identifier=OSPF][name=OSPF]/ospf/...
 		Pattern:  "ospf[",
-		Regex:    regexp.MustCompile(`^ospf\[([^\]]+)\]/(.*)$`),
-		Template: "/network-instances/network-instance[name={instance}]/protocols/protocol[identifier=OSPF][name=OSPF]/ospf/{rest}",
+		Regex:    regexp.MustCompile(`^ospf\[` + keyValue + `\]/(.*)$`),
+		Template: "/network-instances/network-instance[name={instance}]/protocols/protocol[identifier=OSPF][name={protocol}]/ospf/{rest}",
+		Protocol: "OSPF",
 	},
 	{
 		// isis[<network-instance>]/... -> /network-instances/network-instance[name=<ni>]/protocols/protocol[identifier=ISIS][name=ISIS]/isis/...
 		Pattern:  "isis[",
-		Regex:    regexp.MustCompile(`^isis\[([^\]]+)\]/(.*)$`),
-		Template: "/network-instances/network-instance[name={instance}]/protocols/protocol[identifier=ISIS][name=ISIS]/isis/{rest}",
+		Regex:    regexp.MustCompile(`^isis\[` + keyValue + `\]/(.*)$`),
+		Template: "/network-instances/network-instance[name={instance}]/protocols/protocol[identifier=ISIS][name={protocol}]/isis/{rest}",
+		Protocol: "ISIS",
+	},
+	{
+		// pim[<network-instance>]/... -> /network-instances/network-instance[name=<ni>]/protocols/protocol[identifier=PIM][name=PIM]/pim/...
+		Pattern:  "pim[",
+		Regex:    regexp.MustCompile(`^pim\[` + keyValue + `\]/(.*)$`),
+		Template: "/network-instances/network-instance[name={instance}]/protocols/protocol[identifier=PIM][name={protocol}]/pim/{rest}",
+		Protocol: "PIM",
+	},
+	{
+		// igmp[<network-instance>]/... -> /network-instances/network-instance[name=<ni>]/protocols/protocol[identifier=IGMP][name=IGMP]/igmp/...
+		Pattern:  "igmp[",
+		Regex:    regexp.MustCompile(`^igmp\[` + keyValue + `\]/(.*)$`),
+		Template: "/network-instances/network-instance[name={instance}]/protocols/protocol[identifier=IGMP][name={protocol}]/igmp/{rest}",
+		Protocol: "IGMP",
 	},
 	{
 		// interface[<name>]/... -> /interfaces/interface[name=<name>]/...
 		Pattern:  "interface[",
-		Regex:    regexp.MustCompile(`^interface\[([^\]]+)\]/(.*)$`),
+		Regex:    regexp.MustCompile(`^interface\[` + keyValue + `\]/(.*)$`),
 		Template: "/interfaces/interface[name={instance}]/{rest}",
 	},
+	{
+		// component[<name>]/... -> /components/component[name=<name>]/...
+		Pattern:  "component[",
+		Regex:    regexp.MustCompile(`^component\[` + keyValue + `\]/(.*)$`),
+		Template: "/components/component[name={instance}]/{rest}",
+	},
+	{
+		// policy[<name>]/... -> /routing-policy/policy-definitions/policy-definition[name=<name>]/...
+		Pattern:  "policy[",
+		Regex:    regexp.MustCompile(`^policy\[` + keyValue + `\]/(.*)$`),
+		Template: "/routing-policy/policy-definitions/policy-definition[name={instance}]/{rest}",
+	},
+	{
+		// routing-policy/... -> /routing-policy/...
+		Pattern:  "routing-policy/",
+		Regex:    regexp.MustCompile(`^routing-policy/(.*)$`),
+		Template: "/routing-policy/{instance}",
+	},
+	{
+		// acl/... -> /acl/...
+		Pattern:  "acl/",
+		Regex:    regexp.MustCompile(`^acl/(.*)$`),
+		Template: "/acl/{instance}",
+	},
+	{
+		// qos/... -> /qos/...
+		Pattern:  "qos/",
+		Regex:    regexp.MustCompile(`^qos/(.*)$`),
+		Template: "/qos/{instance}",
+	},
+	{
+		// aaa/... -> /system/aaa/...
+		Pattern:  "aaa/",
+		Regex:    regexp.MustCompile(`^aaa/(.*)$`),
+		Template: "/system/aaa/{instance}",
+	},
 	{
 		// lldp/... -> /lldp/...
 		Pattern:  "lldp/",
@@ -53,10 +208,34 @@ var pathPrefixes = []PathPrefix{
 		Regex:    regexp.MustCompile(`^system/(.*)$`),
 		Template: "/system/{instance}",
 	},
+	{
+		// srl-interface[<name>]/... -> srl_nokia:/interface[name=<name>]/...
+		Pattern:  "srl-interface[",
+		Regex:    regexp.MustCompile(`^srl-interface\[` + keyValue + `\]/(.*)$`),
+		Template: OriginSRLNative + ":/interface[name={instance}]/{rest}",
+	},
+	{
+		// srl-bgp[<network-instance>]/... -> srl_nokia:/network-instance[name=<ni>]/protocols/bgp/...
+		Pattern:  "srl-bgp[",
+		Regex:    regexp.MustCompile(`^srl-bgp\[` + keyValue + `\]/(.*)$`),
+		Template: OriginSRLNative + ":/network-instance[name={instance}]/protocols/bgp/{rest}",
+	},
+	{
+		// srl-network-instance[<name>]/... -> srl_nokia:/network-instance[name=<name>]/...
+		Pattern:  "srl-network-instance[",
+		Regex:    regexp.MustCompile(`^srl-network-instance\[` + keyValue + `\]/(.*)$`),
+		Template: OriginSRLNative + ":/network-instance[name={instance}]/{rest}",
+	},
+	{
+		// srl-system/... -> srl_nokia:/system/...
+		Pattern:  "srl-system/",
+		Regex:    regexp.MustCompile(`^srl-system/(.*)$`),
+		Template: OriginSRLNative + ":/system/{instance}",
+	},
 	{
 		// network-instance[<name>]/... -> /network-instances/network-instance[name=<name>]/...
 		Pattern:  "network-instance[",
-		Regex:    regexp.MustCompile(`^network-instance\[([^\]]+)\]/(.*)$`),
+		Regex:    regexp.MustCompile(`^network-instance\[` + keyValue + `\]/(.*)$`),
 		Template: "/network-instances/network-instance[name={instance}]/{rest}",
 	},
 }
@@ -65,13 +244,19 @@ var pathPrefixes = []PathPrefix{
 // Paths starting with "/" are returned unchanged (already absolute).
 // Short paths are matched against known prefixes and expanded.
 func ExpandPath(path string) string {
-	// Absolute paths pass through unchanged
-	if strings.HasPrefix(path, "/") {
+	return ExpandPathFor(path, PlatformGeneric)
+}
+
+// ExpandPathFor expands a short path using the naming conventions of platform.
+// Absolute paths are returned unchanged.
+func ExpandPathFor(path string, platform Platform) string {
+	// Absolute and origin-qualified paths pass through unchanged
+	if !IsShortPath(path) {
 		return path
 	}
 
 	// Try each prefix
-	for _, prefix := range pathPrefixes {
+	for _, prefix := range allPathPrefixes() {
 		if strings.HasPrefix(path, prefix.Pattern) {
 			matches := prefix.Regex.FindStringSubmatch(path)
 			if matches != nil {
@@ -84,6 +269,9 @@ func ExpandPath(path string) string {
 				} else {
 					result = strings.Replace(result, "{rest}", "", 1)
 				}
+				if prefix.Protocol != "" {
+					result = strings.Replace(result, "{protocol}", platform.ProtocolName(prefix.Protocol), 1)
+				}
 				return result
 			}
 		}
@@ -93,35 +281,121 @@ func ExpandPath(path string) string {
 	return "/" + path
 }
 
+// IsFallbackPath reports whether a short path matches no known prefix, so
+// expanding it only adds a leading slash
+func IsFallbackPath(path string) bool {
+	if !IsShortPath(path) {
+		return false
+	}
+	for _, prefix := range allPathPrefixes() {
+		if strings.HasPrefix(path, prefix.Pattern) && prefix.Regex.MatchString(path) {
+			return false
+		}
+	}
+	return true
+}
+
+// srlCompactRegex matches SR Linux native interface and network instance paths
+var srlCompactRegex = regexp.MustCompile(`^(interface|network-instance)\[name=` + keyValue + `\]/(.*)$`)
+
 // CompactPath converts a full OpenConfig path to its short form if possible.
 // This is the inverse of ExpandPath.
 func CompactPath(path string) string {
-	// Try to match against expanded templates
-	
-	// BGP
-	bgpRegex := regexp.MustCompile(`^/network-instances/network-instance\[name=([^\]]+)\]/protocols/protocol\[identifier=BGP\]\[name=BGP\]/bgp/(.*)$`)
-	if matches := bgpRegex.FindStringSubmatch(path); matches != nil {
-		return "bgp[" + matches[1] + "]/" + matches[2]
+	return CompactPathFor(path, PlatformGeneric)
+}
+
+// compactProtocols are the protocol short paths, in CompactPath order.
+// Each regex captures the network instance, the protocol instance name
+// (which must be the platform's), and the rest.
+var compactProtocols = []struct {
+	identifier string
+	short      string
+	regex      *regexp.Regexp
+}{
+	{"BGP", "bgp", compactProtocolRegex("BGP", "bgp")},
+	{"OSPF", "ospf", compactProtocolRegex("OSPF", "ospf")},
+	{"ISIS", "isis", compactProtocolRegex("ISIS", "isis")},
+	{"PIM", "pim", compactProtocolRegex("PIM", "pim")},
+	{"IGMP", "igmp", compactProtocolRegex("IGMP", "igmp")},
+}
+
+func compactProtocolRegex(identifier, short string) *regexp.Regexp {
+	return regexp.MustCompile(`^/network-instances/network-instance\[name=` + keyValue + `\]/protocols/protocol\[identifier=` +
+		identifier + `\]\[name=` + keyValue + `\]/` + short + `/(.*)$`)
+}
+
+// Keyed trees CompactPathFor shortens
+var (
+	compactInterfaceRegex       = regexp.MustCompile(`^/interfaces/interface\[name=` + keyValue + `\]/(.*)$`)
+	compactComponentRegex       = regexp.MustCompile(`^/components/component\[name=` + keyValue + `\]/(.*)$`)
+	compactPolicyRegex          = regexp.MustCompile(`^/routing-policy/policy-definitions/policy-definition\[name=` + keyValue + `\]/(.*)$`)
+	compactNetworkInstanceRegex = regexp.MustCompile(`^/network-instances/network-instance\[name=` + keyValue + `\]/(.*)$`)
+)
+
+// CompactPathFor converts a path to its short form for a platform, so that
+// ExpandPathFor with the same platform restores it. Relative paths are
+// expanded first, so every path comes out in the same style.
+func CompactPathFor(path string, platform Platform) string {
+	if IsShortPath(path) {
+		full := ExpandPathFor(path, platform)
+		if compacted := CompactPathFor(full, platform); compacted != full {
+			return compacted
+		}
+		return full
 	}
 
-	// OSPF
-	ospfRegex := regexp.MustCompile(`^/network-instances/network-instance\[name=([^\]]+)\]/protocols/protocol\[identifier=OSPF\]\[name=OSPF\]/ospf/(.*)$`)
-	if matches := ospfRegex.FindStringSubmatch(path); matches != nil {
-		return "ospf[" + matches[1] + "]/" + matches[2]
+	// Prefixes of your own are more specific than the built-in ones, and
+	// a built-in one they shadow would expand differently
+	if short, ok := compactCustom(path); ok {
+		return short
+	}
+	short := compactBuiltin(path, platform)
+	customPrefixesMu.RLock()
+	defer customPrefixesMu.RUnlock()
+	if short != path && shadowed(short) {
+		return path
 	}
+	return short
+}
 
-	// ISIS
-	isisRegex := regexp.MustCompile(`^/network-instances/network-instance\[name=([^\]]+)\]/protocols/protocol\[identifier=ISIS\]\[name=ISIS\]/isis/(.*)$`)
-	if matches := isisRegex.FindStringSubmatch(path); matches != nil {
-		return "isis[" + matches[1] + "]/" + matches[2]
+// compactBuiltin converts an absolute path to the short form of a built-in
+// prefix, if one matches
+func compactBuiltin(path string, platform Platform) string {
+	// Protocols, named per the platform's instance naming
+	for _, proto := range compactProtocols {
+		matches := proto.regex.FindStringSubmatch(path)
+		if matches != nil && matches[2] == platform.ProtocolName(proto.identifier) {
+			return proto.short + "[" + matches[1] + "]/" + matches[3]
+		}
 	}
 
 	// Interface
-	ifaceRegex := regexp.MustCompile(`^/interfaces/interface\[name=([^\]]+)\]/(.*)$`)
-	if matches := ifaceRegex.FindStringSubmatch(path); matches != nil {
+	if matches := compactInterfaceRegex.FindStringSubmatch(path); matches != nil {
 		return "interface[" + matches[1] + "]/" + matches[2]
 	}
 
+	// Component
+	if matches := compactComponentRegex.FindStringSubmatch(path); matches != nil {
+		return "component[" + matches[1] + "]/" + matches[2]
+	}
+
+	// Routing policy definition, before the rest of /routing-policy
+	if matches := compactPolicyRegex.FindStringSubmatch(path); matches != nil {
+		return "policy[" + matches[1] + "]/" + matches[2]
+	}
+
+	// Whole trees; AAA before the rest of /system
+	for _, tree := range []struct{ full, short string }{
+		{"/routing-policy/", "routing-policy/"},
+		{"/acl/", "acl/"},
+		{"/qos/", "qos/"},
+		{"/system/aaa/", "aaa/"},
+	} {
+		if rest, ok := strings.CutPrefix(path, tree.full); ok {
+			return tree.short + rest
+		}
+	}
+
 	// LLDP
 	if strings.HasPrefix(path, "/lldp/") {
 		return "lldp/" + strings.TrimPrefix(path, "/lldp/")
@@ -132,9 +406,26 @@ func CompactPath(path string) string {
 		return "system/" + strings.TrimPrefix(path, "/system/")
 	}
 
+	// SR Linux native
+	if rest, ok := strings.CutPrefix(path, OriginSRLNative+":/"); ok {
+		if matches := srlCompactRegex.FindStringSubmatch(rest); matches != nil {
+			switch {
+			case matches[1] == "interface":
+				return "srl-interface[" + matches[2] + "]/" + matches[3]
+			case strings.HasPrefix(matches[3], "protocols/bgp/"):
+				return "srl-bgp[" + matches[2] + "]/" + strings.TrimPrefix(matches[3], "protocols/bgp/")
+			default:
+				return "srl-network-instance[" + matches[2] + "]/" + matches[3]
+			}
+		}
+		if strings.HasPrefix(rest, "system/") {
+			return "srl-" + rest
+		}
+		return path
+	}
+
 	// Network instance (generic)
-	niRegex := regexp.MustCompile(`^/network-instances/network-instance\[name=([^\]]+)\]/(.*)$`)
-	if matches := niRegex.FindStringSubmatch(path); matches != nil {
+	if matches := compactNetworkInstanceRegex.FindStringSubmatch(path); matches != nil {
 		return "network-instance[" + matches[1] + "]/" + matches[2]
 	}
 
@@ -142,7 +433,44 @@ func CompactPath(path string) string {
 	return path
 }
 
-// IsShortPath returns true if the path is in short form (doesn't start with /)
+// IsShortPath returns true if the path is in short form (doesn't start with /
+// or an origin)
 func IsShortPath(path string) bool {
-	return !strings.HasPrefix(path, "/")
+	origin, _ := SplitOrigin(path)
+	return !strings.HasPrefix(path, "/") && origin == ""
+}
+
+// Native origins paths may name explicitly
+const (
+	OriginEOSNative = "eos_native" // Arista EOS native state (Sysdb, Smash)
+	OriginSRLNative = "srl_nokia"  // Nokia SR Linux native models
+)
+
+// SplitOrigin splits an origin-qualified path such as
+// eos_native:/Sysdb/interface into its origin and absolute path. Other
+// paths are returned unchanged with an empty origin.
+func SplitOrigin(path string) (origin, rest string) {
+	i := strings.Index(path, ":/")
+	if i <= 0 || strings.ContainsAny(path[:i], "/[") {
+		return "", path
+	}
+	return path[:i], path[i+1:]
+}
+
+// ExpandPaths re-expands every assertion path for the target's platform.
+// Paths are expanded from the form originally written in the file, and a new
+// assertion slice is allocated so targets cloned from a group don't share state.
+func (t *Target) ExpandPaths() {
+	platform := Platform(t.Platform)
+	assertions := make([]Assertion, len(t.Assertions))
+	for i, a := range t.Assertions {
+		if a.RawPath == "" {
+			a.RawPath = a.Path
+		}
+		if a.RawPath != "" {
+			a.Path = ExpandPathFor(a.RawPath, platform)
+		}
+		assertions[i] = a
+	}
+	t.Assertions = assertions
 }
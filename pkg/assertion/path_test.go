@@ -1,6 +1,10 @@
 package assertion
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
 	"testing"
 )
 
@@ -70,6 +74,18 @@ func TestExpandPath(t *testing.T) {
 			expected: "/network-instances/network-instance[name=default]/protocols/protocol[identifier=ISIS][name=ISIS]/isis/levels/level[level-number=2]/state",
 		},
 
+		// Multicast short paths
+		{
+			name:     "pim neighbor",
+			input:    "pim[default]/interfaces/interface[interface-id=Ethernet1]/neighbors/neighbor[neighbor-address=10.1.0.1]/state",
+			expected: "/network-instances/network-instance[name=default]/protocols/protocol[identifier=PIM][name=PIM]/pim/interfaces/interface[interface-id=Ethernet1]/neighbors/neighbor[neighbor-address=10.1.0.1]/state",
+		},
+		{
+			name:     "igmp groups",
+			input:    "igmp[default]/interfaces/interface[interface-id=Vlan10]/membership-groups",
+			expected: "/network-instances/network-instance[name=default]/protocols/protocol[identifier=IGMP][name=IGMP]/igmp/interfaces/interface[interface-id=Vlan10]/membership-groups",
+		},
+
 		// System paths
 		{
 			name:     "system hostname",
@@ -160,11 +176,43 @@ func TestCompactPath(t *testing.T) {
 			expected: "lldp/state/enabled",
 		},
 
+		// Components, policy, ACL, QoS, and AAA trees
+		{
+			name:     "component",
+			input:    "/components/component[name=PowerSupply1]/state/oper-status",
+			expected: "component[PowerSupply1]/state/oper-status",
+		},
+		{
+			name:     "policy definition",
+			input:    "/routing-policy/policy-definitions/policy-definition[name=EXPORT-LOOPBACKS]/statements",
+			expected: "policy[EXPORT-LOOPBACKS]/statements",
+		},
+		{
+			name:     "routing policy sets",
+			input:    "/routing-policy/defined-sets/prefix-sets/prefix-set[name=LOOPBACKS]/prefixes",
+			expected: "routing-policy/defined-sets/prefix-sets/prefix-set[name=LOOPBACKS]/prefixes",
+		},
+		{
+			name:     "acl",
+			input:    "/acl/acl-sets/acl-set[name=test][type=ACL_IPV4]/state",
+			expected: "acl/acl-sets/acl-set[name=test][type=ACL_IPV4]/state",
+		},
+		{
+			name:     "qos",
+			input:    "/qos/interfaces/interface[interface-id=Ethernet1]/output/queues",
+			expected: "qos/interfaces/interface[interface-id=Ethernet1]/output/queues",
+		},
+		{
+			name:     "aaa before system",
+			input:    "/system/aaa/server-groups/server-group[name=TACACS]/state",
+			expected: "aaa/server-groups/server-group[name=TACACS]/state",
+		},
+
 		// Unknown paths stay as-is
 		{
 			name:     "unknown path unchanged",
-			input:    "/acl/acl-sets/acl-set[name=test]/state",
-			expected: "/acl/acl-sets/acl-set[name=test]/state",
+			input:    "/stp/global/state/enabled-protocol",
+			expected: "/stp/global/state/enabled-protocol",
 		},
 	}
 
@@ -185,6 +233,17 @@ func TestRoundTrip(t *testing.T) {
 		"/interfaces/interface[name=Ethernet1]/state/oper-status",
 		"/system/config/hostname",
 		"/lldp/interfaces/interface[name=eth0]/neighbors",
+		"/network-instances/network-instance[name=default]/protocols/protocol[identifier=PIM][name=PIM]/pim/interfaces/interface[interface-id=Ethernet1]/neighbors",
+		"srl_nokia:/interface[name=ethernet-1/1]/oper-state",
+		"srl_nokia:/network-instance[name=default]/protocols/bgp/neighbor[peer-address=10.0.0.2]/session-state",
+		"srl_nokia:/network-instance[name=mgmt]/oper-state",
+		"srl_nokia:/system/name/host-name",
+		"/components/component[name=Linecard3/1]/state/temperature/instant",
+		"/routing-policy/policy-definitions/policy-definition[name=EXPORT]/statements/statement[name=10]/actions",
+		"/routing-policy/defined-sets/bgp-defined-sets",
+		"/acl/interfaces/interface[id=Ethernet1]/ingress-acl-sets",
+		"/qos/classifiers/classifier[name=DSCP]/terms",
+		"/system/aaa/authentication/state/authentication-method",
 	}
 
 	for _, path := range fullPaths {
@@ -198,6 +257,37 @@ func TestRoundTrip(t *testing.T) {
 	}
 }
 
+func TestRoundTripEscapes(t *testing.T) {
+	resetPathPrefixes(t)
+	if err := SetPathPrefix("evpn", "/network-instances/network-instance[name={instance}]/evpn/{rest}"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		short, full string
+	}{
+		{`interface[Eth \]1]/state/oper-status`, `/interfaces/interface[name=Eth \]1]/state/oper-status`},
+		{`interface[a\\]/state/mtu`, `/interfaces/interface[name=a\\]/state/mtu`},
+		{`component[Card \[3\]]/state`, `/components/component[name=Card \[3\]]/state`},
+		{`bgp[vrf\]1]/neighbors`, `/network-instances/network-instance[name=vrf\]1]/protocols/protocol[identifier=BGP][name=BGP]/bgp/neighbors`},
+		{`policy[EXPORT\]]/statements`, `/routing-policy/policy-definitions/policy-definition[name=EXPORT\]]/statements`},
+		{`srl-interface[eth\]1]/oper-state`, `srl_nokia:/interface[name=eth\]1]/oper-state`},
+		{`evpn[red \]blue]/state`, `/network-instances/network-instance[name=red \]blue]/evpn/state`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.short, func(t *testing.T) {
+			if !IsShortPath(tt.short) {
+				t.Errorf("IsShortPath(%q) = false", tt.short)
+			}
+			if got := ExpandPath(tt.short); got != tt.full {
+				t.Errorf("ExpandPath(%q) = %q, want %q", tt.short, got, tt.full)
+			}
+			if got := CompactPath(tt.full); got != tt.short {
+				t.Errorf("CompactPath(%q) = %q, want %q", tt.full, got, tt.short)
+			}
+		})
+	}
+}
+
 func TestIsShortPath(t *testing.T) {
 	tests := []struct {
 		path     string
@@ -217,3 +307,305 @@ func TestIsShortPath(t *testing.T) {
 		})
 	}
 }
+
+func TestExpandPathFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		platform Platform
+		expected string
+	}{
+		{
+			name:     "generic bgp",
+			input:    "bgp[default]/neighbors",
+			platform: PlatformGeneric,
+			expected: "/network-instances/network-instance[name=default]/protocols/protocol[identifier=BGP][name=BGP]/bgp/neighbors",
+		},
+		{
+			name:     "cisco xr bgp instance name",
+			input:    "bgp[default]/neighbors",
+			platform: PlatformCiscoXR,
+			expected: "/network-instances/network-instance[name=default]/protocols/protocol[identifier=BGP][name=default]/bgp/neighbors",
+		},
+		{
+			name:     "absolute path unchanged",
+			input:    "/network-instances/network-instance[name=default]/protocols/protocol[identifier=BGP][name=BGP]/bgp",
+			platform: PlatformCiscoXR,
+			expected: "/network-instances/network-instance[name=default]/protocols/protocol[identifier=BGP][name=BGP]/bgp",
+		},
+		{
+			name:     "interface unaffected by platform",
+			input:    "interface[ethernet-1/1]/state/oper-status",
+			platform: PlatformNokiaSRLinux,
+			expected: "/interfaces/interface[name=ethernet-1/1]/state/oper-status",
+		},
+		{
+			name:     "sr linux native interface",
+			input:    "srl-interface[ethernet-1/1]/oper-state",
+			platform: PlatformNokiaSRLinux,
+			expected: "srl_nokia:/interface[name=ethernet-1/1]/oper-state",
+		},
+		{
+			name:     "sr linux native bgp",
+			input:    "srl-bgp[default]/neighbor[peer-address=10.0.0.2]/session-state",
+			platform: PlatformNokiaSRLinux,
+			expected: "srl_nokia:/network-instance[name=default]/protocols/bgp/neighbor[peer-address=10.0.0.2]/session-state",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ExpandPathFor(tt.input, tt.platform)
+			if result != tt.expected {
+				t.Errorf("ExpandPathFor(%q, %q)\n  got:  %q\n  want: %q", tt.input, tt.platform, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParsePlatform(t *testing.T) {
+	if p, err := ParsePlatform("Arista_EOS"); err != nil || p != PlatformAristaEOS {
+		t.Errorf("ParsePlatform(Arista_EOS) = %q, %v", p, err)
+	}
+	if p, err := ParsePlatform(""); err != nil || p != PlatformGeneric {
+		t.Errorf("ParsePlatform(\"\") = %q, %v", p, err)
+	}
+	if _, err := ParsePlatform("ios"); err == nil {
+		t.Error("expected error for unknown platform")
+	}
+}
+
+func TestSetProfileConcurrent(t *testing.T) {
+	saved := PlatformJuniper.Profile()
+	t.Cleanup(func() { SetProfile(PlatformJuniper, saved) })
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetProfile(PlatformJuniper, Profile{ProtocolName: fmt.Sprint(i)})
+		}()
+		go func() {
+			defer wg.Done()
+			PlatformJuniper.MapPath("/system")
+		}()
+	}
+	wg.Wait()
+}
+
+func TestMapPath(t *testing.T) {
+	saved := PlatformJuniper.Profile()
+	t.Cleanup(func() { SetProfile(PlatformJuniper, saved) })
+	SetProfile(PlatformJuniper, Profile{
+		Rewrites: []PathRewrite{
+			{From: "/system", To: "/native/system"},
+			{From: "/system/state/hostname", To: "/native/hostname"},
+		},
+		Unsupported: []string{"/lldp"},
+	})
+
+	tests := []struct {
+		path        string
+		want        string
+		unsupported bool
+	}{
+		{path: "/system/state/hostname", want: "/native/hostname"},
+		{path: "/system/clock", want: "/native/system/clock"},
+		{path: "/systems", want: "/systems"},
+		{path: "/interfaces/interface[name=et-0/0/0]", want: "/interfaces/interface[name=et-0/0/0]"},
+		{path: "/lldp/interfaces", unsupported: true},
+		{path: "/lldp", unsupported: true},
+	}
+	for _, tt := range tests {
+		got, err := PlatformJuniper.MapPath(tt.path)
+		if tt.unsupported {
+			if !errors.Is(err, ErrUnsupportedPath) {
+				t.Errorf("MapPath(%q) error = %v, want ErrUnsupportedPath", tt.path, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("MapPath(%q) = %q, %v; want %q", tt.path, got, err, tt.want)
+		}
+	}
+
+	// Native origins are only served by platforms whose profile lists them
+	if got, err := PlatformAristaEOS.MapPath("eos_native:/Sysdb/interface"); err != nil || got != "eos_native:/Sysdb/interface" {
+		t.Errorf("MapPath(eos_native) on arista_eos = %q, %v", got, err)
+	}
+	if _, err := PlatformJuniper.MapPath("eos_native:/Sysdb/interface"); !errors.Is(err, ErrUnsupportedPath) {
+		t.Errorf("MapPath(eos_native) on juniper error = %v, want ErrUnsupportedPath", err)
+	}
+
+	// Platforms without rewrites pass paths through
+	if got, err := PlatformAristaEOS.MapPath("/system"); err != nil || got != "/system" {
+		t.Errorf("MapPath on arista_eos = %q, %v", got, err)
+	}
+}
+
+func TestSplitOrigin(t *testing.T) {
+	tests := []struct {
+		path, origin, rest string
+	}{
+		{"eos_native:/Sysdb/interface", "eos_native", "/Sysdb/interface"},
+		{"/interfaces/interface[name=Ethernet1]", "", "/interfaces/interface[name=Ethernet1]"},
+		{"route[2001:db8::/64]/state", "", "route[2001:db8::/64]/state"},
+		{"system/state/hostname", "", "system/state/hostname"},
+	}
+	for _, tt := range tests {
+		origin, rest := SplitOrigin(tt.path)
+		if origin != tt.origin || rest != tt.rest {
+			t.Errorf("SplitOrigin(%q) = %q, %q; want %q, %q", tt.path, origin, rest, tt.origin, tt.rest)
+		}
+	}
+
+	if IsShortPath("eos_native:/Sysdb/interface") {
+		t.Error("origin-qualified path reported as short")
+	}
+	if got := ExpandPathFor("eos_native:/Sysdb/interface", PlatformAristaEOS); got != "eos_native:/Sysdb/interface" {
+		t.Errorf("ExpandPathFor changed origin-qualified path: %q", got)
+	}
+}
+
+func TestCompactPathFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		platform Platform
+		expected string
+	}{
+		{
+			name:     "relative full path",
+			path:     "interfaces/interface[name=Vxlan1]/arista-vxlan/state/src-ip-intf",
+			expected: "interface[Vxlan1]/arista-vxlan/state/src-ip-intf",
+		},
+		{
+			name:     "short path unchanged",
+			path:     "bgp[default]/neighbors",
+			expected: "bgp[default]/neighbors",
+		},
+		{
+			name:     "cisco xr protocol instance name",
+			path:     "/network-instances/network-instance[name=default]/protocols/protocol[identifier=BGP][name=default]/bgp/neighbors",
+			platform: PlatformCiscoXR,
+			expected: "bgp[default]/neighbors",
+		},
+		{
+			name:     "foreign protocol name left full",
+			path:     "/network-instances/network-instance[name=default]/protocols/protocol[identifier=BGP][name=default]/bgp/neighbors",
+			expected: "network-instance[default]/protocols/protocol[identifier=BGP][name=default]/bgp/neighbors",
+		},
+		{
+			name:     "no short form",
+			path:     "stp/global/state",
+			expected: "/stp/global/state",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CompactPathFor(tt.path, tt.platform)
+			if got != tt.expected {
+				t.Errorf("CompactPathFor(%q, %q) = %q, want %q", tt.path, tt.platform, got, tt.expected)
+			}
+			if ExpandPathFor(got, tt.platform) != ExpandPathFor(tt.path, tt.platform) {
+				t.Errorf("CompactPathFor(%q) doesn't round trip: %q", tt.path, got)
+			}
+		})
+	}
+}
+
+func TestIsFallbackPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"bgp[default]/global/state/as", false},
+		{"interface[Ethernet1]/state/oper-status", false},
+		{"interfaces[Ethernet1]/state/oper-status", true},
+		{"bgp/global/state/as", true},
+		{"/system/state/hostname", false},
+		{"eos_native:/Sysdb/hostname", false},
+	}
+	for _, tt := range tests {
+		if got := IsFallbackPath(tt.path); got != tt.want {
+			t.Errorf("IsFallbackPath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+// resetPathPrefixes drops the prefixes a test adds once it ends
+func resetPathPrefixes(t *testing.T) {
+	t.Helper()
+	customPrefixesMu.RLock()
+	saved := slices.Clone(customPrefixes)
+	customPrefixesMu.RUnlock()
+	t.Cleanup(func() {
+		customPrefixesMu.Lock()
+		customPrefixes = saved
+		customPrefixesMu.Unlock()
+	})
+}
+
+func TestSetPathPrefix(t *testing.T) {
+	resetPathPrefixes(t)
+	evpn := "/network-instances/network-instance[name={instance}]/protocols/protocol[identifier=BGP][name=BGP]/bgp/global/afi-safis/afi-safi[afi-safi-name=L2VPN_EVPN]/{rest}"
+	if err := SetPathPrefix("evpn", evpn); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetPathPrefix("qos", "/qos/{rest}"); err != nil {
+		t.Fatal(err)
+	}
+	// Shadows the built-in aaa/
+	if err := SetPathPrefix("aaa", "/system/aaa/authentication/{rest}"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		short, full string
+	}{
+		{"evpn[default]/state/total-prefixes", "/network-instances/network-instance[name=default]/protocols/protocol[identifier=BGP][name=BGP]/bgp/global/afi-safis/afi-safi[afi-safi-name=L2VPN_EVPN]/state/total-prefixes"},
+		{"qos/interfaces/interface[interface-id=Ethernet1]/state", "/qos/interfaces/interface[interface-id=Ethernet1]/state"},
+		{"aaa/state/authentication-method", "/system/aaa/authentication/state/authentication-method"},
+	}
+	for _, tt := range tests {
+		if got := ExpandPath(tt.short); got != tt.full {
+			t.Errorf("ExpandPath(%q) = %q, want %q", tt.short, got, tt.full)
+		}
+		if got := CompactPath(tt.full); got != tt.short {
+			t.Errorf("CompactPath(%q) = %q, want %q", tt.full, got, tt.short)
+		}
+		if IsFallbackPath(tt.short) {
+			t.Errorf("IsFallbackPath(%q) = true", tt.short)
+		}
+	}
+
+	// Paths only the shadowed aaa/ would shorten are left as they are
+	full := "/system/aaa/server-groups/server-group[name=TACACS]/state"
+	if got := CompactPath(full); ExpandPath(got) != full {
+		t.Errorf("CompactPath(%q) = %q, which doesn't expand back", full, got)
+	}
+
+	for name, template := range map[string]string{
+		"Evpn": "/evpn/{rest}",
+		"sr":   "/segment-routing",
+		"ni":   "network-instances/{rest}",
+	} {
+		if err := SetPathPrefix(name, template); err == nil {
+			t.Errorf("SetPathPrefix(%q, %q) succeeded", name, template)
+		}
+	}
+}
+
+func TestResetPathPrefixes(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		resetPathPrefixes(t)
+		if err := SetPathPrefix("mpls", "/network-instances/network-instance[name=default]/mpls/{rest}"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !IsFallbackPath("mpls/global/state") {
+		t.Error("mpls prefix outlived the test that set it")
+	}
+}
@@ -0,0 +1,165 @@
+package assertion
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Platform identifies a network operating system family
+type Platform string
+
+// Supported platforms
+const (
+	PlatformGeneric      Platform = ""
+	PlatformAristaEOS    Platform = "arista_eos"
+	PlatformNokiaSRLinux Platform = "nokia_srlinux"
+	PlatformCiscoXR      Platform = "cisco_xr"
+	PlatformJuniper      Platform = "juniper"
+)
+
+// Platforms lists all named platforms
+var Platforms = []Platform{
+	PlatformAristaEOS,
+	PlatformNokiaSRLinux,
+	PlatformCiscoXR,
+	PlatformJuniper,
+}
+
+// ParsePlatform validates a platform name. An empty name selects generic OpenConfig.
+func ParsePlatform(name string) (Platform, error) {
+	p := Platform(strings.ToLower(strings.TrimSpace(name)))
+	if p == PlatformGeneric {
+		return p, nil
+	}
+	for _, known := range Platforms {
+		if p == known {
+			return p, nil
+		}
+	}
+
+	names := make([]string, len(Platforms))
+	for i, known := range Platforms {
+		names[i] = string(known)
+	}
+	return "", fmt.Errorf("unknown platform %q (supported: %s)", name, strings.Join(names, ", "))
+}
+
+// Profile describes where a platform's paths diverge from generic
+// OpenConfig. Short-path expansion and gNMI requests consult it, so the
+// same assertions and generators work across vendors.
+type Profile struct {
+	// Origin is the gNMI origin for OpenConfig paths
+	Origin string
+
+	// Origins lists the native origins paths may name explicitly, as
+	// <origin>:/path. Paths naming any other origin are unsupported.
+	Origins []string
+
+	// ProtocolName, if set, names every protocol instance under
+	// /network-instances/network-instance/protocols
+	ProtocolName string
+
+	// Rewrites map OpenConfig path prefixes to where the platform serves
+	// the same data, such as a native augmentation. The longest match wins.
+	Rewrites []PathRewrite
+
+	// Unsupported lists path prefixes the platform doesn't serve. Requests
+	// for them fail with ErrUnsupportedPath instead of reaching the device.
+	Unsupported []string
+}
+
+// PathRewrite replaces the path prefix From with To
+type PathRewrite struct {
+	From string
+	To   string
+}
+
+// ErrUnsupportedPath is returned for paths a platform's profile marks unsupported
+var ErrUnsupportedPath = errors.New("path not supported on platform")
+
+// profiles holds the built-in platform profiles. EOS serves native state
+// under eos_native; SR Linux serves its native models by default, so
+// OpenConfig paths need an explicit origin; IOS-XR names its default
+// protocol instances "default".
+var (
+	profilesMu sync.RWMutex
+	profiles   = map[Platform]Profile{
+		PlatformAristaEOS:    {Origins: []string{OriginEOSNative}},
+		PlatformNokiaSRLinux: {Origin: "openconfig", Origins: []string{OriginSRLNative}},
+		PlatformCiscoXR:      {ProtocolName: "default"},
+	}
+)
+
+// Profile returns the platform's path profile
+func (p Platform) Profile() Profile {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+	return profiles[p]
+}
+
+// SetProfile replaces a platform's path profile, e.g. with one extended
+// from configuration. Requests already running may use either profile.
+func SetProfile(p Platform, profile Profile) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	profiles[p] = profile
+}
+
+// Origin returns the gNMI origin to use for OpenConfig paths on this platform.
+func (p Platform) Origin() string {
+	return p.Profile().Origin
+}
+
+// ProtocolName returns the protocol instance name used under
+// /network-instances/network-instance/protocols for the given identifier
+// (e.g., "BGP").
+func (p Platform) ProtocolName(identifier string) string {
+	if name := p.Profile().ProtocolName; name != "" {
+		return name
+	}
+	return identifier
+}
+
+// HasOrigin reports whether paths may name the native origin on this platform
+func (p Platform) HasOrigin(origin string) bool {
+	return slices.Contains(p.Profile().Origins, origin)
+}
+
+// MapPath applies the platform's profile to an absolute request path:
+// unsupported paths fail with ErrUnsupportedPath and rewrites are applied
+func (p Platform) MapPath(path string) (string, error) {
+	profile := p.Profile()
+	if origin, _ := SplitOrigin(path); origin != "" && !p.HasOrigin(origin) {
+		return "", fmt.Errorf("%w %s: %s", ErrUnsupportedPath, p, path)
+	}
+	for _, prefix := range profile.Unsupported {
+		if hasPathPrefix(path, prefix) {
+			return "", fmt.Errorf("%w %s: %s", ErrUnsupportedPath, p, path)
+		}
+	}
+
+	best := -1
+	for i, r := range profile.Rewrites {
+		if hasPathPrefix(path, r.From) && (best < 0 || len(r.From) > len(profile.Rewrites[best].From)) {
+			best = i
+		}
+	}
+	if best >= 0 {
+		r := profile.Rewrites[best]
+		path = strings.TrimSuffix(r.To, "/") + path[len(strings.TrimSuffix(r.From, "/")):]
+	}
+	return path, nil
+}
+
+// hasPathPrefix reports whether prefix matches path on element boundaries
+func hasPathPrefix(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	rest := path[len(prefix):]
+	return rest == "" || rest[0] == '/' || rest[0] == '['
+}
@@ -0,0 +1,88 @@
+package assertion
+
+import (
+	"fmt"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Template is a named assertion set with parameters, referenced in
+// assertions as {{ .param }}
+type Template struct {
+	Params     []string    `yaml:"params,omitempty"`
+	Assertions []Assertion `yaml:"assertions"`
+}
+
+// TemplateUse instantiates a template on a target. It is written as
+// name: {param: value, ...}, or name: [value, ...] in parameter order.
+type TemplateUse struct {
+	Template string
+	Args     map[string]string
+	list     []string // Positional arguments, named by instantiate
+}
+
+// UnmarshalYAML decodes the single-key template: arguments form
+func (u *TemplateUse) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode || len(node.Content) != 2 {
+		return fmt.Errorf("line %d: use entries must be <template>: <arguments>", node.Line)
+	}
+	u.Template = node.Content[0].Value
+	args := node.Content[1]
+	switch args.Kind {
+	case yaml.SequenceNode:
+		return args.Decode(&u.list)
+	case yaml.MappingNode:
+		return args.Decode(&u.Args)
+	case yaml.ScalarNode:
+		if args.Tag == "!!null" {
+			return nil
+		}
+	}
+	return fmt.Errorf("line %d: arguments to %s must be a list or a mapping", args.Line, u.Template)
+}
+
+// MarshalYAML encodes the single-key template: arguments form
+func (u TemplateUse) MarshalYAML() (any, error) {
+	if u.list != nil {
+		return map[string][]string{u.Template: u.list}, nil
+	}
+	return map[string]map[string]string{u.Template: u.Args}, nil
+}
+
+// instantiate returns a template's assertions for one use, carrying the
+// arguments that ApplyMetadata renders them with
+func (af *AssertionFile) instantiate(u TemplateUse) ([]Assertion, error) {
+	tmpl, ok := af.Templates[u.Template]
+	if !ok {
+		return nil, fmt.Errorf("unknown template %s", u.Template)
+	}
+
+	args := u.Args
+	if u.list != nil {
+		if len(u.list) != len(tmpl.Params) {
+			return nil, fmt.Errorf("template %s takes %d arguments (%v), got %d", u.Template, len(tmpl.Params), tmpl.Params, len(u.list))
+		}
+		args = make(map[string]string, len(u.list))
+		for i, v := range u.list {
+			args[tmpl.Params[i]] = v
+		}
+	}
+	for name := range args {
+		if !slices.Contains(tmpl.Params, name) {
+			return nil, fmt.Errorf("template %s has no parameter %s", u.Template, name)
+		}
+	}
+	for _, name := range tmpl.Params {
+		if _, ok := args[name]; !ok {
+			return nil, fmt.Errorf("template %s: missing argument %s", u.Template, name)
+		}
+	}
+
+	assertions := make([]Assertion, len(tmpl.Assertions))
+	for i, a := range tmpl.Assertions {
+		a.Args = args
+		assertions[i] = a
+	}
+	return assertions, nil
+}
@@ -0,0 +1,84 @@
+package assertion
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse_Templates(t *testing.T) {
+	yaml := `
+templates:
+  bgp_peer:
+    params: [addr, asn]
+    assertions:
+      - name: BGP peer {{ .addr }} is established
+        path: bgp[default]/neighbors/neighbor[neighbor-address={{ .addr }}]/state/session-state
+        equals: ESTABLISHED
+      - name: BGP peer {{ .addr }} is AS {{ .asn }}
+        path: bgp[default]/neighbors/neighbor[neighbor-address={{ .addr }}]/state/peer-as
+        equals: "{{ .asn }}"
+targets:
+  - host: spine1:6030
+    use:
+      - bgp_peer: {addr: 10.0.0.1, asn: 65001}
+      - bgp_peer: [10.0.0.3, 65003]
+`
+	af, err := Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	target := &af.Targets[0]
+	if err := target.ApplyMetadata(); err != nil {
+		t.Fatalf("ApplyMetadata() error = %v", err)
+	}
+
+	want := []struct{ name, path, equals string }{
+		{"BGP peer 10.0.0.1 is established", "neighbor-address=10.0.0.1]/state/session-state", "ESTABLISHED"},
+		{"BGP peer 10.0.0.1 is AS 65001", "neighbor-address=10.0.0.1]/state/peer-as", "65001"},
+		{"BGP peer 10.0.0.3 is established", "neighbor-address=10.0.0.3]/state/session-state", "ESTABLISHED"},
+		{"BGP peer 10.0.0.3 is AS 65003", "neighbor-address=10.0.0.3]/state/peer-as", "65003"},
+	}
+	if len(target.Assertions) != len(want) {
+		t.Fatalf("got %d assertions, want %d", len(target.Assertions), len(want))
+	}
+	for i, a := range target.Assertions {
+		if a.Name != want[i].name || !strings.HasSuffix(a.Path, want[i].path) || *a.Equals != want[i].equals {
+			t.Errorf("assertion %d = %q %s %s", i, a.Name, a.Path, *a.Equals)
+		}
+		if !strings.HasPrefix(a.Path, "/network-instances/") {
+			t.Errorf("assertion %d path not expanded: %s", i, a.Path)
+		}
+	}
+}
+
+func TestParse_TemplateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		use  string
+		want string
+	}{
+		{"unknown template", "nope: [1]", "unknown template nope"},
+		{"wrong count", "peer: [10.0.0.1, 65001]", "takes 1 arguments"},
+		{"unknown parameter", "peer: {address: 10.0.0.1}", "no parameter address"},
+		{"missing argument", "peer: {}", "missing argument addr"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yaml := `
+templates:
+  peer:
+    params: [addr]
+    assertions:
+      - path: /peer[{{ .addr }}]
+        exists: true
+targets:
+  - host: spine1:6030
+    use:
+      - ` + tt.use + "\n"
+			_, err := Parse([]byte(yaml))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -2,14 +2,86 @@ package assertion
 
 import (
 	"fmt"
+	"maps"
 	"regexp"
-	"strconv"
+	"slices"
 	"strings"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/hooks"
+	"github.com/ndtobs/netsert/pkg/value"
 )
 
 // AssertionFile is the top-level structure for assertion YAML files
 type AssertionFile struct {
 	Targets []Target `yaml:"targets"`
+
+	// Shared assertions added to every target, and to every target in an
+	// inventory group, once groups are expanded; see ApplyShared
+	All    []Assertion            `yaml:"all,omitempty"`
+	Groups map[string][]Assertion `yaml:"groups,omitempty"`
+
+	// Parameterized assertion sets, instantiated by targets' use: lists
+	Templates map[string]Template `yaml:"templates,omitempty"`
+
+	// Commands and gNMI Sets run before and after the suite and each
+	// target, after any configured hooks
+	Hooks hooks.Hooks `yaml:"hooks,omitempty"`
+}
+
+// Clone returns a copy of af whose targets, shared assertions, templates,
+// and hooks can be changed without changing af. Assertions' expected
+// values are shared: they're replaced when rendered, never changed in place.
+func (af *AssertionFile) Clone() *AssertionFile {
+	c := *af
+	c.Targets = make([]Target, len(af.Targets))
+	for i, t := range af.Targets {
+		t.Assertions = slices.Clone(t.Assertions)
+		t.Use = slices.Clone(t.Use)
+		t.Metadata = maps.Clone(t.Metadata)
+		t.Groups = slices.Clone(t.Groups)
+		c.Targets[i] = t
+	}
+	c.All = slices.Clone(af.All)
+	if af.Groups != nil {
+		c.Groups = make(map[string][]Assertion, len(af.Groups))
+		for g, assertions := range af.Groups {
+			c.Groups[g] = slices.Clone(assertions)
+		}
+	}
+	if af.Templates != nil {
+		c.Templates = make(map[string]Template, len(af.Templates))
+		for name, tmpl := range af.Templates {
+			tmpl.Params = slices.Clone(tmpl.Params)
+			tmpl.Assertions = slices.Clone(tmpl.Assertions)
+			c.Templates[name] = tmpl
+		}
+	}
+	c.Hooks = hooks.Hooks{}.Merge(af.Hooks)
+	return &c
+}
+
+// ApplyShared appends the all: and groups: assertions to each target, after
+// its own: group sections in the target's group order (most specific
+// first), then all. Target groups come from the inventory, so call this
+// after expanding groups.
+func (af *AssertionFile) ApplyShared() {
+	if len(af.All) == 0 && len(af.Groups) == 0 {
+		return
+	}
+	for i := range af.Targets {
+		t := &af.Targets[i]
+		assertions := slices.Clone(t.Assertions)
+		seen := make(map[string]bool)
+		for _, g := range t.Groups {
+			if !seen[g] {
+				seen[g] = true
+				assertions = append(assertions, af.Groups[g]...)
+			}
+		}
+		t.Assertions = append(assertions, af.All...)
+		t.ExpandPaths()
+	}
 }
 
 // Target represents a device and its assertions
@@ -19,7 +91,18 @@ type Target struct {
 	Username   string      `yaml:"username,omitempty"`
 	Password   string      `yaml:"password,omitempty"`
 	Insecure   bool        `yaml:"insecure,omitempty"`
+	Platform   string      `yaml:"platform,omitempty"` // e.g., arista_eos, nokia_srlinux, cisco_xr, juniper
 	Assertions []Assertion `yaml:"assertions"`
+
+	// Templates to instantiate, appended to the target's assertions
+	Use []TemplateUse `yaml:"use,omitempty"`
+
+	// Metadata such as site or role, for when: conditions, {{ .key }}
+	// templates, and results; inventory vars fill in keys not set here
+	Metadata map[string]string `yaml:"metadata,omitempty"`
+
+	// Groups are the inventory groups this target belongs to, most specific first
+	Groups []string `yaml:"-"`
 }
 
 // GetHost returns the host address (prefers host over address)
@@ -34,9 +117,27 @@ func (t *Target) GetHost() string {
 type Assertion struct {
 	Name        string `yaml:"name,omitempty"`
 	Description string `yaml:"description,omitempty"`
-	Path        string `yaml:"path"`
+	Path        string `yaml:"path,omitempty"`
+	RawPath     string `yaml:"-"` // Path as written, before short-path expansion
+	File        string `yaml:"-"` // File it was loaded from, when a suite spans several (see LoadFiles)
+
+	// Run only on targets whose metadata has these values (comma-separated
+	// alternatives), e.g. role: spine
+	When map[string]string `yaml:"when,omitempty"`
+
+	// Repeat the assertion once per item, substituted for {{ item }} in
+	// the name, path, and expected values
+	ForEach []string `yaml:"for_each,omitempty"`
+
+	// Names of assertions on the same target that must pass first; if one
+	// doesn't, this one is reported as blocked instead of run
+	DependsOn []string `yaml:"depends_on,omitempty"`
 
-	// Assertion types (only one should be set)
+	// Template arguments, rendered along with the target's metadata
+	Args map[string]string `yaml:"-"`
+
+	// Assertion types. contains, matches, gt/lt/gte/lte, and operator
+	// combine, the value having to pass each; the others stand alone.
 	Equals   *string `yaml:"equals,omitempty"`
 	Contains *string `yaml:"contains,omitempty"`
 	Matches  *string `yaml:"matches,omitempty"`
@@ -46,22 +147,171 @@ type Assertion struct {
 	LT       *string `yaml:"lt,omitempty"`
 	GTE      *string `yaml:"gte,omitempty"`
 	LTE      *string `yaml:"lte,omitempty"`
+
+	// Shorthands that set the path and check (see expandMacro), e.g.
+	// interface_up: Ethernet49/1
+	BGPEstablished string        `yaml:"bgp_established,omitempty"` // Neighbor address, default VRF
+	InterfaceUp    string        `yaml:"interface_up,omitempty"`
+	LLDPNeighbor   *LLDPNeighbor `yaml:"lldp_neighbor,omitempty"`
+
+	// Custom operator registered with RegisterOperator, e.g. is-valid-mac,
+	// and its argument
+	Operator string `yaml:"operator,omitempty"`
+	Arg      string `yaml:"arg,omitempty"`
+
+	// Require the device to have reported the value within this long (e.g.,
+	// "5m"), going by the notification timestamp, to catch stale caches
+	// and stuck agents. Combines with any other gNMI check but absent.
+	FreshWithin *string `yaml:"fresh_within,omitempty"`
+
+	// Compare the value across every target the assertion runs on, e.g.
+	// from a groups: section: identical requires every target to have the
+	// same value, unique no two to. Targets are matched up by assertion
+	// name and path.
+	Consistent string `yaml:"consistent,omitempty"`
+
+	// Invert the check's result, e.g. matches with not: true passes when
+	// the value doesn't match. The path must still exist.
+	Not bool `yaml:"not,omitempty"`
+
+	// Expect the contents of a golden file (relative to the assertion file;
+	// a trailing newline is ignored), e.g. golden/{{ .host }}-bgp.json
+	EqualsFile string `yaml:"equals_file,omitempty"`
+
+	// Deep-compare the returned JSON subtree against an inline document or
+	// a JSON/YAML file (relative to the assertion file)
+	EqualsJSON     any      `yaml:"equals_json,omitempty"`
+	EqualsJSONFile string   `yaml:"equals_json_file,omitempty"`
+	IgnorePaths    []string `yaml:"ignore_paths,omitempty"` // Volatile fields, e.g. "state/counters"
+
+	// Pass if a list in the returned subtree has an entry matching this
+	// partial object; list_path narrows the search to one list
+	ContainsItem any    `yaml:"contains_item,omitempty"`
+	ListPath     string `yaml:"list_path,omitempty"` // e.g., "next-hops/next-hop"
+
+	// Pass if the returned list (the outermost one, or the one at
+	// list_path) has exactly this many entries
+	Count *int `yaml:"count,omitempty"`
+
+	// Dataplane checks run via gNOI instead of reading a path
+	Ping       *PingCheck       `yaml:"ping,omitempty"`
+	Traceroute *TracerouteCheck `yaml:"traceroute,omitempty"`
+
+	// System checks run via gNOI
+	MaxClockSkew *string `yaml:"max_clock_skew,omitempty"` // e.g., "2s"
+	OSVersion    *string `yaml:"os_version,omitempty"`     // Running OS package version
+}
+
+// PingCheck asserts reachability via gNOI System.Ping
+type PingCheck struct {
+	Destination     string   `yaml:"destination"`
+	Source          string   `yaml:"source,omitempty"`
+	NetworkInstance string   `yaml:"network_instance,omitempty"` // VRF
+	Count           int      `yaml:"count,omitempty"`            // Echo requests (default: 5)
+	MaxLoss         *float64 `yaml:"max_loss,omitempty"`         // Max loss percent (default: 0)
+	MaxRTT          string   `yaml:"max_rtt,omitempty"`          // Max round trip (e.g., "50ms")
+}
+
+// TracerouteCheck asserts the forwarding path via gNOI System.Traceroute
+type TracerouteCheck struct {
+	Destination     string `yaml:"destination"`
+	Source          string `yaml:"source,omitempty"`
+	NetworkInstance string `yaml:"network_instance,omitempty"`
+	MaxHops         int    `yaml:"max_hops,omitempty"` // Fail if the destination is further away
+	Via             string `yaml:"via,omitempty"`      // Address that must appear as a hop
+}
+
+// IsGNOI reports whether the assertion runs a gNOI operation rather than a gNMI Get
+func (a *Assertion) IsGNOI() bool {
+	return a.Ping != nil || a.Traceroute != nil || a.MaxClockSkew != nil || a.OSVersion != nil
 }
 
 // Result represents the outcome of an assertion
 type Result struct {
 	Target      string
+	Metadata    map[string]string // Target metadata, for grouping results
+	Duration    time.Duration     // Wall time, including retries
 	Assertion   Assertion
 	Passed      bool
 	ActualValue string
 	Error       error
+	Status      Status    // Set by the runner; see GetStatus
+	Diff        string    // First mismatch for structural comparisons (equals_json)
+	Timestamp   time.Time // When the device reported the value, if it said
+	ShortPath   string    // Path in short form, if the runner compacted it
+}
+
+// Status classifies a result. Every status other than pass and fail
+// describes why the assertion could not be evaluated.
+type Status string
+
+const (
+	StatusPass            Status = "pass"
+	StatusFail            Status = "fail"
+	StatusError           Status = "error"
+	StatusTimeout         Status = "timeout"         // Device did not answer in time
+	StatusUnauthenticated Status = "unauthenticated" // Credentials rejected
+	StatusUnsupported     Status = "unsupported"     // RPC or path not supported by the device
+	StatusSkipped         Status = "skipped"         // Not run: the run stopped at an earlier failure
+	StatusBlocked         Status = "blocked"         // Not run: an assertion it depends on didn't pass
+)
+
+// DisplayName is the assertion's name, with a name taken from the path in
+// its short form when there is one
+func (r *Result) DisplayName() string {
+	if r.ShortPath != "" && r.Assertion.GetName() == r.Assertion.Path {
+		return r.ShortPath
+	}
+	return r.Assertion.GetName()
+}
+
+// GetStatus returns the result status, deriving pass/fail/error when unset
+func (r *Result) GetStatus() Status {
+	switch {
+	case r.Status != "":
+		return r.Status
+	case r.Error != nil:
+		return StatusError
+	case r.Passed:
+		return StatusPass
+	default:
+		return StatusFail
+	}
+}
+
+// Validate checks if the assertion passes for a given string value
+func (a *Assertion) Validate(v string, exists bool) *Result {
+	return a.ValidateValue(value.String(v), exists)
+}
+
+// ValidateValue checks if the assertion passes for a typed value. Numbers
+// and booleans are compared by value rather than by their string form.
+func (a *Assertion) ValidateValue(v value.Value, exists bool) *Result {
+	return a.ValidateUpdate(v, time.Time{}, exists)
+}
+
+// ValidateUpdate checks if the assertion passes for a typed value the
+// device reported at timestamp (zero if it didn't say), as fresh_within
+// needs
+func (a *Assertion) ValidateUpdate(v value.Value, timestamp time.Time, exists bool) *Result {
+	result := a.validateValue(v, exists)
+	result.Timestamp = timestamp
+	// Errors stay errors whether or not the check is inverted; not:
+	// inverts the value check, never freshness
+	if a.Not && result.Error == nil {
+		result.Passed = !result.Passed
+		result.Diff = ""
+	}
+	if a.FreshWithin != nil && result.Passed && result.Error == nil {
+		a.validateFreshness(result, timestamp)
+	}
+	return result
 }
 
-// Validate checks if the assertion passes for a given value
-func (a *Assertion) Validate(value string, exists bool) *Result {
+func (a *Assertion) validateValue(v value.Value, exists bool) *Result {
 	result := &Result{
 		Assertion:   *a,
-		ActualValue: value,
+		ActualValue: v.String(),
 		Passed:      false,
 	}
 
@@ -84,52 +334,187 @@ func (a *Assertion) Validate(value string, exists bool) *Result {
 
 	// Equals
 	if a.Equals != nil {
-		result.Passed = value == *a.Equals
+		result.Passed = v.Equal(*a.Equals)
 		return result
 	}
 
-	// Contains
-	if a.Contains != nil {
-		result.Passed = strings.Contains(value, *a.Contains)
+	// Structural JSON comparison
+	if a.EqualsJSON != nil {
+		a.validateJSON(result, v)
+		return result
+	}
+	if a.ContainsItem != nil {
+		a.validateContainsItem(result, v)
+		return result
+	}
+	if a.Count != nil {
+		a.validateCount(result, v)
 		return result
 	}
 
-	// Matches (regex)
+	// The rest may be combined, e.g. contains and matches or gte and lte
+	// for a range; the value must pass every one set
+	var checks []func() (bool, error)
+	if a.Contains != nil {
+		checks = append(checks, func() (bool, error) {
+			return strings.Contains(v.String(), *a.Contains), nil
+		})
+	}
 	if a.Matches != nil {
-		re, err := regexp.Compile(*a.Matches)
-		if err != nil {
-			result.Error = fmt.Errorf("invalid regex: %w", err)
+		checks = append(checks, func() (bool, error) {
+			re, err := regexp.Compile(*a.Matches)
+			if err != nil {
+				return false, fmt.Errorf("invalid regex: %w", err)
+			}
+			return re.MatchString(v.String()), nil
+		})
+	}
+	for _, b := range []struct {
+		threshold *string
+		pass      func(c int) bool
+	}{
+		{a.GT, func(c int) bool { return c > 0 }},
+		{a.LT, func(c int) bool { return c < 0 }},
+		{a.GTE, func(c int) bool { return c >= 0 }},
+		{a.LTE, func(c int) bool { return c <= 0 }},
+	} {
+		if b.threshold == nil {
+			continue
+		}
+		checks = append(checks, func() (bool, error) {
+			c, err := v.Compare(*b.threshold)
+			if err != nil {
+				return false, err
+			}
+			return b.pass(c), nil
+		})
+	}
+	if a.Operator != "" {
+		checks = append(checks, func() (bool, error) {
+			fn, err := lookupOperator(a.Operator)
+			if err != nil {
+				return false, err
+			}
+			return fn(v.String(), a.Arg)
+		})
+	}
+
+	for _, check := range checks {
+		result.Passed, result.Error = check()
+		if !result.Passed || result.Error != nil {
 			return result
 		}
-		result.Passed = re.MatchString(value)
+	}
+	if len(checks) > 0 {
 		return result
 	}
+	if a.FreshWithin != nil || a.Consistent != "" {
+		// Alone, these only need the value to exist
+		result.Passed = true
+		return result
+	}
+
+	result.Error = fmt.Errorf("no assertion type specified")
+	return result
+}
+
+// validateFreshness fails a result whose value the device reported longer
+// ago than fresh_within. Timestamps ahead of the local clock count as fresh.
+func (a *Assertion) validateFreshness(result *Result, timestamp time.Time) {
+	within, err := time.ParseDuration(*a.FreshWithin)
+	if err != nil {
+		result.Passed = false
+		result.Error = fmt.Errorf("fresh_within: %w", err)
+		return
+	}
+	if timestamp.IsZero() {
+		result.Passed = false
+		result.Error = fmt.Errorf("fresh_within: device sent no timestamp")
+		return
+	}
+	result.Passed = time.Since(timestamp) <= within
+}
 
-	// Numeric comparisons
-	if a.GT != nil || a.LT != nil || a.GTE != nil || a.LTE != nil {
-		actualNum, err := strconv.ParseFloat(value, 64)
+// ValidatePing checks ping statistics against the ping assertion
+func (a *Assertion) ValidatePing(sent, received int, maxRTT time.Duration) *Result {
+	result := &Result{Assertion: *a}
+	if a.Ping == nil {
+		result.Error = fmt.Errorf("not a ping assertion")
+		return result
+	}
+
+	loss := PingLoss(sent, received)
+	result.ActualValue = fmt.Sprintf("%d/%d received, %.0f%% loss, max rtt %v", received, sent, loss, maxRTT)
+
+	maxLoss := 0.0
+	if a.Ping.MaxLoss != nil {
+		maxLoss = *a.Ping.MaxLoss
+	}
+	result.Passed = loss <= maxLoss
+
+	if result.Passed && a.Ping.MaxRTT != "" {
+		limit, err := time.ParseDuration(a.Ping.MaxRTT)
 		if err != nil {
-			result.Error = fmt.Errorf("value is not numeric: %w", err)
+			result.Error = fmt.Errorf("invalid max_rtt: %w", err)
 			return result
 		}
+		result.Passed = maxRTT <= limit
+	}
+	return result
+}
 
-		if a.GT != nil {
-			threshold, _ := strconv.ParseFloat(*a.GT, 64)
-			result.Passed = actualNum > threshold
-		} else if a.LT != nil {
-			threshold, _ := strconv.ParseFloat(*a.LT, 64)
-			result.Passed = actualNum < threshold
-		} else if a.GTE != nil {
-			threshold, _ := strconv.ParseFloat(*a.GTE, 64)
-			result.Passed = actualNum >= threshold
-		} else if a.LTE != nil {
-			threshold, _ := strconv.ParseFloat(*a.LTE, 64)
-			result.Passed = actualNum <= threshold
-		}
+// PingLoss returns the packet loss percentage, 100 when nothing was sent
+func PingLoss(sent, received int) float64 {
+	if sent <= 0 {
+		return 100
+	}
+	return float64(sent-received) / float64(sent) * 100
+}
+
+// ValidateTraceroute checks traceroute hops (addresses in order) against the
+// traceroute assertion
+func (a *Assertion) ValidateTraceroute(hops []string, reached bool) *Result {
+	result := &Result{Assertion: *a, ActualValue: strings.Join(hops, " -> ")}
+	if a.Traceroute == nil {
+		result.Error = fmt.Errorf("not a traceroute assertion")
 		return result
 	}
 
-	result.Error = fmt.Errorf("no assertion type specified")
+	result.Passed = reached
+	if result.Passed && a.Traceroute.MaxHops > 0 {
+		result.Passed = len(hops) <= a.Traceroute.MaxHops
+	}
+	if result.Passed && a.Traceroute.Via != "" {
+		result.Passed = slices.Contains(hops, a.Traceroute.Via)
+	}
+	return result
+}
+
+// ValidateClockSkew checks the device clock offset against max_clock_skew
+func (a *Assertion) ValidateClockSkew(skew time.Duration) *Result {
+	result := &Result{Assertion: *a, ActualValue: skew.Round(time.Millisecond).String()}
+	if a.MaxClockSkew == nil {
+		result.Error = fmt.Errorf("not a clock skew assertion")
+		return result
+	}
+
+	limit, err := time.ParseDuration(*a.MaxClockSkew)
+	if err != nil {
+		result.Error = fmt.Errorf("invalid max_clock_skew: %w", err)
+		return result
+	}
+	result.Passed = skew.Abs() <= limit
+	return result
+}
+
+// ValidateOSVersion checks the running OS version against os_version
+func (a *Assertion) ValidateOSVersion(version string) *Result {
+	result := &Result{Assertion: *a, ActualValue: version}
+	if a.OSVersion == nil {
+		result.Error = fmt.Errorf("not an OS version assertion")
+		return result
+	}
+	result.Passed = version == *a.OSVersion
 	return result
 }
 
@@ -138,6 +523,18 @@ func (a *Assertion) GetName() string {
 	if a.Name != "" {
 		return a.Name
 	}
+	if a.Ping != nil {
+		return "ping " + a.Ping.Destination
+	}
+	if a.Traceroute != nil {
+		return "traceroute " + a.Traceroute.Destination
+	}
+	if a.MaxClockSkew != nil {
+		return "clock skew within " + *a.MaxClockSkew
+	}
+	if a.OSVersion != nil {
+		return "OS version is " + *a.OSVersion
+	}
 	// Generate a name from the path
 	return a.Path
 }
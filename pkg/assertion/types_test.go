@@ -2,6 +2,9 @@ package assertion
 
 import (
 	"testing"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/value"
 )
 
 func ptr(s string) *string {
@@ -152,6 +155,16 @@ func TestValidate_NumericComparisons(t *testing.T) {
 		{"lte pass equal", Assertion{Path: "/test", LTE: ptr("10")}, "10", true},
 		{"lte pass less", Assertion{Path: "/test", LTE: ptr("10")}, "5", true},
 		{"lte fail", Assertion{Path: "/test", LTE: ptr("10")}, "15", false},
+		{"range pass", Assertion{Path: "/test", GTE: ptr("-5.5"), LTE: ptr("-0.5")}, "-2.31", true},
+		{"range fail low", Assertion{Path: "/test", GTE: ptr("-5.5"), LTE: ptr("-0.5")}, "-7", false},
+		{"range fail high", Assertion{Path: "/test", GT: ptr("0"), LT: ptr("10")}, "10", false},
+		{"contains and matches pass", Assertion{Path: "/test", Contains: ptr("Ethernet"), Matches: ptr(`\d+$`)}, "Ethernet12", true},
+		{"contains and matches fail one", Assertion{Path: "/test", Contains: ptr("Ethernet"), Matches: ptr(`\d+$`)}, "Ethernet-mgmt", false},
+		{"matches and range", Assertion{Path: "/test", Matches: ptr(`^\d+$`), LTE: ptr("9214")}, "9216", false},
+		{"not contains pass", Assertion{Path: "/test", Contains: ptr("err-disabled"), Not: true}, "up", true},
+		{"not matches fail", Assertion{Path: "/test", Matches: ptr(`^Eth`), Not: true}, "Ethernet1", false},
+		{"not range pass", Assertion{Path: "/test", GTE: ptr("1"), LTE: ptr("10"), Not: true}, "11", true},
+		{"not equals fail", Assertion{Path: "/test", Equals: ptr("DOWN"), Not: true}, "DOWN", false},
 	}
 
 	for _, tt := range tests {
@@ -172,6 +185,49 @@ func TestValidate_NumericError(t *testing.T) {
 	}
 }
 
+func TestValidateUpdate_FreshWithin(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		assert    Assertion
+		timestamp time.Time
+		actual    string
+		want      bool
+		wantErr   bool
+	}{
+		{"fresh", Assertion{Path: "/test", FreshWithin: ptr("5m")}, now.Add(-time.Minute), "UP", true, false},
+		{"stale", Assertion{Path: "/test", FreshWithin: ptr("5m")}, now.Add(-time.Hour), "UP", false, false},
+		{"device clock ahead", Assertion{Path: "/test", FreshWithin: ptr("5m")}, now.Add(time.Minute), "UP", true, false},
+		{"fresh but wrong", Assertion{Path: "/test", Equals: ptr("UP"), FreshWithin: ptr("5m")}, now, "DOWN", false, false},
+		{"fresh and right", Assertion{Path: "/test", Equals: ptr("UP"), FreshWithin: ptr("5m")}, now, "UP", true, false},
+		{"no timestamp", Assertion{Path: "/test", FreshWithin: ptr("5m")}, time.Time{}, "UP", false, true},
+		{"not, fresh and absent", Assertion{Path: "/test", Contains: ptr("MAINT"), Not: true, FreshWithin: ptr("5m")}, now, "uplink", true, false},
+		{"not, fresh and present", Assertion{Path: "/test", Contains: ptr("MAINT"), Not: true, FreshWithin: ptr("5m")}, now, "MAINT uplink", false, false},
+		{"not, stale and absent", Assertion{Path: "/test", Contains: ptr("MAINT"), Not: true, FreshWithin: ptr("5m")}, now.Add(-time.Hour), "uplink", false, false},
+		{"not, stale and present", Assertion{Path: "/test", Contains: ptr("MAINT"), Not: true, FreshWithin: ptr("5m")}, now.Add(-time.Hour), "MAINT uplink", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.assert.ValidateUpdate(value.String(tt.actual), tt.timestamp, true)
+			if result.Passed != tt.want || (result.Error != nil) != tt.wantErr {
+				t.Errorf("ValidateUpdate() = %v, %v; want %v, error %v", result.Passed, result.Error, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_NotKeepsErrors(t *testing.T) {
+	a := Assertion{Path: "/test", Contains: ptr("x"), Not: true}
+	if result := a.Validate("", false); result.Passed || result.Error == nil {
+		t.Errorf("Validate() on a missing path = %+v, want an error", result)
+	}
+	a = Assertion{Path: "/test", GT: ptr("10"), Not: true}
+	if result := a.Validate("not-a-number", true); result.Passed || result.Error == nil {
+		t.Errorf("Validate() on a non-number = %+v, want an error", result)
+	}
+}
+
 func TestGetName(t *testing.T) {
 	tests := []struct {
 		name string
@@ -190,3 +246,127 @@ func TestGetName(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatePing(t *testing.T) {
+	loss := 20.0
+	tests := []struct {
+		name     string
+		check    PingCheck
+		sent     int
+		received int
+		maxRTT   time.Duration
+		want     bool
+	}{
+		{"no loss", PingCheck{Destination: "10.0.0.2"}, 5, 5, 2 * time.Millisecond, true},
+		{"loss not allowed by default", PingCheck{Destination: "10.0.0.2"}, 5, 4, 2 * time.Millisecond, false},
+		{"loss within max_loss", PingCheck{Destination: "10.0.0.2", MaxLoss: &loss}, 5, 4, 2 * time.Millisecond, true},
+		{"rtt over max_rtt", PingCheck{Destination: "10.0.0.2", MaxRTT: "10ms"}, 5, 5, 15 * time.Millisecond, false},
+		{"nothing sent", PingCheck{Destination: "10.0.0.2"}, 0, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := tt.check
+			a := Assertion{Ping: &check}
+			result := a.ValidatePing(tt.sent, tt.received, tt.maxRTT)
+			if result.Error != nil {
+				t.Fatalf("ValidatePing() error = %v", result.Error)
+			}
+			if result.Passed != tt.want {
+				t.Errorf("ValidatePing() passed = %v, want %v (%s)", result.Passed, tt.want, result.ActualValue)
+			}
+		})
+	}
+}
+
+func TestValidateTraceroute(t *testing.T) {
+	hops := []string{"10.0.0.1", "10.1.0.1", "10.2.0.5"}
+	tests := []struct {
+		name    string
+		check   TracerouteCheck
+		reached bool
+		want    bool
+	}{
+		{"reached", TracerouteCheck{Destination: "10.2.0.5"}, true, true},
+		{"not reached", TracerouteCheck{Destination: "10.2.0.5"}, false, false},
+		{"via hop", TracerouteCheck{Destination: "10.2.0.5", Via: "10.1.0.1"}, true, true},
+		{"missing via hop", TracerouteCheck{Destination: "10.2.0.5", Via: "10.9.0.1"}, true, false},
+		{"too many hops", TracerouteCheck{Destination: "10.2.0.5", MaxHops: 2}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := tt.check
+			a := Assertion{Traceroute: &check}
+			if got := a.ValidateTraceroute(hops, tt.reached).Passed; got != tt.want {
+				t.Errorf("ValidateTraceroute() passed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateClockSkew(t *testing.T) {
+	a := Assertion{MaxClockSkew: ptr("2s")}
+	tests := []struct {
+		skew time.Duration
+		want bool
+	}{
+		{500 * time.Millisecond, true},
+		{-1500 * time.Millisecond, true},
+		{3 * time.Second, false},
+		{-3 * time.Second, false},
+	}
+
+	for _, tt := range tests {
+		if got := a.ValidateClockSkew(tt.skew).Passed; got != tt.want {
+			t.Errorf("ValidateClockSkew(%v) = %v, want %v", tt.skew, got, tt.want)
+		}
+	}
+}
+
+func TestValidateOSVersion(t *testing.T) {
+	a := Assertion{OSVersion: ptr("4.32.1F")}
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"4.32.1F", true},
+		{"4.32.1F-lab", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := a.ValidateOSVersion(tt.version).Passed; got != tt.want {
+			t.Errorf("ValidateOSVersion(%q) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+	if result := (&Assertion{}).ValidateOSVersion("4.32.1F"); result.Error == nil {
+		t.Error("ValidateOSVersion() on a non-version assertion succeeded")
+	}
+}
+
+func TestValidateValue_Typed(t *testing.T) {
+	tests := []struct {
+		name      string
+		assertion Assertion
+		value     value.Value
+		want      bool
+	}{
+		{"float equals ignores formatting", Assertion{Equals: ptr("0.5")}, value.Float(0.5), true},
+		{"bool equals", Assertion{Equals: ptr("true")}, value.Bool(true), true},
+		{"uint64 counter gt", Assertion{GT: ptr("18446744073709551614")}, value.Uint(18446744073709551615), true},
+		{"int lte", Assertion{LTE: ptr("-1")}, value.Int(-2), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.assertion.ValidateValue(tt.value, true)
+			if result.Error != nil {
+				t.Fatalf("ValidateValue() error = %v", result.Error)
+			}
+			if result.Passed != tt.want {
+				t.Errorf("ValidateValue() passed = %v, want %v", result.Passed, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,17 @@
 package config
 
 import (
+	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
 
+	"github.com/ndtobs/netsert/pkg/hooks"
+	"github.com/ndtobs/netsert/pkg/secrets"
 	"gopkg.in/yaml.v3"
 )
 
@@ -11,23 +19,201 @@ import (
 type Config struct {
 	Defaults Defaults          `yaml:"defaults,omitempty"`
 	Targets  map[string]Target `yaml:"targets,omitempty"`
+	Groups   map[string]Target `yaml:"groups,omitempty"` // Per inventory group settings
+
+	// Named environments selected with --profile or NETSERT_PROFILE
+	Profiles map[string]Profile `yaml:"profiles,omitempty"`
+
+	// Defaults for netsert generate, so teams generate the same baseline
+	Generate Generate `yaml:"generate,omitempty"`
+
+	// Per-platform path overrides, keyed by platform name (e.g., arista_eos)
+	Platforms map[string]PlatformProfile `yaml:"platforms,omitempty"`
+
+	// Short path prefixes of your own: name to expanded path template,
+	// ending in {rest} (see assertion.SetPathPrefix)
+	ShortPaths map[string]string `yaml:"short_paths,omitempty"`
+
+	// Hooks run on every run, before those of the assertion file
+	Hooks hooks.Hooks `yaml:"hooks,omitempty"`
+
+	// Credentials typed at a prompt (--ask-user, --ask-pass); see GetCredentials
+	PromptedUsername string `yaml:"-"`
+	PromptedPassword string `yaml:"-"`
+}
+
+// PlatformProfile adjusts how paths are expanded and requested for one
+// platform. Set fields replace the built-in profile's; rewrites and
+// unsupported prefixes are added to it.
+type PlatformProfile struct {
+	Origin       string   `yaml:"origin,omitempty"`        // gNMI origin for OpenConfig paths
+	Origins      []string `yaml:"origins,omitempty"`       // Native origins paths may name, as <origin>:/path
+	ProtocolName string   `yaml:"protocol_name,omitempty"` // Name of every protocol instance
+
+	// Rewrites move OpenConfig path prefixes to where the platform serves
+	// the data; requests under unsupported prefixes are skipped
+	Rewrites    []PathRewrite `yaml:"rewrites,omitempty"`
+	Unsupported []string      `yaml:"unsupported,omitempty"`
+}
+
+// PathRewrite replaces the path prefix From with To
+type PathRewrite struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// Generate holds netsert generate defaults. Command-line flags win over
+// every setting here.
+type Generate struct {
+	Generators []string                     `yaml:"generators,omitempty"` // Run when --gen is not given (default: all)
+	Args       map[string]map[string]string `yaml:"args,omitempty"`       // Per-generator arguments, as with --gen-arg
+
+	// Regexes of interfaces checked by interface-scoped generators, as
+	// --include-interfaces and --exclude-interfaces
+	IncludeInterfaces string `yaml:"include_interfaces,omitempty"`
+	ExcludeInterfaces string `yaml:"exclude_interfaces,omitempty"`
+	SkipInterfaces    string `yaml:"skip_interfaces,omitempty"` // Deprecated: use exclude_interfaces
+
+	Limit int      `yaml:"limit,omitempty"` // As --limit
+	VLANs []int    `yaml:"vlans,omitempty"` // As --vlan
+	VRFs  []string `yaml:"vrfs,omitempty"`  // As --vrf
+	Mode  string   `yaml:"mode,omitempty"`  // As --mode: snapshot or exists
+
+	Output GenerateOutput `yaml:"output,omitempty"`
+}
+
+// GenerateOutput controls how generated assertions are written
+type GenerateOutput struct {
+	File   string `yaml:"file,omitempty"`   // Output file when -f is not given (default: stdout)
+	Paths  string `yaml:"paths,omitempty"`  // "short" compacts every path, "full" expands them (default: as generated)
+	Header *bool  `yaml:"header,omitempty"` // Leading "Generated by" comment (default: true)
+}
+
+// Path styles for generate output
+const (
+	PathsShort = "short"
+	PathsFull  = "full"
+)
+
+// ExcludePattern returns exclude_interfaces, or the deprecated
+// skip_interfaces when only that is set
+func (g Generate) ExcludePattern() string {
+	if g.ExcludeInterfaces != "" {
+		return g.ExcludeInterfaces
+	}
+	return g.SkipInterfaces
+}
+
+// ArgSpecs returns the configured generator arguments as sorted
+// "<generator>.<key>=<value>" specs
+func (g Generate) ArgSpecs() []string {
+	var specs []string
+	for gen, keys := range g.Args {
+		for k, v := range keys {
+			specs = append(specs, gen+"."+k+"="+v)
+		}
+	}
+	sort.Strings(specs)
+	return specs
+}
+
+// Validate checks the output settings
+func (o GenerateOutput) Validate() error {
+	switch o.Paths {
+	case "", PathsShort, PathsFull:
+		return nil
+	default:
+		return fmt.Errorf("generate.output.paths: must be %s or %s, got %q", PathsShort, PathsFull, o.Paths)
+	}
+}
+
+// Profile bundles defaults and overrides for one environment (e.g., lab, prod)
+type Profile struct {
+	Defaults Defaults          `yaml:"defaults,omitempty"`
+	Targets  map[string]Target `yaml:"targets,omitempty"`
+	Groups   map[string]Target `yaml:"groups,omitempty"`
 }
 
+// EnvProfile selects a profile when --profile is not given
+const EnvProfile = "NETSERT_PROFILE"
+
 // Defaults holds default settings
 type Defaults struct {
-	Username string `yaml:"username,omitempty"`
-	Password string `yaml:"password,omitempty"`
-	Insecure bool   `yaml:"insecure,omitempty"`
-	Timeout  string `yaml:"timeout,omitempty"`
-	Workers  int    `yaml:"workers,omitempty"`  // Concurrent targets (default: 10)
-	Parallel int    `yaml:"parallel,omitempty"` // Concurrent assertions per target (default: 5)
+	Username  string `yaml:"username,omitempty"`
+	Password  string `yaml:"password,omitempty"`
+	Insecure  bool   `yaml:"insecure,omitempty"`
+	Port      int    `yaml:"port,omitempty"`      // gNMI port appended to hosts without one
+	Timeout   string `yaml:"timeout,omitempty"`   // Per-assertion timeout (e.g., "30s")
+	Inventory string `yaml:"inventory,omitempty"` // Inventory file used when -i is not given
+	Workers   int    `yaml:"workers,omitempty"`   // Concurrent targets (default: 10)
+	Parallel  int    `yaml:"parallel,omitempty"`  // Concurrent assertions per target (default: 5)
+	History   string `yaml:"history,omitempty"`   // Record every run to this file, as with run --history
+
+	// GlobalRateLimit caps requests per second across all targets (default: unlimited)
+	GlobalRateLimit float64 `yaml:"global_rate_limit,omitempty"`
+
+	Connection `yaml:",inline"`
 }
 
-// Target holds per-target settings (keyed by address or pattern)
+// Target holds per-target or per-group settings (keyed by address or group name)
 type Target struct {
 	Username string `yaml:"username,omitempty"`
 	Password string `yaml:"password,omitempty"`
 	Insecure *bool  `yaml:"insecure,omitempty"`
+
+	Connection `yaml:",inline"`
+}
+
+// Connection holds transport settings for reaching a target
+type Connection struct {
+	// Proxy routes gNMI through a jump host:
+	// socks5://host:1080, http://host:3128 (CONNECT), or ssh://user@bastion:22
+	Proxy string `yaml:"proxy,omitempty"`
+
+	Keepalive        string `yaml:"keepalive,omitempty"`         // Ping interval on idle connections (e.g., "30s")
+	KeepaliveTimeout string `yaml:"keepalive_timeout,omitempty"` // Wait for a ping ack before dropping (default: 20s)
+	MaxMsgSize       int    `yaml:"max_msg_size,omitempty"`      // Max receive message size in bytes (default: 4MB)
+	Authority        string `yaml:"authority,omitempty"`         // Override :authority and TLS server name
+	Compression      string `yaml:"compression,omitempty"`       // RPC compression ("gzip") for slow links
+
+	// Verify server certificates against server_name and/or ca_file
+	ServerName string `yaml:"server_name,omitempty"` // Name the device certificate was issued to
+	CAFile     string `yaml:"ca_file,omitempty"`     // PEM CA bundle (default: system roots)
+
+	Retries      *int   `yaml:"retries,omitempty"`       // Retries for transient UNAVAILABLE errors (default: 2)
+	RetryBackoff string `yaml:"retry_backoff,omitempty"` // Initial retry delay, doubled each attempt (default: 500ms)
+
+	TargetName string `yaml:"target_name,omitempty"` // gNMI target field (gateways serving several devices)
+	Prefix     string `yaml:"prefix,omitempty"`      // gNMI path prefix sent with every request
+
+	RateLimit float64 `yaml:"rate_limit,omitempty"` // Requests per second to the target (default: unlimited)
+}
+
+// KeepaliveDurations parses the keepalive settings (zero if unset)
+func (c Connection) KeepaliveDurations() (interval, timeout time.Duration, err error) {
+	if c.Keepalive != "" {
+		if interval, err = time.ParseDuration(c.Keepalive); err != nil {
+			return 0, 0, fmt.Errorf("keepalive: %w", err)
+		}
+	}
+	if c.KeepaliveTimeout != "" {
+		if timeout, err = time.ParseDuration(c.KeepaliveTimeout); err != nil {
+			return 0, 0, fmt.Errorf("keepalive_timeout: %w", err)
+		}
+	}
+	return interval, timeout, nil
+}
+
+// RetryBackoffDuration parses the retry backoff (zero if unset)
+func (c Connection) RetryBackoffDuration() (time.Duration, error) {
+	if c.RetryBackoff == "" {
+		return 0, nil
+	}
+	backoff, err := time.ParseDuration(c.RetryBackoff)
+	if err != nil {
+		return 0, fmt.Errorf("retry_backoff: %w", err)
+	}
+	return backoff, nil
 }
 
 // Load loads config from standard locations
@@ -67,36 +253,237 @@ func LoadFile(path string) (*Config, error) {
 		return nil, err
 	}
 
+	// SOPS/age-encrypted configs are decrypted transparently
+	data, err = secrets.Decrypt(path, data)
+	if err != nil {
+		return nil, err
+	}
+
 	var cfg Config
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := cfg.Hooks.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
 	}
 
 	return &cfg, nil
 }
 
-// GetCredentials returns username/password for a target address
-// Checks target-specific config first, then defaults
-func (c *Config) GetCredentials(address string) (username, password string, insecure bool) {
+// Environment variables consulted for credentials. Per-group variants append
+// the upper-cased group name, e.g. NETSERT_PASSWORD_SPINES for group "spines".
+const (
+	EnvUsername = "NETSERT_USERNAME"
+	EnvPassword = "NETSERT_PASSWORD"
+)
+
+// ApplyProfile activates a named profile. The profile's defaults replace the
+// top-level defaults entirely, so nothing (e.g., insecure) leaks between
+// environments; its targets and groups override same-named top-level entries.
+func (c *Config) ApplyProfile(name string) error {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return fmt.Errorf("profile %q not found in config", name)
+	}
+
+	c.Defaults = profile.Defaults
+	if len(profile.Targets) > 0 && c.Targets == nil {
+		c.Targets = make(map[string]Target)
+	}
+	for address, target := range profile.Targets {
+		c.Targets[address] = target
+	}
+	if len(profile.Groups) > 0 && c.Groups == nil {
+		c.Groups = make(map[string]Target)
+	}
+	for group, settings := range profile.Groups {
+		c.Groups[group] = settings
+	}
+	return nil
+}
+
+// TimeoutDuration parses the configured default timeout (zero if unset)
+func (d Defaults) TimeoutDuration() (time.Duration, error) {
+	if d.Timeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(d.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("defaults.timeout: %w", err)
+	}
+	return timeout, nil
+}
+
+// GetCredentials returns username/password for a target address.
+// groups are the inventory groups the target belongs to, most specific first.
+//
+// Each value is taken from the first source that sets it:
+//  1. per-target config (targets:)
+//  2. for each group, most specific first:
+//     a. environment (NETSERT_USERNAME_<GROUP>, NETSERT_PASSWORD_<GROUP>)
+//     b. group config (groups:)
+//  3. credentials typed at a prompt (PromptedUsername, PromptedPassword)
+//  4. global environment (NETSERT_USERNAME, NETSERT_PASSWORD)
+//  5. OS keyring entry for the target (netsert login <target>), then for
+//     each group (netsert login @<group>); only looked up while the
+//     password is still unset
+//  6. config defaults (defaults:)
+//
+// insecure follows the same order for targets: and groups: entries that set it.
+// Credentials set in the assertion file or on the command line take
+// precedence over all of these; see Credentials.
+func (c *Config) GetCredentials(address string, groups ...string) (username, password string, insecure bool) {
+	return c.Credentials(address, "", "", groups...)
+}
+
+// Credentials is GetCredentials for a target whose username or password may
+// already be set (e.g., in the assertion file), keeping those; a set
+// password spares the keyring lookup.
+func (c *Config) Credentials(address, knownUsername, knownPassword string, groups ...string) (username, password string, insecure bool) {
+	username, password = knownUsername, knownPassword
+	fill := func(u, p string) {
+		if username == "" {
+			username = u
+		}
+		if password == "" {
+			password = p
+		}
+	}
+
+	insecureSet := false
+
 	// Check target-specific config
-	if target, ok := c.Targets[address]; ok {
-		username = target.Username
-		password = target.Password
+	if target, ok := c.lookupTarget(address); ok {
+		fill(target.Username, target.Password)
 		if target.Insecure != nil {
 			insecure = *target.Insecure
+			insecureSet = true
 		}
 	}
 
-	// Fall back to defaults for empty values
-	if username == "" {
-		username = c.Defaults.Username
+	// Then the environment and groups, most specific first
+	for _, group := range groups {
+		suffix := "_" + envSuffix(group)
+		fill(os.Getenv(EnvUsername+suffix), os.Getenv(EnvPassword+suffix))
+		if settings, ok := c.Groups[group]; ok {
+			fill(settings.Username, settings.Password)
+			if !insecureSet && settings.Insecure != nil {
+				insecure = *settings.Insecure
+				insecureSet = true
+			}
+		}
 	}
+	fill(c.PromptedUsername, c.PromptedPassword)
+	fill(os.Getenv(EnvUsername), os.Getenv(EnvPassword))
+
+	// The keyring only when nothing else gave a password, as it can be slow
+	// or unavailable (e.g., no D-Bus session on CI runners)
 	if password == "" {
-		password = c.Defaults.Password
+		keys := []string{address}
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			keys = append(keys, host)
+		}
+		for _, group := range groups {
+			keys = append(keys, "@"+group)
+		}
+		for _, key := range keys {
+			if u, p, ok := KeyringCredentials(key); ok {
+				fill(u, p)
+				break
+			}
+		}
 	}
-	if !insecure {
+
+	// Fall back to defaults for empty values
+	fill(c.Defaults.Username, c.Defaults.Password)
+	if !insecureSet {
 		insecure = c.Defaults.Insecure
 	}
 
 	return username, password, insecure
 }
+
+// GetConnection returns transport settings for a target address. Each field
+// comes from the first of targets:, groups: (most specific first), and
+// defaults: that sets it.
+func (c *Config) GetConnection(address string, groups ...string) Connection {
+	var conn Connection
+	fill := func(src Connection) {
+		if conn.Proxy == "" {
+			conn.Proxy = src.Proxy
+		}
+		if conn.Keepalive == "" {
+			conn.Keepalive = src.Keepalive
+		}
+		if conn.KeepaliveTimeout == "" {
+			conn.KeepaliveTimeout = src.KeepaliveTimeout
+		}
+		if conn.MaxMsgSize == 0 {
+			conn.MaxMsgSize = src.MaxMsgSize
+		}
+		if conn.Authority == "" {
+			conn.Authority = src.Authority
+		}
+		if conn.Compression == "" {
+			conn.Compression = src.Compression
+		}
+		if conn.ServerName == "" {
+			conn.ServerName = src.ServerName
+		}
+		if conn.CAFile == "" {
+			conn.CAFile = src.CAFile
+		}
+		if conn.Retries == nil {
+			conn.Retries = src.Retries
+		}
+		if conn.RetryBackoff == "" {
+			conn.RetryBackoff = src.RetryBackoff
+		}
+		if conn.TargetName == "" {
+			conn.TargetName = src.TargetName
+		}
+		if conn.Prefix == "" {
+			conn.Prefix = src.Prefix
+		}
+		if conn.RateLimit == 0 {
+			conn.RateLimit = src.RateLimit
+		}
+	}
+
+	if target, ok := c.lookupTarget(address); ok {
+		fill(target.Connection)
+	}
+	for _, group := range groups {
+		if settings, ok := c.Groups[group]; ok {
+			fill(settings.Connection)
+		}
+	}
+	fill(c.Defaults.Connection)
+
+	return conn
+}
+
+// lookupTarget finds per-target settings by exact address, falling back to
+// the bare host so entries keyed without a port still match after a default
+// port has been applied.
+func (c *Config) lookupTarget(address string) (Target, bool) {
+	if target, ok := c.Targets[address]; ok {
+		return target, true
+	}
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		target, ok := c.Targets[host]
+		return target, ok
+	}
+	return Target{}, false
+}
+
+// envSuffix converts a group name to an environment variable suffix
+// ("dc1-spines" -> "DC1_SPINES")
+func envSuffix(group string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, group)
+}
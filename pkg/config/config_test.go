@@ -0,0 +1,331 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+	"gopkg.in/yaml.v3"
+)
+
+// stubKeyring replaces the OS keyring with an in-memory map for the test
+func stubKeyring(t *testing.T, entries map[string]string) {
+	t.Helper()
+	orig := keyringGet
+	keyringGet = func(service, key string) (string, error) {
+		if secret, ok := entries[key]; ok {
+			return secret, nil
+		}
+		return "", keyring.ErrNotFound
+	}
+	t.Cleanup(func() { keyringGet = orig })
+}
+
+func TestGetCredentials_Precedence(t *testing.T) {
+	cfg := &Config{
+		Defaults: Defaults{Username: "default-user", Password: "default-pass"},
+		Targets: map[string]Target{
+			"spine1": {Username: "spine1-user"},
+		},
+	}
+
+	stubKeyring(t, nil)
+	t.Setenv(EnvPassword, "env-pass")
+	t.Setenv(EnvPassword+"_DC1_SPINES", "group-pass")
+
+	tests := []struct {
+		name     string
+		address  string
+		groups   []string
+		wantUser string
+		wantPass string
+	}{
+		{"defaults and global env", "leaf1:6030", nil, "default-user", "env-pass"},
+		{"group env wins over global env", "leaf1:6030", []string{"dc1-spines"}, "default-user", "group-pass"},
+		{"target config matched without port", "spine1:6030", []string{"dc1-spines"}, "spine1-user", "group-pass"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, pass, _ := cfg.GetCredentials(tt.address, tt.groups...)
+			if user != tt.wantUser || pass != tt.wantPass {
+				t.Errorf("GetCredentials() = %q/%q, want %q/%q", user, pass, tt.wantUser, tt.wantPass)
+			}
+		})
+	}
+}
+
+func TestGetCredentials_Prompted(t *testing.T) {
+	cfg := &Config{
+		Defaults:         Defaults{Username: "default-user", Password: "default-pass"},
+		Targets:          map[string]Target{"spine1": {Username: "spine1-user", Password: "spine1-pass"}},
+		PromptedUsername: "typed-user",
+		PromptedPassword: "typed-pass",
+	}
+	stubKeyring(t, map[string]string{"leaf1": `{"username":"keyring-user","password":"keyring-pass"}`})
+	t.Setenv(EnvUsername, "env-user")
+	t.Setenv(EnvPassword, "env-pass")
+
+	tests := []struct {
+		name     string
+		address  string
+		wantUser string
+		wantPass string
+	}{
+		{"over environment, keyring, and defaults", "leaf1:6030", "typed-user", "typed-pass"},
+		{"under target config", "spine1:6030", "spine1-user", "spine1-pass"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, pass, _ := cfg.GetCredentials(tt.address)
+			if user != tt.wantUser || pass != tt.wantPass {
+				t.Errorf("GetCredentials() = %q/%q, want %q/%q", user, pass, tt.wantUser, tt.wantPass)
+			}
+		})
+	}
+}
+
+func TestGetCredentials_Keyring(t *testing.T) {
+	cfg := &Config{Defaults: Defaults{Username: "default-user"}}
+	stubKeyring(t, map[string]string{
+		"spine1":  `{"username":"spine1-user","password":"spine1-pass"}`,
+		"@leaves": `{"username":"leaf-user","password":"leaf-pass"}`,
+	})
+
+	tests := []struct {
+		name     string
+		address  string
+		groups   []string
+		wantUser string
+		wantPass string
+	}{
+		{"target entry", "spine1:6030", nil, "spine1-user", "spine1-pass"},
+		{"group entry", "leaf1:6030", []string{"leaves"}, "leaf-user", "leaf-pass"},
+		{"no entry", "other:6030", nil, "default-user", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, pass, _ := cfg.GetCredentials(tt.address, tt.groups...)
+			if user != tt.wantUser || pass != tt.wantPass {
+				t.Errorf("GetCredentials() = %q/%q, want %q/%q", user, pass, tt.wantUser, tt.wantPass)
+			}
+		})
+	}
+}
+
+func TestCredentials_KeyringSkipped(t *testing.T) {
+	// The keyring isn't touched once a password is known
+	lookups := 0
+	orig := keyringGet
+	keyringGet = func(service, key string) (string, error) {
+		lookups++
+		return `{"username":"keyring-user","password":"keyring-pass"}`, nil
+	}
+	t.Cleanup(func() { keyringGet = orig })
+
+	cfg := &Config{Targets: map[string]Target{"spine1": {Password: "config-pass"}}}
+	t.Setenv(EnvPassword+"_LEAVES", "env-pass")
+
+	tests := []struct {
+		name     string
+		address  string
+		password string // Set in the assertion file
+		groups   []string
+		wantUser string
+		wantPass string
+	}{
+		{"inline password", "leaf9:6030", "inline-pass", nil, "", "inline-pass"},
+		{"target config", "spine1:6030", "", nil, "", "config-pass"},
+		{"environment", "leaf1:6030", "", []string{"leaves"}, "", "env-pass"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, pass, _ := cfg.Credentials(tt.address, "", tt.password, tt.groups...)
+			if user != tt.wantUser || pass != tt.wantPass {
+				t.Errorf("Credentials() = %q/%q, want %q/%q", user, pass, tt.wantUser, tt.wantPass)
+			}
+		})
+	}
+	if lookups != 0 {
+		t.Errorf("keyring looked up %d times, want none", lookups)
+	}
+
+	// Still consulted when nothing else has a password, keeping a known username
+	user, pass, _ := cfg.Credentials("leaf9:6030", "admin", "")
+	if user != "admin" || pass != "keyring-pass" {
+		t.Errorf("Credentials() = %q/%q, want admin/keyring-pass", user, pass)
+	}
+}
+
+func TestGetCredentials_Groups(t *testing.T) {
+	secure := false
+	cfg := &Config{
+		Defaults: Defaults{Username: "default-user", Password: "default-pass", Insecure: true},
+		Groups: map[string]Target{
+			"spines": {Username: "spine-user", Password: "spine-pass", Insecure: &secure},
+			"all":    {Username: "all-user"},
+		},
+	}
+	stubKeyring(t, nil)
+	t.Setenv(EnvPassword+"_SPINES", "env-spine-pass")
+
+	user, pass, insecure := cfg.GetCredentials("spine1:6030", "spines", "all")
+	if user != "spine-user" || pass != "env-spine-pass" || insecure {
+		t.Errorf("GetCredentials() = %q/%q/%v, want spine-user/env-spine-pass/false", user, pass, insecure)
+	}
+
+	user, pass, insecure = cfg.GetCredentials("leaf1:6030", "all")
+	if user != "all-user" || pass != "default-pass" || !insecure {
+		t.Errorf("GetCredentials() = %q/%q/%v, want all-user/default-pass/true", user, pass, insecure)
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	cfg := &Config{
+		Defaults: Defaults{Username: "base-user", Insecure: true},
+		Targets:  map[string]Target{"spine1": {Username: "base-spine"}},
+		Profiles: map[string]Profile{
+			"prod": {
+				Defaults: Defaults{Username: "prod-user", Timeout: "5s", Inventory: "prod-inventory.yaml"},
+				Targets:  map[string]Target{"spine1": {Username: "prod-spine"}},
+			},
+		},
+	}
+
+	if err := cfg.ApplyProfile("prod"); err != nil {
+		t.Fatalf("ApplyProfile() error = %v", err)
+	}
+	if cfg.Defaults.Username != "prod-user" || cfg.Defaults.Insecure {
+		t.Errorf("Defaults = %+v, want prod defaults without base insecure", cfg.Defaults)
+	}
+	if cfg.Targets["spine1"].Username != "prod-spine" {
+		t.Errorf("target spine1 username = %q, want prod-spine", cfg.Targets["spine1"].Username)
+	}
+	if d, err := cfg.Defaults.TimeoutDuration(); err != nil || d.Seconds() != 5 {
+		t.Errorf("TimeoutDuration() = %v, %v", d, err)
+	}
+
+	if err := cfg.ApplyProfile("missing"); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+}
+
+func TestGetConnection(t *testing.T) {
+	cfg := &Config{
+		Defaults: Defaults{Connection: Connection{Proxy: "socks5://default:1080"}},
+		Targets:  map[string]Target{"spine1": {Connection: Connection{Proxy: "ssh://ops@bastion"}}},
+		Groups:   map[string]Target{"remote": {Connection: Connection{Proxy: "http://squid:3128"}}},
+	}
+
+	tests := []struct {
+		address string
+		groups  []string
+		want    string
+	}{
+		{"spine1:6030", []string{"remote"}, "ssh://ops@bastion"},
+		{"leaf1:6030", []string{"remote"}, "http://squid:3128"},
+		{"leaf2:6030", nil, "socks5://default:1080"},
+	}
+
+	for _, tt := range tests {
+		if got := cfg.GetConnection(tt.address, tt.groups...).Proxy; got != tt.want {
+			t.Errorf("GetConnection(%q).Proxy = %q, want %q", tt.address, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateConfigSkipInterfaces(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"deprecated alias", "generate:\n  skip_interfaces: '^Management'\n", "^Management"},
+		{"exclude wins", "generate:\n  skip_interfaces: '^Management'\n  exclude_interfaces: '^Port-Channel'\n", "^Port-Channel"},
+		{"neither", "generate:\n  limit: 5\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			if err := yaml.Unmarshal([]byte(tt.data), &cfg); err != nil {
+				t.Fatal(err)
+			}
+			if got := cfg.Generate.ExcludePattern(); got != tt.want {
+				t.Errorf("ExcludePattern() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateConfig(t *testing.T) {
+	data := `
+generate:
+  generators: [bgp, interfaces, lldp]
+  exclude_interfaces: '^(Management|Port-Channel)'
+  args:
+    lldp:
+      exclude: '^mgmt'
+    bgp:
+      vrfs: all
+  limit: 50
+  output:
+    file: baseline.yaml
+    paths: full
+    header: false
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	gc := cfg.Generate
+
+	if !reflect.DeepEqual(gc.Generators, []string{"bgp", "interfaces", "lldp"}) || gc.Limit != 50 {
+		t.Errorf("Generate = %+v", gc)
+	}
+	if gc.Output.File != "baseline.yaml" || gc.Output.Header == nil || *gc.Output.Header {
+		t.Errorf("Output = %+v", gc.Output)
+	}
+	if err := gc.Output.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	if got := gc.ExcludePattern(); got != "^(Management|Port-Channel)" {
+		t.Errorf("ExcludePattern() = %q", got)
+	}
+	want := []string{
+		"bgp.vrfs=all",
+		"lldp.exclude=^mgmt",
+	}
+	if got := gc.ArgSpecs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ArgSpecs() = %v, want %v", got, want)
+	}
+
+	if err := (GenerateOutput{Paths: "long"}).Validate(); err == nil {
+		t.Error("expected error for unknown paths style")
+	}
+}
+
+func TestPlatformsConfig(t *testing.T) {
+	data := `
+platforms:
+  juniper:
+    protocol_name: default
+    rewrites:
+      - from: /system/state/hostname
+        to: /junos/system/hostname
+    unsupported: [/lldp]
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	p := cfg.Platforms["juniper"]
+	if p.ProtocolName != "default" || len(p.Rewrites) != 1 || p.Rewrites[0].To != "/junos/system/hostname" {
+		t.Errorf("unexpected platform profile: %+v", p)
+	}
+	if len(p.Unsupported) != 1 || p.Unsupported[0] != "/lldp" {
+		t.Errorf("Unsupported = %v", p.Unsupported)
+	}
+}
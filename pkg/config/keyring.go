@@ -0,0 +1,58 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/zalando/go-keyring"
+)
+
+// KeyringService is the service name netsert credentials are stored under
+const KeyringService = "netsert"
+
+// keyringEntry is the secret stored per key
+type keyringEntry struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Keyring access, replaceable in tests
+var (
+	keyringGet    = keyring.Get
+	keyringSet    = keyring.Set
+	keyringDelete = keyring.Delete
+)
+
+// SaveKeyringCredentials stores credentials in the OS keyring.
+// key is a target address (e.g., "spine1:6030") or a group ("@spines").
+func SaveKeyringCredentials(key, username, password string) error {
+	data, err := json.Marshal(keyringEntry{Username: username, Password: password})
+	if err != nil {
+		return err
+	}
+	return keyringSet(KeyringService, key, string(data))
+}
+
+// DeleteKeyringCredentials removes stored credentials for key
+func DeleteKeyringCredentials(key string) error {
+	err := keyringDelete(KeyringService, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// KeyringCredentials returns stored credentials for key. ok is false if
+// nothing is stored or no keyring is available on this system.
+func KeyringCredentials(key string) (username, password string, ok bool) {
+	secret, err := keyringGet(KeyringService, key)
+	if err != nil {
+		return "", "", false
+	}
+
+	var entry keyringEntry
+	if err := json.Unmarshal([]byte(secret), &entry); err != nil {
+		return "", "", false
+	}
+	return entry.Username, entry.Password, true
+}
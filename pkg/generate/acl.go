@@ -0,0 +1,216 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+)
+
+func init() {
+	Register(&ACLGenerator{})
+}
+
+// DefaultACLEntryLimit caps entry assertions per ACL when neither its limit argument nor Options.Limit is set
+const DefaultACLEntryLimit = 100
+
+// ACLGenerator creates assertions for ACL presence and attachment
+type ACLGenerator struct{}
+
+func (g *ACLGenerator) Name() string {
+	return "acl"
+}
+
+func (g *ACLGenerator) Description() string {
+	return "Generate assertions for ACL sets, their entries, and where they are applied"
+}
+
+func (g *ACLGenerator) Args() map[string]string {
+	return map[string]string{"limit": limitArgDescription}
+}
+
+// aclSet is an ACL and the sequence numbers of its entries
+type aclSet struct {
+	Name    string
+	Type    string
+	Entries []int
+}
+
+// aclBinding is an ACL applied to an interface or the control plane
+type aclBinding struct {
+	Interface string // Empty for the control plane
+	Direction string // "ingress" or "egress"
+	SetName   string
+	Type      string
+}
+
+func (g *ACLGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
+	value, exists, err := client.Get(ctx, "/acl", opts.Username, opts.Password)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query ACLs: %w", err)
+	}
+	if !exists || value == "" {
+		return nil, nil
+	}
+	sets, bindings, err := g.parseACL(value)
+	if err != nil {
+		return nil, err
+	}
+
+	// Control-plane ACLs are optional; many platforms don't model them
+	cpValue, exists, err := client.Get(ctx, "/system/control-plane-traffic/ingress/acl", opts.Username, opts.Password)
+	if err == nil && exists && cpValue != "" {
+		cp, err := g.parseControlPlane(cpValue)
+		if err != nil {
+			return nil, err
+		}
+		bindings = append(bindings, cp...)
+	}
+
+	limit, err := limitArg(opts, g.Name(), DefaultACLEntryLimit)
+	if err != nil {
+		return nil, err
+	}
+	return g.assertions(sets, bindings, limit), nil
+}
+
+// assertions checks each ACL set exists with its entry count and entries
+// (up to limit per set) and each binding is in place
+func (g *ACLGenerator) assertions(sets []aclSet, bindings []aclBinding, limit int) []assertion.Assertion {
+	var assertions []assertion.Assertion
+
+	for _, set := range sets {
+		base := fmt.Sprintf("acl/acl-sets/acl-set[name=%s][type=%s]", set.Name, set.Type)
+		assertions = append(assertions, assertion.Assertion{
+			Name:   fmt.Sprintf("ACL %s exists", set.Name),
+			Path:   base + "/state/name",
+			Equals: strPtr(set.Name),
+		})
+		count := len(set.Entries)
+		assertions = append(assertions, assertion.Assertion{
+			Name:     fmt.Sprintf("ACL %s has %d entries", set.Name, count),
+			Path:     base + "/acl-entries",
+			Count:    &count,
+			ListPath: "acl-entry",
+		})
+		for i, seq := range set.Entries {
+			if i >= limit {
+				break
+			}
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("ACL %s has entry %d", set.Name, seq),
+				Path:   fmt.Sprintf("%s/acl-entries/acl-entry[sequence-id=%d]/state/sequence-id", base, seq),
+				Equals: strPtr(fmt.Sprintf("%d", seq)),
+			})
+		}
+	}
+
+	for _, b := range bindings {
+		if b.Interface == "" {
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("ACL %s applied to control plane", b.SetName),
+				Path:   fmt.Sprintf("system/control-plane-traffic/ingress/acl/acl-set[set-name=%s][type=%s]/state/set-name", b.SetName, b.Type),
+				Equals: strPtr(b.SetName),
+			})
+			continue
+		}
+		assertions = append(assertions, assertion.Assertion{
+			Name:   fmt.Sprintf("ACL %s applied %s on %s", b.SetName, b.Direction, b.Interface),
+			Path:   fmt.Sprintf("acl/interfaces/interface[id=%s]/%s-acl-sets/%s-acl-set[set-name=%s][type=%s]/state/set-name", b.Interface, b.Direction, b.Direction, b.SetName, b.Type),
+			Equals: strPtr(b.SetName),
+		})
+	}
+
+	return assertions
+}
+
+func (g *ACLGenerator) parseACL(jsonData string) ([]aclSet, []aclBinding, error) {
+	type setRef struct {
+		SetName string `json:"set-name"`
+		Type    string `json:"type"`
+	}
+	type aclContainer struct {
+		ACLSets struct {
+			ACLSet []struct {
+				Name       string `json:"name"`
+				Type       string `json:"type"`
+				ACLEntries struct {
+					ACLEntry []struct {
+						SequenceID int `json:"sequence-id"`
+					} `json:"acl-entry"`
+				} `json:"acl-entries"`
+			} `json:"acl-set"`
+		} `json:"acl-sets"`
+		Interfaces struct {
+			Interface []struct {
+				ID             string `json:"id"`
+				IngressACLSets struct {
+					IngressACLSet []setRef `json:"ingress-acl-set"`
+				} `json:"ingress-acl-sets"`
+				EgressACLSets struct {
+					EgressACLSet []setRef `json:"egress-acl-set"`
+				} `json:"egress-acl-sets"`
+			} `json:"interface"`
+		} `json:"interfaces"`
+	}
+
+	// The response may be the acl container itself or wrapped in it
+	var wrapped struct {
+		ACL *aclContainer `json:"acl"`
+	}
+	if err := unmarshalStripped(jsonData, &wrapped); err != nil {
+		return nil, nil, fmt.Errorf("parse ACLs: %w", err)
+	}
+	acl := wrapped.ACL
+	if acl == nil {
+		acl = &aclContainer{}
+		if err := unmarshalStripped(jsonData, acl); err != nil {
+			return nil, nil, fmt.Errorf("parse ACLs: %w", err)
+		}
+	}
+
+	var sets []aclSet
+	for _, s := range acl.ACLSets.ACLSet {
+		set := aclSet{Name: s.Name, Type: normalizeAfiSafiName(s.Type)}
+		for _, e := range s.ACLEntries.ACLEntry {
+			set.Entries = append(set.Entries, e.SequenceID)
+		}
+		sort.Ints(set.Entries)
+		sets = append(sets, set)
+	}
+
+	var bindings []aclBinding
+	for _, iface := range acl.Interfaces.Interface {
+		for _, ref := range iface.IngressACLSets.IngressACLSet {
+			bindings = append(bindings, aclBinding{iface.ID, "ingress", ref.SetName, normalizeAfiSafiName(ref.Type)})
+		}
+		for _, ref := range iface.EgressACLSets.EgressACLSet {
+			bindings = append(bindings, aclBinding{iface.ID, "egress", ref.SetName, normalizeAfiSafiName(ref.Type)})
+		}
+	}
+
+	return sets, bindings, nil
+}
+
+func (g *ACLGenerator) parseControlPlane(jsonData string) ([]aclBinding, error) {
+	var resp struct {
+		ACLSet []struct {
+			SetName string `json:"set-name"`
+			Type    string `json:"type"`
+		} `json:"acl-set"`
+	}
+	if err := unmarshalStripped(jsonData, &resp); err != nil {
+		return nil, fmt.Errorf("parse control-plane ACLs: %w", err)
+	}
+
+	var bindings []aclBinding
+	for _, s := range resp.ACLSet {
+		bindings = append(bindings, aclBinding{Direction: "ingress", SetName: s.SetName, Type: normalizeAfiSafiName(s.Type)})
+	}
+	return bindings, nil
+}
@@ -0,0 +1,47 @@
+package generate
+
+import "testing"
+
+func TestACLGenerator(t *testing.T) {
+	g := &ACLGenerator{}
+	sets, bindings, err := g.parseACL(`{"openconfig-acl:acl": {
+  "acl-sets": {"acl-set": [{"name": "PROTECT", "type": "openconfig-acl:ACL_IPV4",
+    "acl-entries": {"acl-entry": [{"sequence-id": 20}, {"sequence-id": 10}]}}]},
+  "interfaces": {"interface": [{"id": "Ethernet1",
+    "ingress-acl-sets": {"ingress-acl-set": [{"set-name": "PROTECT", "type": "openconfig-acl:ACL_IPV4"}]}}]}
+}}`)
+	if err != nil {
+		t.Fatalf("parseACL() error = %v", err)
+	}
+	cp, err := g.parseControlPlane(`{"openconfig-system:acl-set": [{"set-name": "CPP", "type": "ACL_IPV4"}]}`)
+	if err != nil {
+		t.Fatalf("parseControlPlane() error = %v", err)
+	}
+	bindings = append(bindings, cp...)
+
+	want := []string{
+		"acl/acl-sets/acl-set[name=PROTECT][type=ACL_IPV4]/state/name",
+		"acl/acl-sets/acl-set[name=PROTECT][type=ACL_IPV4]/acl-entries",
+		"acl/acl-sets/acl-set[name=PROTECT][type=ACL_IPV4]/acl-entries/acl-entry[sequence-id=10]/state/sequence-id",
+		"acl/acl-sets/acl-set[name=PROTECT][type=ACL_IPV4]/acl-entries/acl-entry[sequence-id=20]/state/sequence-id",
+		"acl/interfaces/interface[id=Ethernet1]/ingress-acl-sets/ingress-acl-set[set-name=PROTECT][type=ACL_IPV4]/state/set-name",
+		"system/control-plane-traffic/ingress/acl/acl-set[set-name=CPP][type=ACL_IPV4]/state/set-name",
+	}
+	got := g.assertions(sets, bindings, DefaultACLEntryLimit)
+	if len(got) != len(want) {
+		t.Fatalf("assertions() = %d, want %d", len(got), len(want))
+	}
+	for i, a := range got {
+		if a.Path != want[i] {
+			t.Errorf("assertion %d path = %s, want %s", i, a.Path, want[i])
+		}
+	}
+
+	if count := got[1]; count.Count == nil || *count.Count != 2 || count.ListPath != "acl-entry" {
+		t.Errorf("entry count assertion = %+v, want count 2 of acl-entry", count)
+	}
+
+	if got := g.assertions(sets, nil, 1); len(got) != 3 {
+		t.Errorf("assertions(limit 1) = %d, want 3", len(got))
+	}
+}
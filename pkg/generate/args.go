@@ -0,0 +1,139 @@
+package generate
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Configurable is implemented by generators that accept per-generator
+// arguments, given on the command line as --gen-arg <generator>.<key>=<value>
+type Configurable interface {
+	// Args returns the accepted argument names and their descriptions
+	Args() map[string]string
+}
+
+// ParseArgs parses "<generator>.<key>=<value>" specs into per-generator
+// arguments, rejecting unknown generators and keys
+func ParseArgs(specs []string) (map[string]map[string]string, error) {
+	args := make(map[string]map[string]string)
+	for _, spec := range specs {
+		name, val, ok := strings.Cut(spec, "=")
+		gen, key, dotted := strings.Cut(name, ".")
+		if !ok || !dotted || gen == "" || key == "" {
+			return nil, fmt.Errorf("invalid generator argument %q: expected <generator>.<key>=<value>", spec)
+		}
+
+		g, found := Get(gen)
+		if !found {
+			return nil, fmt.Errorf("invalid generator argument %q: unknown generator %s", spec, gen)
+		}
+		c, ok := g.(Configurable)
+		if !ok || len(c.Args()) == 0 {
+			return nil, fmt.Errorf("invalid generator argument %q: %s takes no arguments", spec, gen)
+		}
+		if _, ok := c.Args()[key]; !ok {
+			return nil, fmt.Errorf("invalid generator argument %q: %s accepts %s", spec, gen, strings.Join(ArgNames(c), ", "))
+		}
+
+		if args[gen] == nil {
+			args[gen] = make(map[string]string)
+		}
+		args[gen][key] = val
+	}
+	return args, nil
+}
+
+// ArgNames returns a generator's argument names, sorted
+func ArgNames(c Configurable) []string {
+	names := make([]string, 0, len(c.Args()))
+	for name := range c.Args() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Arg returns a generator argument, or "" if unset
+func (o Options) Arg(generator, key string) string {
+	return o.Args[generator][key]
+}
+
+// patternArg compiles a regex argument, returning nil if unset
+func patternArg(opts Options, generator, key string) (*regexp.Regexp, error) {
+	arg := opts.Arg(generator, key)
+	if arg == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(arg)
+	if err != nil {
+		return nil, fmt.Errorf("%s.%s: %w", generator, key, err)
+	}
+	return re, nil
+}
+
+// interfaceFilterArgs are the arguments of generators that filter interfaces
+var interfaceFilterArgs = map[string]string{
+	"include": "Regex of interface names to check, replacing the built-in skip list (overrides --include-interfaces)",
+	"exclude": "Regex of interface names to skip, replacing the built-in skip list (overrides --exclude-interfaces)",
+}
+
+// interfaceFilter returns a generator's interface skip function. The
+// generator's include/exclude arguments win over Options.IncludeInterfaces
+// and Options.ExcludeInterfaces. With an include pattern only matching
+// interfaces are kept; the built-in def list applies only when neither
+// pattern is set, so e.g. include '^(Ethernet|Loopback)' monitors loopbacks.
+func interfaceFilter(opts Options, generator string, def *regexp.Regexp) (func(string) bool, error) {
+	pattern := func(key, global string) (*regexp.Regexp, error) {
+		if opts.Arg(generator, key) != "" {
+			return patternArg(opts, generator, key)
+		}
+		if global == "" {
+			return nil, nil
+		}
+		re, err := regexp.Compile(global)
+		if err != nil {
+			return nil, fmt.Errorf("%s interfaces: %w", key, err)
+		}
+		return re, nil
+	}
+	include, err := pattern("include", opts.IncludeInterfaces)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := pattern("exclude", opts.ExcludeInterfaces)
+	if err != nil {
+		return nil, err
+	}
+
+	if include == nil && exclude == nil {
+		exclude = def
+	}
+	return func(name string) bool {
+		if include != nil && !include.MatchString(name) {
+			return true
+		}
+		return exclude != nil && exclude.MatchString(name)
+	}, nil
+}
+
+// limitArg resolves a generator's entry cap: its limit argument, then
+// Options.Limit, then the generator's default
+func limitArg(opts Options, generator string, def int) (int, error) {
+	if arg := opts.Arg(generator, "limit"); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("%s.limit: must be a positive integer, got %q", generator, arg)
+		}
+		return n, nil
+	}
+	if opts.Limit > 0 {
+		return opts.Limit, nil
+	}
+	return def, nil
+}
+
+// limitArgDescription documents the limit argument shared by table generators
+const limitArgDescription = "Maximum entries to assert on (overrides --limit)"
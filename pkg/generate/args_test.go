@@ -0,0 +1,145 @@
+package generate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []string
+		want    map[string]map[string]string
+		wantErr string
+	}{
+		{
+			name:  "valid",
+			specs: []string{"interfaces.exclude=^Port-Channel", "bgp.vrfs=all", "routes.limit=50"},
+			want: map[string]map[string]string{
+				"interfaces": {"exclude": "^Port-Channel"},
+				"bgp":        {"vrfs": "all"},
+				"routes":     {"limit": "50"},
+			},
+		},
+		{name: "value with equals", specs: []string{"interfaces.exclude=a=b"}, want: map[string]map[string]string{"interfaces": {"exclude": "a=b"}}},
+		{name: "missing key", specs: []string{"bgp=all"}, wantErr: "expected <generator>.<key>=<value>"},
+		{name: "missing value", specs: []string{"bgp.vrfs"}, wantErr: "expected <generator>.<key>=<value>"},
+		{name: "unknown generator", specs: []string{"nope.x=1"}, wantErr: "unknown generator nope"},
+		{name: "no arguments", specs: []string{"system.x=1"}, wantErr: "system takes no arguments"},
+		{name: "unknown key", specs: []string{"bgp.vrf=all"}, wantErr: "bgp accepts vrfs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseArgs(tt.specs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseArgs() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseArgs() error = %v", err)
+			}
+			for gen, keys := range tt.want {
+				for k, v := range keys {
+					if got[gen][k] != v {
+						t.Errorf("%s.%s = %q, want %q", gen, k, got[gen][k], v)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLimitArg(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		want    int
+		wantErr bool
+	}{
+		{"default", Options{}, 100, false},
+		{"global", Options{Limit: 20}, 20, false},
+		{"argument wins", Options{Limit: 20, Args: map[string]map[string]string{"routes": {"limit": "5"}}}, 5, false},
+		{"other generator", Options{Args: map[string]map[string]string{"mac": {"limit": "5"}}}, 100, false},
+		{"invalid", Options{Args: map[string]map[string]string{"routes": {"limit": "-1"}}}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := limitArg(tt.opts, "routes", 100)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("limitArg() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("limitArg() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultInterfaceExclude(t *testing.T) {
+	tests := []struct {
+		name string
+		skip bool
+	}{
+		{"Management1", true},
+		{"ma1", true},
+		{"mgmt0", true},
+		{"system0", true},
+		{"lo0", true},
+		{"Ethernet1", false},
+		{"ethernet-1/1", false},
+		{"macsec0", false},
+		{"main-uplink", false},
+		{"local0", false},
+	}
+	for _, tt := range tests {
+		if got := defaultInterfaceExclude.MatchString(tt.name); got != tt.skip {
+			t.Errorf("DefaultInterfaceExclude matches %s = %v, want %v", tt.name, got, tt.skip)
+		}
+	}
+}
+
+func TestInterfaceFilter(t *testing.T) {
+	args := func(gen, key, val string) map[string]map[string]string {
+		return map[string]map[string]string{gen: {key: val}}
+	}
+	names := []string{"Ethernet1", "Loopback0", "Management1", "Port-Channel1"}
+
+	tests := []struct {
+		name string
+		opts Options
+		kept []string
+	}{
+		{"built-in", Options{}, []string{"Ethernet1", "Port-Channel1"}},
+		{"include replaces built-in", Options{IncludeInterfaces: "^(Ethernet|Loopback|Management)"}, []string{"Ethernet1", "Loopback0", "Management1"}},
+		{"exclude replaces built-in", Options{ExcludeInterfaces: "^Port-Channel"}, []string{"Ethernet1", "Loopback0", "Management1"}},
+		{"include and exclude", Options{IncludeInterfaces: ".", ExcludeInterfaces: "^Management"}, []string{"Ethernet1", "Loopback0", "Port-Channel1"}},
+		{"argument wins", Options{ExcludeInterfaces: "^Port-Channel", Args: args("interfaces", "exclude", "^Loopback")}, []string{"Ethernet1", "Management1", "Port-Channel1"}},
+		{"other generator's argument", Options{Args: args("lldp", "include", ".")}, []string{"Ethernet1", "Port-Channel1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skip, err := interfaceFilter(tt.opts, "interfaces", defaultInterfaceExclude)
+			if err != nil {
+				t.Fatalf("interfaceFilter() error = %v", err)
+			}
+			var kept []string
+			for _, n := range names {
+				if !skip(n) {
+					kept = append(kept, n)
+				}
+			}
+			if strings.Join(kept, ",") != strings.Join(tt.kept, ",") {
+				t.Errorf("kept %v, want %v", kept, tt.kept)
+			}
+		})
+	}
+
+	if _, err := interfaceFilter(Options{IncludeInterfaces: "("}, "interfaces", defaultInterfaceExclude); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
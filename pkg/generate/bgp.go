@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/ndtobs/netsert/pkg/assertion"
@@ -25,6 +27,12 @@ func (g *BGPGenerator) Description() string {
 	return "Generate assertions for BGP neighbor states and AFI-SAFI"
 }
 
+func (g *BGPGenerator) Args() map[string]string {
+	return map[string]string{
+		"vrfs": "Comma-separated VRFs to check, or all (overrides --vrf)",
+	}
+}
+
 // bgpNeighborState represents the relevant BGP neighbor state
 type bgpNeighborState struct {
 	NeighborAddress string
@@ -42,17 +50,43 @@ type afiSafiState struct {
 }
 
 func (g *BGPGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
-	// Get neighbors with AFI-SAFI info
-	neighbors, err := g.getOpenConfigNeighbors(ctx, client, opts)
+	vrfs, err := g.getInstances(ctx, client, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	getNeighbors, vrfAssertions := g.getOpenConfigNeighbors, g.assertions
+	if srlNative(opts) {
+		getNeighbors, vrfAssertions = g.getSRLNeighbors, g.srlAssertions
+	}
+	if opts.Mode == ModeExists {
+		vrfAssertions = g.existsAssertions
+		if srlNative(opts) {
+			vrfAssertions = g.srlExistsAssertions
+		}
+	}
+
+	var assertions []assertion.Assertion
+	for _, vrf := range vrfs {
+		// Get neighbors with AFI-SAFI info
+		neighbors, err := getNeighbors(ctx, client, vrf, opts)
+		if err != nil {
+			return nil, err
+		}
+		assertions = append(assertions, vrfAssertions(vrf, neighbors)...)
+	}
+
+	return assertions, nil
+}
+
+func (g *BGPGenerator) assertions(vrf string, neighbors []bgpNeighborState) []assertion.Assertion {
+	label := bgpPeerLabel(vrf)
+
 	var assertions []assertion.Assertion
 	for _, n := range neighbors {
 		// Session state assertion
-		name := fmt.Sprintf("BGP peer %s is %s", n.NeighborAddress, n.SessionState)
-		path := fmt.Sprintf("bgp[default]/neighbors/neighbor[neighbor-address=%s]/state/session-state", n.NeighborAddress)
+		name := fmt.Sprintf("%s %s is %s", label, n.NeighborAddress, n.SessionState)
+		path := fmt.Sprintf("bgp[%s]/neighbors/neighbor[neighbor-address=%s]/state/session-state", vrf, n.NeighborAddress)
 
 		assertions = append(assertions, assertion.Assertion{
 			Name:   name,
@@ -63,8 +97,8 @@ func (g *BGPGenerator) Generate(ctx context.Context, client *gnmiclient.Client,
 		// AFI-SAFI assertions for active address families
 		for _, afi := range n.AfiSafis {
 			if afi.Active {
-				afiName := fmt.Sprintf("BGP peer %s AFI %s is active", n.NeighborAddress, afi.Name)
-				afiPath := fmt.Sprintf("bgp[default]/neighbors/neighbor[neighbor-address=%s]/afi-safis/afi-safi[afi-safi-name=%s]/state/active", n.NeighborAddress, afi.Name)
+				afiName := fmt.Sprintf("%s %s AFI %s is active", label, n.NeighborAddress, afi.Name)
+				afiPath := fmt.Sprintf("bgp[%s]/neighbors/neighbor[neighbor-address=%s]/afi-safis/afi-safi[afi-safi-name=%s]/state/active", vrf, n.NeighborAddress, afi.Name)
 
 				assertions = append(assertions, assertion.Assertion{
 					Name:   afiName,
@@ -75,20 +109,143 @@ func (g *BGPGenerator) Generate(ctx context.Context, client *gnmiclient.Client,
 		}
 	}
 
-	return assertions, nil
+	return assertions
+}
+
+// existsAssertions checks each peer is configured with its peer AS and
+// address families, leaving session state to recover on its own
+func (g *BGPGenerator) existsAssertions(vrf string, neighbors []bgpNeighborState) []assertion.Assertion {
+	label := bgpPeerLabel(vrf)
+
+	var assertions []assertion.Assertion
+	for _, n := range neighbors {
+		neighbor := fmt.Sprintf("bgp[%s]/neighbors/neighbor[neighbor-address=%s]", vrf, n.NeighborAddress)
+		assertions = append(assertions, peerConfigured(label, n, neighbor+"/state/peer-as", neighbor+"/state/neighbor-address"))
+
+		for _, afi := range n.AfiSafis {
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("%s %s AFI %s is configured", label, n.NeighborAddress, afi.Name),
+				Path:   fmt.Sprintf("%s/afi-safis/afi-safi[afi-safi-name=%s]/state/afi-safi-name", neighbor, afi.Name),
+				Exists: boolPtr(true),
+			})
+		}
+	}
+	return assertions
+}
+
+// peerConfigured asserts a peer's AS at asPath, or just that the peer
+// exists at keyPath when the AS isn't known
+func peerConfigured(label string, n bgpNeighborState, asPath, keyPath string) assertion.Assertion {
+	if n.PeerAS == 0 {
+		return assertion.Assertion{
+			Name:   fmt.Sprintf("%s %s is configured", label, n.NeighborAddress),
+			Path:   keyPath,
+			Exists: boolPtr(true),
+		}
+	}
+	return assertion.Assertion{
+		Name:   fmt.Sprintf("%s %s is configured with AS %d", label, n.NeighborAddress, n.PeerAS),
+		Path:   asPath,
+		Equals: strPtr(fmt.Sprintf("%d", n.PeerAS)),
+	}
 }
 
-func (g *BGPGenerator) getOpenConfigNeighbors(ctx context.Context, client *gnmiclient.Client, opts Options) ([]bgpNeighborState, error) {
+// SupportsMode reports that exists mode asserts peers are configured
+func (g *BGPGenerator) SupportsMode(mode string) bool {
+	return true
+}
+
+// bgpPeerLabel names peers in assertion names; peers outside the default
+// instance are named with their VRF
+func bgpPeerLabel(vrf string) string {
+	if vrf != "default" {
+		return fmt.Sprintf("VRF %s BGP peer", vrf)
+	}
+	return "BGP peer"
+}
+
+// getInstances lists the network instances running BGP, restricted to the
+// vrfs argument or opts.VRFs when set. Devices that can't enumerate
+// protocols fall back to the default instance.
+func (g *BGPGenerator) getInstances(ctx context.Context, client *gnmiclient.Client, opts Options) ([]string, error) {
+	only := opts.VRFs
+	if arg := opts.Arg(g.Name(), "vrfs"); arg != "" {
+		only = nil
+		if arg != "all" {
+			only = strings.Split(arg, ",")
+		}
+	}
+
+	path := "/network-instances/network-instance/protocols/protocol/state"
+	if srlNative(opts) {
+		path = assertion.OriginSRLNative + ":/network-instance/protocols/bgp/admin-state"
+	}
+	updates, err := client.GetAll(ctx, path, opts.Username, opts.Password)
+	if err != nil {
+		updates = nil
+	}
+	return g.bgpInstances(updates, only)
+}
+
+func (g *BGPGenerator) bgpInstances(updates []gnmiclient.Update, only []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var vrfs []string
+	for _, u := range updates {
+		keys, _, err := updateKeys(u.Path)
+		if err != nil {
+			return nil, err
+		}
+		// OpenConfig names the protocol in a key; SR Linux has a bgp container
+		ni := keys["network-instance"]["name"]
+		isBGP := normalizeAfiSafiName(keys["protocol"]["identifier"]) == "BGP"
+		if _, oc := keys["protocol"]; !oc {
+			_, isBGP = keys["bgp"]
+		}
+		if ni == "" || seen[ni] || !isBGP {
+			continue
+		}
+		seen[ni] = true
+		vrfs = append(vrfs, ni)
+	}
+	if len(vrfs) == 0 {
+		// Nothing enumerated: query the requested VRFs directly
+		if len(only) > 0 {
+			return only, nil
+		}
+		return []string{"default"}, nil
+	}
+
+	// Default first, then VRFs by name
+	sort.Slice(vrfs, func(i, j int) bool {
+		if (vrfs[i] == "default") != (vrfs[j] == "default") {
+			return vrfs[i] == "default"
+		}
+		return vrfs[i] < vrfs[j]
+	})
+
+	if len(only) == 0 {
+		return vrfs, nil
+	}
+	var filtered []string
+	for _, vrf := range vrfs {
+		if slices.Contains(only, vrf) {
+			filtered = append(filtered, vrf)
+		}
+	}
+	return filtered, nil
+}
+
+func (g *BGPGenerator) getOpenConfigNeighbors(ctx context.Context, client *gnmiclient.Client, vrf string, opts Options) ([]bgpNeighborState, error) {
 	// Query BGP neighbors path
-	path := "/network-instances/network-instance[name=default]/protocols/protocol[identifier=BGP][name=BGP]/bgp/neighbors"
+	path := assertion.ExpandPathFor(fmt.Sprintf("bgp[%s]/neighbors", vrf), opts.Platform)
 
 	value, exists, err := client.Get(ctx, path, opts.Username, opts.Password)
 	if err != nil {
 		// BGP might not be configured
-		if strings.Contains(err.Error(), "NotFound") || strings.Contains(err.Error(), "not found") {
+		if isNotFound(err) {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("query BGP neighbors: %w", err)
+		return nil, fmt.Errorf("query BGP neighbors in %s: %w", vrf, err)
 	}
 
 	if !exists || value == "" {
@@ -227,3 +384,7 @@ func normalizeAfiSafiName(name string) string {
 func strPtr(s string) *string {
 	return &s
 }
+
+func boolPtr(b bool) *bool {
+	return &b
+}
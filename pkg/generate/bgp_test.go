@@ -0,0 +1,99 @@
+package generate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+	"github.com/ndtobs/netsert/pkg/value"
+)
+
+func TestBGPInstances(t *testing.T) {
+	proto := func(ni, id string) gnmiclient.Update {
+		return gnmiclient.Update{
+			Path:  "/network-instances/network-instance[name=" + ni + "]/protocols/protocol[identifier=" + id + "][name=" + id + "]/state",
+			Value: value.JSON([]byte(`{}`)),
+		}
+	}
+	updates := []gnmiclient.Update{
+		proto("TENANT-B", "BGP"),
+		proto("default", "openconfig-policy-types:BGP"),
+		proto("TENANT-A", "BGP"),
+		proto("MGMT", "STATIC"),
+	}
+
+	tests := []struct {
+		name    string
+		updates []gnmiclient.Update
+		only    []string
+		want    []string
+	}{
+		{"all", updates, nil, []string{"default", "TENANT-A", "TENANT-B"}},
+		{"filter", updates, []string{"TENANT-B", "MGMT"}, []string{"TENANT-B"}},
+		{"no enumeration", nil, nil, []string{"default"}},
+		{"no enumeration with filter", nil, []string{"TENANT-A"}, []string{"TENANT-A"}},
+	}
+
+	g := &BGPGenerator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.bgpInstances(tt.updates, tt.only)
+			if err != nil {
+				t.Fatalf("bgpInstances() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bgpInstances() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBGPAssertionsVRF(t *testing.T) {
+	neighbors := []bgpNeighborState{{
+		NeighborAddress: "10.1.0.1",
+		SessionState:    "ESTABLISHED",
+		AfiSafis:        []afiSafiState{{Name: "IPV4_UNICAST", Active: true}},
+	}}
+
+	got := (&BGPGenerator{}).assertions("TENANT-A", neighbors)
+	want := []struct{ name, path string }{
+		{"VRF TENANT-A BGP peer 10.1.0.1 is ESTABLISHED", "bgp[TENANT-A]/neighbors/neighbor[neighbor-address=10.1.0.1]/state/session-state"},
+		{"VRF TENANT-A BGP peer 10.1.0.1 AFI IPV4_UNICAST is active", "bgp[TENANT-A]/neighbors/neighbor[neighbor-address=10.1.0.1]/afi-safis/afi-safi[afi-safi-name=IPV4_UNICAST]/state/active"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("assertions() = %d, want %d", len(got), len(want))
+	}
+	for i, a := range got {
+		if a.Name != want[i].name || a.Path != want[i].path {
+			t.Errorf("assertion %d = %q %s, want %q %s", i, a.Name, a.Path, want[i].name, want[i].path)
+		}
+	}
+}
+
+func TestBGPExistsAssertions(t *testing.T) {
+	neighbors := []bgpNeighborState{
+		{NeighborAddress: "10.1.0.1", SessionState: "IDLE", PeerAS: 65001, AfiSafis: []afiSafiState{{Name: "IPV4_UNICAST"}}},
+		{NeighborAddress: "10.1.0.3", SessionState: "ACTIVE"},
+	}
+
+	got := (&BGPGenerator{}).existsAssertions("default", neighbors)
+	want := []struct{ name, path, equals string }{
+		{"BGP peer 10.1.0.1 is configured with AS 65001", "bgp[default]/neighbors/neighbor[neighbor-address=10.1.0.1]/state/peer-as", "65001"},
+		{"BGP peer 10.1.0.1 AFI IPV4_UNICAST is configured", "bgp[default]/neighbors/neighbor[neighbor-address=10.1.0.1]/afi-safis/afi-safi[afi-safi-name=IPV4_UNICAST]/state/afi-safi-name", ""},
+		{"BGP peer 10.1.0.3 is configured", "bgp[default]/neighbors/neighbor[neighbor-address=10.1.0.3]/state/neighbor-address", ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("existsAssertions() = %d, want %d", len(got), len(want))
+	}
+	for i, a := range got {
+		if a.Name != want[i].name || a.Path != want[i].path {
+			t.Errorf("assertion %d = %q %s, want %q %s", i, a.Name, a.Path, want[i].name, want[i].path)
+		}
+		if want[i].equals != "" && (a.Equals == nil || *a.Equals != want[i].equals) {
+			t.Errorf("assertion %d equals = %v, want %s", i, a.Equals, want[i].equals)
+		}
+		if want[i].equals == "" && (a.Exists == nil || !*a.Exists) {
+			t.Errorf("assertion %d should be an exists check", i)
+		}
+	}
+}
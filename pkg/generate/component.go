@@ -0,0 +1,101 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+)
+
+// ocComponent is the subset of an OpenConfig /components/component entry
+// used by the hardware generators
+type ocComponent struct {
+	Name  string `json:"name"`
+	State struct {
+		Type        string `json:"type"`
+		OperStatus  string `json:"oper-status"`
+		SerialNo    string `json:"serial-no"`
+		PartNo      string `json:"part-no"`
+		Temperature struct {
+			Instant        *ocDecimal `json:"instant"`
+			AlarmStatus    *bool      `json:"alarm-status"`
+			AlarmThreshold *ocDecimal `json:"alarm-threshold"`
+		} `json:"temperature"`
+	} `json:"state"`
+	Transceiver struct {
+		State struct {
+			Present       string `json:"present"`
+			FormFactor    string `json:"form-factor"`
+			VendorPart    string `json:"vendor-part"`
+			SupplyVoltage struct {
+				Instant *ocDecimal `json:"instant"`
+			} `json:"supply-voltage"`
+		} `json:"state"`
+		PhysicalChannels struct {
+			Channel []struct {
+				Index int `json:"index"`
+				State struct {
+					InputPower struct {
+						Instant *ocDecimal `json:"instant"`
+					} `json:"input-power"`
+					OutputPower struct {
+						Instant *ocDecimal `json:"instant"`
+					} `json:"output-power"`
+				} `json:"state"`
+			} `json:"channel"`
+		} `json:"physical-channels"`
+	} `json:"transceiver"`
+}
+
+// ocDecimal decodes decimal64 leaves, which RFC 7951 encodes as strings
+type ocDecimal float64
+
+func (d *ocDecimal) UnmarshalJSON(data []byte) error {
+	f, err := strconv.ParseFloat(strings.Trim(string(data), `"`), 64)
+	if err != nil {
+		return fmt.Errorf("decimal %s: %w", data, err)
+	}
+	*d = ocDecimal(f)
+	return nil
+}
+
+// getComponents queries /components, returning nil if the device doesn't model them
+func getComponents(ctx context.Context, client *gnmiclient.Client, opts Options) ([]ocComponent, error) {
+	value, exists, err := client.Get(ctx, "/components", opts.Username, opts.Password)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query components: %w", err)
+	}
+	if !exists || value == "" {
+		return nil, nil
+	}
+	return parseComponents(value)
+}
+
+func parseComponents(jsonData string) ([]ocComponent, error) {
+	// The response may be the components container itself or wrapped in it
+	var resp struct {
+		Component  []ocComponent `json:"component"`
+		Components *struct {
+			Component []ocComponent `json:"component"`
+		} `json:"components"`
+	}
+	if err := unmarshalStripped(jsonData, &resp); err != nil {
+		return nil, fmt.Errorf("parse components: %w", err)
+	}
+	if resp.Components != nil {
+		return resp.Components.Component, nil
+	}
+	return resp.Component, nil
+}
+
+// formatDecimal renders a threshold with as many decimal places as it
+// needs, rounding away float arithmetic noise past the sixth
+func formatDecimal(f float64) string {
+	return strconv.FormatFloat(math.Round(f*1e6)/1e6, 'f', -1, 64)
+}
@@ -0,0 +1,68 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+)
+
+func init() {
+	Register(&ComponentsGenerator{})
+}
+
+// ComponentsGenerator creates assertions for field-replaceable modules
+type ComponentsGenerator struct{}
+
+func (g *ComponentsGenerator) Name() string {
+	return "components"
+}
+
+func (g *ComponentsGenerator) Description() string {
+	return "Generate assertions for linecard, supervisor, and fabric module state and serials"
+}
+
+// moduleTypes are the component types checked, by OpenConfig identity
+var moduleTypes = map[string]string{
+	"LINECARD":        "Linecard",
+	"CONTROLLER_CARD": "Supervisor",
+	"FABRIC":          "Fabric module",
+}
+
+func (g *ComponentsGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
+	components, err := getComponents(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+	return g.assertions(components), nil
+}
+
+func (g *ComponentsGenerator) assertions(components []ocComponent) []assertion.Assertion {
+	var assertions []assertion.Assertion
+
+	for _, c := range components {
+		kind, ok := moduleTypes[normalizeAfiSafiName(c.State.Type)]
+		if !ok {
+			continue
+		}
+		base := fmt.Sprintf("components/component[name=%s]/state", c.Name)
+
+		if c.State.SerialNo != "" {
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("%s %s is serial %s", kind, c.Name, c.State.SerialNo),
+				Path:   base + "/serial-no",
+				Equals: strPtr(c.State.SerialNo),
+			})
+		}
+		if c.State.OperStatus != "" {
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("%s %s is %s", kind, c.Name, normalizeAfiSafiName(c.State.OperStatus)),
+				Path:   base + "/oper-status",
+				Equals: strPtr(c.State.OperStatus),
+			})
+		}
+	}
+
+	return assertions
+}
@@ -0,0 +1,32 @@
+package generate
+
+import "testing"
+
+func TestComponentsGenerator(t *testing.T) {
+	components, err := parseComponents(`{"component": [
+  {"name": "Supervisor1", "state": {"type": "openconfig-platform-types:CONTROLLER_CARD", "oper-status": "openconfig-platform-types:ACTIVE", "serial-no": "SSJ1234"}},
+  {"name": "Linecard3", "state": {"type": "openconfig-platform-types:LINECARD", "oper-status": "openconfig-platform-types:ACTIVE", "serial-no": "JPE5678"}},
+  {"name": "Fabric1", "state": {"type": "FABRIC", "oper-status": "ACTIVE"}},
+  {"name": "PowerSupply1", "state": {"type": "openconfig-platform-types:POWER_SUPPLY", "serial-no": "PSU1"}}
+]}`)
+	if err != nil {
+		t.Fatalf("parseComponents() error = %v", err)
+	}
+
+	got := (&ComponentsGenerator{}).assertions(components)
+	want := []string{
+		"Supervisor Supervisor1 is serial SSJ1234",
+		"Supervisor Supervisor1 is ACTIVE",
+		"Linecard Linecard3 is serial JPE5678",
+		"Linecard Linecard3 is ACTIVE",
+		"Fabric module Fabric1 is ACTIVE",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("assertions() = %d, want %d", len(got), len(want))
+	}
+	for i, a := range got {
+		if a.Name != want[i] {
+			t.Errorf("assertion %d = %q, want %q", i, a.Name, want[i])
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+)
+
+func init() {
+	Register(&CountersGenerator{})
+}
+
+// Counter modes for the counters generator
+const (
+	CountersDelta = "delta" // No increase over the value at generation time
+	CountersZero  = "zero"  // Counter must be zero
+)
+
+// errorCounters are the interface counters that indicate link problems, in
+// output order, with the label used in assertion names
+var errorCounters = []struct {
+	leaf  string
+	label string
+}{
+	{"in-errors", "input errors"},
+	{"in-fcs-errors", "CRC errors"},
+	{"in-discards", "input discards"},
+	{"out-errors", "output errors"},
+	{"out-discards", "output discards"},
+}
+
+// CountersGenerator creates assertions that error counters on active
+// interfaces stay clean
+type CountersGenerator struct{}
+
+func (g *CountersGenerator) Name() string {
+	return "counters"
+}
+
+func (g *CountersGenerator) Description() string {
+	return "Generate assertions for interface error and discard counters"
+}
+
+func (g *CountersGenerator) Args() map[string]string {
+	args := map[string]string{
+		"mode": "delta (default): no increase over current values; zero: counters must be 0",
+	}
+	for k, v := range interfaceFilterArgs {
+		args[k] = v
+	}
+	return args
+}
+
+// interfaceCounters is an interface's status and error counters by leaf name
+type interfaceCounters struct {
+	Name        string
+	AdminStatus string
+	OperStatus  string
+	Counters    map[string]uint64
+}
+
+func (g *CountersGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
+	mode := opts.Arg(g.Name(), "mode")
+	if mode == "" {
+		mode = CountersDelta
+	}
+	if mode != CountersDelta && mode != CountersZero {
+		return nil, fmt.Errorf("counters.mode: must be %s or %s, got %q", CountersDelta, CountersZero, mode)
+	}
+	skip, err := interfaceFilter(opts, g.Name(), defaultInterfaceExclude)
+	if err != nil {
+		return nil, err
+	}
+
+	value, exists, err := client.Get(ctx, "/interfaces", opts.Username, opts.Password)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query interfaces: %w", err)
+	}
+	if !exists || value == "" {
+		return nil, nil
+	}
+	interfaces, err := g.parseCounters(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.assertions(interfaces, mode, skip), nil
+}
+
+// assertions checks each error counter on interfaces that are up. In delta
+// mode the threshold is the current value, so the check fails only if
+// errors are added after the baseline was taken.
+func (g *CountersGenerator) assertions(interfaces []interfaceCounters, mode string, skip func(string) bool) []assertion.Assertion {
+	var assertions []assertion.Assertion
+
+	for _, iface := range interfaces {
+		if normalizeAfiSafiName(iface.AdminStatus) != "UP" || normalizeAfiSafiName(iface.OperStatus) != "UP" || skip(iface.Name) {
+			continue
+		}
+		for _, c := range errorCounters {
+			current, ok := iface.Counters[c.leaf]
+			if !ok {
+				continue
+			}
+			limit := uint64(0)
+			name := fmt.Sprintf("%s has no %s", iface.Name, c.label)
+			if mode == CountersDelta && current > 0 {
+				limit = current
+				name = fmt.Sprintf("%s %s not increasing (%d)", iface.Name, c.label, current)
+			}
+			assertions = append(assertions, assertion.Assertion{
+				Name: name,
+				Path: fmt.Sprintf("interface[%s]/state/counters/%s", iface.Name, c.leaf),
+				LTE:  strPtr(strconv.FormatUint(limit, 10)),
+			})
+		}
+	}
+
+	return assertions
+}
+
+// ocCounter decodes counter64 leaves, which RFC 7951 encodes as strings
+type ocCounter uint64
+
+func (c *ocCounter) UnmarshalJSON(data []byte) error {
+	n, err := strconv.ParseUint(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return fmt.Errorf("counter %s: %w", data, err)
+	}
+	*c = ocCounter(n)
+	return nil
+}
+
+func (g *CountersGenerator) parseCounters(jsonData string) ([]interfaceCounters, error) {
+	type ifaceList struct {
+		Interface []struct {
+			Name  string `json:"name"`
+			State struct {
+				AdminStatus string `json:"admin-status"`
+				OperStatus  string `json:"oper-status"`
+				Counters    struct {
+					InErrors    *ocCounter `json:"in-errors"`
+					InFCSErrors *ocCounter `json:"in-fcs-errors"`
+					InDiscards  *ocCounter `json:"in-discards"`
+					OutErrors   *ocCounter `json:"out-errors"`
+					OutDiscards *ocCounter `json:"out-discards"`
+				} `json:"counters"`
+			} `json:"state"`
+		} `json:"interface"`
+	}
+
+	// The response may be the interfaces container itself or wrapped in it
+	var resp struct {
+		ifaceList
+		Interfaces *ifaceList `json:"interfaces"`
+	}
+	if err := unmarshalStripped(jsonData, &resp); err != nil {
+		return nil, fmt.Errorf("parse interfaces: %w", err)
+	}
+	list := resp.ifaceList
+	if resp.Interfaces != nil {
+		list = *resp.Interfaces
+	}
+
+	var interfaces []interfaceCounters
+	for _, i := range list.Interface {
+		iface := interfaceCounters{
+			Name:        i.Name,
+			AdminStatus: i.State.AdminStatus,
+			OperStatus:  i.State.OperStatus,
+			Counters:    make(map[string]uint64),
+		}
+		c := i.State.Counters
+		for leaf, v := range map[string]*ocCounter{
+			"in-errors":     c.InErrors,
+			"in-fcs-errors": c.InFCSErrors,
+			"in-discards":   c.InDiscards,
+			"out-errors":    c.OutErrors,
+			"out-discards":  c.OutDiscards,
+		} {
+			if v != nil {
+				iface.Counters[leaf] = uint64(*v)
+			}
+		}
+		interfaces = append(interfaces, iface)
+	}
+	sort.Slice(interfaces, func(i, j int) bool { return interfaces[i].Name < interfaces[j].Name })
+	return interfaces, nil
+}
@@ -0,0 +1,63 @@
+package generate
+
+import "testing"
+
+const countersJSON = `{"openconfig-interfaces:interface": [
+  {"name": "Ethernet2", "state": {"admin-status": "UP", "oper-status": "UP",
+    "counters": {"in-errors": "12", "in-fcs-errors": "12", "in-discards": "0", "out-errors": "0", "out-discards": "3", "last-clear": "1700000000000000000"}}},
+  {"name": "Ethernet1", "state": {"admin-status": "UP", "oper-status": "UP",
+    "counters": {"in-errors": "0", "in-fcs-errors": "0"}}},
+  {"name": "Ethernet3", "state": {"admin-status": "UP", "oper-status": "DOWN",
+    "counters": {"in-errors": "0"}}},
+  {"name": "Management1", "state": {"admin-status": "UP", "oper-status": "UP",
+    "counters": {"in-errors": "0"}}}
+]}`
+
+func TestCountersGenerator(t *testing.T) {
+	g := &CountersGenerator{}
+	interfaces, err := g.parseCounters(countersJSON)
+	if err != nil {
+		t.Fatalf("parseCounters() error = %v", err)
+	}
+	skip := (&InterfacesGenerator{}).isSkippedInterface
+
+	type want struct{ path, lte string }
+	tests := []struct {
+		name string
+		mode string
+		want []want
+	}{
+		{"delta", CountersDelta, []want{
+			{"interface[Ethernet1]/state/counters/in-errors", "0"},
+			{"interface[Ethernet1]/state/counters/in-fcs-errors", "0"},
+			{"interface[Ethernet2]/state/counters/in-errors", "12"},
+			{"interface[Ethernet2]/state/counters/in-fcs-errors", "12"},
+			{"interface[Ethernet2]/state/counters/in-discards", "0"},
+			{"interface[Ethernet2]/state/counters/out-errors", "0"},
+			{"interface[Ethernet2]/state/counters/out-discards", "3"},
+		}},
+		{"zero", CountersZero, []want{
+			{"interface[Ethernet1]/state/counters/in-errors", "0"},
+			{"interface[Ethernet1]/state/counters/in-fcs-errors", "0"},
+			{"interface[Ethernet2]/state/counters/in-errors", "0"},
+			{"interface[Ethernet2]/state/counters/in-fcs-errors", "0"},
+			{"interface[Ethernet2]/state/counters/in-discards", "0"},
+			{"interface[Ethernet2]/state/counters/out-errors", "0"},
+			{"interface[Ethernet2]/state/counters/out-discards", "0"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := g.assertions(interfaces, tt.mode, skip)
+			if len(got) != len(tt.want) {
+				t.Fatalf("assertions() = %d, want %d", len(got), len(tt.want))
+			}
+			for i, a := range got {
+				if a.Path != tt.want[i].path || a.LTE == nil || *a.LTE != tt.want[i].lte {
+					t.Errorf("assertion %d = %s lte %v, want %s lte %s", i, a.Path, a.LTE, tt.want[i].path, tt.want[i].lte)
+				}
+			}
+		})
+	}
+}
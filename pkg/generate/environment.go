@@ -0,0 +1,73 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+)
+
+func init() {
+	Register(&EnvironmentGenerator{})
+}
+
+// EnvironmentGenerator creates assertions for power supplies, fans, and
+// temperature sensors
+type EnvironmentGenerator struct{}
+
+func (g *EnvironmentGenerator) Name() string {
+	return "environment"
+}
+
+func (g *EnvironmentGenerator) Description() string {
+	return "Generate assertions for PSU and fan status and temperature alarms"
+}
+
+func (g *EnvironmentGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
+	components, err := getComponents(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+	return g.assertions(components), nil
+}
+
+func (g *EnvironmentGenerator) assertions(components []ocComponent) []assertion.Assertion {
+	var assertions []assertion.Assertion
+
+	for _, c := range components {
+		base := fmt.Sprintf("components/component[name=%s]/state", c.Name)
+
+		switch normalizeAfiSafiName(c.State.Type) {
+		case "TRANSCEIVER":
+			continue // Covered by the transceivers generator
+		case "POWER_SUPPLY", "FAN", "FAN_TRAY":
+			if c.State.OperStatus != "" {
+				assertions = append(assertions, assertion.Assertion{
+					Name:   fmt.Sprintf("%s is %s", c.Name, normalizeAfiSafiName(c.State.OperStatus)),
+					Path:   base + "/oper-status",
+					Equals: strPtr(c.State.OperStatus),
+				})
+			}
+		}
+
+		// Any component can report temperature; prefer the numeric threshold
+		temp := c.State.Temperature
+		switch {
+		case temp.Instant != nil && temp.AlarmThreshold != nil:
+			assertions = append(assertions, assertion.Assertion{
+				Name: fmt.Sprintf("%s temperature below alarm threshold %s", c.Name, formatDecimal(float64(*temp.AlarmThreshold))),
+				Path: base + "/temperature/instant",
+				LT:   strPtr(formatDecimal(float64(*temp.AlarmThreshold))),
+			})
+		case temp.AlarmStatus != nil:
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("%s temperature not in alarm", c.Name),
+				Path:   base + "/temperature/alarm-status",
+				Equals: strPtr("false"),
+			})
+		}
+	}
+
+	return assertions
+}
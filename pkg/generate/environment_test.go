@@ -0,0 +1,36 @@
+package generate
+
+import "testing"
+
+func TestEnvironmentGenerator(t *testing.T) {
+	components, err := parseComponents(`{"component": [
+  {"name": "PowerSupply1", "state": {"type": "openconfig-platform-types:POWER_SUPPLY", "oper-status": "openconfig-platform-types:ACTIVE"}},
+  {"name": "Fan1", "state": {"type": "openconfig-platform-types:FAN", "oper-status": "openconfig-platform-types:ACTIVE"}},
+  {"name": "TempSensor1", "state": {"type": "openconfig-platform-types:SENSOR",
+    "temperature": {"instant": "38.0", "alarm-status": false, "alarm-threshold": 85}}},
+  {"name": "TempSensor2", "state": {"type": "openconfig-platform-types:SENSOR", "temperature": {"alarm-status": false}}},
+  {"name": "Ethernet1", "state": {"type": "openconfig-platform-types:PORT"}}
+]}`)
+	if err != nil {
+		t.Fatalf("parseComponents() error = %v", err)
+	}
+
+	want := []string{
+		"components/component[name=PowerSupply1]/state/oper-status",
+		"components/component[name=Fan1]/state/oper-status",
+		"components/component[name=TempSensor1]/state/temperature/instant",
+		"components/component[name=TempSensor2]/state/temperature/alarm-status",
+	}
+	got := (&EnvironmentGenerator{}).assertions(components)
+	if len(got) != len(want) {
+		t.Fatalf("assertions() = %d, want %d", len(got), len(want))
+	}
+	for i, a := range got {
+		if a.Path != want[i] {
+			t.Errorf("assertion %d path = %s, want %s", i, a.Path, want[i])
+		}
+	}
+	if *got[0].Equals != "openconfig-platform-types:ACTIVE" || *got[2].LT != "85" {
+		t.Errorf("unexpected values: %s, %s", *got[0].Equals, *got[2].LT)
+	}
+}
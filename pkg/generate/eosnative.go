@@ -0,0 +1,95 @@
+package generate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+)
+
+func init() {
+	Register(&EOSNativeGenerator{})
+}
+
+// eosNativeLeaf is a Sysdb leaf and the label used in assertion names
+type eosNativeLeaf struct {
+	label string
+	path  string
+}
+
+// eosNativeLeaves hold state EOS doesn't expose via OpenConfig
+var eosNativeLeaves = []eosNativeLeaf{
+	{"Vxlan1 oper-status", "/Sysdb/interface/status/eth/vxlan/intfStatus/Vxlan1/operStatus"},
+}
+
+// EOSNativeGenerator creates assertions for Arista EOS native (Sysdb) state.
+// It only runs on platforms whose profile serves the eos_native origin.
+type EOSNativeGenerator struct{}
+
+func (g *EOSNativeGenerator) Name() string {
+	return "eos-native"
+}
+
+func (g *EOSNativeGenerator) Description() string {
+	return "Generate assertions for Arista EOS state only in native Sysdb paths (platform arista_eos)"
+}
+
+func (g *EOSNativeGenerator) Args() map[string]string {
+	return map[string]string{
+		"paths": "Comma-separated Sysdb leaf paths to check in addition to the built-in ones",
+	}
+}
+
+func (g *EOSNativeGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
+	if !opts.Platform.HasOrigin(assertion.OriginEOSNative) {
+		return nil, nil
+	}
+
+	leaves := slices.Clone(eosNativeLeaves)
+	if arg := opts.Arg(g.Name(), "paths"); arg != "" {
+		for _, p := range strings.Split(arg, ",") {
+			p = "/" + strings.Trim(strings.TrimSpace(p), "/")
+			leaves = append(leaves, eosNativeLeaf{label: p, path: p})
+		}
+	}
+
+	var assertions []assertion.Assertion
+	for _, leaf := range leaves {
+		path := assertion.OriginEOSNative + ":" + leaf.path
+		value, exists, err := client.Get(ctx, path, opts.Username, opts.Password)
+		if err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("query %s: %w", path, err)
+		}
+		if !exists || value == "" {
+			continue
+		}
+		assertions = append(assertions, g.assertion(leaf.label, path, value))
+	}
+
+	return assertions, nil
+}
+
+// assertion checks a leaf's current value. Sysdb encodes some leaves (e.g.,
+// enums) as objects, which are compared as JSON.
+func (g *EOSNativeGenerator) assertion(label, path, value string) assertion.Assertion {
+	var decoded any
+	if strings.HasPrefix(value, "{") && json.Unmarshal([]byte(value), &decoded) == nil {
+		return assertion.Assertion{
+			Name:       fmt.Sprintf("%s matches baseline", label),
+			Path:       path,
+			EqualsJSON: decoded,
+		}
+	}
+	return assertion.Assertion{
+		Name:   fmt.Sprintf("%s is %s", label, value),
+		Path:   path,
+		Equals: strPtr(value),
+	}
+}
@@ -0,0 +1,24 @@
+package generate
+
+import (
+	"testing"
+)
+
+func TestEOSNativeAssertion(t *testing.T) {
+	g := &EOSNativeGenerator{}
+	path := "eos_native:/Sysdb/interface/status/eth/vxlan/intfStatus/Vxlan1/operStatus"
+
+	a := g.assertion("Vxlan1 oper-status", path, "intfOperUp")
+	if a.Equals == nil || *a.Equals != "intfOperUp" || a.Path != path {
+		t.Errorf("scalar leaf: got %+v", a)
+	}
+	if a.Name != "Vxlan1 oper-status is intfOperUp" {
+		t.Errorf("Name = %q", a.Name)
+	}
+
+	a = g.assertion("Vxlan1 oper-status", path, `{"Name": "intfOperUp", "Value": 1}`)
+	obj, ok := a.EqualsJSON.(map[string]any)
+	if a.Equals != nil || !ok || obj["Name"] != "intfOperUp" {
+		t.Errorf("object leaf: got %+v", a)
+	}
+}
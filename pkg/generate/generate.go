@@ -3,6 +3,9 @@ package generate
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/ndtobs/netsert/pkg/assertion"
 	"github.com/ndtobs/netsert/pkg/gnmiclient"
@@ -20,6 +23,31 @@ type Generator interface {
 	Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error)
 }
 
+// Generation modes
+const (
+	ModeSnapshot = "snapshot" // Freeze current values, e.g. session-state ESTABLISHED
+	ModeExists   = "exists"   // Assert what is configured, not its current state
+)
+
+// ParseMode validates a generation mode; empty selects ModeSnapshot
+func ParseMode(mode string) (string, error) {
+	switch mode {
+	case "":
+		return ModeSnapshot, nil
+	case ModeSnapshot, ModeExists:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid mode %q: must be %s or %s", mode, ModeSnapshot, ModeExists)
+	}
+}
+
+// ModeAware is implemented by generators that emit their own structural
+// assertions (peer configured, interface enabled) in exists mode. Other
+// generators' assertions are reduced to existence checks on their paths.
+type ModeAware interface {
+	SupportsMode(mode string) bool
+}
+
 // Options controls what gets generated
 type Options struct {
 	// Target address for output
@@ -28,6 +56,57 @@ type Options struct {
 	// Credentials (passed through for context)
 	Username string
 	Password string
+
+	// Platform selects vendor-specific paths (e.g., arista_eos); empty means generic OpenConfig
+	Platform assertion.Platform
+
+	// Limit caps assertions from generators that emit one per table entry,
+	// such as routes (0 uses the generator's default)
+	Limit int
+
+	// VLANs restricts VLAN-scoped generators such as mac (empty means all)
+	VLANs []int
+
+	// IncludeInterfaces and ExcludeInterfaces are regexes of interface names
+	// for interface-scoped generators such as interfaces, lldp and counters;
+	// either replaces the generator's built-in skip list
+	IncludeInterfaces string
+	ExcludeInterfaces string
+
+	// VRFs restricts per-instance generators such as bgp (empty means every
+	// network instance running the protocol)
+	VRFs []string
+
+	// NTPServers is the approved server list for the ntp generator (empty
+	// approves the configured servers); MaxNTPOffset is its offset threshold
+	NTPServers   []string
+	MaxNTPOffset time.Duration
+
+	// DOMMargin is the dB band around current optical power for the
+	// transceivers generator (0 uses DefaultDOMMargin)
+	DOMMargin float64
+
+	// MaxPTPOffset is the offset-from-master threshold for the ptp generator
+	MaxPTPOffset time.Duration
+
+	// MaxCPU and MinMemoryFree are the resources generator's CPU
+	// utilization ceiling and free memory floor, in percent (0 uses
+	// DefaultMaxCPU and DefaultMinMemoryFreePct)
+	MaxCPU        int
+	MinMemoryFree int
+
+	// Args holds per-generator tunables keyed by generator then argument
+	// name; see Configurable and ParseArgs
+	Args map[string]map[string]string
+
+	// Mode is ModeSnapshot (default) or ModeExists
+	Mode string
+}
+
+// isNotFound reports whether a query error means the device has no data at
+// the path: NotFound, or a path the platform's profile marks unsupported
+func isNotFound(err error) bool {
+	return errors.Is(err, assertion.ErrUnsupportedPath) || errors.Is(err, gnmiclient.ErrNotFound)
 }
 
 // Registry holds all available generators
@@ -67,6 +146,11 @@ func GenerateFile(ctx context.Context, client *gnmiclient.Client, generators []s
 		if err != nil {
 			return nil, err
 		}
+		if opts.Mode == ModeExists {
+			if m, ok := gen.(ModeAware); !ok || !m.SupportsMode(ModeExists) {
+				assertions = existsAssertions(assertions)
+			}
+		}
 		allAssertions = append(allAssertions, assertions...)
 	}
 
@@ -74,8 +158,31 @@ func GenerateFile(ctx context.Context, client *gnmiclient.Client, generators []s
 		Targets: []assertion.Target{
 			{
 				Host:       opts.Target,
+				Platform:   string(opts.Platform),
 				Assertions: allAssertions,
 			},
 		},
 	}, nil
 }
+
+// existsAssertions reduces value checks to one existence check per path.
+// gNOI checks, which have no path to exist, are dropped.
+func existsAssertions(assertions []assertion.Assertion) []assertion.Assertion {
+	var out []assertion.Assertion
+	seen := make(map[string]bool)
+	for _, a := range assertions {
+		if a.Path == "" || seen[a.Path] {
+			continue
+		}
+		seen[a.Path] = true
+		if a.Exists == nil && a.Absent == nil {
+			a = assertion.Assertion{
+				Name:   fmt.Sprintf("%s exists", a.Path),
+				Path:   a.Path,
+				Exists: boolPtr(true),
+			}
+		}
+		out = append(out, a)
+	}
+	return out
+}
@@ -0,0 +1,77 @@
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"gNMI NotFound", fmt.Errorf("get: %w", gnmiclient.Classify(status.Error(codes.NotFound, "no such path"))), true},
+		{"unsupported on platform", fmt.Errorf("%w juniper: /lldp", assertion.ErrUnsupportedPath), true},
+		{"message mentioning not found", errors.New("certificate not found"), false},
+		{"other status", gnmiclient.Classify(status.Error(codes.Internal, "NotFound handler crashed")), false},
+	}
+	for _, tt := range tests {
+		if got := isNotFound(tt.err); got != tt.want {
+			t.Errorf("isNotFound(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		want    string
+		wantErr bool
+	}{
+		{"", ModeSnapshot, false},
+		{"snapshot", ModeSnapshot, false},
+		{"exists", ModeExists, false},
+		{"intent", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMode(tt.mode)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseMode(%q) = %q, %v; want %q", tt.mode, got, err, tt.want)
+		}
+	}
+}
+
+func TestExistsAssertions(t *testing.T) {
+	in := []assertion.Assertion{
+		{Name: "Ethernet1 in-errors not increasing", Path: "interface[Ethernet1]/state/counters/in-errors", LTE: strPtr("0")},
+		{Name: "Default route via 10.0.0.1", Path: "network-instance[default]/afts/ipv4-unicast/ipv4-entry[prefix=0.0.0.0/0]", Contains: strPtr("10.0.0.1")},
+		{Name: "Default route via 10.0.0.2", Path: "network-instance[default]/afts/ipv4-unicast/ipv4-entry[prefix=0.0.0.0/0]", Contains: strPtr("10.0.0.2")},
+		{Name: "VLAN 10 exists", Path: "network-instance[default]/vlans/vlan[vlan-id=10]/state/vlan-id", Exists: boolPtr(true)},
+		{Name: "Clock skew", MaxClockSkew: strPtr("1s")},
+	}
+
+	got := existsAssertions(in)
+	want := []struct{ name, path string }{
+		{"interface[Ethernet1]/state/counters/in-errors exists", "interface[Ethernet1]/state/counters/in-errors"},
+		{"network-instance[default]/afts/ipv4-unicast/ipv4-entry[prefix=0.0.0.0/0] exists", "network-instance[default]/afts/ipv4-unicast/ipv4-entry[prefix=0.0.0.0/0]"},
+		{"VLAN 10 exists", "network-instance[default]/vlans/vlan[vlan-id=10]/state/vlan-id"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("existsAssertions() = %d assertions, want %d", len(got), len(want))
+	}
+	for i, a := range got {
+		if a.Name != want[i].name || a.Path != want[i].path {
+			t.Errorf("assertion %d = %q %s, want %q %s", i, a.Name, a.Path, want[i].name, want[i].path)
+		}
+		if a.Exists == nil || !*a.Exists || a.Equals != nil || a.Contains != nil || a.LTE != nil {
+			t.Errorf("assertion %d = %+v, want only exists: true", i, a)
+		}
+	}
+}
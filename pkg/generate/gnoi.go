@@ -0,0 +1,64 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+	"github.com/ndtobs/netsert/pkg/gnoiclient"
+)
+
+// DefaultMaxClockSkew is the clock skew threshold written by the gnoi generator
+const DefaultMaxClockSkew = "5s"
+
+func init() {
+	Register(&GNOIGenerator{})
+}
+
+// GNOIGenerator creates system checks that run via gNOI
+type GNOIGenerator struct{}
+
+func (g *GNOIGenerator) Name() string {
+	return "gnoi"
+}
+
+func (g *GNOIGenerator) Description() string {
+	return "Generate gNOI checks for clock skew and running OS version"
+}
+
+func (g *GNOIGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
+	var assertions []assertion.Assertion
+	if client.Conn() == nil {
+		// Replaying fixtures, which hold no gNOI responses
+		return nil, nil
+	}
+	gnoi := gnoiclient.New(client.Conn())
+
+	// Devices without gNOI (or without one of the RPCs) simply yield no
+	// assertions for it; any other failure is reported
+	_, err := gnoi.ClockSkew(ctx, opts.Username, opts.Password)
+	if err != nil && !errors.Is(err, gnmiclient.ErrUnimplemented) {
+		return nil, err
+	}
+	if err == nil {
+		assertions = append(assertions, assertion.Assertion{
+			Name:         fmt.Sprintf("Clock skew within %s", DefaultMaxClockSkew),
+			MaxClockSkew: strPtr(DefaultMaxClockSkew),
+		})
+	}
+
+	version, err := gnoi.OSVersion(ctx, opts.Username, opts.Password)
+	if err != nil && !errors.Is(err, gnmiclient.ErrUnimplemented) {
+		return nil, err
+	}
+	if err == nil && version != "" {
+		assertions = append(assertions, assertion.Assertion{
+			Name:      fmt.Sprintf("OS version is %s", version),
+			OSVersion: strPtr(version),
+		})
+	}
+
+	return assertions, nil
+}
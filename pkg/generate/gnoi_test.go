@@ -0,0 +1,139 @@
+package generate
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+	ospb "github.com/openconfig/gnoi/os"
+	"github.com/openconfig/gnoi/system"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// gnoiServer answers System.Time and OS.Verify, or fails them with the
+// given errors
+type gnoiServer struct {
+	system.UnimplementedSystemServer
+	ospb.UnimplementedOSServer
+	timeErr, verifyErr error
+	version            string
+}
+
+func (s *gnoiServer) Time(context.Context, *system.TimeRequest) (*system.TimeResponse, error) {
+	if s.timeErr != nil {
+		return nil, s.timeErr
+	}
+	return &system.TimeResponse{Time: uint64(time.Now().UnixNano())}, nil
+}
+
+func (s *gnoiServer) Verify(context.Context, *ospb.VerifyRequest) (*ospb.VerifyResponse, error) {
+	if s.verifyErr != nil {
+		return nil, s.verifyErr
+	}
+	return &ospb.VerifyResponse{Version: s.version}, nil
+}
+
+func TestGNOIGenerator(t *testing.T) {
+	unimplemented := status.Error(codes.Unimplemented, "not supported")
+	tests := []struct {
+		name    string
+		server  *gnoiServer
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "clock and version",
+			server: &gnoiServer{version: "4.32.1F"},
+			want:   []string{"Clock skew within 5s", "OS version is 4.32.1F"},
+		},
+		{
+			name:   "no OS service",
+			server: &gnoiServer{verifyErr: unimplemented},
+			want:   []string{"Clock skew within 5s"},
+		},
+		{
+			name:   "no gNOI at all",
+			server: &gnoiServer{timeErr: unimplemented, verifyErr: unimplemented},
+		},
+		{
+			name:    "other errors are reported",
+			server:  &gnoiServer{timeErr: status.Error(codes.PermissionDenied, "denied")},
+			wantErr: true,
+		},
+		{
+			name:    "version errors are reported",
+			server:  &gnoiServer{verifyErr: status.Error(codes.Internal, "boom")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv := grpc.NewServer()
+			system.RegisterSystemServer(srv, tt.server)
+			ospb.RegisterOSServer(srv, tt.server)
+			go srv.Serve(lis)
+			defer srv.Stop()
+
+			client, err := gnmiclient.NewClient(gnmiclient.Config{Address: lis.Addr().String(), Insecure: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			got, err := (&GNOIGenerator{}).Generate(context.Background(), client, Options{})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Generate() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, a := range got {
+				names = append(names, a.Name)
+			}
+			if len(names) != len(tt.want) {
+				t.Fatalf("Generate() = %v, want %v", names, tt.want)
+			}
+			for i := range names {
+				if names[i] != tt.want[i] {
+					t.Errorf("Generate() = %v, want %v", names, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestGNOIGeneratorReplay(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "spine1.json"), []byte(`{"target": "spine1:6030"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fixtures, err := gnmiclient.ReplayFixtures(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := gnmiclient.NewClient(gnmiclient.Config{Address: "spine1:6030", Fixtures: fixtures})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	got, err := (&GNOIGenerator{}).Generate(context.Background(), client, Options{})
+	if err != nil || len(got) != 0 {
+		t.Errorf("Generate() from fixtures = %+v, %v; want no assertions", got, err)
+	}
+}
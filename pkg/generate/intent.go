@@ -0,0 +1,358 @@
+package generate
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+)
+
+// Intended configuration formats accepted by ParseIntent
+const (
+	FormatOpenConfigJSON = "openconfig-json" // RFC 7951 JSON of the OpenConfig config tree
+	FormatEOS            = "eos"             // Arista EOS running-config text
+)
+
+// Intent is the state a device should reach once its configuration is applied
+type Intent struct {
+	Hostname   string
+	Interfaces []string // Interfaces that should be oper-status UP
+	Neighbors  []IntentNeighbor
+}
+
+// IntentNeighbor is a BGP peer that should be established
+type IntentNeighbor struct {
+	NetworkInstance string
+	Address         string
+	PeerAS          uint32 // Zero if not configured
+}
+
+// DetectFormat guesses the config format from a file name
+func DetectFormat(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatOpenConfigJSON
+	}
+	return FormatEOS
+}
+
+// ParseIntent reads an intended configuration in the given format
+func ParseIntent(data []byte, format string) (*Intent, error) {
+	switch format {
+	case FormatOpenConfigJSON:
+		return parseOpenConfigIntent(data)
+	case FormatEOS:
+		return parseEOSIntent(data)
+	default:
+		return nil, fmt.Errorf("unknown config format %q (use %s or %s)", format, FormatOpenConfigJSON, FormatEOS)
+	}
+}
+
+// Assertions converts the intent to assertions, limited to the named generators
+// (empty means all)
+func (i *Intent) Assertions(generators []string) []assertion.Assertion {
+	want := func(name string) bool {
+		return len(generators) == 0 || slices.Contains(generators, name)
+	}
+
+	var assertions []assertion.Assertion
+	if want("system") && i.Hostname != "" {
+		assertions = append(assertions, assertion.Assertion{
+			Name:   fmt.Sprintf("Hostname is %s", i.Hostname),
+			Path:   "system/state/hostname",
+			Equals: strPtr(i.Hostname),
+		})
+	}
+	if want("interfaces") {
+		for _, name := range i.Interfaces {
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("%s is UP", name),
+				Path:   fmt.Sprintf("interface[%s]/state/oper-status", name),
+				Equals: strPtr("UP"),
+			})
+		}
+	}
+	if want("bgp") {
+		for _, n := range i.Neighbors {
+			base := fmt.Sprintf("bgp[%s]/neighbors/neighbor[neighbor-address=%s]/state", n.NetworkInstance, n.Address)
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("BGP peer %s is ESTABLISHED", n.Address),
+				Path:   base + "/session-state",
+				Equals: strPtr("ESTABLISHED"),
+			})
+			if n.PeerAS != 0 {
+				assertions = append(assertions, assertion.Assertion{
+					Name:   fmt.Sprintf("BGP peer %s is AS %d", n.Address, n.PeerAS),
+					Path:   base + "/peer-as",
+					Equals: strPtr(strconv.FormatUint(uint64(n.PeerAS), 10)),
+				})
+			}
+		}
+	}
+	return assertions
+}
+
+// parseEOSIntent reads interfaces, BGP neighbors, and the hostname from an
+// EOS running-config. Interfaces without "shutdown" and neighbors without
+// "neighbor X shutdown" are expected up.
+func parseEOSIntent(data []byte) (*Intent, error) {
+	intent := &Intent{}
+	skip := (&InterfacesGenerator{}).isSkippedInterface
+
+	type peer struct {
+		vrf      string
+		remoteAS uint32
+		group    string
+		shutdown bool
+	}
+	var (
+		section string // "interface" or "bgp" while inside that block
+		iface   string
+		ifaceUp bool
+		vrf     = "default"
+		peers   = make(map[string]*peer) // keyed by vrf + " " + neighbor or peer group
+		order   []string
+	)
+	flushInterface := func() {
+		if iface != "" && ifaceUp && !skip(iface) {
+			intent.Interfaces = append(intent.Interfaces, iface)
+		}
+		iface = ""
+	}
+	getPeer := func(name string) *peer {
+		key := vrf + " " + name
+		p, ok := peers[key]
+		if !ok {
+			p = &peer{vrf: vrf}
+			peers[key] = p
+			order = append(order, key)
+		}
+		return p
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "!") {
+			continue
+		}
+		fields := strings.Fields(trimmed)
+
+		// Unindented lines start a new top-level section
+		if line[0] != ' ' {
+			flushInterface()
+			section = ""
+			switch {
+			case fields[0] == "hostname" && len(fields) > 1:
+				intent.Hostname = fields[1]
+			case fields[0] == "interface" && len(fields) > 1:
+				section, iface, ifaceUp = "interface", fields[1], true
+			case fields[0] == "router" && len(fields) > 2 && fields[1] == "bgp":
+				section, vrf = "bgp", "default"
+			}
+			continue
+		}
+
+		switch section {
+		case "interface":
+			switch trimmed {
+			case "shutdown":
+				ifaceUp = false
+			case "no shutdown":
+				ifaceUp = true
+			}
+		case "bgp":
+			indent := len(line) - len(strings.TrimLeft(line, " "))
+			if fields[0] == "vrf" && len(fields) > 1 {
+				vrf = fields[1]
+				continue
+			}
+			if indent <= 3 && vrf != "default" {
+				vrf = "default"
+			}
+			if fields[0] != "neighbor" || len(fields) < 3 {
+				continue
+			}
+			p := getPeer(fields[1])
+			switch {
+			case fields[2] == "remote-as" && len(fields) > 3:
+				as, err := strconv.ParseUint(fields[3], 10, 32)
+				if err != nil {
+					return nil, fmt.Errorf("neighbor %s: invalid remote-as %q", fields[1], fields[3])
+				}
+				p.remoteAS = uint32(as)
+			case fields[2] == "shutdown":
+				p.shutdown = true
+			case fields[2] == "peer-group" && len(fields) > 3:
+				p.group = fields[3]
+			case fields[2] == "peer" && len(fields) > 4 && fields[3] == "group":
+				p.group = fields[4]
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	flushInterface()
+
+	for _, key := range order {
+		p := peers[key]
+		address := strings.TrimPrefix(key, p.vrf+" ")
+		// Peer group definitions share the neighbor keyword but aren't peers
+		if net.ParseIP(address) == nil || p.shutdown {
+			continue
+		}
+		as := p.remoteAS
+		if as == 0 && p.group != "" {
+			// Peer groups are usually defined globally and reused in VRFs
+			for _, scope := range []string{p.vrf, "default"} {
+				if g, ok := peers[scope+" "+p.group]; ok && g.remoteAS != 0 {
+					as = g.remoteAS
+					break
+				}
+			}
+		}
+		intent.Neighbors = append(intent.Neighbors, IntentNeighbor{
+			NetworkInstance: p.vrf,
+			Address:         address,
+			PeerAS:          as,
+		})
+	}
+
+	return intent, nil
+}
+
+// parseOpenConfigIntent reads the config containers of an OpenConfig JSON
+// document. Module prefixes on keys are optional.
+func parseOpenConfigIntent(data []byte) (*Intent, error) {
+	var doc struct {
+		System struct {
+			Config struct {
+				Hostname string `json:"hostname"`
+			} `json:"config"`
+		} `json:"system"`
+		Interfaces struct {
+			Interface []struct {
+				Name   string `json:"name"`
+				Config struct {
+					Enabled *bool `json:"enabled"`
+				} `json:"config"`
+			} `json:"interface"`
+		} `json:"interfaces"`
+		NetworkInstances struct {
+			NetworkInstance []struct {
+				Name      string `json:"name"`
+				Protocols struct {
+					Protocol []struct {
+						Identifier string `json:"identifier"`
+						BGP        struct {
+							Neighbors struct {
+								Neighbor []struct {
+									NeighborAddress string      `json:"neighbor-address"`
+									Config          ocBGPConfig `json:"config"`
+								} `json:"neighbor"`
+							} `json:"neighbors"`
+							PeerGroups struct {
+								PeerGroup []struct {
+									PeerGroupName string      `json:"peer-group-name"`
+									Config        ocBGPConfig `json:"config"`
+								} `json:"peer-group"`
+							} `json:"peer-groups"`
+						} `json:"bgp"`
+					} `json:"protocol"`
+				} `json:"protocols"`
+			} `json:"network-instance"`
+		} `json:"network-instances"`
+	}
+	if err := unmarshalStripped(string(data), &doc); err != nil {
+		return nil, fmt.Errorf("parse OpenConfig JSON: %w", err)
+	}
+
+	intent := &Intent{Hostname: doc.System.Config.Hostname}
+	skip := (&InterfacesGenerator{}).isSkippedInterface
+	for _, i := range doc.Interfaces.Interface {
+		enabled := i.Config.Enabled == nil || *i.Config.Enabled // Defaults to true
+		if enabled && !skip(i.Name) {
+			intent.Interfaces = append(intent.Interfaces, i.Name)
+		}
+	}
+
+	for _, ni := range doc.NetworkInstances.NetworkInstance {
+		for _, proto := range ni.Protocols.Protocol {
+			if normalizeAfiSafiName(proto.Identifier) != "BGP" {
+				continue
+			}
+			groupAS := make(map[string]uint32)
+			for _, pg := range proto.BGP.PeerGroups.PeerGroup {
+				groupAS[pg.PeerGroupName] = pg.Config.PeerAS
+			}
+			for _, n := range proto.BGP.Neighbors.Neighbor {
+				cfg := n.Config
+				if cfg.Enabled != nil && !*cfg.Enabled {
+					continue
+				}
+				address := n.NeighborAddress
+				if address == "" {
+					address = cfg.NeighborAddress
+				}
+				as := cfg.PeerAS
+				if as == 0 {
+					as = groupAS[cfg.PeerGroup]
+				}
+				intent.Neighbors = append(intent.Neighbors, IntentNeighbor{
+					NetworkInstance: ni.Name,
+					Address:         address,
+					PeerAS:          as,
+				})
+			}
+		}
+	}
+
+	return intent, nil
+}
+
+// ocBGPConfig is the config container of an OpenConfig BGP neighbor or peer group
+type ocBGPConfig struct {
+	NeighborAddress string `json:"neighbor-address"`
+	PeerAS          uint32 `json:"peer-as"`
+	PeerGroup       string `json:"peer-group"`
+	Enabled         *bool  `json:"enabled"`
+}
+
+// unmarshalStripped decodes JSON into v after removing module prefixes from keys
+func unmarshalStripped(jsonData string, v any) error {
+	var raw any
+	if err := json.Unmarshal([]byte(jsonData), &raw); err != nil {
+		return err
+	}
+	stripped, err := json.Marshal(stripModulePrefixes(raw))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(stripped, v)
+}
+
+// stripModulePrefixes removes "module:" prefixes from every object key
+func stripModulePrefixes(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, child := range t {
+			out[normalizeAfiSafiName(k)] = stripModulePrefixes(child)
+		}
+		return out
+	case []any:
+		for i, child := range t {
+			t[i] = stripModulePrefixes(child)
+		}
+		return t
+	}
+	return v
+}
@@ -0,0 +1,121 @@
+package generate
+
+import (
+	"reflect"
+	"testing"
+)
+
+const eosConfig = `hostname leaf1
+!
+interface Ethernet1
+   description to spine1
+   no switchport
+!
+interface Ethernet2
+   shutdown
+!
+interface Loopback0
+   ip address 10.0.0.11/32
+!
+interface Management1
+!
+router bgp 65011
+   router-id 10.0.0.11
+   neighbor SPINES peer group
+   neighbor SPINES remote-as 65000
+   neighbor 10.1.0.0 peer group SPINES
+   neighbor 10.1.0.2 remote-as 65001
+   neighbor 10.1.0.4 remote-as 65002
+   neighbor 10.1.0.4 shutdown
+   !
+   vrf TENANT
+      neighbor 10.2.0.0 peer group SPINES
+!
+end
+`
+
+const openConfigJSON = `{
+  "openconfig-system:system": {"config": {"hostname": "leaf1"}},
+  "openconfig-interfaces:interfaces": {
+    "interface": [
+      {"name": "Ethernet1", "config": {"name": "Ethernet1"}},
+      {"name": "Ethernet2", "config": {"name": "Ethernet2", "enabled": false}},
+      {"name": "Loopback0", "config": {"name": "Loopback0", "enabled": true}}
+    ]
+  },
+  "openconfig-network-instance:network-instances": {
+    "network-instance": [{
+      "name": "default",
+      "protocols": {"protocol": [{
+        "identifier": "openconfig-policy-types:BGP",
+        "name": "BGP",
+        "bgp": {
+          "peer-groups": {"peer-group": [{"peer-group-name": "SPINES", "config": {"peer-as": 65000}}]},
+          "neighbors": {"neighbor": [
+            {"neighbor-address": "10.1.0.0", "config": {"peer-group": "SPINES"}},
+            {"neighbor-address": "10.1.0.2", "config": {"peer-as": 65001}},
+            {"neighbor-address": "10.1.0.4", "config": {"peer-as": 65002, "enabled": false}}
+          ]}
+        }
+      }]}
+    }]
+  }
+}`
+
+func TestParseIntent(t *testing.T) {
+	tests := []struct {
+		format string
+		data   string
+		want   *Intent
+	}{
+		{FormatEOS, eosConfig, &Intent{
+			Hostname:   "leaf1",
+			Interfaces: []string{"Ethernet1"},
+			Neighbors: []IntentNeighbor{
+				{"default", "10.1.0.0", 65000},
+				{"default", "10.1.0.2", 65001},
+				{"TENANT", "10.2.0.0", 65000},
+			},
+		}},
+		{FormatOpenConfigJSON, openConfigJSON, &Intent{
+			Hostname:   "leaf1",
+			Interfaces: []string{"Ethernet1"},
+			Neighbors: []IntentNeighbor{
+				{"default", "10.1.0.0", 65000},
+				{"default", "10.1.0.2", 65001},
+			},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := ParseIntent([]byte(tt.data), tt.format)
+			if err != nil {
+				t.Fatalf("ParseIntent() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseIntent() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseIntent(nil, "junos"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestIntentAssertions(t *testing.T) {
+	intent := &Intent{
+		Hostname:   "leaf1",
+		Interfaces: []string{"Ethernet1"},
+		Neighbors:  []IntentNeighbor{{"default", "10.1.0.2", 65001}},
+	}
+
+	if got := len(intent.Assertions(nil)); got != 4 {
+		t.Errorf("Assertions(all) = %d, want 4", got)
+	}
+	got := intent.Assertions([]string{"bgp"})
+	if len(got) != 2 || got[0].Path != "bgp[default]/neighbors/neighbor[neighbor-address=10.1.0.2]/state/session-state" {
+		t.Errorf("Assertions(bgp) = %+v", got)
+	}
+}
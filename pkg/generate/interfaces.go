@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
+	"regexp"
 
 	"github.com/ndtobs/netsert/pkg/assertion"
 	"github.com/ndtobs/netsert/pkg/gnmiclient"
@@ -25,6 +25,10 @@ func (g *InterfacesGenerator) Description() string {
 	return "Generate assertions for interface oper-status"
 }
 
+func (g *InterfacesGenerator) Args() map[string]string {
+	return interfaceFilterArgs
+}
+
 type interfaceState struct {
 	Name        string
 	OperStatus  string
@@ -32,7 +36,19 @@ type interfaceState struct {
 }
 
 func (g *InterfacesGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
-	interfaces, err := g.getInterfaces(ctx, client, opts)
+	skip, err := interfaceFilter(opts, g.Name(), defaultInterfaceExclude)
+	if err != nil {
+		return nil, err
+	}
+
+	getInterfaces, statusPath := g.getInterfaces, "interface[%s]/state/oper-status"
+	adminPath, enabled := "interface[%s]/state/admin-status", "UP"
+	if srlNative(opts) {
+		getInterfaces, statusPath = g.getSRLInterfaces, "srl-interface[%s]/oper-state"
+		adminPath, enabled = "srl-interface[%s]/admin-state", "enable"
+	}
+
+	interfaces, err := getInterfaces(ctx, client, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -45,13 +61,24 @@ func (g *InterfacesGenerator) Generate(ctx context.Context, client *gnmiclient.C
 		}
 
 		// Skip management and internal interfaces
-		if g.isSkippedInterface(iface.Name) {
+		if skip(iface.Name) {
+			continue
+		}
+
+		// In exists mode assert the interface is enabled, which holds
+		// whether or not the link is currently up
+		if opts.Mode == ModeExists {
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("%s is enabled", iface.Name),
+				Path:   fmt.Sprintf(adminPath, iface.Name),
+				Equals: strPtr(enabled),
+			})
 			continue
 		}
 
 		name := fmt.Sprintf("%s is %s", iface.Name, iface.OperStatus)
 		// Use short path format - will be expanded at load time
-		path := fmt.Sprintf("interface[%s]/state/oper-status", iface.Name)
+		path := fmt.Sprintf(statusPath, iface.Name)
 
 		assertions = append(assertions, assertion.Assertion{
 			Name:   name,
@@ -63,13 +90,18 @@ func (g *InterfacesGenerator) Generate(ctx context.Context, client *gnmiclient.C
 	return assertions, nil
 }
 
+// SupportsMode reports that exists mode asserts interfaces are enabled
+func (g *InterfacesGenerator) SupportsMode(mode string) bool {
+	return true
+}
+
 func (g *InterfacesGenerator) getInterfaces(ctx context.Context, client *gnmiclient.Client, opts Options) ([]interfaceState, error) {
 	// Query /interfaces to get all interfaces
 	path := "/interfaces"
 
 	value, exists, err := client.Get(ctx, path, opts.Username, opts.Password)
 	if err != nil {
-		if strings.Contains(err.Error(), "NotFound") {
+		if isNotFound(err) {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("query interfaces: %w", err)
@@ -137,23 +169,13 @@ func (g *InterfacesGenerator) parseInterfaces(jsonData string) ([]interfaceState
 	return interfaces, nil
 }
 
-// isSkippedInterface returns true for interfaces we typically don't monitor
-func (g *InterfacesGenerator) isSkippedInterface(name string) bool {
-	// Skip common internal/management interfaces
-	prefixes := []string{
-		"Management",
-		"Loopback",
-		"Null",
-		"Cpu",
-		"Vxlan",
-		"ma", // management on some platforms
-	}
+// DefaultInterfaceExclude matches the management and internal interfaces
+// skipped unless include or exclude patterns are given
+const DefaultInterfaceExclude = `^(Management|Loopback|Null|Cpu|Vxlan|ma[0-9]|mgmt|system|lo[0-9]|irb)`
 
-	for _, prefix := range prefixes {
-		if strings.HasPrefix(name, prefix) {
-			return true
-		}
-	}
+var defaultInterfaceExclude = regexp.MustCompile(DefaultInterfaceExclude)
 
-	return false
+// isSkippedInterface returns true for interfaces we typically don't monitor
+func (g *InterfacesGenerator) isSkippedInterface(name string) bool {
+	return defaultInterfaceExclude.MatchString(name)
 }
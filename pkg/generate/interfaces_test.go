@@ -0,0 +1,48 @@
+package generate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+	"github.com/ndtobs/netsert/pkg/gnmitest"
+)
+
+func TestInterfacesGenerator(t *testing.T) {
+	srv, err := gnmitest.Start(map[string]any{
+		"interface[Ethernet1]/state/oper-status":   "UP",
+		"interface[Ethernet1]/state/admin-status":  "UP",
+		"interface[Ethernet2]/state/oper-status":   "DOWN",
+		"interface[Ethernet2]/state/admin-status":  "UP",
+		"interface[Ethernet3]/state/oper-status":   "DOWN",
+		"interface[Ethernet3]/state/admin-status":  "DOWN",
+		"interface[Management1]/state/oper-status": "UP",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	client, err := gnmiclient.NewClient(gnmiclient.Config{Address: srv.Addr(), Insecure: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	assertions, err := (&InterfacesGenerator{}).Generate(context.Background(), client, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Admin-down and management interfaces are left out
+	want := map[string]string{
+		"interface[Ethernet1]/state/oper-status": "UP",
+		"interface[Ethernet2]/state/oper-status": "DOWN",
+	}
+	if len(assertions) != len(want) {
+		t.Fatalf("Generate() = %d assertions, want %d: %+v", len(assertions), len(want), assertions)
+	}
+	for _, a := range assertions {
+		if a.Equals == nil || want[a.Path] != *a.Equals {
+			t.Errorf("unexpected assertion %s = %v", a.Path, a.Equals)
+		}
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
+	"regexp"
 
 	"github.com/ndtobs/netsert/pkg/assertion"
 	"github.com/ndtobs/netsert/pkg/gnmiclient"
@@ -25,6 +25,10 @@ func (g *LLDPGenerator) Description() string {
 	return "Generate assertions for LLDP neighbor relationships"
 }
 
+func (g *LLDPGenerator) Args() map[string]string {
+	return interfaceFilterArgs
+}
+
 type lldpNeighbor struct {
 	LocalInterface string
 	RemoteSystem   string
@@ -32,7 +36,17 @@ type lldpNeighbor struct {
 }
 
 func (g *LLDPGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
-	neighbors, err := g.getNeighbors(ctx, client, opts)
+	skip, err := interfaceFilter(opts, g.Name(), defaultLLDPExclude)
+	if err != nil {
+		return nil, err
+	}
+
+	getNeighbors, systemPath := g.getNeighbors, "lldp/interfaces/interface[name=%s]/neighbors/neighbor/state/system-name"
+	if srlNative(opts) {
+		getNeighbors, systemPath = g.getSRLNeighbors, "srl-system/lldp/interface[name=%s]/neighbor/system-name"
+	}
+
+	neighbors, err := getNeighbors(ctx, client, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +57,7 @@ func (g *LLDPGenerator) Generate(ctx context.Context, client *gnmiclient.Client,
 	var assertions []assertion.Assertion
 	for _, n := range neighbors {
 		// Skip management interfaces (often have multiple neighbors in lab environments)
-		if g.isSkippedInterface(n.LocalInterface) {
+		if skip(n.LocalInterface) {
 			continue
 		}
 
@@ -56,7 +70,7 @@ func (g *LLDPGenerator) Generate(ctx context.Context, client *gnmiclient.Client,
 		// Assert on remote system name
 		if n.RemoteSystem != "" {
 			name := fmt.Sprintf("LLDP %s connects to %s", n.LocalInterface, n.RemoteSystem)
-			path := fmt.Sprintf("lldp/interfaces/interface[name=%s]/neighbors/neighbor/state/system-name", n.LocalInterface)
+			path := fmt.Sprintf(systemPath, n.LocalInterface)
 
 			assertions = append(assertions, assertion.Assertion{
 				Name:     name,
@@ -69,32 +83,19 @@ func (g *LLDPGenerator) Generate(ctx context.Context, client *gnmiclient.Client,
 	return assertions, nil
 }
 
-// isSkippedInterface returns true for interfaces we typically don't monitor
-func (g *LLDPGenerator) isSkippedInterface(name string) bool {
-	prefixes := []string{
-		"Management",
-		"mgmt",
-		"ma",
-		"fxp",    // Juniper management
-		"em",     // Juniper internal
-		"vme",    // Arista
-	}
-
-	for _, prefix := range prefixes {
-		if strings.HasPrefix(name, prefix) {
-			return true
-		}
-	}
+// DefaultLLDPExclude matches the management interfaces skipped unless
+// include or exclude patterns are given. Management ports often see several
+// neighbors in lab environments. fxp/em are Juniper, vme is Arista.
+const DefaultLLDPExclude = `^(Management|mgmt|ma|fxp|em|vme)`
 
-	return false
-}
+var defaultLLDPExclude = regexp.MustCompile(DefaultLLDPExclude)
 
 func (g *LLDPGenerator) getNeighbors(ctx context.Context, client *gnmiclient.Client, opts Options) ([]lldpNeighbor, error) {
 	path := "/lldp/interfaces"
 
 	value, exists, err := client.Get(ctx, path, opts.Username, opts.Password)
 	if err != nil {
-		if strings.Contains(err.Error(), "NotFound") || strings.Contains(err.Error(), "not found") {
+		if isNotFound(err) {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("query LLDP interfaces: %w", err)
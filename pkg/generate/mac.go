@@ -0,0 +1,159 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+)
+
+func init() {
+	Register(&MACGenerator{})
+}
+
+// DefaultMACLimit caps MAC assertions when neither its limit argument nor Options.Limit is set
+const DefaultMACLimit = 100
+
+// MACGenerator creates assertions for MAC address table (FDB) entries
+type MACGenerator struct{}
+
+func (g *MACGenerator) Name() string {
+	return "mac"
+}
+
+func (g *MACGenerator) Description() string {
+	return "Generate assertions for MAC table entries and where they are learned"
+}
+
+func (g *MACGenerator) Args() map[string]string {
+	return map[string]string{"limit": limitArgDescription}
+}
+
+// macEntry is one FDB entry and where it was learned
+type macEntry struct {
+	MAC       string
+	VLAN      int
+	Interface string // Local port, if learned locally
+	PeerIP    string // Remote VTEP, if learned over VXLAN/EVPN
+}
+
+func (g *MACGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
+	path := assertion.ExpandPathFor("network-instance[default]/fdb/mac-table/entries", opts.Platform)
+
+	value, exists, err := client.Get(ctx, path, opts.Username, opts.Password)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query MAC table: %w", err)
+	}
+	if !exists || value == "" {
+		return nil, nil
+	}
+
+	entries, err := g.parseEntries(value)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := limitArg(opts, g.Name(), DefaultMACLimit)
+	if err != nil {
+		return nil, err
+	}
+	return g.assertions(entries, opts.VLANs, limit), nil
+}
+
+// assertions emits one assertion per entry in the given VLANs (all if empty):
+// the learned interface or VTEP when known, otherwise presence
+func (g *MACGenerator) assertions(entries []macEntry, vlans []int, limit int) []assertion.Assertion {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].VLAN != entries[j].VLAN {
+			return entries[i].VLAN < entries[j].VLAN
+		}
+		return entries[i].MAC < entries[j].MAC
+	})
+
+	var assertions []assertion.Assertion
+	for _, e := range entries {
+		if len(assertions) >= limit {
+			break
+		}
+		if len(vlans) > 0 && !slices.Contains(vlans, e.VLAN) {
+			continue
+		}
+
+		base := fmt.Sprintf("network-instance[default]/fdb/mac-table/entries/entry[mac-address=%s][vlan=%d]", e.MAC, e.VLAN)
+		switch {
+		case e.PeerIP != "":
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("MAC %s in VLAN %d via VTEP %s", e.MAC, e.VLAN, e.PeerIP),
+				Path:   base + "/peer/state/peer-ip",
+				Equals: strPtr(e.PeerIP),
+			})
+		case e.Interface != "":
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("MAC %s in VLAN %d on %s", e.MAC, e.VLAN, e.Interface),
+				Path:   base + "/interface/interface-ref/state/interface",
+				Equals: strPtr(e.Interface),
+			})
+		default:
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("MAC %s in VLAN %d exists", e.MAC, e.VLAN),
+				Path:   base + "/state/mac-address",
+				Exists: boolPtr(true),
+			})
+		}
+	}
+	return assertions
+}
+
+func (g *MACGenerator) parseEntries(jsonData string) ([]macEntry, error) {
+	var resp struct {
+		Entry []struct {
+			MACAddress string `json:"mac-address"`
+			VLAN       int    `json:"vlan"`
+			State      struct {
+				MACAddress string `json:"mac-address"`
+				VLAN       int    `json:"vlan"`
+			} `json:"state"`
+			Interface struct {
+				InterfaceRef struct {
+					State struct {
+						Interface string `json:"interface"`
+					} `json:"state"`
+				} `json:"interface-ref"`
+			} `json:"interface"`
+			Peer struct {
+				State struct {
+					PeerIP string `json:"peer-ip"`
+				} `json:"state"`
+			} `json:"peer"`
+		} `json:"entry"`
+	}
+	if err := unmarshalStripped(jsonData, &resp); err != nil {
+		return nil, fmt.Errorf("parse MAC table: %w", err)
+	}
+
+	var entries []macEntry
+	for _, e := range resp.Entry {
+		entry := macEntry{
+			MAC:       e.MACAddress,
+			VLAN:      e.VLAN,
+			Interface: e.Interface.InterfaceRef.State.Interface,
+			PeerIP:    e.Peer.State.PeerIP,
+		}
+		if entry.MAC == "" {
+			entry.MAC = e.State.MACAddress
+		}
+		if entry.VLAN == 0 {
+			entry.VLAN = e.State.VLAN
+		}
+		if entry.MAC != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
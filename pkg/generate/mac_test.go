@@ -0,0 +1,52 @@
+package generate
+
+import "testing"
+
+const macJSON = `{"openconfig-network-instance:entry": [
+  {"mac-address": "00:1c:73:00:00:02", "vlan": 20, "state": {"mac-address": "00:1c:73:00:00:02", "vlan": 20},
+   "peer": {"state": {"peer-ip": "10.0.1.2", "vni": 10020}}},
+  {"mac-address": "00:1c:73:00:00:01", "vlan": 10, "state": {"mac-address": "00:1c:73:00:00:01", "vlan": 10},
+   "interface": {"interface-ref": {"state": {"interface": "Ethernet3"}}}},
+  {"mac-address": "00:1c:73:00:00:03", "vlan": 10, "state": {"mac-address": "00:1c:73:00:00:03", "vlan": 10}}
+]}`
+
+func TestMACGenerator(t *testing.T) {
+	g := &MACGenerator{}
+	entries, err := g.parseEntries(macJSON)
+	if err != nil {
+		t.Fatalf("parseEntries() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		vlans     []int
+		limit     int
+		wantPaths []string
+	}{
+		{"all", nil, DefaultMACLimit, []string{
+			"network-instance[default]/fdb/mac-table/entries/entry[mac-address=00:1c:73:00:00:01][vlan=10]/interface/interface-ref/state/interface",
+			"network-instance[default]/fdb/mac-table/entries/entry[mac-address=00:1c:73:00:00:03][vlan=10]/state/mac-address",
+			"network-instance[default]/fdb/mac-table/entries/entry[mac-address=00:1c:73:00:00:02][vlan=20]/peer/state/peer-ip",
+		}},
+		{"vlan filter", []int{20}, DefaultMACLimit, []string{
+			"network-instance[default]/fdb/mac-table/entries/entry[mac-address=00:1c:73:00:00:02][vlan=20]/peer/state/peer-ip",
+		}},
+		{"limit", nil, 1, []string{
+			"network-instance[default]/fdb/mac-table/entries/entry[mac-address=00:1c:73:00:00:01][vlan=10]/interface/interface-ref/state/interface",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := g.assertions(entries, tt.vlans, tt.limit)
+			if len(got) != len(tt.wantPaths) {
+				t.Fatalf("assertions() = %d, want %d", len(got), len(tt.wantPaths))
+			}
+			for i, a := range got {
+				if a.Path != tt.wantPaths[i] {
+					t.Errorf("assertion %d path = %s, want %s", i, a.Path, tt.wantPaths[i])
+				}
+			}
+		})
+	}
+}
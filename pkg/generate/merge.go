@@ -0,0 +1,259 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"gopkg.in/yaml.v3"
+)
+
+// checkKeys are the assertion keys holding expected values. Merge refreshes
+// these and leaves every other key (name, ignore_paths, ...) as authored.
+var checkKeys = []string{
+	"equals", "contains", "matches", "exists", "absent",
+	"gt", "lt", "gte", "lte",
+	"equals_json", "contains_item", "count",
+	"max_clock_skew", "os_version",
+	"operator", "arg",
+	"fresh_within", "consistent", "equals_file",
+}
+
+// MergeResult counts the assertions a merge changed
+type MergeResult struct {
+	Updated int // Existing assertions whose expected values were refreshed
+	Added   int // Generated assertions with no existing counterpart
+}
+
+// Merge folds freshly generated assertions into an existing assertion file.
+// Generated assertions are matched to existing ones by target host and
+// path; a match with the same kind of check has its expected values
+// updated, keeping its name and comments. Unmatched assertions are appended,
+// and assertions the generators didn't produce are kept as they are, as are
+// matches whose check was changed by hand (e.g., equals to matches).
+// Negated (not:) assertions are never matched: the value they rule out
+// isn't one to refresh.
+func Merge(existing []byte, generated *assertion.AssertionFile) ([]byte, MergeResult, error) {
+	var result MergeResult
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(existing, &doc); err != nil {
+		return nil, result, fmt.Errorf("parse existing file: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, result, fmt.Errorf("parse existing file: expected a mapping with targets")
+	}
+	targets := mappingChild(root, "targets", yaml.SequenceNode)
+
+	for _, gt := range generated.Targets {
+		tnode := findTarget(targets, gt.GetHost())
+		if tnode == nil {
+			var n yaml.Node
+			if err := n.Encode(gt); err != nil {
+				return nil, result, err
+			}
+			targets.Content = append(targets.Content, &n)
+			result.Added += len(gt.Assertions)
+			continue
+		}
+
+		platform := assertion.Platform(scalarChild(tnode, "platform"))
+		if platform == "" {
+			platform = assertion.Platform(gt.Platform)
+		}
+		list := mappingChild(tnode, "assertions", yaml.SequenceNode)
+
+		gens := make([]*yaml.Node, len(gt.Assertions))
+		for i, a := range gt.Assertions {
+			gens[i] = &yaml.Node{}
+			if err := gens[i].Encode(a); err != nil {
+				return nil, result, err
+			}
+		}
+
+		// Pair unchanged assertions first, so several assertions on one
+		// path (e.g., route next hops) keep their own entries
+		matches := make([]*yaml.Node, len(gens))
+		used := make(map[*yaml.Node]bool)
+		for _, exact := range []bool{true, false} {
+			for i, gen := range gens {
+				if matches[i] != nil {
+					continue
+				}
+				for _, cand := range list.Content {
+					if used[cand] || negated(cand) || matchKey(cand, platform) != matchKey(gen, platform) {
+						continue
+					}
+					if exact && !sameChecks(cand, gen) {
+						continue
+					}
+					matches[i] = cand
+					used[cand] = true
+					break
+				}
+			}
+		}
+
+		for i, gen := range gens {
+			switch {
+			case matches[i] == nil:
+				list.Content = append(list.Content, gen)
+				result.Added++
+			case updateChecks(matches[i], gen):
+				result.Updated++
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(detectIndent(existing))
+	if err := enc.Encode(&doc); err != nil {
+		return nil, result, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, result, err
+	}
+	return buf.Bytes(), result, nil
+}
+
+// matchKey identifies an assertion: its expanded path, or for path-less
+// gNOI checks the kind of check
+func matchKey(n *yaml.Node, platform assertion.Platform) string {
+	if path := scalarChild(n, "path"); path != "" {
+		return assertion.ExpandPathFor(path, platform)
+	}
+	for _, k := range checkKeys {
+		if child(n, k) != nil {
+			return k
+		}
+	}
+	return ""
+}
+
+// negated reports whether an assertion has not: set
+func negated(n *yaml.Node) bool {
+	return scalarChild(n, "not") == "true"
+}
+
+// presentChecks lists the check keys set on an assertion
+func presentChecks(n *yaml.Node) []string {
+	var keys []string
+	for _, k := range checkKeys {
+		if child(n, k) != nil {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// sameChecks reports whether two assertions check the same values
+func sameChecks(a, b *yaml.Node) bool {
+	keys := presentChecks(a)
+	if !reflect.DeepEqual(keys, presentChecks(b)) {
+		return false
+	}
+	for _, k := range keys {
+		if !sameValue(child(a, k), child(b, k)) {
+			return false
+		}
+	}
+	return true
+}
+
+// updateChecks copies the generated expected values onto an existing
+// assertion with the same kind of check, reporting whether anything changed
+func updateChecks(existing, gen *yaml.Node) bool {
+	keys := presentChecks(gen)
+	if !reflect.DeepEqual(presentChecks(existing), keys) {
+		return false
+	}
+	changed := false
+	for _, k := range keys {
+		ev, gv := child(existing, k), child(gen, k)
+		if sameValue(ev, gv) {
+			continue
+		}
+		head, line, foot := ev.HeadComment, ev.LineComment, ev.FootComment
+		*ev = *gv
+		ev.HeadComment, ev.LineComment, ev.FootComment = head, line, foot
+		changed = true
+	}
+	return changed
+}
+
+// sameValue compares two values, treating scalars by their text so that
+// equals: 10 and equals: "10" match
+func sameValue(a, b *yaml.Node) bool {
+	if a.Kind == yaml.ScalarNode && b.Kind == yaml.ScalarNode {
+		return a.Value == b.Value
+	}
+	var av, bv any
+	if a.Decode(&av) != nil || b.Decode(&bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+// findTarget returns the target entry for host, matching host or address
+func findTarget(targets *yaml.Node, host string) *yaml.Node {
+	for _, t := range targets.Content {
+		if scalarChild(t, "host") == host || scalarChild(t, "address") == host {
+			return t
+		}
+	}
+	return nil
+}
+
+// child returns the value of a mapping key, or nil
+func child(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// scalarChild returns the text of a scalar mapping value, or ""
+func scalarChild(n *yaml.Node, key string) string {
+	if c := child(n, key); c != nil && c.Kind == yaml.ScalarNode {
+		return c.Value
+	}
+	return ""
+}
+
+// mappingChild returns the value of a mapping key, adding an empty node of
+// the given kind if the key is missing or null
+func mappingChild(n *yaml.Node, key string, kind yaml.Kind) *yaml.Node {
+	if c := child(n, key); c != nil {
+		if c.Kind == yaml.ScalarNode && c.Tag == "!!null" {
+			c.Kind, c.Tag, c.Value = kind, "", ""
+		}
+		return c
+	}
+	c := &yaml.Node{Kind: kind}
+	n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, c)
+	return c
+}
+
+// detectIndent returns the indentation step of a YAML document, so merged
+// files keep their layout (default 2)
+func detectIndent(data []byte) int {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if indent := len(line) - len(trimmed); indent > 0 && trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return indent
+		}
+	}
+	return 2
+}
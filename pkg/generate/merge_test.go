@@ -0,0 +1,132 @@
+package generate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+)
+
+func TestMerge(t *testing.T) {
+	existing := `# Fabric baseline
+targets:
+  - host: spine1:6030
+    assertions:
+      # Uplink to leaf1
+      - name: Uplink to leaf1 is up
+        path: interface[Ethernet1]/state/oper-status
+        equals: UP
+      - name: Ethernet2 in-errors not increasing (3)
+        path: interface[Ethernet2]/state/counters/in-errors
+        lte: "3"
+      - name: Hostname looks right
+        path: system/state/hostname
+        matches: ^spine
+      - name: Hand-written check
+        path: interface[Ethernet9]/state/description
+        equals: spare
+`
+	generated := &assertion.AssertionFile{Targets: []assertion.Target{
+		{
+			Host: "spine1:6030",
+			Assertions: []assertion.Assertion{
+				{Name: "Ethernet1 is UP", Path: "interface[Ethernet1]/state/oper-status", Equals: strPtr("UP")},
+				{Name: "Ethernet2 in-errors not increasing (7)", Path: "/interfaces/interface[name=Ethernet2]/state/counters/in-errors", LTE: strPtr("7")},
+				{Name: "Hostname is spine1", Path: "system/state/hostname", Equals: strPtr("spine1")},
+				{Name: "Ethernet3 is UP", Path: "interface[Ethernet3]/state/oper-status", Equals: strPtr("UP")},
+			},
+		},
+		{
+			Host:       "leaf1:6030",
+			Assertions: []assertion.Assertion{{Name: "Hostname is leaf1", Path: "system/state/hostname", Equals: strPtr("leaf1")}},
+		},
+	}}
+
+	out, result, err := Merge([]byte(existing), generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Updated != 1 || result.Added != 2 {
+		t.Errorf("result = %+v, want 1 updated, 2 added", result)
+	}
+
+	merged := string(out)
+	for _, want := range []string{
+		"# Fabric baseline",
+		"# Uplink to leaf1",
+		"name: Uplink to leaf1 is up",
+		"name: Ethernet2 in-errors not increasing (3)",
+		`lte: "7"`,
+		"matches: ^spine",
+		"equals: spare",
+		"name: Ethernet3 is UP",
+		"host: leaf1:6030",
+		"\n  - host: spine1:6030",
+	} {
+		if !strings.Contains(merged, want) {
+			t.Errorf("merged file missing %q:\n%s", want, merged)
+		}
+	}
+	if strings.Contains(merged, `lte: "3"`) || strings.Contains(merged, "Hostname is spine1") {
+		t.Errorf("stale or hand-edited values not handled:\n%s", merged)
+	}
+}
+
+func TestMergeSamePath(t *testing.T) {
+	existing := `targets:
+  - host: r1
+    assertions:
+      - name: via 10.0.0.1
+        path: network-instance[default]/afts/next-hops
+        contains_item: {state: {ip-address: 10.0.0.1}}
+        list_path: next-hop
+      - name: via 10.0.0.2
+        path: network-instance[default]/afts/next-hops
+        contains_item: {state: {ip-address: 10.0.0.2}}
+        list_path: next-hop
+`
+	hop := func(ip string) assertion.Assertion {
+		return assertion.Assertion{
+			Path:         "network-instance[default]/afts/next-hops",
+			ContainsItem: map[string]any{"state": map[string]any{"ip-address": ip}},
+			ListPath:     "next-hop",
+		}
+	}
+	generated := &assertion.AssertionFile{Targets: []assertion.Target{
+		{Host: "r1", Assertions: []assertion.Assertion{hop("10.0.0.2"), hop("10.0.0.1")}},
+	}}
+
+	_, result, err := Merge([]byte(existing), generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Updated != 0 || result.Added != 0 {
+		t.Errorf("result = %+v, want nothing changed", result)
+	}
+}
+
+func TestMergeNot(t *testing.T) {
+	existing := `targets:
+  - host: spine1
+    assertions:
+      - name: Not in maintenance
+        path: interface[Ethernet1]/state/description
+        equals: MAINT
+        not: true
+`
+	generated := &assertion.AssertionFile{Targets: []assertion.Target{{
+		Host:       "spine1",
+		Assertions: []assertion.Assertion{{Path: "interface[Ethernet1]/state/description", Equals: strPtr("uplink")}},
+	}}}
+
+	out, result, err := Merge([]byte(existing), generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Updated != 0 || result.Added != 1 {
+		t.Errorf("result = %+v, want the negated assertion kept and the generated one added", result)
+	}
+	if merged := string(out); !strings.Contains(merged, "equals: MAINT\n        not: true") {
+		t.Errorf("negated assertion rewritten:\n%s", merged)
+	}
+}
@@ -0,0 +1,174 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+)
+
+func init() {
+	Register(&MulticastGenerator{})
+}
+
+// DefaultIGMPLimit caps IGMP group assertions when neither its limit argument nor Options.Limit is set
+const DefaultIGMPLimit = 100
+
+// MulticastGenerator creates assertions for PIM adjacencies and IGMP group
+// membership. OpenConfig has no widely implemented mroute table, so (S,G)
+// state is covered through the IGMP groups that drive it.
+type MulticastGenerator struct{}
+
+func (g *MulticastGenerator) Name() string {
+	return "multicast"
+}
+
+func (g *MulticastGenerator) Description() string {
+	return "Generate assertions for PIM neighbors and IGMP group membership"
+}
+
+func (g *MulticastGenerator) Args() map[string]string {
+	return map[string]string{"limit": limitArgDescription}
+}
+
+// pimNeighbor is a PIM adjacency on an interface
+type pimNeighbor struct {
+	Interface string
+	Address   string
+}
+
+// igmpGroup is a group joined on an interface
+type igmpGroup struct {
+	Interface string
+	Group     string
+}
+
+func (g *MulticastGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
+	var assertions []assertion.Assertion
+
+	pimData, err := g.get(ctx, client, "pim[default]/interfaces", opts)
+	if err != nil {
+		return nil, fmt.Errorf("query PIM: %w", err)
+	}
+	neighbors, err := g.parsePIM(pimData)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range neighbors {
+		assertions = append(assertions, assertion.Assertion{
+			Name:   fmt.Sprintf("PIM neighbor %s on %s", n.Address, n.Interface),
+			Path:   fmt.Sprintf("pim[default]/interfaces/interface[interface-id=%s]/neighbors/neighbor[neighbor-address=%s]/state/neighbor-address", n.Interface, n.Address),
+			Equals: strPtr(n.Address),
+		})
+	}
+
+	igmpData, err := g.get(ctx, client, "igmp[default]/interfaces", opts)
+	if err != nil {
+		return nil, fmt.Errorf("query IGMP: %w", err)
+	}
+	groups, err := g.parseIGMP(igmpData)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := limitArg(opts, g.Name(), DefaultIGMPLimit)
+	if err != nil {
+		return nil, err
+	}
+	for i, grp := range groups {
+		if i >= limit {
+			break
+		}
+		assertions = append(assertions, assertion.Assertion{
+			Name:   fmt.Sprintf("IGMP group %s joined on %s", grp.Group, grp.Interface),
+			Path:   fmt.Sprintf("igmp[default]/interfaces/interface[interface-id=%s]/membership-groups/group[group=%s]/state/group", grp.Interface, grp.Group),
+			Equals: strPtr(grp.Group),
+		})
+	}
+
+	return assertions, nil
+}
+
+// get queries a short path, returning "" when the protocol isn't running
+func (g *MulticastGenerator) get(ctx context.Context, client *gnmiclient.Client, path string, opts Options) (string, error) {
+	value, exists, err := client.Get(ctx, assertion.ExpandPathFor(path, opts.Platform), opts.Username, opts.Password)
+	if err != nil {
+		if isNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if !exists {
+		return "", nil
+	}
+	return value, nil
+}
+
+func (g *MulticastGenerator) parsePIM(jsonData string) ([]pimNeighbor, error) {
+	if jsonData == "" {
+		return nil, nil
+	}
+	var resp struct {
+		Interface []struct {
+			InterfaceID string `json:"interface-id"`
+			Neighbors   struct {
+				Neighbor []struct {
+					NeighborAddress string `json:"neighbor-address"`
+					State           struct {
+						NeighborAddress string `json:"neighbor-address"`
+					} `json:"state"`
+				} `json:"neighbor"`
+			} `json:"neighbors"`
+		} `json:"interface"`
+	}
+	if err := unmarshalStripped(jsonData, &resp); err != nil {
+		return nil, fmt.Errorf("parse PIM: %w", err)
+	}
+
+	var neighbors []pimNeighbor
+	for _, iface := range resp.Interface {
+		for _, n := range iface.Neighbors.Neighbor {
+			address := n.NeighborAddress
+			if address == "" {
+				address = n.State.NeighborAddress
+			}
+			neighbors = append(neighbors, pimNeighbor{Interface: iface.InterfaceID, Address: address})
+		}
+	}
+	return neighbors, nil
+}
+
+func (g *MulticastGenerator) parseIGMP(jsonData string) ([]igmpGroup, error) {
+	if jsonData == "" {
+		return nil, nil
+	}
+	var resp struct {
+		Interface []struct {
+			InterfaceID      string `json:"interface-id"`
+			MembershipGroups struct {
+				Group []struct {
+					Group string `json:"group"`
+				} `json:"group"`
+			} `json:"membership-groups"`
+		} `json:"interface"`
+	}
+	if err := unmarshalStripped(jsonData, &resp); err != nil {
+		return nil, fmt.Errorf("parse IGMP: %w", err)
+	}
+
+	var groups []igmpGroup
+	for _, iface := range resp.Interface {
+		for _, grp := range iface.MembershipGroups.Group {
+			groups = append(groups, igmpGroup{Interface: iface.InterfaceID, Group: grp.Group})
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Interface != groups[j].Interface {
+			return groups[i].Interface < groups[j].Interface
+		}
+		return groups[i].Group < groups[j].Group
+	})
+	return groups, nil
+}
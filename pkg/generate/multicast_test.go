@@ -0,0 +1,38 @@
+package generate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMulticastParse(t *testing.T) {
+	g := &MulticastGenerator{}
+
+	pim := `{"openconfig-network-instance:interface": [
+  {"interface-id": "Ethernet1", "neighbors": {"neighbor": [{"neighbor-address": "10.1.0.1", "state": {"neighbor-address": "10.1.0.1"}}]}},
+  {"interface-id": "Ethernet2"}
+]}`
+	neighbors, err := g.parsePIM(pim)
+	if err != nil {
+		t.Fatalf("parsePIM() error = %v", err)
+	}
+	if want := []pimNeighbor{{"Ethernet1", "10.1.0.1"}}; !reflect.DeepEqual(neighbors, want) {
+		t.Errorf("parsePIM() = %+v, want %+v", neighbors, want)
+	}
+
+	igmp := `{"interface": [
+  {"interface-id": "Vlan20", "membership-groups": {"group": [{"group": "239.1.1.2"}]}},
+  {"interface-id": "Vlan10", "membership-groups": {"group": [{"group": "239.1.1.1"}]}}
+]}`
+	groups, err := g.parseIGMP(igmp)
+	if err != nil {
+		t.Fatalf("parseIGMP() error = %v", err)
+	}
+	if want := []igmpGroup{{"Vlan10", "239.1.1.1"}, {"Vlan20", "239.1.1.2"}}; !reflect.DeepEqual(groups, want) {
+		t.Errorf("parseIGMP() = %+v, want %+v", groups, want)
+	}
+
+	if got, err := g.parsePIM(""); err != nil || got != nil {
+		t.Errorf("parsePIM(\"\") = %v, %v, want nil", got, err)
+	}
+}
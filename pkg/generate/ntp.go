@@ -0,0 +1,173 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+)
+
+func init() {
+	Register(&NTPGenerator{})
+}
+
+// DefaultMaxNTPOffset is the offset threshold when Options.MaxNTPOffset is unset
+const DefaultMaxNTPOffset = 100 * time.Millisecond
+
+// ntpUnsynchronized is the stratum of a server that can't be used for sync
+const ntpUnsynchronized = 16
+
+// NTPGenerator creates assertions for NTP synchronization
+type NTPGenerator struct{}
+
+func (g *NTPGenerator) Name() string {
+	return "ntp"
+}
+
+func (g *NTPGenerator) Description() string {
+	return "Generate assertions for NTP sync to an approved server and clock offset"
+}
+
+// ntpServer is the state of one configured NTP server
+type ntpServer struct {
+	Address  string
+	Stratum  int
+	Selected bool // Reported by some platforms for the current sync source
+}
+
+func (g *NTPGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
+	value, exists, err := client.Get(ctx, "/system/ntp", opts.Username, opts.Password)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query NTP: %w", err)
+	}
+	if !exists || value == "" {
+		return nil, nil
+	}
+
+	enabled, servers, err := g.parseNTP(value)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled && len(servers) == 0 {
+		return nil, nil
+	}
+
+	maxOffset := opts.MaxNTPOffset
+	if maxOffset == 0 {
+		maxOffset = DefaultMaxNTPOffset
+	}
+	return g.assertions(servers, opts.NTPServers, maxOffset), nil
+}
+
+// assertions checks that NTP is enabled, every approved server is configured,
+// and the device is synchronized to an approved server within maxOffset.
+// Without an approved list, the configured servers are approved.
+func (g *NTPGenerator) assertions(servers []ntpServer, approved []string, maxOffset time.Duration) []assertion.Assertion {
+	if len(approved) == 0 {
+		for _, s := range servers {
+			approved = append(approved, s.Address)
+		}
+	}
+
+	assertions := []assertion.Assertion{{
+		Name:   "NTP is enabled",
+		Path:   "system/ntp/state/enabled",
+		Equals: strPtr("true"),
+	}}
+	for _, addr := range approved {
+		assertions = append(assertions, assertion.Assertion{
+			Name:   fmt.Sprintf("NTP server %s is configured", addr),
+			Path:   fmt.Sprintf("system/ntp/servers/server[address=%s]/state/address", addr),
+			Equals: strPtr(addr),
+		})
+	}
+
+	sync := g.syncSource(servers, approved)
+	if sync == "" {
+		return assertions
+	}
+	base := fmt.Sprintf("system/ntp/servers/server[address=%s]/state", sync)
+	ms := strconv.FormatInt(maxOffset.Milliseconds(), 10)
+	assertions = append(assertions,
+		assertion.Assertion{
+			Name: fmt.Sprintf("NTP synchronized to %s", sync),
+			Path: base + "/stratum",
+			LT:   strPtr(strconv.Itoa(ntpUnsynchronized)),
+		},
+		assertion.Assertion{
+			Name: fmt.Sprintf("NTP offset from %s within %sms", sync, ms),
+			Path: base + "/offset",
+			LTE:  strPtr(ms),
+		},
+	)
+	return assertions
+}
+
+// syncSource picks the approved server the device is synchronized to: the
+// one flagged selected, else the lowest usable stratum
+func (g *NTPGenerator) syncSource(servers []ntpServer, approved []string) string {
+	best, bestStratum := "", ntpUnsynchronized
+	for _, s := range servers {
+		if !slices.Contains(approved, s.Address) {
+			continue
+		}
+		if s.Selected {
+			return s.Address
+		}
+		if s.Stratum > 0 && s.Stratum < bestStratum {
+			best, bestStratum = s.Address, s.Stratum
+		}
+	}
+	return best
+}
+
+// ntpContainer is the OpenConfig /system/ntp container
+type ntpContainer struct {
+	State struct {
+		Enabled bool `json:"enabled"`
+	} `json:"state"`
+	Servers struct {
+		Server []struct {
+			Address string `json:"address"`
+			State   struct {
+				Address  string `json:"address"`
+				Stratum  int    `json:"stratum"`
+				Selected bool   `json:"selected"`
+			} `json:"state"`
+		} `json:"server"`
+	} `json:"servers"`
+}
+
+func (g *NTPGenerator) parseNTP(jsonData string) (bool, []ntpServer, error) {
+	// The response may be the ntp container itself or wrapped in it
+	var wrapped struct {
+		NTP *ntpContainer `json:"ntp"`
+	}
+	if err := unmarshalStripped(jsonData, &wrapped); err != nil {
+		return false, nil, fmt.Errorf("parse NTP: %w", err)
+	}
+	ntp := wrapped.NTP
+	if ntp == nil {
+		ntp = &ntpContainer{}
+		if err := unmarshalStripped(jsonData, ntp); err != nil {
+			return false, nil, fmt.Errorf("parse NTP: %w", err)
+		}
+	}
+
+	var servers []ntpServer
+	for _, s := range ntp.Servers.Server {
+		addr := s.Address
+		if addr == "" {
+			addr = s.State.Address
+		}
+		servers = append(servers, ntpServer{Address: addr, Stratum: s.State.Stratum, Selected: s.State.Selected})
+	}
+	return ntp.State.Enabled, servers, nil
+}
@@ -0,0 +1,48 @@
+package generate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNTPGenerator(t *testing.T) {
+	g := &NTPGenerator{}
+	enabled, servers, err := g.parseNTP(`{"openconfig-system:ntp": {
+  "state": {"enabled": true},
+  "servers": {"server": [
+    {"address": "10.0.0.100", "state": {"address": "10.0.0.100", "stratum": 3}},
+    {"address": "10.0.0.101", "state": {"address": "10.0.0.101", "stratum": 2}},
+    {"address": "192.0.2.1", "state": {"address": "192.0.2.1", "stratum": 1}}
+  ]}
+}}`)
+	if err != nil || !enabled || len(servers) != 3 {
+		t.Fatalf("parseNTP() = %v, %+v, %v", enabled, servers, err)
+	}
+
+	tests := []struct {
+		name     string
+		approved []string
+		wantSync string
+	}{
+		{"all configured approved", nil, "192.0.2.1"},
+		{"lowest approved stratum", []string{"10.0.0.100", "10.0.0.101"}, "10.0.0.101"},
+		{"no usable approved server", []string{"10.0.0.200"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := g.assertions(servers, tt.approved, 50*time.Millisecond)
+			last := got[len(got)-1]
+			if tt.wantSync == "" {
+				if last.LTE != nil {
+					t.Errorf("unexpected offset assertion %+v", last)
+				}
+				return
+			}
+			want := "system/ntp/servers/server[address=" + tt.wantSync + "]/state/offset"
+			if last.Path != want || *last.LTE != "50" {
+				t.Errorf("offset assertion = %s <= %v, want %s <= 50", last.Path, *last.LTE, want)
+			}
+		})
+	}
+}
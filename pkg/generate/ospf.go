@@ -58,13 +58,12 @@ func (g *OSPFGenerator) Generate(ctx context.Context, client *gnmiclient.Client,
 
 func (g *OSPFGenerator) getNeighbors(ctx context.Context, client *gnmiclient.Client, opts Options) ([]ospfNeighbor, error) {
 	// Query OSPF areas to find neighbors
-	path := "/network-instances/network-instance[name=default]/protocols/protocol[identifier=OSPF][name=OSPF]/ospf/areas"
+	path := assertion.ExpandPathFor("ospf[default]/areas", opts.Platform)
 
 	value, exists, err := client.Get(ctx, path, opts.Username, opts.Password)
 	if err != nil {
 		// OSPF might not be configured - that's okay, return empty
-		if strings.Contains(err.Error(), "NotFound") || 
-		   strings.Contains(err.Error(), "not found") ||
+		if isNotFound(err) ||
 		   strings.Contains(err.Error(), "path invalid") ||
 		   strings.Contains(err.Error(), "InvalidArgument") {
 			return nil, nil
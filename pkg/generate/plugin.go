@@ -0,0 +1,214 @@
+package generate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+	"gopkg.in/yaml.v3"
+)
+
+// Plugins are executables named netsert-gen-<name> in a plugin directory.
+// netsert speaks JSON to them, so a plugin never needs a gNMI client:
+//
+//	netsert-gen-<name> describe
+//	  stdout: {"description": "...", "paths": ["/gNMI/path", ...],
+//	           "args": {"<key>": "<description>"}}
+//
+//	netsert-gen-<name> generate
+//	  stdin:  {"target": "...", "platform": "...", "args": {"<key>": "<value>"},
+//	           "values": {"/gNMI/path": "<value as returned by netsert get>"}}
+//	  stdout: {"assertions": [{"name": "...", "path": "...", "equals": "..."}]}
+//
+// Paths the device doesn't have are left out of values. Assertions use the
+// same keys as assertion YAML files. A non-zero exit fails generation, with
+// the plugin's stderr in the error.
+const PluginPrefix = "netsert-gen-"
+
+// EnvPluginDir adds plugin directories (separated like $PATH) searched
+// before the default ~/.config/netsert/plugins
+const EnvPluginDir = "NETSERT_PLUGIN_DIR"
+
+// pluginDescribeTimeout bounds the describe call made at discovery
+const pluginDescribeTimeout = 5 * time.Second
+
+// PluginGenerator runs an external generator executable
+type PluginGenerator struct {
+	name        string
+	exe         string
+	description string
+	paths       []string
+	args        map[string]string
+}
+
+func (g *PluginGenerator) Name() string {
+	return g.name
+}
+
+func (g *PluginGenerator) Description() string {
+	if g.description == "" {
+		return fmt.Sprintf("External generator %s", g.exe)
+	}
+	return g.description
+}
+
+func (g *PluginGenerator) Args() map[string]string {
+	return g.args
+}
+
+// pluginInput is the document written to a plugin's stdin
+type pluginInput struct {
+	Target   string            `json:"target"`
+	Platform string            `json:"platform,omitempty"`
+	Args     map[string]string `json:"args,omitempty"`
+	Values   map[string]string `json:"values"`
+}
+
+func (g *PluginGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
+	input := pluginInput{
+		Target:   opts.Target,
+		Platform: string(opts.Platform),
+		Args:     opts.Args[g.name],
+		Values:   make(map[string]string),
+	}
+	for _, path := range g.paths {
+		value, exists, err := client.Get(ctx, assertion.ExpandPathFor(path, opts.Platform), opts.Username, opts.Password)
+		if err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("plugin %s: query %s: %w", g.name, path, err)
+		}
+		if exists {
+			input.Values[path] = value
+		}
+	}
+
+	return g.generate(ctx, input)
+}
+
+func (g *PluginGenerator) generate(ctx context.Context, input pluginInput) ([]assertion.Assertion, error) {
+	stdin, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", g.name, err)
+	}
+	out, err := runPlugin(ctx, g.exe, "generate", stdin)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", g.name, err)
+	}
+
+	// Decode as YAML (a superset of JSON) so assertion keys match the
+	// assertion file format, e.g. list_path and contains_item
+	var resp struct {
+		Assertions []assertion.Assertion `yaml:"assertions"`
+	}
+	if err := yaml.Unmarshal(out, &resp); err != nil {
+		return nil, fmt.Errorf("plugin %s: parse output: %w", g.name, err)
+	}
+	return resp.Assertions, nil
+}
+
+// PluginDirs returns the directories searched for plugins, in order
+func PluginDirs() []string {
+	var dirs []string
+	if env := os.Getenv(EnvPluginDir); env != "" {
+		dirs = append(dirs, filepath.SplitList(env)...)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".config", "netsert", "plugins"))
+	}
+	return dirs
+}
+
+// LoadPlugins registers the plugins found in dirs. Missing directories are
+// skipped; built-in generators and plugins found earlier win name clashes.
+// Plugins whose describe fails are left out and reported as warnings, so
+// one broken plugin doesn't stop the others or the built-in generators.
+func LoadPlugins(ctx context.Context, dirs []string) (warnings []string, err error) {
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return warnings, fmt.Errorf("read plugin directory: %w", err)
+		}
+
+		var names []string
+		for _, e := range entries {
+			if strings.HasPrefix(e.Name(), PluginPrefix) && !e.IsDir() {
+				names = append(names, e.Name())
+			}
+		}
+		sort.Strings(names)
+
+		for _, file := range names {
+			name := strings.TrimSuffix(strings.TrimPrefix(file, PluginPrefix), filepath.Ext(file))
+			if _, exists := Get(name); exists || name == "" {
+				continue
+			}
+			exe := filepath.Join(dir, file)
+			if info, err := os.Stat(exe); err != nil || info.Mode()&0111 == 0 {
+				continue
+			}
+			g, err := describePlugin(ctx, name, exe)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("%v (skipped)", err))
+				continue
+			}
+			Register(g)
+		}
+	}
+	return warnings, nil
+}
+
+func describePlugin(ctx context.Context, name, exe string) (*PluginGenerator, error) {
+	ctx, cancel := context.WithTimeout(ctx, pluginDescribeTimeout)
+	defer cancel()
+
+	out, err := runPlugin(ctx, exe, "describe", nil)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", name, err)
+	}
+	var desc struct {
+		Description string            `json:"description"`
+		Paths       []string          `json:"paths"`
+		Args        map[string]string `json:"args"`
+	}
+	if err := json.Unmarshal(out, &desc); err != nil {
+		return nil, fmt.Errorf("plugin %s: parse describe output: %w", name, err)
+	}
+	return &PluginGenerator{
+		name:        name,
+		exe:         exe,
+		description: desc.Description,
+		paths:       desc.Paths,
+		args:        desc.Args,
+	}, nil
+}
+
+// runPlugin runs a plugin subcommand, returning its stdout
+func runPlugin(ctx context.Context, exe, command string, stdin []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, exe, command)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", command, err, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", command, err)
+	}
+	return stdout.Bytes(), nil
+}
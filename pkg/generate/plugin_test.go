@@ -0,0 +1,107 @@
+package generate
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writePlugin creates a shell-script plugin that answers describe with
+// describeJSON and generate by saving its stdin and printing generateOut
+func writePlugin(t *testing.T, dir, name, describeJSON, generateOut string) {
+	t.Helper()
+	script := `#!/bin/sh
+case "$1" in
+describe) echo '` + describeJSON + `' ;;
+generate) cat > "$(dirname "$0")/input.json"; echo '` + generateOut + `' ;;
+*) echo "unknown command $1" >&2; exit 2 ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, PluginPrefix+name), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPluginGenerator(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script plugins")
+	}
+	dir := t.TempDir()
+	writePlugin(t, dir, "widgets",
+		`{"description": "Widget state", "paths": ["/widgets"], "args": {"min": "Minimum widgets"}}`,
+		`{"assertions": [{"name": "widget 1 is up", "path": "/widgets/widget[id=1]/state", "contains_item": {"status": "UP"}, "list_path": "status"}]}`)
+	// Built-in names can't be shadowed
+	writePlugin(t, dir, "bgp", `{}`, `{}`)
+	// A plugin failing describe is skipped with a warning
+	if err := os.WriteFile(filepath.Join(dir, PluginPrefix+"broken"), []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { delete(Registry, "widgets"); delete(Registry, "broken") })
+
+	warnings, err := LoadPlugins(context.Background(), []string{filepath.Join(dir, "missing"), dir})
+	if err != nil {
+		t.Fatalf("LoadPlugins() error = %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "plugin broken") {
+		t.Errorf("LoadPlugins() warnings = %q, want one for plugin broken", warnings)
+	}
+	if _, ok := Get("broken"); ok {
+		t.Error("broken plugin registered")
+	}
+	if _, ok := Registry["bgp"].(*PluginGenerator); ok {
+		t.Error("plugin replaced built-in bgp generator")
+	}
+	gen, ok := Get("widgets")
+	if !ok {
+		t.Fatal("plugin widgets not registered")
+	}
+	g := gen.(*PluginGenerator)
+	if g.Description() != "Widget state" || len(g.paths) != 1 || g.Args()["min"] == "" {
+		t.Errorf("describe = %+v", g)
+	}
+
+	got, err := g.generate(context.Background(), pluginInput{
+		Target: "spine1:6030",
+		Args:   map[string]string{"min": "2"},
+		Values: map[string]string{"/widgets": `{"widget": []}`},
+	})
+	if err != nil {
+		t.Fatalf("generate() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "widget 1 is up" || got[0].ListPath != "status" || got[0].ContainsItem == nil {
+		t.Errorf("generate() = %+v", got)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "input.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var input pluginInput
+	if err := json.Unmarshal(data, &input); err != nil {
+		t.Fatalf("plugin input: %v", err)
+	}
+	if input.Target != "spine1:6030" || input.Args["min"] != "2" || input.Values["/widgets"] == "" {
+		t.Errorf("plugin input = %+v", input)
+	}
+}
+
+func TestPluginGeneratorError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-script plugins")
+	}
+	exe := filepath.Join(t.TempDir(), PluginPrefix+"broken")
+	script := "#!/bin/sh\necho 'device model unsupported' >&2\nexit 1\n"
+	if err := os.WriteFile(exe, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	g := &PluginGenerator{name: "broken", exe: exe}
+	_, err := g.generate(context.Background(), pluginInput{})
+	if err == nil || !strings.Contains(err.Error(), "device model unsupported") {
+		t.Errorf("generate() error = %v, want plugin stderr", err)
+	}
+}
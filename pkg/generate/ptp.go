@@ -0,0 +1,161 @@
+package generate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+)
+
+func init() {
+	Register(&PTPGenerator{})
+}
+
+// DefaultMaxPTPOffset is the offset-from-master threshold when Options.MaxPTPOffset is unset
+const DefaultMaxPTPOffset = time.Microsecond
+
+// PTPGenerator creates assertions for PTP port states, grandmaster, and
+// offset from master. OpenConfig has no PTP model, so this reads the IETF
+// model (RFC 8575) that PTP-capable platforms expose under /ptp.
+type PTPGenerator struct{}
+
+func (g *PTPGenerator) Name() string {
+	return "ptp"
+}
+
+func (g *PTPGenerator) Description() string {
+	return "Generate assertions for PTP port states, grandmaster, and offset from master"
+}
+
+// ptpInstance is one PTP clock instance
+type ptpInstance struct {
+	Number      string
+	Grandmaster string
+	HasOffset   bool
+	Ports       []ptpPort
+}
+
+// ptpPort is a PTP port and its state (master, slave, passive, ...)
+type ptpPort struct {
+	Number string
+	State  string
+}
+
+func (g *PTPGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
+	value, exists, err := client.Get(ctx, "/ptp", opts.Username, opts.Password)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query PTP: %w", err)
+	}
+	if !exists || value == "" {
+		return nil, nil
+	}
+
+	instances, err := g.parsePTP(value)
+	if err != nil {
+		return nil, err
+	}
+
+	maxOffset := opts.MaxPTPOffset
+	if maxOffset == 0 {
+		maxOffset = DefaultMaxPTPOffset
+	}
+	return g.assertions(instances, maxOffset), nil
+}
+
+func (g *PTPGenerator) assertions(instances []ptpInstance, maxOffset time.Duration) []assertion.Assertion {
+	var assertions []assertion.Assertion
+
+	for _, inst := range instances {
+		base := fmt.Sprintf("ptp/instance-list[instance-number=%s]", inst.Number)
+
+		for _, port := range inst.Ports {
+			// Ports in transient or disabled states aren't worth pinning
+			switch strings.ToLower(port.State) {
+			case "master", "slave", "passive":
+			default:
+				continue
+			}
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("PTP port %s is %s", port.Number, port.State),
+				Path:   fmt.Sprintf("%s/port-ds-list[port-number=%s]/port-state", base, port.Number),
+				Equals: strPtr(port.State),
+			})
+		}
+
+		if inst.Grandmaster != "" {
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("PTP grandmaster is %s", inst.Grandmaster),
+				Path:   base + "/parent-ds/grandmaster-identity",
+				Equals: strPtr(inst.Grandmaster),
+			})
+		}
+
+		if inst.HasOffset {
+			// time-interval-type is in scaled nanoseconds (ns * 2^16)
+			scaled := strconv.FormatInt(maxOffset.Nanoseconds()<<16, 10)
+			assertions = append(assertions, assertion.Assertion{
+				Name: fmt.Sprintf("PTP offset from master within %v", maxOffset),
+				Path: base + "/current-ds/offset-from-master",
+				GTE:  strPtr("-" + scaled),
+				LTE:  strPtr(scaled),
+			})
+		}
+	}
+
+	return assertions
+}
+
+func (g *PTPGenerator) parsePTP(jsonData string) ([]ptpInstance, error) {
+	type ptpContainer struct {
+		InstanceList []struct {
+			InstanceNumber json.RawMessage `json:"instance-number"`
+			ParentDS       struct {
+				GrandmasterIdentity string `json:"grandmaster-identity"`
+			} `json:"parent-ds"`
+			CurrentDS struct {
+				OffsetFromMaster json.RawMessage `json:"offset-from-master"`
+			} `json:"current-ds"`
+			PortDSList []struct {
+				PortNumber json.RawMessage `json:"port-number"`
+				PortState  string          `json:"port-state"`
+			} `json:"port-ds-list"`
+		} `json:"instance-list"`
+	}
+
+	// The response may be the ptp container itself or wrapped in it
+	var wrapped struct {
+		PTP *ptpContainer `json:"ptp"`
+	}
+	if err := unmarshalStripped(jsonData, &wrapped); err != nil {
+		return nil, fmt.Errorf("parse PTP: %w", err)
+	}
+	ptp := wrapped.PTP
+	if ptp == nil {
+		ptp = &ptpContainer{}
+		if err := unmarshalStripped(jsonData, ptp); err != nil {
+			return nil, fmt.Errorf("parse PTP: %w", err)
+		}
+	}
+
+	var instances []ptpInstance
+	for _, i := range ptp.InstanceList {
+		inst := ptpInstance{
+			Number:      rawID(i.InstanceNumber),
+			Grandmaster: i.ParentDS.GrandmasterIdentity,
+			HasOffset:   len(i.CurrentDS.OffsetFromMaster) > 0,
+		}
+		for _, p := range i.PortDSList {
+			inst.Ports = append(inst.Ports, ptpPort{Number: rawID(p.PortNumber), State: p.PortState})
+		}
+		instances = append(instances, inst)
+	}
+	return instances, nil
+}
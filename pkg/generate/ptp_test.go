@@ -0,0 +1,42 @@
+package generate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPTPGenerator(t *testing.T) {
+	g := &PTPGenerator{}
+	instances, err := g.parsePTP(`{"ietf-ptp:ptp": {"instance-list": [{
+  "instance-number": 0,
+  "parent-ds": {"grandmaster-identity": "00:1c:73:ff:fe:00:00:01"},
+  "current-ds": {"offset-from-master": "-3276800"},
+  "port-ds-list": [
+    {"port-number": 1, "port-state": "slave"},
+    {"port-number": 2, "port-state": "master"},
+    {"port-number": 3, "port-state": "listening"}
+  ]
+}]}}`)
+	if err != nil {
+		t.Fatalf("parsePTP() error = %v", err)
+	}
+
+	got := g.assertions(instances, time.Microsecond)
+	want := []string{
+		"ptp/instance-list[instance-number=0]/port-ds-list[port-number=1]/port-state",
+		"ptp/instance-list[instance-number=0]/port-ds-list[port-number=2]/port-state",
+		"ptp/instance-list[instance-number=0]/parent-ds/grandmaster-identity",
+		"ptp/instance-list[instance-number=0]/current-ds/offset-from-master",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("assertions() = %d, want %d", len(got), len(want))
+	}
+	for i, a := range got {
+		if a.Path != want[i] {
+			t.Errorf("assertion %d path = %s, want %s", i, a.Path, want[i])
+		}
+	}
+	if offset := got[3]; *offset.GTE != "-65536000" || *offset.LTE != "65536000" {
+		t.Errorf("offset band = [%s, %s], want ±65536000", *offset.GTE, *offset.LTE)
+	}
+}
@@ -0,0 +1,163 @@
+package generate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+)
+
+func init() {
+	Register(&ResourcesGenerator{})
+}
+
+// Resource thresholds, used when Options leaves them unset
+const (
+	DefaultMaxCPU             = 80 // Percent utilization per CPU
+	DefaultMinMemoryFreePct   = 10 // Percent of physical memory
+	resourcesProcessesToWatch = 10 // Busiest processes to assert on
+)
+
+// ResourcesGenerator creates threshold assertions for CPU, memory, and processes
+type ResourcesGenerator struct{}
+
+func (g *ResourcesGenerator) Name() string {
+	return "resources"
+}
+
+func (g *ResourcesGenerator) Description() string {
+	return "Generate threshold assertions for CPU and free memory, and check the busiest processes run"
+}
+
+// systemResources is the subset of /system used for resource checks
+type systemResources struct {
+	CPUs      []string // CPU indexes ("ALL" or a number)
+	Physical  uint64
+	Free      uint64
+	HasFree   bool
+	Processes []processUsage
+}
+
+// processUsage is one process and its utilization
+type processUsage struct {
+	Name   string
+	CPU    uint64 // Percent
+	Memory uint64 // Percent
+}
+
+func (g *ResourcesGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
+	res := &systemResources{}
+	for _, path := range []string{"/system/cpus", "/system/memory", "/system/processes"} {
+		value, exists, err := client.Get(ctx, path, opts.Username, opts.Password)
+		if err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("query %s: %w", path, err)
+		}
+		if !exists || value == "" {
+			continue
+		}
+		if err := g.parse(value, res); err != nil {
+			return nil, err
+		}
+	}
+	maxCPU, minFreePct := opts.MaxCPU, opts.MinMemoryFree
+	if maxCPU == 0 {
+		maxCPU = DefaultMaxCPU
+	}
+	if minFreePct == 0 {
+		minFreePct = DefaultMinMemoryFreePct
+	}
+	return g.assertions(res, maxCPU, minFreePct), nil
+}
+
+func (g *ResourcesGenerator) assertions(res *systemResources, maxCPU, minFreePct int) []assertion.Assertion {
+	var assertions []assertion.Assertion
+
+	for _, cpu := range res.CPUs {
+		assertions = append(assertions, assertion.Assertion{
+			Name: fmt.Sprintf("CPU %s utilization below %d%%", cpu, maxCPU),
+			Path: fmt.Sprintf("system/cpus/cpu[index=%s]/state/total/instant", cpu),
+			LTE:  strPtr(strconv.Itoa(maxCPU)),
+		})
+	}
+
+	if res.HasFree && res.Physical > 0 {
+		minFree := res.Physical * uint64(minFreePct) / 100
+		assertions = append(assertions, assertion.Assertion{
+			Name: fmt.Sprintf("At least %d%% of memory free", minFreePct),
+			Path: "system/memory/state/free",
+			GTE:  strPtr(strconv.FormatUint(minFree, 10)),
+		})
+	}
+
+	// Processes are keyed by PID, which changes whenever one restarts, so
+	// the busiest ones are checked to be running by name instead of
+	// asserting on their utilization leaves
+	seen := make(map[string]bool)
+	for _, p := range res.Processes {
+		if len(seen) >= resourcesProcessesToWatch {
+			break
+		}
+		if p.Name == "" || seen[p.Name] {
+			continue
+		}
+		seen[p.Name] = true
+		assertions = append(assertions, assertion.Assertion{
+			Name:         fmt.Sprintf("Process %s is running", p.Name),
+			Path:         "system/processes",
+			ContainsItem: map[string]any{"state": map[string]any{"name": p.Name}},
+			ListPath:     "process",
+		})
+	}
+
+	return assertions
+}
+
+// parse merges a /system/cpus, /system/memory, or /system/processes response into res
+func (g *ResourcesGenerator) parse(jsonData string, res *systemResources) error {
+	var resp struct {
+		CPU []struct {
+			Index json.RawMessage `json:"index"`
+		} `json:"cpu"`
+		State *struct {
+			Physical json.RawMessage `json:"physical"`
+			Free     json.RawMessage `json:"free"`
+		} `json:"state"`
+		Process []struct {
+			State struct {
+				Name              string          `json:"name"`
+				CPUUtilization    json.RawMessage `json:"cpu-utilization"`
+				MemoryUtilization json.RawMessage `json:"memory-utilization"`
+			} `json:"state"`
+		} `json:"process"`
+	}
+	if err := unmarshalStripped(jsonData, &resp); err != nil {
+		return fmt.Errorf("parse system resources: %w", err)
+	}
+
+	for _, cpu := range resp.CPU {
+		res.CPUs = append(res.CPUs, rawID(cpu.Index))
+	}
+	if resp.State != nil {
+		res.Physical, _ = strconv.ParseUint(rawID(resp.State.Physical), 10, 64)
+		if len(resp.State.Free) > 0 {
+			res.Free, _ = strconv.ParseUint(rawID(resp.State.Free), 10, 64)
+			res.HasFree = true
+		}
+	}
+	for _, p := range resp.Process {
+		cpu, _ := strconv.ParseUint(rawID(p.State.CPUUtilization), 10, 64)
+		mem, _ := strconv.ParseUint(rawID(p.State.MemoryUtilization), 10, 64)
+		res.Processes = append(res.Processes, processUsage{Name: p.State.Name, CPU: cpu, Memory: mem})
+	}
+	sort.SliceStable(res.Processes, func(i, j int) bool {
+		return res.Processes[i].CPU+res.Processes[i].Memory > res.Processes[j].CPU+res.Processes[j].Memory
+	})
+	return nil
+}
@@ -0,0 +1,47 @@
+package generate
+
+import "testing"
+
+func TestResourcesGenerator(t *testing.T) {
+	g := &ResourcesGenerator{}
+	res := &systemResources{}
+	for _, data := range []string{
+		`{"openconfig-system:cpu": [{"index": "ALL"}, {"index": 0}]}`,
+		`{"openconfig-system:state": {"physical": "8000000000", "free": "5000000000"}}`,
+		`{"openconfig-system:process": [
+  {"pid": "10", "state": {"name": "idle", "cpu-utilization": 0, "memory-utilization": 0}},
+  {"pid": "20", "state": {"name": "Bgp", "cpu-utilization": 12, "memory-utilization": 3}},
+  {"pid": "21", "state": {"name": "Bgp", "cpu-utilization": 2, "memory-utilization": 1}}
+]}`,
+	} {
+		if err := g.parse(data, res); err != nil {
+			t.Fatalf("parse() error = %v", err)
+		}
+	}
+
+	got := g.assertions(res, 70, 20)
+	want := []struct{ path, threshold string }{
+		{"system/cpus/cpu[index=ALL]/state/total/instant", "70"},
+		{"system/cpus/cpu[index=0]/state/total/instant", "70"},
+		{"system/memory/state/free", "1600000000"},
+		{"system/processes", "Bgp"},
+		{"system/processes", "idle"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("assertions() = %d, want %d", len(got), len(want))
+	}
+	for i, a := range got {
+		var threshold string
+		switch {
+		case a.LTE != nil:
+			threshold = *a.LTE
+		case a.GTE != nil:
+			threshold = *a.GTE
+		case a.ContainsItem != nil:
+			threshold = a.ContainsItem.(map[string]any)["state"].(map[string]any)["name"].(string)
+		}
+		if a.Path != want[i].path || threshold != want[i].threshold {
+			t.Errorf("assertion %d = %s %s, want %s %s", i, a.Path, threshold, want[i].path, want[i].threshold)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package generate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+)
+
+func init() {
+	Register(&RoutesGenerator{})
+}
+
+// DefaultRouteLimit caps route assertions when neither its limit argument nor Options.Limit is set
+const DefaultRouteLimit = 100
+
+// RoutesGenerator creates assertions for key prefixes in the AFT
+type RoutesGenerator struct{}
+
+func (g *RoutesGenerator) Name() string {
+	return "routes"
+}
+
+func (g *RoutesGenerator) Description() string {
+	return "Generate assertions for default and loopback routes in the AFT"
+}
+
+func (g *RoutesGenerator) Args() map[string]string {
+	return map[string]string{"limit": limitArgDescription}
+}
+
+// routeEntry is one AFT prefix with its resolved next hops
+type routeEntry struct {
+	Prefix   string
+	Protocol string // origin-protocol as reported, e.g. "openconfig-policy-types:BGP"
+	NextHops []string
+}
+
+func (g *RoutesGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
+	path := assertion.ExpandPathFor("network-instance[default]/afts", opts.Platform)
+
+	value, exists, err := client.Get(ctx, path, opts.Username, opts.Password)
+	if err != nil {
+		// Many platforms don't stream the AFT
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query AFT: %w", err)
+	}
+	if !exists || value == "" {
+		return nil, nil
+	}
+
+	routes, err := g.parseAFT(value)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := limitArg(opts, g.Name(), DefaultRouteLimit)
+	if err != nil {
+		return nil, err
+	}
+	return g.assertions(g.keyRoutes(routes), limit), nil
+}
+
+// keyRoutes selects default routes and host routes learned from a routing
+// protocol (typically remote loopbacks), default routes first
+func (g *RoutesGenerator) keyRoutes(routes []routeEntry) []routeEntry {
+	var key []routeEntry
+	for _, r := range routes {
+		prefix, err := netip.ParsePrefix(r.Prefix)
+		if err != nil {
+			continue
+		}
+		isDefault := prefix.Bits() == 0
+		isHost := prefix.Bits() == prefix.Addr().BitLen()
+		switch normalizeAfiSafiName(r.Protocol) {
+		case "DIRECTLY_CONNECTED", "LOCAL_AGGREGATE", "":
+			isHost = false
+		}
+		if isDefault || isHost {
+			key = append(key, r)
+		}
+	}
+
+	sort.SliceStable(key, func(i, j int) bool {
+		pi, _ := netip.ParsePrefix(key[i].Prefix)
+		pj, _ := netip.ParsePrefix(key[j].Prefix)
+		if (pi.Bits() == 0) != (pj.Bits() == 0) {
+			return pi.Bits() == 0
+		}
+		if pi.Addr().Is4() != pj.Addr().Is4() {
+			return pi.Addr().Is4()
+		}
+		return pi.Addr().Less(pj.Addr())
+	})
+	return key
+}
+
+// assertions emits an origin-protocol check per route and a presence check
+// per next hop, up to limit assertions in total. Next-hop-group IDs aren't
+// stable across reboots, so next hops are matched in the AFT next-hop table
+// rather than through the route's group.
+func (g *RoutesGenerator) assertions(routes []routeEntry, limit int) []assertion.Assertion {
+	var assertions []assertion.Assertion
+	seenHops := make(map[string]bool)
+
+	for _, r := range routes {
+		if len(assertions) >= limit {
+			break
+		}
+
+		afi := "ipv4"
+		if strings.Contains(r.Prefix, ":") {
+			afi = "ipv6"
+		}
+		assertions = append(assertions, assertion.Assertion{
+			Name:   fmt.Sprintf("Route %s via %s", r.Prefix, normalizeAfiSafiName(r.Protocol)),
+			Path:   fmt.Sprintf("network-instance[default]/afts/%s-unicast/%s-entry[prefix=%s]/state/origin-protocol", afi, afi, r.Prefix),
+			Equals: strPtr(r.Protocol),
+		})
+
+		for _, hop := range r.NextHops {
+			if seenHops[hop] || len(assertions) >= limit {
+				continue
+			}
+			seenHops[hop] = true
+			assertions = append(assertions, assertion.Assertion{
+				Name:         fmt.Sprintf("Next hop %s is programmed (route %s)", hop, r.Prefix),
+				Path:         "network-instance[default]/afts/next-hops",
+				ContainsItem: map[string]any{"state": map[string]any{"ip-address": hop}},
+				ListPath:     "next-hop",
+			})
+		}
+	}
+
+	return assertions
+}
+
+func (g *RoutesGenerator) parseAFT(jsonData string) ([]routeEntry, error) {
+	var raw any
+	if err := json.Unmarshal([]byte(jsonData), &raw); err != nil {
+		return nil, fmt.Errorf("parse AFT: %w", err)
+	}
+	stripped, err := json.Marshal(stripModulePrefixes(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	type aftEntry struct {
+		Prefix string `json:"prefix"`
+		State  struct {
+			Prefix         string          `json:"prefix"`
+			OriginProtocol string          `json:"origin-protocol"`
+			NextHopGroup   json.RawMessage `json:"next-hop-group"`
+		} `json:"state"`
+	}
+	var aft struct {
+		IPv4Unicast struct {
+			Entry []aftEntry `json:"ipv4-entry"`
+		} `json:"ipv4-unicast"`
+		IPv6Unicast struct {
+			Entry []aftEntry `json:"ipv6-entry"`
+		} `json:"ipv6-unicast"`
+		NextHopGroups struct {
+			NextHopGroup []struct {
+				ID       json.RawMessage `json:"id"`
+				NextHops struct {
+					NextHop []struct {
+						Index json.RawMessage `json:"index"`
+					} `json:"next-hop"`
+				} `json:"next-hops"`
+			} `json:"next-hop-group"`
+		} `json:"next-hop-groups"`
+		NextHops struct {
+			NextHop []struct {
+				Index json.RawMessage `json:"index"`
+				State struct {
+					IPAddress string `json:"ip-address"`
+				} `json:"state"`
+			} `json:"next-hop"`
+		} `json:"next-hops"`
+	}
+
+	// The response may be the afts container itself or wrapped in it
+	var wrapped struct {
+		AFTs json.RawMessage `json:"afts"`
+	}
+	if err := json.Unmarshal(stripped, &wrapped); err == nil && len(wrapped.AFTs) > 0 {
+		stripped = wrapped.AFTs
+	}
+	if err := json.Unmarshal(stripped, &aft); err != nil {
+		return nil, fmt.Errorf("parse AFT: %w", err)
+	}
+
+	hopAddrs := make(map[string]string)
+	for _, nh := range aft.NextHops.NextHop {
+		if nh.State.IPAddress != "" {
+			hopAddrs[rawID(nh.Index)] = nh.State.IPAddress
+		}
+	}
+	groupHops := make(map[string][]string)
+	for _, nhg := range aft.NextHopGroups.NextHopGroup {
+		id := rawID(nhg.ID)
+		for _, nh := range nhg.NextHops.NextHop {
+			if addr, ok := hopAddrs[rawID(nh.Index)]; ok {
+				groupHops[id] = append(groupHops[id], addr)
+			}
+		}
+	}
+
+	var routes []routeEntry
+	for _, e := range append(aft.IPv4Unicast.Entry, aft.IPv6Unicast.Entry...) {
+		prefix := e.Prefix
+		if prefix == "" {
+			prefix = e.State.Prefix
+		}
+		routes = append(routes, routeEntry{
+			Prefix:   prefix,
+			Protocol: e.State.OriginProtocol,
+			NextHops: groupHops[rawID(e.State.NextHopGroup)],
+		})
+	}
+	return routes, nil
+}
+
+// rawID renders a JSON number or string ID (uint64 may be either) as a string
+func rawID(raw json.RawMessage) string {
+	return strings.Trim(string(raw), `"`)
+}
@@ -0,0 +1,47 @@
+package generate
+
+import "testing"
+
+const aftJSON = `{
+  "openconfig-network-instance:ipv4-unicast": {"ipv4-entry": [
+    {"prefix": "10.0.0.2/32", "state": {"prefix": "10.0.0.2/32", "origin-protocol": "openconfig-policy-types:BGP", "next-hop-group": "7"}},
+    {"prefix": "0.0.0.0/0", "state": {"prefix": "0.0.0.0/0", "origin-protocol": "openconfig-policy-types:BGP", "next-hop-group": 7}},
+    {"prefix": "10.0.0.1/32", "state": {"prefix": "10.0.0.1/32", "origin-protocol": "openconfig-policy-types:DIRECTLY_CONNECTED"}},
+    {"prefix": "10.1.0.0/31", "state": {"prefix": "10.1.0.0/31", "origin-protocol": "openconfig-policy-types:DIRECTLY_CONNECTED"}}
+  ]},
+  "openconfig-network-instance:next-hop-groups": {"next-hop-group": [
+    {"id": "7", "next-hops": {"next-hop": [{"index": "1"}, {"index": "2"}]}}
+  ]},
+  "openconfig-network-instance:next-hops": {"next-hop": [
+    {"index": "1", "state": {"ip-address": "10.1.0.0"}},
+    {"index": "2", "state": {"ip-address": "10.1.0.2"}}
+  ]}
+}`
+
+func TestRoutesGenerator(t *testing.T) {
+	g := &RoutesGenerator{}
+	routes, err := g.parseAFT(aftJSON)
+	if err != nil {
+		t.Fatalf("parseAFT() error = %v", err)
+	}
+
+	key := g.keyRoutes(routes)
+	if len(key) != 2 || key[0].Prefix != "0.0.0.0/0" || key[1].Prefix != "10.0.0.2/32" {
+		t.Fatalf("keyRoutes() = %+v, want default then 10.0.0.2/32", key)
+	}
+	if len(key[0].NextHops) != 2 {
+		t.Errorf("default route next hops = %v, want 2", key[0].NextHops)
+	}
+
+	// Route + 2 next hops for the default, then the loopback (hops already seen)
+	all := g.assertions(key, DefaultRouteLimit)
+	if len(all) != 4 {
+		t.Errorf("assertions() = %d, want 4", len(all))
+	}
+	if got := g.assertions(key, 2); len(got) != 2 {
+		t.Errorf("assertions(limit 2) = %d, want 2", len(got))
+	}
+	if *all[0].Equals != "openconfig-policy-types:BGP" || all[0].Name != "Route 0.0.0.0/0 via BGP" {
+		t.Errorf("first assertion = %+v", all[0])
+	}
+}
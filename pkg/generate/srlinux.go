@@ -0,0 +1,221 @@
+package generate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+)
+
+// SR Linux serves its native models whether or not OpenConfig is enabled
+// (containerlab nodes ship without it), so on platforms serving the
+// srl_nokia origin the core generators read native paths and assert on
+// srl-* short paths.
+
+// srlNative reports whether generators should use SR Linux native paths
+func srlNative(opts Options) bool {
+	return opts.Platform.HasOrigin(assertion.OriginSRLNative)
+}
+
+// getSRL queries a native SR Linux path, returning no updates if it doesn't
+// exist
+func getSRL(ctx context.Context, client *gnmiclient.Client, opts Options, path string) ([]gnmiclient.Update, error) {
+	updates, err := client.GetAll(ctx, assertion.OriginSRLNative+":"+path, opts.Username, opts.Password)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query %s: %w", path, err)
+	}
+	return updates, nil
+}
+
+// srlList decodes the entries of a native list into out, a pointer to a
+// slice. SR Linux returns one update per entry with the keys in the update
+// path, but the list may also arrive whole under its name; keys from the
+// path fill in leaves the value leaves out.
+func srlList(updates []gnmiclient.Update, list string, out any) error {
+	var entries []map[string]any
+	for _, u := range updates {
+		keys, last, err := updateKeys(u.Path)
+		if err != nil {
+			return err
+		}
+		if last == list && strings.HasPrefix(u.Value.String(), "[") {
+			var items []map[string]any
+			if err := unmarshalStripped(u.Value.String(), &items); err != nil {
+				return fmt.Errorf("parse %s: %w", u.Path, err)
+			}
+			entries = append(entries, items...)
+			continue
+		}
+		fields, err := updateFields(u, last)
+		if err != nil {
+			return err
+		}
+		if items, ok := fields[list].([]any); ok {
+			for _, item := range items {
+				if entry, ok := item.(map[string]any); ok {
+					entries = append(entries, entry)
+				}
+			}
+			continue
+		}
+		for k, v := range keys[list] {
+			if _, ok := fields[k]; !ok {
+				fields[k] = v
+			}
+		}
+		entries = append(entries, fields)
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("parse %s: %w", list, err)
+	}
+	return nil
+}
+
+func (g *InterfacesGenerator) getSRLInterfaces(ctx context.Context, client *gnmiclient.Client, opts Options) ([]interfaceState, error) {
+	updates, err := getSRL(ctx, client, opts, "/interface")
+	if err != nil {
+		return nil, err
+	}
+	var list []struct {
+		Name       string `json:"name"`
+		AdminState string `json:"admin-state"`
+		OperState  string `json:"oper-state"`
+	}
+	if err := srlList(updates, "interface", &list); err != nil {
+		return nil, err
+	}
+
+	var interfaces []interfaceState
+	for _, i := range list {
+		// admin-state is enable/disable rather than UP/DOWN
+		admin := "UP"
+		if i.AdminState == "disable" {
+			admin = "DOWN"
+		}
+		interfaces = append(interfaces, interfaceState{Name: i.Name, OperStatus: i.OperState, AdminStatus: admin})
+	}
+	sort.Slice(interfaces, func(i, j int) bool { return interfaces[i].Name < interfaces[j].Name })
+	return interfaces, nil
+}
+
+func (g *BGPGenerator) getSRLNeighbors(ctx context.Context, client *gnmiclient.Client, vrf string, opts Options) ([]bgpNeighborState, error) {
+	updates, err := getSRL(ctx, client, opts, fmt.Sprintf("/network-instance[name=%s]/protocols/bgp/neighbor", vrf))
+	if err != nil {
+		return nil, err
+	}
+	var list []struct {
+		PeerAddress  string `json:"peer-address"`
+		SessionState string `json:"session-state"`
+		PeerAS       uint32 `json:"peer-as"`
+	}
+	if err := srlList(updates, "neighbor", &list); err != nil {
+		return nil, err
+	}
+
+	var neighbors []bgpNeighborState
+	for _, n := range list {
+		neighbors = append(neighbors, bgpNeighborState{
+			NeighborAddress: n.PeerAddress,
+			SessionState:    n.SessionState,
+			PeerAS:          n.PeerAS,
+		})
+	}
+	sort.Slice(neighbors, func(i, j int) bool { return neighbors[i].NeighborAddress < neighbors[j].NeighborAddress })
+	return neighbors, nil
+}
+
+// srlAssertions checks each peer's session state at its native path
+func (g *BGPGenerator) srlAssertions(vrf string, neighbors []bgpNeighborState) []assertion.Assertion {
+	label := bgpPeerLabel(vrf)
+
+	var assertions []assertion.Assertion
+	for _, n := range neighbors {
+		assertions = append(assertions, assertion.Assertion{
+			Name:   fmt.Sprintf("%s %s is %s", label, n.NeighborAddress, n.SessionState),
+			Path:   fmt.Sprintf("srl-bgp[%s]/neighbor[peer-address=%s]/session-state", vrf, n.NeighborAddress),
+			Equals: strPtr(n.SessionState),
+		})
+	}
+	return assertions
+}
+
+// srlExistsAssertions checks each peer is configured at its native path
+func (g *BGPGenerator) srlExistsAssertions(vrf string, neighbors []bgpNeighborState) []assertion.Assertion {
+	label := bgpPeerLabel(vrf)
+
+	var assertions []assertion.Assertion
+	for _, n := range neighbors {
+		neighbor := fmt.Sprintf("srl-bgp[%s]/neighbor[peer-address=%s]", vrf, n.NeighborAddress)
+		assertions = append(assertions, peerConfigured(label, n, neighbor+"/peer-as", neighbor+"/peer-address"))
+	}
+	return assertions
+}
+
+func (g *LLDPGenerator) getSRLNeighbors(ctx context.Context, client *gnmiclient.Client, opts Options) ([]lldpNeighbor, error) {
+	updates, err := getSRL(ctx, client, opts, "/system/lldp/interface")
+	if err != nil {
+		return nil, err
+	}
+	var list []struct {
+		Name     string `json:"name"`
+		Neighbor []struct {
+			SystemName string `json:"system-name"`
+			PortID     string `json:"port-id"`
+		} `json:"neighbor"`
+	}
+	if err := srlList(updates, "interface", &list); err != nil {
+		return nil, err
+	}
+
+	var neighbors []lldpNeighbor
+	for _, iface := range list {
+		for _, n := range iface.Neighbor {
+			if n.SystemName != "" {
+				neighbors = append(neighbors, lldpNeighbor{
+					LocalInterface: iface.Name,
+					RemoteSystem:   n.SystemName,
+					RemotePort:     n.PortID,
+				})
+			}
+		}
+	}
+	sort.SliceStable(neighbors, func(i, j int) bool { return neighbors[i].LocalInterface < neighbors[j].LocalInterface })
+	return neighbors, nil
+}
+
+// srlAssertions checks the hostname and software version at their native paths
+func (g *SystemGenerator) srlAssertions(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
+	leaves := []struct {
+		label string
+		path  string
+	}{
+		{"Hostname", "system/name/host-name"},
+		{"Software version", "system/information/version"},
+	}
+
+	var assertions []assertion.Assertion
+	for _, leaf := range leaves {
+		value, exists, err := client.Get(ctx, assertion.OriginSRLNative+":/"+leaf.path, opts.Username, opts.Password)
+		if err != nil || !exists || value == "" {
+			continue
+		}
+		assertions = append(assertions, assertion.Assertion{
+			Name:   fmt.Sprintf("%s is %s", leaf.label, value),
+			Path:   "srl-" + leaf.path,
+			Equals: strPtr(value),
+		})
+	}
+	return assertions, nil
+}
@@ -0,0 +1,84 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+	"github.com/ndtobs/netsert/pkg/value"
+)
+
+func TestSRLList(t *testing.T) {
+	type entry struct {
+		Name      string `json:"name"`
+		OperState string `json:"oper-state"`
+	}
+	tests := []struct {
+		name    string
+		updates []gnmiclient.Update
+		want    []entry
+	}{
+		{
+			name: "entry per update, keys in path",
+			updates: []gnmiclient.Update{
+				{Path: "/srl_nokia-interfaces:interface[name=ethernet-1/1]", Value: value.JSON([]byte(`{"admin-state":"enable","oper-state":"up"}`))},
+				{Path: "/srl_nokia-interfaces:interface[name=mgmt0]", Value: value.JSON([]byte(`{"srl_nokia-interfaces:oper-state":"down"}`))},
+			},
+			want: []entry{{"ethernet-1/1", "up"}, {"mgmt0", "down"}},
+		},
+		{
+			name: "whole list",
+			updates: []gnmiclient.Update{
+				{Path: "/", Value: value.JSON([]byte(`{"srl_nokia-interfaces:interface":[{"name":"ethernet-1/1","oper-state":"up"}]}`))},
+			},
+			want: []entry{{"ethernet-1/1", "up"}},
+		},
+		{
+			name: "bare list",
+			updates: []gnmiclient.Update{
+				{Path: "/interface", Value: value.JSON([]byte(`[{"name":"ethernet-1/2","oper-state":"down"}]`))},
+			},
+			want: []entry{{"ethernet-1/2", "down"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []entry
+			if err := srlList(tt.updates, "interface", &got); err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("entry %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSRLBGPInstances(t *testing.T) {
+	g := &BGPGenerator{}
+	updates := []gnmiclient.Update{
+		{Path: "/network-instance[name=red]/protocols/srl_nokia-bgp:bgp/admin-state", Value: value.String("enable")},
+		{Path: "/network-instance[name=default]/protocols/srl_nokia-bgp:bgp/admin-state", Value: value.String("enable")},
+	}
+	vrfs, err := g.bgpInstances(updates, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vrfs) != 2 || vrfs[0] != "default" || vrfs[1] != "red" {
+		t.Errorf("bgpInstances = %v, want [default red]", vrfs)
+	}
+
+	a := g.srlAssertions("red", []bgpNeighborState{{NeighborAddress: "10.0.0.1", SessionState: "established"}})
+	if len(a) != 1 || a[0].Path != "srl-bgp[red]/neighbor[peer-address=10.0.0.1]/session-state" || a[0].Name != "VRF red BGP peer 10.0.0.1 is established" {
+		t.Errorf("srlAssertions = %+v", a)
+	}
+	if !srlNative(Options{Platform: assertion.PlatformNokiaSRLinux}) || srlNative(Options{Platform: assertion.PlatformAristaEOS}) {
+		t.Error("srlNative should only hold for nokia_srlinux")
+	}
+}
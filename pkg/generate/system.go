@@ -26,6 +26,10 @@ func (g *SystemGenerator) Description() string {
 }
 
 func (g *SystemGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
+	if srlNative(opts) {
+		return g.srlAssertions(ctx, client, opts)
+	}
+
 	var assertions []assertion.Assertion
 
 	// Get hostname
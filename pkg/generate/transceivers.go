@@ -0,0 +1,123 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+)
+
+func init() {
+	Register(&TransceiversGenerator{})
+}
+
+// DOM thresholds. Optical power varies by optic type, so its band is centred
+// on the current reading; temperature and voltage use fixed module limits.
+const (
+	DefaultDOMMargin  = 3.0   // dB either side of the current rx/tx power
+	domMaxTemperature = 70.0  // °C, commercial-grade optic limit
+	domMinVoltage     = 3.135 // V, 3.3V -5%
+	domMaxVoltage     = 3.465 // V, 3.3V +5%
+	domNoLight        = -30.0 // dBm; channels at or below this are dark
+)
+
+// TransceiversGenerator creates assertions for installed optics and their DOM readings
+type TransceiversGenerator struct{}
+
+func (g *TransceiversGenerator) Name() string {
+	return "transceivers"
+}
+
+func (g *TransceiversGenerator) Description() string {
+	return "Generate assertions for optic presence, part numbers, and DOM levels"
+}
+
+func (g *TransceiversGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
+	components, err := getComponents(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	margin := opts.DOMMargin
+	if margin == 0 {
+		margin = DefaultDOMMargin
+	}
+	return g.assertions(components, margin), nil
+}
+
+func (g *TransceiversGenerator) assertions(components []ocComponent, margin float64) []assertion.Assertion {
+	var assertions []assertion.Assertion
+
+	for _, c := range components {
+		xcvr := c.Transceiver.State
+		if normalizeAfiSafiName(xcvr.Present) != "PRESENT" {
+			continue
+		}
+		base := fmt.Sprintf("components/component[name=%s]", c.Name)
+
+		assertions = append(assertions, assertion.Assertion{
+			Name:   fmt.Sprintf("%s transceiver is present", c.Name),
+			Path:   base + "/transceiver/state/present",
+			Equals: strPtr(xcvr.Present),
+		})
+		if xcvr.FormFactor != "" {
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("%s form factor is %s", c.Name, normalizeAfiSafiName(xcvr.FormFactor)),
+				Path:   base + "/transceiver/state/form-factor",
+				Equals: strPtr(xcvr.FormFactor),
+			})
+		}
+		if xcvr.VendorPart != "" {
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("%s part number is %s", c.Name, xcvr.VendorPart),
+				Path:   base + "/transceiver/state/vendor-part",
+				Equals: strPtr(xcvr.VendorPart),
+			})
+		}
+
+		if c.State.Temperature.Instant != nil {
+			assertions = append(assertions, assertion.Assertion{
+				Name: fmt.Sprintf("%s temperature below %.0f°C", c.Name, domMaxTemperature),
+				Path: base + "/state/temperature/instant",
+				LTE:  strPtr(formatDecimal(domMaxTemperature)),
+			})
+		}
+		if xcvr.SupplyVoltage.Instant != nil {
+			assertions = append(assertions, assertion.Assertion{
+				Name: fmt.Sprintf("%s supply voltage within %.3f-%.3fV", c.Name, domMinVoltage, domMaxVoltage),
+				Path: base + "/transceiver/state/supply-voltage/instant",
+				GTE:  strPtr(formatDecimal(domMinVoltage)),
+				LTE:  strPtr(formatDecimal(domMaxVoltage)),
+			})
+		}
+
+		channels := c.Transceiver.PhysicalChannels.Channel
+		sort.Slice(channels, func(i, j int) bool { return channels[i].Index < channels[j].Index })
+		for _, ch := range channels {
+			chBase := fmt.Sprintf("%s/transceiver/physical-channels/channel[index=%d]/state", base, ch.Index)
+			for _, p := range []struct {
+				label string
+				leaf  string
+				value *ocDecimal
+			}{
+				{"rx", "input-power", ch.State.InputPower.Instant},
+				{"tx", "output-power", ch.State.OutputPower.Instant},
+			} {
+				if p.value == nil || float64(*p.value) <= domNoLight {
+					continue
+				}
+				low, high := float64(*p.value)-margin, float64(*p.value)+margin
+				assertions = append(assertions, assertion.Assertion{
+					Name: fmt.Sprintf("%s lane %d %s power within %.2f to %.2f dBm", c.Name, ch.Index, p.label, low, high),
+					Path: chBase + "/" + p.leaf + "/instant",
+					GTE:  strPtr(formatDecimal(low)),
+					LTE:  strPtr(formatDecimal(high)),
+				})
+			}
+		}
+	}
+
+	return assertions
+}
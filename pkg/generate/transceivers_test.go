@@ -0,0 +1,42 @@
+package generate
+
+import "testing"
+
+func TestTransceiversGenerator(t *testing.T) {
+	components, err := parseComponents(`{"openconfig-platform:components": {"component": [
+  {"name": "Ethernet1/1", "state": {"temperature": {"instant": "41.5"}},
+   "transceiver": {
+     "state": {"present": "PRESENT", "form-factor": "openconfig-transport-types:QSFP28", "vendor-part": "QSFP-100G-LR4",
+               "supply-voltage": {"instant": "3.29"}},
+     "physical-channels": {"channel": [
+       {"index": 1, "state": {"input-power": {"instant": "-40.00"}, "output-power": {"instant": "1.10"}}},
+       {"index": 0, "state": {"input-power": {"instant": "-2.31"}, "output-power": {"instant": 0.5}}}
+     ]}}},
+  {"name": "Ethernet2/1", "transceiver": {"state": {"present": "NOT_PRESENT"}}},
+  {"name": "Chassis", "state": {"type": "openconfig-platform-types:CHASSIS"}}
+]}}`)
+	if err != nil {
+		t.Fatalf("parseComponents() error = %v", err)
+	}
+
+	got := (&TransceiversGenerator{}).assertions(components, 3)
+
+	// present, form factor, part, temperature, voltage, lane 0 rx+tx, lane 1 tx (rx dark)
+	if len(got) != 8 {
+		for _, a := range got {
+			t.Log(a.Name)
+		}
+		t.Fatalf("assertions() = %d, want 8", len(got))
+	}
+	rx := got[5]
+	if rx.Path != "components/component[name=Ethernet1/1]/transceiver/physical-channels/channel[index=0]/state/input-power/instant" ||
+		*rx.GTE != "-5.31" || *rx.LTE != "0.69" {
+		t.Errorf("rx power assertion = %s [%s, %s]", rx.Path, *rx.GTE, *rx.LTE)
+	}
+	if voltage := got[4]; *voltage.GTE != "3.135" || *voltage.LTE != "3.465" {
+		t.Errorf("voltage assertion = [%s, %s], want [3.135, 3.465]", *voltage.GTE, *voltage.LTE)
+	}
+	if temp := got[3]; *temp.LTE != "70" {
+		t.Errorf("temperature assertion <= %s, want 70", *temp.LTE)
+	}
+}
@@ -0,0 +1,206 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"gopkg.in/yaml.v3"
+)
+
+// ExpectedUpdate is a value observed by an equals: assertion that failed,
+// to be written as its new expected value
+type ExpectedUpdate struct {
+	Host string // Target host as written in the file
+	Name string // Assertion name; empty for assertions named by their path
+	Path string // Expanded path
+	Old  string // Expected value the assertion failed with
+	New  string // Value the device returned
+}
+
+// UpdateExpected rewrites the equals: values of failed assertions in an
+// assertion file in place, keeping comments, blank lines, quoting, and
+// everything else as written. Only assertions written under their target
+// with a literal one-line equals: of the old value are updated; ones
+// shared through all:, groups:, or templates, or whose value is templated
+// or a block scalar, are left alone. It returns the file and the updates
+// it applied.
+func UpdateExpected(existing []byte, updates []ExpectedUpdate) ([]byte, []ExpectedUpdate, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(existing, &doc); err != nil {
+		return nil, nil, fmt.Errorf("parse existing file: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("parse existing file: expected a mapping with targets")
+	}
+	targets := child(doc.Content[0], "targets")
+	if targets == nil || targets.Kind != yaml.SequenceNode {
+		return existing, nil, nil
+	}
+
+	var applied []ExpectedUpdate
+	var edits []splice
+	used := make(map[*yaml.Node]bool)
+	for _, u := range updates {
+		tnode := findTarget(targets, u.Host)
+		if tnode == nil {
+			continue
+		}
+		list := child(tnode, "assertions")
+		if list == nil || list.Kind != yaml.SequenceNode {
+			continue
+		}
+		platform := assertion.Platform(scalarChild(tnode, "platform"))
+		for _, n := range list.Content {
+			equals := child(n, "equals")
+			if used[n] || equals == nil || equals.Kind != yaml.ScalarNode || equals.Value != u.Old || !sameAssertion(n, u, platform) {
+				continue
+			}
+			edit, ok := scalarSplice(existing, equals, u.New, n.Style&yaml.FlowStyle != 0)
+			if !ok {
+				continue
+			}
+			edits = append(edits, edit)
+			used[n] = true
+			applied = append(applied, u)
+			break
+		}
+	}
+	if len(applied) == 0 {
+		return existing, nil, nil
+	}
+
+	// Spliced from the end so earlier offsets stay put
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	out := slices.Clone(existing)
+	for _, e := range edits {
+		out = slices.Replace(out, e.start, e.end, e.text...)
+	}
+	return out, applied, nil
+}
+
+// splice replaces existing[start:end] with text
+type splice struct {
+	start, end int
+	text       []byte
+}
+
+// scalarSplice finds a single-line scalar in the file it was parsed from
+// and encodes its replacement in the same quoting style, so the rest of
+// the line (and the file) stays as written. Block and multi-line scalars
+// aren't spliced.
+func scalarSplice(src []byte, n *yaml.Node, value string, flow bool) (splice, bool) {
+	if n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return splice{}, false
+	}
+	lineStart := 0
+	for line := 1; line < n.Line; line++ {
+		i := bytes.IndexByte(src[lineStart:], '\n')
+		if i < 0 {
+			return splice{}, false
+		}
+		lineStart += i + 1
+	}
+	line := src[lineStart:]
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	// Columns count characters, not bytes
+	start := 0
+	for col := 1; col < n.Column && start < len(line); col++ {
+		_, size := utf8.DecodeRune(line[start:])
+		start += size
+	}
+	end, ok := scalarEnd(line, start, n.Style, flow)
+	if !ok {
+		return splice{}, false
+	}
+	// The span must decode to the value the node was parsed with
+	var got string
+	if err := yaml.Unmarshal(line[start:end], &got); err != nil || got != n.Value {
+		return splice{}, false
+	}
+
+	style := n.Style & (yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle)
+	if flow && style == 0 {
+		// Plain values may hold the commas and brackets that end flow scalars
+		style = yaml.DoubleQuotedStyle
+	}
+	text, err := encodeScalar(value, style)
+	if err == nil && bytes.Contains(text, []byte("\n")) {
+		// Multi-line values stay on the line, escaped
+		text, err = encodeScalar(value, yaml.DoubleQuotedStyle)
+	}
+	if err != nil {
+		return splice{}, false
+	}
+	return splice{start: lineStart + start, end: lineStart + end, text: text}, true
+}
+
+// scalarEnd returns where the scalar starting at line[start] ends, or
+// false if it doesn't end on this line
+func scalarEnd(line []byte, start int, style yaml.Style, flow bool) (int, bool) {
+	switch {
+	case style&yaml.DoubleQuotedStyle != 0:
+		for i := start + 1; i < len(line); i++ {
+			switch line[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1, true
+			}
+		}
+		return 0, false
+	case style&yaml.SingleQuotedStyle != 0:
+		for i := start + 1; i < len(line); i++ {
+			if line[i] != '\'' {
+				continue
+			}
+			if i+1 < len(line) && line[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1, true
+		}
+		return 0, false
+	}
+	end := len(line)
+	for i := start; i < len(line); i++ {
+		if line[i] == '#' && i > start && (line[i-1] == ' ' || line[i-1] == '\t') {
+			end = i
+			break
+		}
+		if flow && strings.IndexByte(",]}", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+	return start + len(bytes.TrimRight(line[start:end], " \t\r")), true
+}
+
+// encodeScalar encodes a string scalar alone, without its trailing newline
+func encodeScalar(value string, style yaml.Style) ([]byte, error) {
+	data, err := yaml.Marshal(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Style: style})
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(data, []byte("\n")), nil
+}
+
+// sameAssertion reports whether an assertion node is the one an update is
+// for: by name if it has one, otherwise by expanded path. for_each and
+// not: assertions aren't written with the values they were run with.
+func sameAssertion(n *yaml.Node, u ExpectedUpdate, platform assertion.Platform) bool {
+	if child(n, "for_each") != nil || scalarChild(n, "not") == "true" {
+		return false
+	}
+	if name := scalarChild(n, "name"); name != "" || u.Name != "" {
+		return name == u.Name
+	}
+	path := scalarChild(n, "path")
+	return path != "" && assertion.ExpandPathFor(path, platform) == u.Path
+}
@@ -0,0 +1,119 @@
+package generate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUpdateExpected(t *testing.T) {
+	existing := `# Fabric baseline
+targets:
+  - host: spine1
+    assertions:
+      # Pinned after the 4.30 upgrade
+      - name: Software version
+        path: system/state/software-version
+        equals: "4.30.1F"
+      - path: interface[Ethernet1]/state/mtu
+        equals: 9214 # jumbo
+      - name: Hostname
+        path: system/state/hostname
+        equals: "{{ .host }}"
+      - name: Not in maintenance
+        path: interface[Ethernet1]/state/description
+        equals: MAINT
+        not: true
+  - host: spine2
+    assertions:
+      - name: Software version
+        path: system/state/software-version
+        equals: "4.30.1F"
+`
+	updates := []ExpectedUpdate{
+		{Host: "spine1", Name: "Software version", Path: "/system/state/software-version", Old: "4.30.1F", New: "4.31.0F"},
+		{Host: "spine1", Path: "/interfaces/interface[name=Ethernet1]/state/mtu", Old: "9214", New: "1500"},
+		{Host: "spine1", Name: "Hostname", Path: "/system/state/hostname", Old: "spine1", New: "spine1.lab"},
+		{Host: "spine1", Name: "Not in maintenance", Path: "/interfaces/interface[name=Ethernet1]/state/description", Old: "MAINT", New: "MAINT"},
+		{Host: "leaf1", Name: "Software version", Path: "/system/state/software-version", Old: "4.30.1F", New: "4.31.0F"},
+	}
+
+	out, applied, err := UpdateExpected([]byte(existing), updates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 2 {
+		t.Errorf("applied %d updates, want 2 (templated, not:, and unknown targets left alone): %+v", len(applied), applied)
+	}
+
+	updated := string(out)
+	for _, want := range []string{
+		"# Fabric baseline",
+		"# Pinned after the 4.30 upgrade",
+		`equals: "4.31.0F"`,
+		`equals: "1500" # jumbo`,
+		`equals: "{{ .host }}"`,
+		"equals: MAINT",
+	} {
+		if !strings.Contains(updated, want) {
+			t.Errorf("updated file missing %q:\n%s", want, updated)
+		}
+	}
+	// spine2's assertion of the same name keeps its value
+	if strings.Count(updated, `equals: "4.30.1F"`) != 1 {
+		t.Errorf("other target's assertion changed:\n%s", updated)
+	}
+}
+
+func TestUpdateExpectedKeepsLayout(t *testing.T) {
+	existing := `targets:
+  - host: spine1
+    assertions:
+
+      - name: Sänger
+        path: system/state/hostname
+        equals:   'old''s'    # quoted
+
+      - {name: Flow, path: system/state/domain-name, equals: lab}
+      - name: Motd
+        path: system/state/motd-banner
+        equals: "a\tb"
+      - name: Login
+        path: system/state/login-banner
+        equals: |
+          welcome
+`
+	updates := []ExpectedUpdate{
+		{Host: "spine1", Name: "Sänger", Old: "old's", New: "new's"},
+		{Host: "spine1", Name: "Flow", Old: "lab", New: "a, b"},
+		{Host: "spine1", Name: "Motd", Old: "a\tb", New: "two\nlines"},
+		{Host: "spine1", Name: "Login", Old: "welcome\n", New: "hello\n"},
+	}
+	want := `targets:
+  - host: spine1
+    assertions:
+
+      - name: Sänger
+        path: system/state/hostname
+        equals:   'new''s'    # quoted
+
+      - {name: Flow, path: system/state/domain-name, equals: "a, b"}
+      - name: Motd
+        path: system/state/motd-banner
+        equals: "two\nlines"
+      - name: Login
+        path: system/state/login-banner
+        equals: |
+          welcome
+`
+
+	out, applied, err := UpdateExpected([]byte(existing), updates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 3 {
+		t.Errorf("applied %d updates, want 3 (block scalar left alone): %+v", len(applied), applied)
+	}
+	if string(out) != want {
+		t.Errorf("UpdateExpected() =\n%s\nwant\n%s", out, want)
+	}
+}
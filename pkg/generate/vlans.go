@@ -0,0 +1,248 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+)
+
+func init() {
+	Register(&VLANsGenerator{})
+}
+
+// VLANsGenerator creates assertions for VLANs and the trunks carrying them
+type VLANsGenerator struct{}
+
+func (g *VLANsGenerator) Name() string {
+	return "vlans"
+}
+
+func (g *VLANsGenerator) Description() string {
+	return "Generate assertions for VLAN names and status, and trunk VLAN sets"
+}
+
+// vlanState is a VLAN in the default network instance
+type vlanState struct {
+	ID     int
+	Name   string
+	Status string
+}
+
+// trunkState is a trunk port and the VLANs it carries. TrunkVLANs keeps the
+// device's encoding: IDs as numbers, ranges as "low..high" strings.
+type trunkState struct {
+	Interface  string
+	Container  string // "ethernet" or "aggregation"
+	NativeVLAN int
+	TrunkVLANs []any
+}
+
+func (g *VLANsGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
+	path := assertion.ExpandPathFor("network-instance[default]/vlans", opts.Platform)
+	value, exists, err := client.Get(ctx, path, opts.Username, opts.Password)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query VLANs: %w", err)
+	}
+	if !exists || value == "" {
+		return nil, nil
+	}
+	vlans, err := g.parseVLANs(value)
+	if err != nil {
+		return nil, err
+	}
+
+	// Switched-vlan state hangs off each interface; no trunks is not an error
+	var trunks []trunkState
+	value, exists, err = client.Get(ctx, "/interfaces", opts.Username, opts.Password)
+	if err == nil && exists && value != "" {
+		trunks, err = g.parseTrunks(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return g.assertions(vlans, trunks, opts.VLANs), nil
+}
+
+// assertions checks each VLAN's name and status, and each trunk's mode and
+// exact VLAN set. With a VLAN filter, only those VLANs are checked.
+func (g *VLANsGenerator) assertions(vlans []vlanState, trunks []trunkState, only []int) []assertion.Assertion {
+	var assertions []assertion.Assertion
+
+	for _, v := range vlans {
+		if len(only) > 0 && !slices.Contains(only, v.ID) {
+			continue
+		}
+		base := fmt.Sprintf("network-instance[default]/vlans/vlan[vlan-id=%d]/state", v.ID)
+		if v.Name != "" {
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("VLAN %d is named %s", v.ID, v.Name),
+				Path:   base + "/name",
+				Equals: strPtr(v.Name),
+			})
+		} else {
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("VLAN %d exists", v.ID),
+				Path:   base + "/vlan-id",
+				Equals: strPtr(fmt.Sprintf("%d", v.ID)),
+			})
+		}
+		if v.Status != "" {
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("VLAN %d is %s", v.ID, normalizeAfiSafiName(v.Status)),
+				Path:   base + "/status",
+				Equals: strPtr(v.Status),
+			})
+		}
+	}
+
+	// A trunk VLAN set is only meaningful as a whole, so skip trunks when
+	// filtering by VLAN
+	if len(only) > 0 {
+		return assertions
+	}
+	for _, t := range trunks {
+		base := fmt.Sprintf("interface[%s]/%s/switched-vlan/state", t.Interface, t.Container)
+		assertions = append(assertions, assertion.Assertion{
+			Name:       fmt.Sprintf("%s trunks VLANs %s", t.Interface, formatTrunkVLANs(t.TrunkVLANs)),
+			Path:       base + "/trunk-vlans",
+			EqualsJSON: t.TrunkVLANs,
+		})
+		if t.NativeVLAN != 0 {
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("%s native VLAN is %d", t.Interface, t.NativeVLAN),
+				Path:   base + "/native-vlan",
+				Equals: strPtr(fmt.Sprintf("%d", t.NativeVLAN)),
+			})
+		}
+	}
+
+	return assertions
+}
+
+func (g *VLANsGenerator) parseVLANs(jsonData string) ([]vlanState, error) {
+	type vlanList struct {
+		VLAN []struct {
+			VLANID int `json:"vlan-id"`
+			State  struct {
+				VLANID int    `json:"vlan-id"`
+				Name   string `json:"name"`
+				Status string `json:"status"`
+			} `json:"state"`
+		} `json:"vlan"`
+	}
+
+	// The response may be the vlans container itself or wrapped in it
+	var resp struct {
+		vlanList
+		VLANs *vlanList `json:"vlans"`
+	}
+	if err := unmarshalStripped(jsonData, &resp); err != nil {
+		return nil, fmt.Errorf("parse VLANs: %w", err)
+	}
+	list := resp.vlanList
+	if resp.VLANs != nil {
+		list = *resp.VLANs
+	}
+
+	var vlans []vlanState
+	for _, v := range list.VLAN {
+		id := v.VLANID
+		if id == 0 {
+			id = v.State.VLANID
+		}
+		vlans = append(vlans, vlanState{ID: id, Name: v.State.Name, Status: v.State.Status})
+	}
+	sort.Slice(vlans, func(i, j int) bool { return vlans[i].ID < vlans[j].ID })
+	return vlans, nil
+}
+
+func (g *VLANsGenerator) parseTrunks(jsonData string) ([]trunkState, error) {
+	type switchedVLAN struct {
+		SwitchedVLAN struct {
+			State struct {
+				InterfaceMode string `json:"interface-mode"`
+				NativeVLAN    int    `json:"native-vlan"`
+				TrunkVLANs    []any  `json:"trunk-vlans"`
+			} `json:"state"`
+		} `json:"switched-vlan"`
+	}
+	type ifaceList struct {
+		Interface []struct {
+			Name        string       `json:"name"`
+			Ethernet    switchedVLAN `json:"ethernet"`
+			Aggregation switchedVLAN `json:"aggregation"`
+		} `json:"interface"`
+	}
+
+	// The response may be the interfaces container itself or wrapped in it
+	var resp struct {
+		ifaceList
+		Interfaces *ifaceList `json:"interfaces"`
+	}
+	if err := unmarshalStripped(jsonData, &resp); err != nil {
+		return nil, fmt.Errorf("parse interfaces: %w", err)
+	}
+	list := resp.ifaceList
+	if resp.Interfaces != nil {
+		list = *resp.Interfaces
+	}
+
+	var trunks []trunkState
+	for _, iface := range list.Interface {
+		for _, c := range []struct {
+			name  string
+			state switchedVLAN
+		}{
+			{"ethernet", iface.Ethernet},
+			{"aggregation", iface.Aggregation},
+		} {
+			st := c.state.SwitchedVLAN.State
+			if normalizeAfiSafiName(st.InterfaceMode) != "TRUNK" {
+				continue
+			}
+			trunks = append(trunks, trunkState{
+				Interface:  iface.Name,
+				Container:  c.name,
+				NativeVLAN: st.NativeVLAN,
+				TrunkVLANs: trunkVLANList(st.TrunkVLANs),
+			})
+		}
+	}
+	sort.Slice(trunks, func(i, j int) bool { return trunks[i].Interface < trunks[j].Interface })
+	return trunks, nil
+}
+
+// trunkVLANList converts decoded trunk-vlans into ints and range strings so
+// they render cleanly in YAML; an empty set stays an empty list
+func trunkVLANList(raw []any) []any {
+	vlans := make([]any, 0, len(raw))
+	for _, v := range raw {
+		if f, ok := v.(float64); ok {
+			vlans = append(vlans, int(f))
+			continue
+		}
+		vlans = append(vlans, v)
+	}
+	return vlans
+}
+
+// formatTrunkVLANs renders a trunk VLAN set for an assertion name
+func formatTrunkVLANs(vlans []any) string {
+	if len(vlans) == 0 {
+		return "none"
+	}
+	parts := make([]string, len(vlans))
+	for i, v := range vlans {
+		parts[i] = strings.ReplaceAll(fmt.Sprint(v), "..", "-")
+	}
+	return strings.Join(parts, ",")
+}
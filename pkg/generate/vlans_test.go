@@ -0,0 +1,74 @@
+package generate
+
+import (
+	"reflect"
+	"testing"
+)
+
+const vlansJSON = `{"openconfig-network-instance:vlans": {"vlan": [
+  {"vlan-id": 20, "state": {"vlan-id": 20, "name": "SERVERS", "status": "ACTIVE"}},
+  {"vlan-id": 10, "state": {"vlan-id": 10, "name": "MGMT", "status": "ACTIVE"}}
+]}}`
+
+const trunksJSON = `{"openconfig-interfaces:interface": [
+  {"name": "Ethernet1", "ethernet": {"openconfig-vlan:switched-vlan": {"state": {
+    "interface-mode": "TRUNK", "native-vlan": 1, "trunk-vlans": [10, "20..30"]}}}},
+  {"name": "Ethernet2", "ethernet": {"openconfig-vlan:switched-vlan": {"state": {
+    "interface-mode": "ACCESS", "access-vlan": 10}}}},
+  {"name": "Port-Channel1", "aggregation": {"openconfig-vlan:switched-vlan": {"state": {
+    "interface-mode": "openconfig-vlan-types:TRUNK", "trunk-vlans": [20]}}}}
+]}`
+
+func TestVLANsGenerator(t *testing.T) {
+	g := &VLANsGenerator{}
+	vlans, err := g.parseVLANs(vlansJSON)
+	if err != nil {
+		t.Fatalf("parseVLANs() error = %v", err)
+	}
+	trunks, err := g.parseTrunks(trunksJSON)
+	if err != nil {
+		t.Fatalf("parseTrunks() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		only      []int
+		wantPaths []string
+	}{
+		{"all", nil, []string{
+			"network-instance[default]/vlans/vlan[vlan-id=10]/state/name",
+			"network-instance[default]/vlans/vlan[vlan-id=10]/state/status",
+			"network-instance[default]/vlans/vlan[vlan-id=20]/state/name",
+			"network-instance[default]/vlans/vlan[vlan-id=20]/state/status",
+			"interface[Ethernet1]/ethernet/switched-vlan/state/trunk-vlans",
+			"interface[Ethernet1]/ethernet/switched-vlan/state/native-vlan",
+			"interface[Port-Channel1]/aggregation/switched-vlan/state/trunk-vlans",
+		}},
+		{"vlan filter", []int{20}, []string{
+			"network-instance[default]/vlans/vlan[vlan-id=20]/state/name",
+			"network-instance[default]/vlans/vlan[vlan-id=20]/state/status",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := g.assertions(vlans, trunks, tt.only)
+			if len(got) != len(tt.wantPaths) {
+				t.Fatalf("assertions() = %d, want %d", len(got), len(tt.wantPaths))
+			}
+			for i, a := range got {
+				if a.Path != tt.wantPaths[i] {
+					t.Errorf("assertion %d path = %s, want %s", i, a.Path, tt.wantPaths[i])
+				}
+			}
+		})
+	}
+
+	got := g.assertions(vlans, trunks, nil)[4]
+	if want := []any{10, "20..30"}; !reflect.DeepEqual(got.EqualsJSON, want) {
+		t.Errorf("trunk-vlans equals_json = %#v, want %#v", got.EqualsJSON, want)
+	}
+	if want := "Ethernet1 trunks VLANs 10,20-30"; got.Name != want {
+		t.Errorf("trunk assertion name = %q, want %q", got.Name, want)
+	}
+}
@@ -0,0 +1,204 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+	"github.com/ndtobs/netsert/pkg/value"
+)
+
+func init() {
+	Register(&VRFGenerator{})
+}
+
+// VRFGenerator creates assertions for network instances and their protocols
+type VRFGenerator struct{}
+
+func (g *VRFGenerator) Name() string {
+	return "vrf"
+}
+
+func (g *VRFGenerator) Description() string {
+	return "Generate assertions for VRF type, route distinguisher, and enabled protocols"
+}
+
+// vrfState is a network instance and the protocols running in it
+type vrfState struct {
+	Name               string
+	Type               string
+	RouteDistinguisher string
+	Enabled            *bool
+	Protocols          []vrfProtocol
+}
+
+// vrfProtocol is a protocol instance keyed as the device reported it
+type vrfProtocol struct {
+	Identifier string
+	Name       string
+}
+
+func (g *VRFGenerator) Generate(ctx context.Context, client *gnmiclient.Client, opts Options) ([]assertion.Assertion, error) {
+	// Omitted keys match every instance; querying state containers avoids
+	// pulling whole RIBs from /network-instances
+	instances, err := client.GetAll(ctx, "/network-instances/network-instance/state", opts.Username, opts.Password)
+	if err != nil {
+		return nil, fmt.Errorf("query network instances: %w", err)
+	}
+	protocols, err := client.GetAll(ctx, "/network-instances/network-instance/protocols/protocol/state", opts.Username, opts.Password)
+	if err != nil {
+		return nil, fmt.Errorf("query protocols: %w", err)
+	}
+
+	vrfs, err := g.collect(instances, protocols)
+	if err != nil {
+		return nil, err
+	}
+	return g.assertions(vrfs), nil
+}
+
+func (g *VRFGenerator) assertions(vrfs []vrfState) []assertion.Assertion {
+	var assertions []assertion.Assertion
+
+	for _, v := range vrfs {
+		base := fmt.Sprintf("network-instance[%s]", v.Name)
+		if v.Type != "" {
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("VRF %s is %s", v.Name, normalizeAfiSafiName(v.Type)),
+				Path:   base + "/state/type",
+				Equals: strPtr(v.Type),
+			})
+		}
+		if v.RouteDistinguisher != "" {
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("VRF %s route distinguisher is %s", v.Name, v.RouteDistinguisher),
+				Path:   base + "/state/route-distinguisher",
+				Equals: strPtr(v.RouteDistinguisher),
+			})
+		}
+		if v.Enabled != nil && *v.Enabled {
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("VRF %s is enabled", v.Name),
+				Path:   base + "/state/enabled",
+				Equals: strPtr("true"),
+			})
+		}
+		for _, p := range v.Protocols {
+			assertions = append(assertions, assertion.Assertion{
+				Name:   fmt.Sprintf("VRF %s runs %s", v.Name, normalizeAfiSafiName(p.Identifier)),
+				Path:   fmt.Sprintf("%s/protocols/protocol[identifier=%s][name=%s]/state/identifier", base, p.Identifier, p.Name),
+				Exists: boolPtr(true),
+			})
+		}
+	}
+
+	return assertions
+}
+
+// collect merges state updates into per-instance state. Devices return either
+// one JSON container per instance or one update per leaf; both are handled.
+func (g *VRFGenerator) collect(instances, protocols []gnmiclient.Update) ([]vrfState, error) {
+	byName := make(map[string]*vrfState)
+	get := func(name string) *vrfState {
+		v, ok := byName[name]
+		if !ok {
+			v = &vrfState{Name: name}
+			byName[name] = v
+		}
+		return v
+	}
+
+	for _, u := range instances {
+		keys, leaf, err := updateKeys(u.Path)
+		if err != nil {
+			return nil, err
+		}
+		name := keys["network-instance"]["name"]
+		if name == "" {
+			continue
+		}
+		fields, err := updateFields(u, leaf)
+		if err != nil {
+			return nil, err
+		}
+		v := get(name)
+		for k, val := range fields {
+			switch k {
+			case "type":
+				v.Type = fmt.Sprint(val)
+			case "route-distinguisher":
+				v.RouteDistinguisher = fmt.Sprint(val)
+			case "enabled":
+				if b, ok := val.(bool); ok {
+					v.Enabled = &b
+				}
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, u := range protocols {
+		keys, _, err := updateKeys(u.Path)
+		if err != nil {
+			return nil, err
+		}
+		ni, proto := keys["network-instance"]["name"], keys["protocol"]
+		if ni == "" || proto["identifier"] == "" {
+			continue
+		}
+		id := ni + "|" + proto["identifier"] + "|" + proto["name"]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		v := get(ni)
+		v.Protocols = append(v.Protocols, vrfProtocol{Identifier: proto["identifier"], Name: proto["name"]})
+	}
+
+	var vrfs []vrfState
+	for _, v := range byName {
+		sort.Slice(v.Protocols, func(i, j int) bool {
+			return v.Protocols[i].Identifier+v.Protocols[i].Name < v.Protocols[j].Identifier+v.Protocols[j].Name
+		})
+		vrfs = append(vrfs, *v)
+	}
+	sort.Slice(vrfs, func(i, j int) bool { return vrfs[i].Name < vrfs[j].Name })
+	return vrfs, nil
+}
+
+// updateKeys returns the list keys along an update path by element name
+// (every element is present, unkeyed ones with no keys) and the last
+// element's name. Module prefixes on element names, as SR Linux sends, are
+// dropped.
+func updateKeys(path string) (map[string]map[string]string, string, error) {
+	p, err := gnmiclient.ParsePath(path)
+	if err != nil {
+		return nil, "", err
+	}
+	keys := make(map[string]map[string]string)
+	var last string
+	for _, elem := range p.Elem {
+		last = normalizeAfiSafiName(elem.Name)
+		keys[last] = elem.Key
+	}
+	return keys, last, nil
+}
+
+// updateFields returns the leaves carried by an update: the members of a
+// JSON container, or the single leaf the update path ends in
+func updateFields(u gnmiclient.Update, leaf string) (map[string]any, error) {
+	if u.Value.Kind != value.KindJSON {
+		var v any = u.Value.String()
+		if u.Value.Kind == value.KindBool {
+			v = u.Value.String() == "true"
+		}
+		return map[string]any{leaf: v}, nil
+	}
+	fields := make(map[string]any)
+	if err := unmarshalStripped(u.Value.String(), &fields); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", u.Path, err)
+	}
+	return fields, nil
+}
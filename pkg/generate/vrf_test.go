@@ -0,0 +1,53 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+	"github.com/ndtobs/netsert/pkg/value"
+)
+
+func TestVRFGenerator(t *testing.T) {
+	instances := []gnmiclient.Update{
+		// Container-style update
+		{Path: "/network-instances/network-instance[name=default]/state",
+			Value: value.JSON([]byte(`{"openconfig-network-instance:type": "openconfig-network-instance-types:DEFAULT_INSTANCE", "enabled": true}`))},
+		// Leaf-style updates
+		{Path: "/network-instances/network-instance[name=TENANT]/state/type", Value: value.String("openconfig-network-instance-types:L3VRF")},
+		{Path: "/network-instances/network-instance[name=TENANT]/state/route-distinguisher", Value: value.String("10.0.0.11:100")},
+		{Path: "/network-instances/network-instance[name=TENANT]/state/enabled", Value: value.Bool(true)},
+	}
+	protocols := []gnmiclient.Update{
+		{Path: "/network-instances/network-instance[name=TENANT]/protocols/protocol[identifier=BGP][name=BGP]/state/identifier", Value: value.String("BGP")},
+		{Path: "/network-instances/network-instance[name=TENANT]/protocols/protocol[identifier=BGP][name=BGP]/state/name", Value: value.String("BGP")},
+		{Path: "/network-instances/network-instance[name=default]/protocols/protocol[identifier=STATIC][name=STATIC]/state", Value: value.JSON([]byte(`{}`))},
+	}
+
+	g := &VRFGenerator{}
+	vrfs, err := g.collect(instances, protocols)
+	if err != nil {
+		t.Fatalf("collect() error = %v", err)
+	}
+
+	want := []string{
+		"network-instance[TENANT]/state/type",
+		"network-instance[TENANT]/state/route-distinguisher",
+		"network-instance[TENANT]/state/enabled",
+		"network-instance[TENANT]/protocols/protocol[identifier=BGP][name=BGP]/state/identifier",
+		"network-instance[default]/state/type",
+		"network-instance[default]/state/enabled",
+		"network-instance[default]/protocols/protocol[identifier=STATIC][name=STATIC]/state/identifier",
+	}
+	got := g.assertions(vrfs)
+	if len(got) != len(want) {
+		for _, a := range got {
+			t.Log(a.Path)
+		}
+		t.Fatalf("assertions() = %d, want %d", len(got), len(want))
+	}
+	for i, a := range got {
+		if a.Path != want[i] {
+			t.Errorf("assertion %d path = %s, want %s", i, a.Path, want[i])
+		}
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
-	"strings"
 
 	"github.com/ndtobs/netsert/pkg/assertion"
 	"github.com/ndtobs/netsert/pkg/gnmiclient"
@@ -60,7 +59,8 @@ func (g *VXLANGenerator) Generate(ctx context.Context, client *gnmiclient.Client
 	var assertions []assertion.Assertion
 
 	// Note: Arista doesn't expose oper-status for Vxlan interfaces via OpenConfig
-	// so we skip that assertion and focus on config validation
+	// so we skip that assertion and focus on config validation; the eos-native
+	// generator checks it in Sysdb
 
 	// VTEP source interface
 	if vxlan.VTEPSource != "" {
@@ -98,7 +98,7 @@ func (g *VXLANGenerator) getVxlanState(ctx context.Context, client *gnmiclient.C
 
 	value, exists, err := client.Get(ctx, path, opts.Username, opts.Password)
 	if err != nil {
-		if strings.Contains(err.Error(), "NotFound") || strings.Contains(err.Error(), "not found") {
+		if isNotFound(err) {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("query VXLAN interface: %w", err)
@@ -3,15 +3,29 @@ package gnmiclient
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/ndtobs/netsert/pkg/value"
 	"github.com/openconfig/gnmi/proto/gnmi"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
 )
 
 // Client wraps a gNMI client connection
@@ -19,64 +33,363 @@ type Client struct {
 	conn   *grpc.ClientConn
 	client gnmi.GNMIClient
 	target string
+	origin string
+
+	encoding gnmi.Encoding
+
+	retries      int
+	retryBackoff time.Duration
+
+	prefix *gnmi.Path // Optional request prefix carrying the gNMI target name
+
+	pathMapper PathMapper
+
+	rpcCount atomic.Int64 // See Stats
+	rpcNanos atomic.Int64
 }
 
 // Config holds connection configuration
 type Config struct {
-	Address  string
+	Address  string // host:port, unix:///path/to/socket, or any gRPC target (dns:///, passthrough:///)
 	Username string
 	Password string
 	Insecure bool
 	Timeout  time.Duration
+	Origin   string // gNMI origin applied to request paths (e.g., "openconfig")
+	Encoding string // Requested encoding: json_ietf (default), json, proto, ascii, or bytes
+	Proxy    string // Optional socks5://, http://, or ssh:// proxy URL
+
+	KeepaliveTime    time.Duration // Ping interval on idle connections (0 disables keepalives)
+	KeepaliveTimeout time.Duration // Wait for a ping ack before closing (default: 20s)
+	MaxMsgSize       int           // Max receive message size in bytes (default: 4MB)
+	Authority        string        // Override :authority and TLS server name
+	Compression      string        // RPC compression: "gzip" or "" (none)
+
+	// Certificate verification. Setting either enables it; otherwise
+	// server certificates are not verified.
+	ServerName string // Name expected in the server certificate
+	CAFile     string // PEM bundle of trusted CAs (default: system roots)
+
+	Retries      int           // Extra attempts for RPCs failing with UNAVAILABLE
+	RetryBackoff time.Duration // Initial retry/reconnect delay, doubled each attempt (default: 500ms)
+
+	TargetName string // gNMI target field, for gateways multiplexing several devices
+	Prefix     string // Path prefix sent with every request (e.g., "/network-instances")
+
+	// PathMapper rewrites or rejects request paths for the device's
+	// platform (e.g., an assertion.Platform); nil sends paths unchanged
+	PathMapper PathMapper
+
+	// Fixtures, if set, record the device's Get responses, or when
+	// replaying answer Gets in its place without dialing it
+	Fixtures *Fixtures
+}
+
+// PathMapper maps a request path to the path the device serves
+type PathMapper interface {
+	MapPath(path string) (string, error)
 }
 
 // NewClient creates a new gNMI client
 func NewClient(cfg Config) (*Client, error) {
 	var opts []grpc.DialOption
 
+	var prefix *gnmi.Path
+	if cfg.Prefix != "" || cfg.TargetName != "" {
+		var err error
+		if prefix, err = parsePath(cfg.Prefix); err != nil {
+			return nil, fmt.Errorf("parse prefix: %w", err)
+		}
+		prefix.Target = cfg.TargetName
+	}
+	encoding, err := parseEncoding(cfg.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Fixtures != nil && cfg.Fixtures.Replaying() {
+		return &Client{
+			client:     &fixtureClient{fixtures: cfg.Fixtures, target: cfg.Address},
+			target:     cfg.Address,
+			origin:     cfg.Origin,
+			encoding:   encoding,
+			pathMapper: cfg.PathMapper,
+			prefix:     prefix,
+		}, nil
+	}
+
 	if cfg.Insecure {
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	} else {
-		tlsConfig := &tls.Config{
-			InsecureSkipVerify: true, // TODO: proper cert validation
+		tlsConfig, err := tlsConfig(cfg)
+		if err != nil {
+			return nil, err
 		}
 		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
 	}
 
-	if cfg.Timeout == 0 {
-		cfg.Timeout = 10 * time.Second
+	if cfg.Proxy != "" {
+		if strings.HasPrefix(cfg.Address, "unix:") {
+			return nil, fmt.Errorf("proxy %s cannot be used with unix socket target %s", cfg.Proxy, cfg.Address)
+		}
+		dialer, err := proxyDialer(cfg.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithContextDialer(dialer))
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
-	defer cancel()
+	if cfg.KeepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                cfg.KeepaliveTime,
+			Timeout:             cfg.KeepaliveTimeout,
+			PermitWithoutStream: true,
+		}))
+	}
+	if cfg.MaxMsgSize > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(cfg.MaxMsgSize)))
+	}
+	if cfg.Authority != "" {
+		opts = append(opts, grpc.WithAuthority(cfg.Authority))
+	}
+	switch cfg.Compression {
+	case "", "none":
+	case gzip.Name:
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
+	default:
+		return nil, fmt.Errorf("unsupported compression %q (use gzip)", cfg.Compression)
+	}
 
-	conn, err := grpc.DialContext(ctx, cfg.Address, opts...)
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.RetryBackoff == 0 {
+		cfg.RetryBackoff = DefaultRetryBackoff
+	}
+	opts = append(opts, grpc.WithUnaryInterceptor(throttleUnary), grpc.WithStreamInterceptor(throttleStream))
+
+	// Reconnects after a dropped connection follow the same backoff
+	opts = append(opts, grpc.WithConnectParams(grpc.ConnectParams{
+		Backoff: backoff.Config{
+			BaseDelay:  cfg.RetryBackoff,
+			Multiplier: 2,
+			Jitter:     0.5,
+			MaxDelay:   maxRetryBackoff,
+		},
+		MinConnectTimeout: cfg.Timeout,
+	}))
+
+	// Connecting is lazy: an unreachable target fails the first RPC with
+	// UNAVAILABLE, which is retried like any other transient failure
+	conn, err := grpc.DialContext(context.Background(), cfg.Address, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("dial: %w", err)
 	}
 
+	var client gnmi.GNMIClient = gnmi.NewGNMIClient(conn)
+	if cfg.Fixtures != nil {
+		client = &fixtureClient{GNMIClient: client, fixtures: cfg.Fixtures, target: cfg.Address}
+	}
+
 	return &Client{
 		conn:   conn,
-		client: gnmi.NewGNMIClient(conn),
+		client: client,
 		target: cfg.Address,
+		origin: cfg.Origin,
+
+		encoding: encoding,
+
+		pathMapper: cfg.PathMapper,
+
+		retries:      cfg.Retries,
+		retryBackoff: cfg.RetryBackoff,
+
+		prefix: prefix,
 	}, nil
 }
 
+// parseEncoding parses an encoding name as gNMI spells it, in any case
+func parseEncoding(name string) (gnmi.Encoding, error) {
+	if name == "" {
+		return gnmi.Encoding_JSON_IETF, nil
+	}
+	encoding, ok := gnmi.Encoding_value[strings.ToUpper(strings.ReplaceAll(name, "-", "_"))]
+	if !ok {
+		return 0, fmt.Errorf("unknown encoding %q (json_ietf, json, proto, ascii, or bytes)", name)
+	}
+	return gnmi.Encoding(encoding), nil
+}
+
+// tlsConfig builds the TLS settings for a client
+func tlsConfig(cfg Config) (*tls.Config, error) {
+	if cfg.ServerName == "" && cfg.CAFile == "" {
+		return &tls.Config{
+			InsecureSkipVerify: true, // Set server_name or ca_file to verify
+		}, nil
+	}
+
+	tlsCfg := &tls.Config{ServerName: cfg.ServerName}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", cfg.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	return tlsCfg, nil
+}
+
+// Conn returns the underlying connection, e.g. for gNOI clients. Clients
+// replaying fixtures have none.
+func (c *Client) Conn() *grpc.ClientConn {
+	return c.conn
+}
+
 // Close closes the client connection
 func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
 	return c.conn.Close()
 }
 
-// Get performs a gNMI Get request for a single path
+// WaitReady connects, if the client hasn't yet, and waits for the
+// connection to be ready. Failed attempts are retried with the backoff
+// RPCs get, until the retries run out or ctx is done. Clients replaying
+// fixtures are always ready.
+func (c *Client) WaitReady(ctx context.Context) error {
+	if c.conn == nil {
+		return nil
+	}
+	unavailable := func(state connectivity.State) error {
+		return &Error{Kind: ErrUnavailable, Err: fmt.Errorf("%s unreachable (%s)", c.target, strings.ToLower(state.String()))}
+	}
+	for attempt := 0; ; {
+		c.conn.Connect()
+		state := c.conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Shutdown:
+			return fmt.Errorf("%s: connection closed", c.target)
+		case connectivity.TransientFailure:
+			// The connection keeps reconnecting with the same backoff
+			if attempt >= c.retries {
+				return unavailable(state)
+			}
+			waitCtx, cancel := context.WithTimeout(ctx, backoffDelay(c.retryBackoff, attempt))
+			c.conn.WaitForStateChange(waitCtx, state)
+			cancel()
+			attempt++
+		default:
+			c.conn.WaitForStateChange(ctx, state)
+		}
+		if ctx.Err() != nil {
+			return unavailable(c.conn.GetState())
+		}
+	}
+}
+
+// Get performs a gNMI Get request for a single path and returns the value as a string
 func (c *Client) Get(ctx context.Context, path string, username, password string) (string, bool, error) {
-	gnmiPath, err := parsePath(path)
+	v, exists, err := c.GetValue(ctx, path, username, password)
+	if err != nil || !exists {
+		return "", exists, err
+	}
+	return v.String(), true, nil
+}
+
+// GetValue performs a gNMI Get request for a single path and returns the typed value
+func (c *Client) GetValue(ctx context.Context, path string, username, password string) (value.Value, bool, error) {
+	u, exists, err := c.GetUpdate(ctx, path, username, password)
+	return u.Value, exists, err
+}
+
+// GetUpdate performs a gNMI Get request for a single path and returns the
+// typed value along with when the device reported it
+func (c *Client) GetUpdate(ctx context.Context, path string, username, password string) (Update, bool, error) {
+	resp, err := c.get(ctx, path, username, password)
+	if err != nil || resp == nil {
+		return Update{}, false, err
+	}
+
+	if len(resp.Notification) == 0 || len(resp.Notification[0].Update) == 0 {
+		return Update{}, false, nil
+	}
+
+	// JSON-wrapped leaves ("UP", {"oper-status":"UP"}) are unwrapped to scalars
+	return notificationUpdates(resp.Notification[0])[0], true, nil
+}
+
+// Update is one value returned by GetAll or Subscribe with its full path
+type Update struct {
+	Path  string
+	Value value.Value
+
+	Timestamp time.Time // When the device reported it, if it said
+	Deleted   bool      // Subscribe only: the path was removed
+}
+
+// GetAll performs a gNMI Get request and returns every update, for paths
+// with wildcard or omitted keys that match several list entries
+func (c *Client) GetAll(ctx context.Context, path string, username, password string) ([]Update, error) {
+	resp, err := c.get(ctx, path, username, password)
+	if err != nil || resp == nil {
+		return nil, err
+	}
+
+	var updates []Update
+	for _, n := range resp.Notification {
+		updates = append(updates, notificationUpdates(n)...)
+	}
+	return updates, nil
+}
+
+// requestPath maps and parses a path for a request, returning the request
+// prefix (nil if the client sends none) along with it
+func (c *Client) requestPath(path string) (prefix, gnmiPath *gnmi.Path, err error) {
+	if c.pathMapper != nil {
+		if path, err = c.pathMapper.MapPath(path); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	gnmiPath, err = parsePath(path)
 	if err != nil {
-		return "", false, fmt.Errorf("parse path: %w", err)
+		return nil, nil, fmt.Errorf("parse path: %w", err)
+	}
+
+	// An origin named in the path (e.g., eos_native:/Sysdb) wins over the
+	// client's. The origin belongs on the prefix when one is sent.
+	origin := gnmiPath.Origin
+	if origin == "" {
+		origin = c.origin
+	}
+	if c.prefix != nil {
+		prefix = proto.Clone(c.prefix).(*gnmi.Path)
+		prefix.Origin = origin
+		gnmiPath.Origin = ""
+	} else {
+		gnmiPath.Origin = origin
+	}
+	return prefix, gnmiPath, nil
+}
+
+// get sends a Get request, returning a nil response if the path doesn't exist
+func (c *Client) get(ctx context.Context, path string, username, password string) (*gnmi.GetResponse, error) {
+	prefix, gnmiPath, err := c.requestPath(path)
+	if err != nil {
+		return nil, err
 	}
 
 	req := &gnmi.GetRequest{
+		Prefix:   prefix,
 		Path:     []*gnmi.Path{gnmiPath},
-		Encoding: gnmi.Encoding_JSON_IETF,
+		Encoding: c.encoding,
 	}
 
 	// Add credentials to context
@@ -84,27 +397,99 @@ func (c *Client) Get(ctx context.Context, path string, username, password string
 		ctx = metadata.AppendToOutgoingContext(ctx, "username", username, "password", password)
 	}
 
-	resp, err := c.client.Get(ctx, req)
+	var resp *gnmi.GetResponse
+	err = c.withRetry(ctx, func() error {
+		var err error
+		start := time.Now()
+		resp, err = c.client.Get(ctx, req)
+		c.recordRPC(time.Since(start))
+		return err
+	})
 	if err != nil {
-		// Check if it's a "not found" error
-		if strings.Contains(err.Error(), "NotFound") || strings.Contains(err.Error(), "not found") {
-			return "", false, nil
+		// A missing path is a valid answer, not a failure
+		err = Classify(err)
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
 		}
-		return "", false, fmt.Errorf("get: %w", err)
+		return nil, fmt.Errorf("get: %w", err)
 	}
+	return resp, nil
+}
 
-	if len(resp.Notification) == 0 || len(resp.Notification[0].Update) == 0 {
-		return "", false, nil
+// Set sends a gNMI Set updating path to val. Values that are valid JSON
+// (numbers, booleans, objects) are sent as is, anything else as a string.
+func (c *Client) Set(ctx context.Context, path, val string, username, password string) error {
+	prefix, gnmiPath, err := c.requestPath(path)
+	if err != nil {
+		return err
+	}
+
+	raw := []byte(val)
+	if !json.Valid(raw) {
+		if raw, err = json.Marshal(val); err != nil {
+			return err
+		}
+	}
+	req := &gnmi.SetRequest{
+		Prefix: prefix,
+		Update: []*gnmi.Update{{
+			Path: gnmiPath,
+			Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: raw}},
+		}},
+	}
+
+	if username != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "username", username, "password", password)
 	}
 
-	update := resp.Notification[0].Update[0]
-	value := extractValue(update.Val)
+	err = c.withRetry(ctx, func() error {
+		start := time.Now()
+		_, err := c.client.Set(ctx, req)
+		c.recordRPC(time.Since(start))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("set: %w", Classify(err))
+	}
+	return nil
+}
 
-	return value, true, nil
+// ParsePath converts a string path (e.g., /interfaces/interface[name=Ethernet1]/state)
+// to a gNMI Path
+func ParsePath(path string) (*gnmi.Path, error) {
+	return parsePath(path)
 }
 
-// parsePath converts a string path to a gNMI Path
+// PathString renders a gNMI Path as /elem[key=value]/..., with keys sorted
+// and ] and \ in key values escaped
+func PathString(p *gnmi.Path) string {
+	var b strings.Builder
+	for _, elem := range p.GetElem() {
+		b.WriteString("/")
+		b.WriteString(elem.Name)
+		keys := make([]string, 0, len(elem.Key))
+		for k := range elem.Key {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(&b, "[%s=%s]", k, escapeKey.Replace(elem.Key[k]))
+		}
+	}
+	if b.Len() == 0 {
+		return "/"
+	}
+	return b.String()
+}
+
+// parsePath converts a string path, optionally origin-qualified as
+// <origin>:/path, to a gNMI Path
 func parsePath(path string) (*gnmi.Path, error) {
+	var origin string
+	if i := strings.Index(path, ":/"); i > 0 && !strings.ContainsAny(path[:i], "/[") {
+		origin, path = path[:i], path[i+1:]
+	}
+
 	// Remove leading slash
 	path = strings.TrimPrefix(path, "/")
 
@@ -117,31 +502,35 @@ func parsePath(path string) (*gnmi.Path, error) {
 		elems = append(elems, elem)
 	}
 
-	return &gnmi.Path{Elem: elems}, nil
+	return &gnmi.Path{Origin: origin, Elem: elems}, nil
 }
 
-// splitPath splits a path string into segments, respecting brackets
+// splitPath splits a path string into segments, respecting brackets and
+// backslash escapes; escapes are kept for parsePathElem
 func splitPath(path string) []string {
 	var segments []string
 	var current strings.Builder
-	depth := 0
+	inKey := false // Inside [key=value], where only an unescaped ] ends it
+	escaped := false
 
 	for _, r := range path {
-		switch r {
-		case '[':
-			depth++
+		switch {
+		case escaped:
+			escaped = false
+			current.WriteRune(r)
+		case r == '\\':
+			escaped = true
 			current.WriteRune(r)
-		case ']':
-			depth--
+		case r == '[':
+			inKey = true
 			current.WriteRune(r)
-		case '/':
-			if depth == 0 {
-				if current.Len() > 0 {
-					segments = append(segments, current.String())
-					current.Reset()
-				}
-			} else {
-				current.WriteRune(r)
+		case r == ']':
+			inKey = false
+			current.WriteRune(r)
+		case r == '/' && !inKey:
+			if current.Len() > 0 {
+				segments = append(segments, current.String())
+				current.Reset()
 			}
 		default:
 			current.WriteRune(r)
@@ -155,20 +544,22 @@ func splitPath(path string) []string {
 	return segments
 }
 
-// parsePathElem parses a path segment like "interface[name=Ethernet1]"
+// parsePathElem parses a path segment like "interface[name=Ethernet1]".
+// Key values may hold ], [, =, /, or \ escaped with a backslash, e.g.
+// member[name=uplink \[spine1\]]; only ] and \ need escaping.
 func parsePathElem(segment string) (*gnmi.PathElem, error) {
 	elem := &gnmi.PathElem{
 		Key: make(map[string]string),
 	}
 
 	// Find brackets
-	bracketStart := strings.Index(segment, "[")
+	bracketStart := indexUnescaped(segment, '[')
 	if bracketStart == -1 {
-		elem.Name = segment
+		elem.Name = unescape(segment)
 		return elem, nil
 	}
 
-	elem.Name = segment[:bracketStart]
+	elem.Name = unescape(segment[:bracketStart])
 
 	// Parse keys
 	keysPart := segment[bracketStart:]
@@ -176,19 +567,19 @@ func parsePathElem(segment string) (*gnmi.PathElem, error) {
 		if keysPart[0] != '[' {
 			break
 		}
-		end := strings.Index(keysPart, "]")
+		end := indexUnescaped(keysPart, ']')
 		if end == -1 {
 			return nil, fmt.Errorf("unclosed bracket in path segment: %s", segment)
 		}
 
 		kv := keysPart[1:end]
-		eqIdx := strings.Index(kv, "=")
+		eqIdx := indexUnescaped(kv, '=')
 		if eqIdx == -1 {
 			return nil, fmt.Errorf("invalid key-value pair: %s", kv)
 		}
 
-		key := kv[:eqIdx]
-		value := kv[eqIdx+1:]
+		key := unescape(kv[:eqIdx])
+		value := unescape(kv[eqIdx+1:])
 		elem.Key[key] = value
 
 		keysPart = keysPart[end+1:]
@@ -197,30 +588,93 @@ func parsePathElem(segment string) (*gnmi.PathElem, error) {
 	return elem, nil
 }
 
-// extractValue converts a gNMI TypedValue to a string
-func extractValue(val *gnmi.TypedValue) string {
+// indexUnescaped returns the index of the first c in s not escaped with a
+// backslash, or -1
+func indexUnescaped(s string, c byte) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case c:
+			return i
+		}
+	}
+	return -1
+}
+
+// unescape drops the backslashes escaping characters in a path element
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// escapeKey escapes a key value for PathString, so it parses back the same
+var escapeKey = strings.NewReplacer(`\`, `\\`, `]`, `\]`)
+
+// typedValue converts a gNMI TypedValue to a Value
+func typedValue(val *gnmi.TypedValue) value.Value {
 	if val == nil {
-		return ""
+		return value.String("")
 	}
 
 	switch v := val.Value.(type) {
 	case *gnmi.TypedValue_StringVal:
-		return v.StringVal
+		return value.String(v.StringVal)
+	case *gnmi.TypedValue_AsciiVal:
+		return value.String(v.AsciiVal)
 	case *gnmi.TypedValue_IntVal:
-		return fmt.Sprintf("%d", v.IntVal)
+		return value.Int(v.IntVal)
 	case *gnmi.TypedValue_UintVal:
-		return fmt.Sprintf("%d", v.UintVal)
+		return value.Uint(v.UintVal)
 	case *gnmi.TypedValue_BoolVal:
-		return fmt.Sprintf("%t", v.BoolVal)
+		return value.Bool(v.BoolVal)
 	case *gnmi.TypedValue_FloatVal:
-		return fmt.Sprintf("%f", v.FloatVal)
+		// Round-trip through the float32 text form so 0.1 stays 0.1
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(v.FloatVal), 'g', -1, 32), 64)
+		return value.Float(f)
+	case *gnmi.TypedValue_DoubleVal:
+		return value.Float(v.DoubleVal)
+	case *gnmi.TypedValue_DecimalVal:
+		return value.Float(float64(v.DecimalVal.Digits) / math.Pow10(int(v.DecimalVal.Precision)))
 	case *gnmi.TypedValue_JsonVal:
-		return string(v.JsonVal)
+		return value.JSON(v.JsonVal)
 	case *gnmi.TypedValue_JsonIetfVal:
-		return string(v.JsonIetfVal)
-	case *gnmi.TypedValue_AsciiVal:
-		return v.AsciiVal
+		return value.JSON(v.JsonIetfVal)
+	case *gnmi.TypedValue_BytesVal:
+		return value.Bytes(v.BytesVal)
+	case *gnmi.TypedValue_ProtoBytes:
+		return value.Bytes(v.ProtoBytes)
+	case *gnmi.TypedValue_LeaflistVal:
+		return leaflistValue(v.LeaflistVal)
 	default:
-		return fmt.Sprintf("%v", val.Value)
+		return value.String(fmt.Sprintf("%v", val.Value))
+	}
+}
+
+// leaflistValue encodes a leaf-list as a JSON array, the same shape a JSON
+// encoding would return, so equals_json can compare it
+func leaflistValue(list *gnmi.ScalarArray) value.Value {
+	items := make([]any, 0, len(list.GetElement()))
+	for _, elem := range list.GetElement() {
+		v := typedValue(elem)
+		switch v.Kind {
+		case value.KindInt, value.KindUint, value.KindFloat:
+			items = append(items, json.Number(v.String()))
+		case value.KindBool:
+			items = append(items, v.Equal("true"))
+		default:
+			items = append(items, v.String())
+		}
 	}
+	data, _ := json.Marshal(items)
+	return value.JSON(data)
 }
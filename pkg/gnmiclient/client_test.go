@@ -1,8 +1,16 @@
 package gnmiclient
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/value"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestSplitPath(t *testing.T) {
@@ -31,6 +39,16 @@ func TestSplitPath(t *testing.T) {
 			"protocol[identifier=BGP][name=BGP]/neighbors",
 			[]string{"protocol[identifier=BGP][name=BGP]", "neighbors"},
 		},
+		{
+			"slash in key value",
+			"mount-points/mount-point[name=/var/log]/state",
+			[]string{"mount-points", "mount-point[name=/var/log]", "state"},
+		},
+		{
+			"escaped bracket in key value",
+			`interface[name=Port-Channel1]/state/description[text=to spine1 \]/x]/y`,
+			[]string{"interface[name=Port-Channel1]", "state", `description[text=to spine1 \]/x]`, "y"},
+		},
 		{
 			"single element",
 			"system",
@@ -89,6 +107,34 @@ func TestParsePathElem(t *testing.T) {
 			map[string]string{"neighbor-address": "10.0.0.1"},
 			false,
 		},
+		{
+			"escaped brackets",
+			`group[name=uplinks \[spine\]]`,
+			"group",
+			map[string]string{"name": "uplinks [spine]"},
+			false,
+		},
+		{
+			"escaped backslash and equals",
+			`user[name=DOMAIN\\admin][role=a\=b=c]`,
+			"user",
+			map[string]string{"name": `DOMAIN\admin`, "role": "a=b=c"},
+			false,
+		},
+		{
+			"unescaped open bracket",
+			"filter[expr=a[0]",
+			"filter",
+			map[string]string{"expr": "a[0"},
+			false,
+		},
+		{
+			"escaped closing bracket only",
+			`interface[name=Ethernet1\]`,
+			"",
+			nil,
+			true,
+		},
 		{
 			"unclosed bracket",
 			"interface[name=Ethernet1",
@@ -178,3 +224,257 @@ func TestParsePath(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePathOrigin(t *testing.T) {
+	tests := []struct {
+		path       string
+		wantOrigin string
+		wantLen    int
+	}{
+		{"eos_native:/Sysdb/interface/status", "eos_native", 3},
+		{"/interfaces/interface[name=Ethernet1]", "", 2},
+		{"/network-instances/network-instance[name=default]/afts/ipv6-entry[prefix=2001:db8::/64]", "", 4},
+	}
+	for _, tt := range tests {
+		p, err := parsePath(tt.path)
+		if err != nil {
+			t.Fatalf("parsePath(%q) error = %v", tt.path, err)
+		}
+		if p.Origin != tt.wantOrigin || len(p.Elem) != tt.wantLen {
+			t.Errorf("parsePath(%q) = origin %q, %d elements; want %q, %d", tt.path, p.Origin, len(p.Elem), tt.wantOrigin, tt.wantLen)
+		}
+	}
+}
+
+func TestPathString(t *testing.T) {
+	paths := []string{
+		"/interfaces/interface[name=Ethernet1]/state/oper-status",
+		"/network-instances/network-instance[name=default]/protocols/protocol[identifier=BGP][name=BGP]/bgp",
+		"/system/mount-points/mount-point[name=/var/log]/state",
+		`/groups/group[name=uplinks [spine\]]/members`,
+		`/users/user[name=DOMAIN\\admin]`,
+		"/",
+	}
+
+	for _, want := range paths {
+		p, err := parsePath(want)
+		if err != nil {
+			t.Fatalf("parsePath(%q) error = %v", want, err)
+		}
+		if got := PathString(p); got != want {
+			t.Errorf("PathString(parsePath(%q)) = %q", want, got)
+		}
+	}
+}
+
+func TestTypedValueLeaflist(t *testing.T) {
+	val := &gnmi.TypedValue{Value: &gnmi.TypedValue_LeaflistVal{LeaflistVal: &gnmi.ScalarArray{
+		Element: []*gnmi.TypedValue{
+			{Value: &gnmi.TypedValue_UintVal{UintVal: 10}},
+			{Value: &gnmi.TypedValue_StringVal{StringVal: "100..200"}},
+		},
+	}}}
+
+	got := typedValue(val)
+	if got.Kind != value.KindJSON {
+		t.Fatalf("typedValue() kind = %v, want json", got.Kind)
+	}
+	if want := `[10,"100..200"]`; got.String() != want {
+		t.Errorf("typedValue() = %s, want %s", got.String(), want)
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	base := 100 * time.Millisecond
+	for attempt := 0; attempt < 10; attempt++ {
+		want := base << attempt
+		if want > maxRetryBackoff {
+			want = maxRetryBackoff
+		}
+		got := backoffDelay(base, attempt)
+		if got < want/2 || got > want {
+			t.Errorf("backoffDelay(%v, %d) = %v, want within [%v, %v]", base, attempt, got, want/2, want)
+		}
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "connection reset")
+	tests := []struct {
+		name      string
+		retries   int
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{"succeeds after transient failure", 2, []error{unavailable, nil}, 2, false},
+		{"gives up after retries", 1, []error{unavailable, unavailable, unavailable}, 2, true},
+		{"permanent error not retried", 3, []error{status.Error(codes.PermissionDenied, "denied")}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{retries: tt.retries, retryBackoff: time.Millisecond}
+			calls := 0
+			err := c.withRetry(context.Background(), func() error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("withRetry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryUnreachable(t *testing.T) {
+	// Connecting is retried through the first RPC
+	c, err := NewClient(Config{Address: "127.0.0.1:1", Insecure: true, Retries: 2, RetryBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v, want connecting deferred to the first RPC", err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, _, err := c.Get(ctx, "/system/state/hostname", "", ""); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Get() error = %v, want ErrUnavailable", err)
+	}
+	if n := c.Stats().Count; n != 3 {
+		t.Errorf("attempts = %d, want 3 (one plus 2 retries)", n)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	c, err := NewClient(Config{Address: "127.0.0.1:1", Insecure: true, Retries: 2, RetryBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// Every attempt waits on the throttle
+	var calls int
+	ctx := WithThrottle(context.Background(), func(context.Context) error {
+		calls++
+		return nil
+	})
+	c.Get(ctx, "/system/state/hostname", "", "")
+	if calls != 3 {
+		t.Errorf("throttle calls = %d, want 3 (one per attempt)", calls)
+	}
+
+	// A throttle error fails the RPC
+	stop := errors.New("stop")
+	ctx = WithThrottle(context.Background(), func(context.Context) error { return stop })
+	if _, _, err := c.Get(ctx, "/system/state/hostname", "", ""); err == nil {
+		t.Error("Get() with a failing throttle succeeded")
+	}
+}
+
+func TestNewClient_Prefix(t *testing.T) {
+	c, err := NewClient(Config{Address: "127.0.0.1:1", Insecure: true, TargetName: "leaf7", Prefix: "/network-instances"})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer c.Close()
+
+	if c.prefix == nil || c.prefix.Target != "leaf7" {
+		t.Fatalf("prefix = %v, want target leaf7", c.prefix)
+	}
+	if len(c.prefix.Elem) != 1 || c.prefix.Elem[0].Name != "network-instances" {
+		t.Errorf("prefix elems = %v, want [network-instances]", c.prefix.Elem)
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    gnmi.Encoding
+		wantErr bool
+	}{
+		{"", gnmi.Encoding_JSON_IETF, false},
+		{"json", gnmi.Encoding_JSON, false},
+		{"json-ietf", gnmi.Encoding_JSON_IETF, false},
+		{"PROTO", gnmi.Encoding_PROTO, false},
+		{"ascii", gnmi.Encoding_ASCII, false},
+		{"xml", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseEncoding(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseEncoding(%q) = %v, %v, want %v (error: %v)", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	cfg, err := tlsConfig(Config{})
+	if err != nil || !cfg.InsecureSkipVerify {
+		t.Errorf("tlsConfig() without verification settings = %+v, %v; want skip verify", cfg, err)
+	}
+
+	cfg, err = tlsConfig(Config{ServerName: "spine1.example.com"})
+	if err != nil || cfg.InsecureSkipVerify || cfg.ServerName != "spine1.example.com" {
+		t.Errorf("tlsConfig() with server name = %+v, %v", cfg, err)
+	}
+
+	if _, err := tlsConfig(Config{CAFile: "testdata/missing.pem"}); err == nil {
+		t.Error("expected error for missing CA file")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"not found", status.Error(codes.NotFound, "path not found"), ErrNotFound},
+		{"unauthenticated", status.Error(codes.Unauthenticated, "bad password"), ErrUnauthenticated},
+		{"permission denied", status.Error(codes.PermissionDenied, "denied"), ErrUnauthenticated},
+		{"deadline", status.Error(codes.DeadlineExceeded, "timeout"), ErrDeadline},
+		{"context deadline", context.DeadlineExceeded, ErrDeadline},
+		{"unimplemented", status.Error(codes.Unimplemented, "no gnoi"), ErrUnimplemented},
+		{"unavailable", status.Error(codes.Unavailable, "connection refused"), ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Classify(tt.err)
+			if !errors.Is(got, tt.want) {
+				t.Errorf("Classify() = %v, want kind %v", got, tt.want)
+			}
+			if !errors.Is(got, tt.err) {
+				t.Errorf("Classify() lost the original error")
+			}
+		})
+	}
+
+	other := status.Error(codes.Internal, "boom")
+	if got := Classify(other); got != other {
+		t.Errorf("Classify(internal) = %v, want unchanged", got)
+	}
+}
+
+func TestRPCStats(t *testing.T) {
+	c := &Client{}
+	c.recordRPC(10 * time.Millisecond)
+	before := c.Stats()
+	c.recordRPC(20 * time.Millisecond)
+	c.recordRPC(40 * time.Millisecond)
+
+	got := c.Stats().Sub(before)
+	if got.Count != 2 || got.Total != 60*time.Millisecond {
+		t.Errorf("Sub() = %+v, want 2 RPCs in 60ms", got)
+	}
+	if avg := got.Average(); avg != 30*time.Millisecond {
+		t.Errorf("Average() = %v, want 30ms", avg)
+	}
+	if avg := (RPCStats{}).Average(); avg != 0 {
+		t.Errorf("Average() of no RPCs = %v, want 0", avg)
+	}
+}
@@ -0,0 +1,64 @@
+package gnmiclient
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Error kinds returned (wrapped) by client operations; test with errors.Is
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrDeadline        = errors.New("deadline exceeded")
+	ErrUnimplemented   = errors.New("unimplemented")
+	ErrUnavailable     = errors.New("unavailable")
+)
+
+// Error pairs an error kind with the underlying gRPC error
+type Error struct {
+	Kind error // One of the Err* kinds
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// Classify maps a gRPC error to its kind by status code. Errors that match
+// no kind are returned unchanged.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var kind error
+	switch status.Code(err) {
+	case codes.NotFound:
+		kind = ErrNotFound
+	case codes.Unauthenticated, codes.PermissionDenied:
+		kind = ErrUnauthenticated
+	case codes.DeadlineExceeded:
+		kind = ErrDeadline
+	case codes.Unimplemented:
+		kind = ErrUnimplemented
+	case codes.Unavailable:
+		kind = ErrUnavailable
+	default:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+		kind = ErrDeadline
+	}
+
+	if errors.Is(err, kind) {
+		return err
+	}
+	return &Error{Kind: kind, Err: err}
+}
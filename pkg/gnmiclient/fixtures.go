@@ -0,0 +1,250 @@
+package gnmiclient
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Fixtures hold the gNMI Get responses of a run, a JSON file per target in
+// a directory, so the run can be repeated without the devices: recorded
+// with RecordFixtures, served with ReplayFixtures (see Config.Fixtures).
+// Only Gets are recorded; replayed clients fail other RPCs.
+type Fixtures struct {
+	dir    string
+	replay bool
+
+	mu      sync.Mutex
+	targets map[string]*fixtureFile // By address
+}
+
+// fixtureFile is one target's recorded responses
+type fixtureFile struct {
+	Target    string            `json:"target"`
+	Responses []fixtureResponse `json:"responses"`
+}
+
+// fixtureResponse is a Get request and its answer: a response, or the
+// gRPC status it failed with
+type fixtureResponse struct {
+	Prefix   string          `json:"prefix,omitempty"`
+	Path     string          `json:"path"`
+	Encoding string          `json:"encoding,omitempty"`
+	Response json.RawMessage `json:"response,omitempty"` // gnmi.GetResponse as protojson
+	Code     string          `json:"code,omitempty"`
+	Message  string          `json:"message,omitempty"`
+}
+
+// same reports whether two recorded responses answer the same request
+func (r fixtureResponse) same(o fixtureResponse) bool {
+	return r.Prefix == o.Prefix && r.Path == o.Path && r.Encoding == o.Encoding
+}
+
+// RecordFixtures returns fixtures to record into; Save writes them to dir
+func RecordFixtures(dir string) *Fixtures {
+	return &Fixtures{dir: dir, targets: make(map[string]*fixtureFile)}
+}
+
+// ReplayFixtures loads fixtures recorded into dir, to answer Gets with
+func ReplayFixtures(dir string) (*Fixtures, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no fixtures in %s", dir)
+	}
+	f := &Fixtures{dir: dir, replay: true, targets: make(map[string]*fixtureFile)}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read fixtures: %w", err)
+		}
+		var ff fixtureFile
+		if err := json.Unmarshal(data, &ff); err != nil {
+			return nil, fmt.Errorf("parse fixtures %s: %w", file, err)
+		}
+		f.targets[ff.Target] = &ff
+	}
+	return f, nil
+}
+
+// Replaying reports whether the fixtures answer requests rather than
+// record them
+func (f *Fixtures) Replaying() bool {
+	return f.replay
+}
+
+// Len returns the number of responses held
+func (f *Fixtures) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, ff := range f.targets {
+		n += len(ff.Responses)
+	}
+	return n
+}
+
+// unsafeFileChars are replaced in fixture file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// Save writes the recorded responses to the directory, a file per target
+// named after its address, sorted by path
+func (f *Fixtures) Save() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		return err
+	}
+	for target, ff := range f.targets {
+		slices.SortFunc(ff.Responses, func(a, b fixtureResponse) int {
+			return cmp.Or(cmp.Compare(a.Path, b.Path), cmp.Compare(a.Prefix, b.Prefix), cmp.Compare(a.Encoding, b.Encoding))
+		})
+		data, err := json.MarshalIndent(ff, "", "  ")
+		if err != nil {
+			return err
+		}
+		name := unsafeFileChars.ReplaceAllString(target, "_") + ".json"
+		if err := os.WriteFile(filepath.Join(f.dir, name), append(data, '\n'), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// request identifies a Get request in the fixtures; credentials and
+// other metadata aren't part of it
+func request(req *gnmi.GetRequest) fixtureResponse {
+	r := fixtureResponse{Encoding: req.Encoding.String()}
+	if req.Prefix != nil {
+		r.Prefix = originPathString(req.Prefix)
+	}
+	if len(req.Path) > 0 {
+		r.Path = originPathString(req.Path[0])
+	}
+	return r
+}
+
+// originPathString is PathString with the origin, as <origin>:/path
+func originPathString(p *gnmi.Path) string {
+	if p.Origin != "" {
+		return p.Origin + ":" + PathString(p)
+	}
+	return PathString(p)
+}
+
+// record stores a target's answer to a request, replacing any earlier one
+// (e.g., before a retry succeeded)
+func (f *Fixtures) record(target string, req *gnmi.GetRequest, resp *gnmi.GetResponse, err error) {
+	r := request(req)
+	if err != nil {
+		s := status.Convert(err)
+		r.Code, r.Message = s.Code().String(), s.Message()
+	} else if data, merr := protojson.Marshal(resp); merr == nil {
+		r.Response = data
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ff, ok := f.targets[target]
+	if !ok {
+		ff = &fixtureFile{Target: target}
+		f.targets[target] = ff
+	}
+	if i := slices.IndexFunc(ff.Responses, r.same); i >= 0 {
+		ff.Responses[i] = r
+		return
+	}
+	ff.Responses = append(ff.Responses, r)
+}
+
+// lookup answers a request from a target's recorded responses
+func (f *Fixtures) lookup(target string, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	r := request(req)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ff, ok := f.targets[target]
+	if !ok {
+		return nil, status.Errorf(codes.FailedPrecondition, "no fixtures recorded for %s", target)
+	}
+	i := slices.IndexFunc(ff.Responses, r.same)
+	if i < 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "no recorded response for %s", r.Path)
+	}
+	recorded := ff.Responses[i]
+	if recorded.Code != "" {
+		return nil, status.Error(parseCode(recorded.Code), recorded.Message)
+	}
+	resp := &gnmi.GetResponse{}
+	if err := protojson.Unmarshal(recorded.Response, resp); err != nil {
+		return nil, fmt.Errorf("recorded response for %s: %w", r.Path, err)
+	}
+	return resp, nil
+}
+
+// parseCode parses a gRPC status code name as codes.Code.String writes it
+func parseCode(name string) codes.Code {
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		if c.String() == name {
+			return c
+		}
+	}
+	return codes.Unknown
+}
+
+// fixtureClient records the Gets a device answers, or replays them with no
+// device behind it
+type fixtureClient struct {
+	gnmi.GNMIClient // The device; nil when replaying
+
+	fixtures *Fixtures
+	target   string
+}
+
+func (c *fixtureClient) Get(ctx context.Context, req *gnmi.GetRequest, opts ...grpc.CallOption) (*gnmi.GetResponse, error) {
+	if c.GNMIClient == nil {
+		return c.fixtures.lookup(c.target, req)
+	}
+	resp, err := c.GNMIClient.Get(ctx, req, opts...)
+	c.fixtures.record(c.target, req, resp, err)
+	return resp, err
+}
+
+func (c *fixtureClient) Capabilities(ctx context.Context, req *gnmi.CapabilityRequest, opts ...grpc.CallOption) (*gnmi.CapabilityResponse, error) {
+	if c.GNMIClient == nil {
+		return nil, notReplayed("Capabilities")
+	}
+	return c.GNMIClient.Capabilities(ctx, req, opts...)
+}
+
+func (c *fixtureClient) Set(ctx context.Context, req *gnmi.SetRequest, opts ...grpc.CallOption) (*gnmi.SetResponse, error) {
+	if c.GNMIClient == nil {
+		return nil, notReplayed("Set")
+	}
+	return c.GNMIClient.Set(ctx, req, opts...)
+}
+
+func (c *fixtureClient) Subscribe(ctx context.Context, opts ...grpc.CallOption) (gnmi.GNMI_SubscribeClient, error) {
+	if c.GNMIClient == nil {
+		return nil, notReplayed("Subscribe")
+	}
+	return c.GNMIClient.Subscribe(ctx, opts...)
+}
+
+// notReplayed is the error of RPCs fixtures don't hold
+func notReplayed(rpc string) error {
+	return status.Errorf(codes.Unimplemented, "%s isn't replayed from fixtures", rpc)
+}
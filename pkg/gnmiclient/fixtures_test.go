@@ -0,0 +1,79 @@
+package gnmiclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// missingServer answers Gets for /missing with NOT_FOUND, others as getServer
+type missingServer struct {
+	getServer
+}
+
+func (s *missingServer) Get(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	if PathString(req.Path[0]) == "/missing" {
+		return nil, status.Error(codes.NotFound, "no such path")
+	}
+	return s.getServer.Get(ctx, req)
+}
+
+func TestFixturesRecordReplay(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+	srv := grpc.NewServer()
+	gnmi.RegisterGNMIServer(srv, &missingServer{})
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	dir := t.TempDir()
+	const mtu = "/interfaces/interface[name=Ethernet1]/state/mtu"
+	recording := RecordFixtures(dir)
+	c, err := NewClient(Config{Address: lis.Addr().String(), Insecure: true, Fixtures: recording})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{mtu, "/missing"} {
+		if _, _, err := c.GetUpdate(context.Background(), path, "", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.Close()
+	if err := recording.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replayed with the server gone
+	srv.Stop()
+	replay, err := ReplayFixtures(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err = NewClient(Config{Address: lis.Addr().String(), Insecure: true, Fixtures: replay})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	u, exists, err := c.GetUpdate(context.Background(), mtu, "", "")
+	if err != nil || !exists || u.Value.String() != "9214" || u.Timestamp.IsZero() {
+		t.Errorf("GetUpdate(%s) = %+v, %v, %v, want the recorded 9214", mtu, u, exists, err)
+	}
+	if _, exists, err := c.GetUpdate(context.Background(), "/missing", "", ""); err != nil || exists {
+		t.Errorf("GetUpdate(/missing) = %v, %v, want the recorded NOT_FOUND", exists, err)
+	}
+	if _, _, err := c.GetUpdate(context.Background(), "/system/state/hostname", "", ""); err == nil {
+		t.Error("GetUpdate of an unrecorded path succeeded")
+	}
+	if err := c.Set(context.Background(), "/system/config/hostname", "spine1", "", ""); !errors.Is(err, ErrUnimplemented) {
+		t.Errorf("Set() error = %v, want unimplemented", err)
+	}
+}
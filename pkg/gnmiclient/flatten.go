@@ -0,0 +1,129 @@
+package gnmiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/value"
+)
+
+// Flatten expands an update holding a JSON subtree into one update per
+// leaf, with full paths. List entries are keyed as listKeys describes, and
+// module prefixes are dropped. Leaf-lists stay JSON arrays; scalar updates
+// are returned as is.
+func Flatten(u Update) []Update {
+	if u.Value.Kind != value.KindJSON {
+		return []Update{u}
+	}
+	dec := json.NewDecoder(strings.NewReader(u.Value.String()))
+	dec.UseNumber()
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return []Update{u}
+	}
+
+	var leaves []Update
+	flatten(strings.TrimSuffix(u.Path, "/"), tree, func(path string, leaf any) {
+		raw, _ := json.Marshal(leaf)
+		leaves = append(leaves, Update{Path: path, Value: value.Normalize(value.JSON(raw)), Timestamp: u.Timestamp})
+	})
+	return leaves
+}
+
+// flatten walks node, calling leaf for every scalar and leaf-list under path
+func flatten(path string, node any, leaf func(path string, v any)) {
+	switch n := node.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(n))
+		for k := range n {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := n[k]
+			name := path + "/" + stripModule(k)
+			if entries, ok := child.([]any); ok && !isLeafList(entries) {
+				for _, entry := range entries {
+					flatten(name+listKeys(entry), entry, leaf)
+				}
+				continue
+			}
+			flatten(name, child, leaf)
+		}
+	default:
+		if path == "" {
+			path = "/"
+		}
+		leaf(path, n)
+	}
+}
+
+// isLeafList reports whether a JSON array holds scalars rather than list entries
+func isLeafList(entries []any) bool {
+	for _, e := range entries {
+		if _, ok := e.(map[string]any); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// listKeys renders a list entry's keys as path keys. Without a schema the
+// keys are guessed: in OpenConfig they are the scalar members mirrored
+// under config or state, so when the entry has those containers other
+// scalar members aren't keys. Otherwise, as in native models, every scalar
+// member is taken as a key, and an entry with leaves beside its keys gets
+// paths a Get won't find.
+func listKeys(entry any) string {
+	obj, ok := entry.(map[string]any)
+	if !ok {
+		return ""
+	}
+	var keys, mirrored []string
+	for k, v := range obj {
+		switch v.(type) {
+		case map[string]any, []any, nil:
+			continue
+		}
+		key := fmt.Sprintf("[%s=%v]", stripModule(k), v)
+		keys = append(keys, key)
+		if inConfigOrState(obj, stripModule(k)) {
+			mirrored = append(mirrored, key)
+		}
+	}
+	if len(mirrored) > 0 {
+		keys = mirrored
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "")
+}
+
+// inConfigOrState reports whether an entry's config or state container has
+// a member named name
+func inConfigOrState(entry map[string]any, name string) bool {
+	for k, v := range entry {
+		if c := stripModule(k); c != "config" && c != "state" {
+			continue
+		}
+		container, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		for member := range container {
+			if stripModule(member) == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stripModule drops a module prefix from a JSON key
+func stripModule(key string) string {
+	if i := strings.Index(key, ":"); i >= 0 {
+		return key[i+1:]
+	}
+	return key
+}
@@ -0,0 +1,87 @@
+package gnmiclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ndtobs/netsert/pkg/value"
+)
+
+func TestFlatten(t *testing.T) {
+	subtree := `{
+		"openconfig-interfaces:interface": [
+			{"name": "Ethernet1", "state": {"oper-status": "UP", "mtu": 9214}},
+			{"name": "Ethernet2", "state": {"oper-status": "DOWN", "mtu": 1500}}
+		],
+		"tpids": ["0x8100", "0x88a8"]
+	}`
+	got := Flatten(Update{Path: "/interfaces", Value: value.JSON([]byte(subtree))})
+
+	want := []struct{ path, value string }{
+		{"/interfaces/interface[name=Ethernet1]/name", "Ethernet1"},
+		{"/interfaces/interface[name=Ethernet1]/state/mtu", "9214"},
+		{"/interfaces/interface[name=Ethernet1]/state/oper-status", "UP"},
+		{"/interfaces/interface[name=Ethernet2]/name", "Ethernet2"},
+		{"/interfaces/interface[name=Ethernet2]/state/mtu", "1500"},
+		{"/interfaces/interface[name=Ethernet2]/state/oper-status", "DOWN"},
+		{"/interfaces/tpids", `["0x8100","0x88a8"]`},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Flatten() = %+v, want %d leaves", got, len(want))
+	}
+	for i, w := range want {
+		if got[i].Path != w.path || got[i].Value.String() != w.value {
+			t.Errorf("leaf %d = %s = %s, want %s = %s", i, got[i].Path, got[i].Value, w.path, w.value)
+		}
+	}
+
+	scalar := Update{Path: "/system/state/hostname", Value: value.String("spine1")}
+	if got := Flatten(scalar); len(got) != 1 || got[0] != scalar {
+		t.Errorf("Flatten(scalar) = %+v, want it unchanged", got)
+	}
+}
+
+func TestFlattenListKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry string
+		want  string
+	}{
+		{
+			name:  "keys mirrored under config",
+			entry: `{"name": "Ethernet1", "config": {"name": "Ethernet1", "mtu": 9214}}`,
+			want:  "[name=Ethernet1]",
+		},
+		{
+			name:  "extra entry leaves aren't keys",
+			entry: `{"name": "Ethernet1", "ifindex": 1, "state": {"name": "Ethernet1", "oper-status": "UP"}}`,
+			want:  "[name=Ethernet1]",
+		},
+		{
+			name:  "composite keys under state",
+			entry: `{"identifier": "BGP", "name": "BGP", "extra": "x", "openconfig-network-instance:state": {"openconfig-network-instance:identifier": "BGP", "name": "BGP"}}`,
+			want:  "[identifier=BGP][name=BGP]",
+		},
+		{
+			name:  "no config or state: every scalar",
+			entry: `{"name": "ethernet-1/1", "admin-state": "enable"}`,
+			want:  "[admin-state=enable][name=ethernet-1/1]",
+		},
+		{
+			name:  "nothing mirrored: every scalar",
+			entry: `{"name": "Ethernet1", "state": {"mtu": 9214}}`,
+			want:  "[name=Ethernet1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entry any
+			if err := json.Unmarshal([]byte(tt.entry), &entry); err != nil {
+				t.Fatal(err)
+			}
+			if got := listKeys(entry); got != tt.want {
+				t.Errorf("listKeys() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
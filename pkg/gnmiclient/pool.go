@@ -0,0 +1,126 @@
+package gnmiclient
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+)
+
+// DefaultIdleTimeout is how long a pooled connection may sit unused
+const DefaultIdleTimeout = 5 * time.Minute
+
+// Pool keeps clients open between runs so repeated runs (watch, soak)
+// don't redial every device each cycle. It is safe for concurrent use.
+type Pool struct {
+	IdleTimeout time.Duration // Evict clients unused for this long (default: 5m)
+
+	mu      sync.Mutex
+	clients map[string]*pooledClient
+}
+
+type pooledClient struct {
+	client   *Client
+	lastUsed time.Time
+}
+
+// NewPool creates an empty pool
+func NewPool(idleTimeout time.Duration) *Pool {
+	if idleTimeout == 0 {
+		idleTimeout = DefaultIdleTimeout
+	}
+	return &Pool{
+		IdleTimeout: idleTimeout,
+		clients:     make(map[string]*pooledClient),
+	}
+}
+
+// Get returns an open client for cfg, dialing a new one if none is pooled
+// or the pooled connection has failed. Callers must not Close it.
+func (p *Pool) Get(cfg Config) (*Client, error) {
+	key := poolKey(cfg)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.evictIdleLocked()
+
+	if pc, ok := p.clients[key]; ok {
+		if pc.client.Healthy() {
+			pc.lastUsed = time.Now()
+			return pc.client, nil
+		}
+		pc.client.Close()
+		delete(p.clients, key)
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	p.clients[key] = &pooledClient{client: client, lastUsed: time.Now()}
+	return client, nil
+}
+
+// EvictIdle closes clients unused for longer than IdleTimeout and returns
+// how many were closed
+func (p *Pool) EvictIdle() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.evictIdleLocked()
+}
+
+func (p *Pool) evictIdleLocked() int {
+	evicted := 0
+	for key, pc := range p.clients {
+		if time.Since(pc.lastUsed) > p.IdleTimeout {
+			pc.client.Close()
+			delete(p.clients, key)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// Len returns the number of pooled clients
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.clients)
+}
+
+// Close closes every pooled client
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var firstErr error
+	for key, pc := range p.clients {
+		if err := pc.client.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(p.clients, key)
+	}
+	return firstErr
+}
+
+// Healthy reports whether the underlying connection is usable or recovering
+// normally, as opposed to failed or shut down
+func (c *Client) Healthy() bool {
+	if c.conn == nil {
+		return true
+	}
+	switch c.conn.GetState() {
+	case connectivity.TransientFailure, connectivity.Shutdown:
+		return false
+	}
+	return true
+}
+
+// poolKey identifies clients that can be shared: ones with every setting
+// the same, save the password, which is sent with each request
+func poolKey(cfg Config) string {
+	cfg.Password = ""
+	return fmt.Sprintf("%#v", cfg)
+}
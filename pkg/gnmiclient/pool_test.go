@@ -0,0 +1,69 @@
+package gnmiclient
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPool(t *testing.T) {
+	pool := NewPool(time.Hour)
+	defer pool.Close()
+
+	cfg := Config{Address: "127.0.0.1:1", Insecure: true}
+	first, err := pool.Get(cfg)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	second, err := pool.Get(cfg)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if first != second {
+		t.Error("Get() dialed a new client for the same target")
+	}
+
+	cfg.Username = "other"
+	if other, _ := pool.Get(cfg); other == first {
+		t.Error("Get() shared a client across usernames")
+	}
+	if pool.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", pool.Len())
+	}
+
+	pool.IdleTimeout = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	if n := pool.EvictIdle(); n != 2 {
+		t.Errorf("EvictIdle() = %d, want 2", n)
+	}
+	if pool.Len() != 0 {
+		t.Errorf("Len() after eviction = %d, want 0", pool.Len())
+	}
+}
+
+func TestPoolKey(t *testing.T) {
+	base := Config{Address: "spine1:6030", Username: "admin", Password: "secret"}
+	for name, change := range map[string]func(*Config){
+		"authority":         func(c *Config) { c.Authority = "spine1.example.com" },
+		"compression":       func(c *Config) { c.Compression = "gzip" },
+		"max message size":  func(c *Config) { c.MaxMsgSize = 64 << 20 },
+		"keepalive time":    func(c *Config) { c.KeepaliveTime = 30 * time.Second },
+		"keepalive timeout": func(c *Config) { c.KeepaliveTimeout = 5 * time.Second },
+		"retries":           func(c *Config) { c.Retries = 5 },
+		"retry backoff":     func(c *Config) { c.RetryBackoff = time.Second },
+		"timeout":           func(c *Config) { c.Timeout = time.Minute },
+		"fixtures":          func(c *Config) { c.Fixtures = RecordFixtures(t.TempDir()) },
+	} {
+		other := base
+		change(&other)
+		if poolKey(other) == poolKey(base) {
+			t.Errorf("%s: configs share a pooled client", name)
+		}
+	}
+
+	// Credentials go with each request, so the password doesn't matter
+	other := base
+	other.Password = "rotated"
+	if poolKey(other) != poolKey(base) {
+		t.Error("configs differing only in password don't share a pooled client")
+	}
+}
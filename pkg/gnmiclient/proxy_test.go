@@ -0,0 +1,246 @@
+package gnmiclient
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// echoServer echoes back what each connection sends
+func echoServer(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// pipe copies between two connections until either closes
+func pipe(a, b io.ReadWriteCloser) {
+	defer a.Close()
+	defer b.Close()
+	go io.Copy(a, b)
+	io.Copy(b, a)
+}
+
+// checkEcho sends a line through conn and expects it back
+func checkEcho(t *testing.T, conn net.Conn) {
+	t.Helper()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write([]byte("hello\n")); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || line != "hello\n" {
+		t.Fatalf("echo = %q, %v", line, err)
+	}
+}
+
+func TestProxyDialerErrors(t *testing.T) {
+	for _, proxyURL := range []string{"ftp://proxy:21", "://bad"} {
+		if _, err := proxyDialer(proxyURL); err == nil {
+			t.Errorf("proxyDialer(%q) succeeded, want an error", proxyURL)
+		}
+	}
+}
+
+func TestHTTPConnectProxy(t *testing.T) {
+	target := echoServer(t)
+	var auth atomic.Value
+	proxySrv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth.Store(r.Header.Get("Proxy-Authorization"))
+		if r.Method != http.MethodConnect || r.Host != target {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		pipe(conn, upstream)
+	})}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go proxySrv.Serve(ln)
+	t.Cleanup(func() { proxySrv.Close() })
+
+	dial, err := proxyDialer("http://user:secret@" + ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := dial(context.Background(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	checkEcho(t, conn)
+	if got := auth.Load(); got != "Basic dXNlcjpzZWNyZXQ=" {
+		t.Errorf("Proxy-Authorization = %q", got)
+	}
+
+	if _, err := dial(context.Background(), "127.0.0.1:1"); err == nil {
+		t.Error("CONNECT to a refused target succeeded")
+	}
+}
+
+func TestSSHProxy(t *testing.T) {
+	target := echoServer(t)
+
+	// A user key held only by an agent
+	_, userKey, _ := ed25519.GenerateKey(rand.Reader)
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: userKey}); err != nil {
+		t.Fatal(err)
+	}
+	userSigner, _ := ssh.NewSignerFromKey(userKey)
+
+	dir := t.TempDir()
+	sock := filepath.Join(dir, "agent.sock")
+	agentLn, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { agentLn.Close() })
+	var agentConns atomic.Int32
+	go func() {
+		for {
+			conn, err := agentLn.Accept()
+			if err != nil {
+				return
+			}
+			agentConns.Add(1)
+			go func() {
+				agent.ServeAgent(keyring, conn)
+				agentConns.Add(-1)
+			}()
+		}
+	}()
+
+	// A bastion accepting that key and forwarding direct-tcpip channels
+	_, hostKey, _ := ed25519.GenerateKey(rand.Reader)
+	hostSigner, _ := ssh.NewSignerFromKey(hostKey)
+	serverCfg := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(userSigner.PublicKey().Marshal()) {
+				return nil, io.EOF
+			}
+			return nil, nil
+		},
+	}
+	serverCfg.AddHostKey(hostSigner)
+	bastionLn, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { bastionLn.Close() })
+	go func() {
+		for {
+			conn, err := bastionLn.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, serverCfg)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for nc := range chans {
+					var forward struct {
+						Host     string
+						Port     uint32
+						OrigHost string
+						OrigPort uint32
+					}
+					if nc.ChannelType() != "direct-tcpip" || ssh.Unmarshal(nc.ExtraData(), &forward) != nil {
+						nc.Reject(ssh.UnknownChannelType, "unsupported")
+						continue
+					}
+					upstream, err := net.Dial("tcp", net.JoinHostPort(forward.Host, strconv.Itoa(int(forward.Port))))
+					if err != nil {
+						nc.Reject(ssh.ConnectionFailed, err.Error())
+						continue
+					}
+					ch, creqs, err := nc.Accept()
+					if err != nil {
+						upstream.Close()
+						continue
+					}
+					go ssh.DiscardRequests(creqs)
+					go pipe(ch, upstream)
+				}
+			}()
+		}
+	}()
+
+	// The client trusts the bastion through known_hosts in $HOME
+	bastion := bastionLn.Addr().String()
+	if err := os.MkdirAll(filepath.Join(dir, ".ssh"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	line := knownhosts.Line([]string{knownhosts.Normalize(bastion)}, hostSigner.PublicKey())
+	if err := os.WriteFile(filepath.Join(dir, ".ssh", "known_hosts"), []byte(line+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", dir)
+	t.Setenv("SSH_AUTH_SOCK", sock)
+
+	dial, err := proxyDialer("ssh://netops@" + bastion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		conn, err := dial(context.Background(), target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkEcho(t, conn)
+		conn.Close()
+	}
+
+	// Each dial hangs up on the agent once signed in
+	deadline := time.Now().Add(5 * time.Second)
+	for agentConns.Load() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := agentConns.Load(); n != 0 {
+		t.Errorf("%d agent connection(s) left open", n)
+	}
+}
@@ -0,0 +1,53 @@
+package gnmiclient
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// Retry defaults
+const (
+	DefaultRetryBackoff = 500 * time.Millisecond // Delay before the first retry
+	maxRetryBackoff     = 10 * time.Second
+)
+
+// retryable reports whether err is a transient transport failure worth retrying.
+// Deadlines, auth failures and unsupported RPCs are never retried.
+func retryable(err error) bool {
+	return errors.Is(Classify(err), ErrUnavailable)
+}
+
+// backoffDelay returns the exponential delay before retry attempt n (0-based),
+// with jitter spreading it over [d/2, d) so targets don't retry in lockstep
+func backoffDelay(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		base = DefaultRetryBackoff
+	}
+	d := base << attempt
+	if d <= 0 || d > maxRetryBackoff {
+		d = maxRetryBackoff
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// withRetry calls fn until it succeeds, fails permanently, the client's retry
+// budget is spent, or ctx is done
+func (c *Client) withRetry(ctx context.Context, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= c.retries || !retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(backoffDelay(c.retryBackoff, attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
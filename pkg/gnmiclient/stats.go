@@ -0,0 +1,37 @@
+package gnmiclient
+
+import (
+	"time"
+)
+
+// RPCStats counts a client's gNMI RPCs (each retry attempt is one) and the
+// time spent waiting on them
+type RPCStats struct {
+	Count int64
+	Total time.Duration
+}
+
+// Average returns the mean RPC latency
+func (s RPCStats) Average() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// Sub returns the RPCs made since an earlier snapshot of the same client
+func (s RPCStats) Sub(earlier RPCStats) RPCStats {
+	return RPCStats{Count: s.Count - earlier.Count, Total: s.Total - earlier.Total}
+}
+
+// Stats returns the RPCs the client has made so far. Pooled clients keep
+// counting across runs; diff two snapshots for one run's share.
+func (c *Client) Stats() RPCStats {
+	return RPCStats{Count: c.rpcCount.Load(), Total: time.Duration(c.rpcNanos.Load())}
+}
+
+// recordRPC counts one RPC that took d
+func (c *Client) recordRPC(d time.Duration) {
+	c.rpcCount.Add(1)
+	c.rpcNanos.Add(int64(d))
+}
@@ -0,0 +1,164 @@
+package gnmiclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/value"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// SubscribeMode selects how a subscription delivers updates
+type SubscribeMode string
+
+const (
+	SubscribeStream SubscribeMode = "stream" // Updates as they happen, until cancelled
+	SubscribeOnce   SubscribeMode = "once"   // Current values, then done
+	SubscribePoll   SubscribeMode = "poll"   // Current values every PollInterval
+)
+
+// SubscribeOptions configure Subscribe
+type SubscribeOptions struct {
+	Mode SubscribeMode
+
+	// Stream subscriptions sample every SampleInterval, or report changes
+	// when it is 0
+	SampleInterval time.Duration
+
+	PollInterval time.Duration // Poll subscriptions (default: 10s)
+}
+
+// Subscribe subscribes to paths and calls fn with every update, with
+// Timestamp set and Deleted marking removed paths. It returns when a once
+// subscription completes, fn returns an error, or ctx is cancelled.
+func (c *Client) Subscribe(ctx context.Context, paths []string, opts SubscribeOptions, username, password string, fn func(Update) error) error {
+	req, err := c.subscribeRequest(paths, opts)
+	if err != nil {
+		return err
+	}
+
+	if username != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "username", username, "password", password)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.client.Subscribe(ctx)
+	if err != nil {
+		return fmt.Errorf("subscribe: %w", Classify(err))
+	}
+	if err := stream.Send(req); err != nil {
+		return fmt.Errorf("subscribe: %w", Classify(err))
+	}
+
+	pollInterval := opts.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = 10 * time.Second
+	}
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("subscribe: %w", Classify(err))
+		}
+
+		switch r := resp.Response.(type) {
+		case *gnmi.SubscribeResponse_Update:
+			for _, u := range notificationUpdates(r.Update) {
+				if err := fn(u); err != nil {
+					return err
+				}
+			}
+		case *gnmi.SubscribeResponse_SyncResponse:
+			switch opts.Mode {
+			case SubscribeOnce:
+				return nil
+			case SubscribePoll:
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-time.After(pollInterval):
+				}
+				poll := &gnmi.SubscribeRequest{Request: &gnmi.SubscribeRequest_Poll{Poll: &gnmi.Poll{}}}
+				if err := stream.Send(poll); err != nil {
+					return fmt.Errorf("poll: %w", Classify(err))
+				}
+			}
+		}
+	}
+}
+
+// subscribeRequest builds the subscription for paths. Paths share the
+// request prefix, so they must agree on its origin.
+func (c *Client) subscribeRequest(paths []string, opts SubscribeOptions) (*gnmi.SubscribeRequest, error) {
+	list := &gnmi.SubscriptionList{Encoding: c.encoding}
+	switch opts.Mode {
+	case SubscribeStream, "":
+		list.Mode = gnmi.SubscriptionList_STREAM
+	case SubscribeOnce:
+		list.Mode = gnmi.SubscriptionList_ONCE
+	case SubscribePoll:
+		list.Mode = gnmi.SubscriptionList_POLL
+	default:
+		return nil, fmt.Errorf("unknown subscribe mode %q (stream, once, or poll)", opts.Mode)
+	}
+
+	for i, path := range paths {
+		prefix, gnmiPath, err := c.requestPath(path)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			list.Prefix = prefix
+		} else if !proto.Equal(prefix, list.Prefix) {
+			return nil, fmt.Errorf("paths %s and %s have different origins", paths[0], path)
+		}
+
+		sub := &gnmi.Subscription{Path: gnmiPath}
+		if list.Mode == gnmi.SubscriptionList_STREAM {
+			sub.Mode = gnmi.SubscriptionMode_ON_CHANGE
+			if opts.SampleInterval > 0 {
+				sub.Mode = gnmi.SubscriptionMode_SAMPLE
+				sub.SampleInterval = uint64(opts.SampleInterval.Nanoseconds())
+			}
+		}
+		list.Subscription = append(list.Subscription, sub)
+	}
+	return &gnmi.SubscribeRequest{Request: &gnmi.SubscribeRequest_Subscribe{Subscribe: list}}, nil
+}
+
+// notificationUpdates flattens a notification into updates with full paths
+func notificationUpdates(n *gnmi.Notification) []Update {
+	full := func(p *gnmi.Path) string {
+		joined := &gnmi.Path{}
+		if n.Prefix != nil {
+			joined.Elem = append(joined.Elem, n.Prefix.Elem...)
+		}
+		joined.Elem = append(joined.Elem, p.GetElem()...)
+		return PathString(joined)
+	}
+
+	var timestamp time.Time
+	if n.Timestamp != 0 {
+		timestamp = time.Unix(0, n.Timestamp)
+	}
+	var updates []Update
+	for _, u := range n.Update {
+		updates = append(updates, Update{
+			Path:      full(u.Path),
+			Value:     value.Normalize(typedValue(u.Val)),
+			Timestamp: timestamp,
+		})
+	}
+	for _, p := range n.Delete {
+		updates = append(updates, Update{Path: full(p), Timestamp: timestamp, Deleted: true})
+	}
+	return updates
+}
@@ -0,0 +1,143 @@
+package gnmiclient
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+)
+
+// onceServer answers subscriptions with one notification and a sync
+type onceServer struct {
+	gnmi.UnimplementedGNMIServer
+	got *gnmi.SubscriptionList
+}
+
+func (s *onceServer) Subscribe(stream gnmi.GNMI_SubscribeServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	s.got = req.GetSubscribe()
+	notification := &gnmi.Notification{
+		Timestamp: 1700000000000000000,
+		Prefix:    &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "system"}}},
+		Update: []*gnmi.Update{{
+			Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "state"}, {Name: "hostname"}}},
+			Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: "spine1"}},
+		}},
+		Delete: []*gnmi.Path{{Elem: []*gnmi.PathElem{{Name: "config"}, {Name: "domain-name"}}}},
+	}
+	if err := stream.Send(&gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: notification}}); err != nil {
+		return err
+	}
+	if err := stream.Send(&gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true}}); err != nil {
+		return err
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+func TestSubscribeOnce(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+	srv := grpc.NewServer()
+	fake := &onceServer{}
+	gnmi.RegisterGNMIServer(srv, fake)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	c, err := NewClient(Config{Address: lis.Addr().String(), Insecure: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var updates []Update
+	err = c.Subscribe(context.Background(), []string{"/system/state/hostname", "/system/config"}, SubscribeOptions{Mode: SubscribeOnce}, "", "", func(u Update) error {
+		updates = append(updates, u)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	if fake.got.GetMode() != gnmi.SubscriptionList_ONCE || len(fake.got.GetSubscription()) != 2 {
+		t.Errorf("subscription = %v, want ONCE with two paths", fake.got)
+	}
+	if len(updates) != 2 {
+		t.Fatalf("updates = %+v, want an update and a delete", updates)
+	}
+	if updates[0].Path != "/system/state/hostname" || updates[0].Value.String() != "spine1" || updates[0].Timestamp.IsZero() {
+		t.Errorf("update = %+v, want /system/state/hostname = spine1 with a timestamp", updates[0])
+	}
+	if updates[1].Path != "/system/config/domain-name" || !updates[1].Deleted {
+		t.Errorf("delete = %+v, want /system/config/domain-name deleted", updates[1])
+	}
+}
+
+func TestSubscribeRequest(t *testing.T) {
+	c := &Client{}
+	req, err := c.subscribeRequest([]string{"/interfaces/interface/state/counters"}, SubscribeOptions{Mode: SubscribeStream, SampleInterval: 5e9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub := req.GetSubscribe().GetSubscription()[0]
+	if sub.Mode != gnmi.SubscriptionMode_SAMPLE || sub.SampleInterval != 5e9 {
+		t.Errorf("subscription = %v, want SAMPLE every 5s", sub)
+	}
+
+	req, _ = c.subscribeRequest([]string{"/system"}, SubscribeOptions{})
+	if sub := req.GetSubscribe().GetSubscription()[0]; sub.Mode != gnmi.SubscriptionMode_ON_CHANGE {
+		t.Errorf("default subscription mode = %v, want ON_CHANGE", sub.Mode)
+	}
+
+	if _, err := c.subscribeRequest([]string{"/system"}, SubscribeOptions{Mode: "sometimes"}); err == nil {
+		t.Error("subscribeRequest() accepted an unknown mode")
+	}
+}
+
+// getServer answers Gets with one timestamped leaf
+type getServer struct {
+	gnmi.UnimplementedGNMIServer
+}
+
+func (s *getServer) Get(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	return &gnmi.GetResponse{Notification: []*gnmi.Notification{{
+		Timestamp: 1700000000000000000,
+		Update: []*gnmi.Update{{
+			Path: req.Path[0],
+			Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: 9214}},
+		}},
+	}}}, nil
+}
+
+func TestGetUpdate(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+	srv := grpc.NewServer()
+	gnmi.RegisterGNMIServer(srv, &getServer{})
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	c, err := NewClient(Config{Address: lis.Addr().String(), Insecure: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	u, exists, err := c.GetUpdate(context.Background(), "/interfaces/interface[name=Ethernet1]/state/mtu", "", "")
+	if err != nil || !exists {
+		t.Fatalf("GetUpdate() = %+v, %v, %v", u, exists, err)
+	}
+	if u.Value.String() != "9214" || !u.Timestamp.Equal(time.Unix(0, 1700000000000000000)) {
+		t.Errorf("GetUpdate() = %+v, want 9214 with the notification timestamp", u)
+	}
+}
@@ -0,0 +1,41 @@
+package gnmiclient
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// Throttle is called before each RPC a client sends, including retries and
+// gNOI RPCs over its connection, e.g. to rate-limit requests to a device.
+// An error fails the RPC without sending it.
+type Throttle func(ctx context.Context) error
+
+type throttleKey struct{}
+
+// WithThrottle returns a context whose RPCs wait on throttle first
+func WithThrottle(ctx context.Context, throttle Throttle) context.Context {
+	return context.WithValue(ctx, throttleKey{}, throttle)
+}
+
+// throttle waits on the context's throttle, if any
+func throttle(ctx context.Context) error {
+	if t, ok := ctx.Value(throttleKey{}).(Throttle); ok {
+		return t(ctx)
+	}
+	return nil
+}
+
+func throttleUnary(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if err := throttle(ctx); err != nil {
+		return err
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func throttleStream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if err := throttle(ctx); err != nil {
+		return nil, err
+	}
+	return streamer(ctx, desc, cc, method, opts...)
+}
@@ -0,0 +1,611 @@
+// Package gnmitest runs an in-process gNMI server answering from path →
+// value data, to test assertion suites and generators without devices:
+//
+//	srv, err := gnmitest.Start(map[string]any{
+//		"interface[Ethernet1]/state/oper-status": "UP",
+//		"/system/state/hostname":                 "spine1",
+//	})
+//	...
+//	defer srv.Close()
+//	// Point a target or gnmiclient.Config at srv.Addr(), insecure
+//
+// Gets of a leaf return its value; Gets of a container or list return the
+// JSON of everything beneath it, list entries carrying their keys, one
+// update per matching entry for paths with wildcard or omitted keys.
+// Subscriptions stream leaves, once, on poll, or as they change through
+// Update, Delete, or gNMI Set.
+package gnmitest
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v3"
+)
+
+// Server is a running gNMI server; it is safe for concurrent use
+type Server struct {
+	lis  net.Listener
+	grpc *grpc.Server
+	gnmi *server
+}
+
+// Start serves values, keyed by path, on a local port. Paths may be full
+// (optionally <origin>:/path) or short (interface[Ethernet1]/...); values
+// are scalars, or maps and lists served as JSON.
+func Start(values map[string]any) (*Server, error) {
+	s := &server{leaves: make(map[string]*leaf), watchers: make(map[chan change]<-chan struct{})}
+	for path, v := range values {
+		if err := s.put(path, v); err != nil {
+			return nil, err
+		}
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+	srv := grpc.NewServer()
+	gnmi.RegisterGNMIServer(srv, s)
+	go srv.Serve(lis)
+	return &Server{lis: lis, grpc: srv, gnmi: s}, nil
+}
+
+// StartFile serves the path → value map in a YAML or JSON file (see LoadFile)
+func StartFile(path string) (*Server, error) {
+	values, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Start(values)
+}
+
+// LoadFile reads a YAML or JSON file mapping paths to values, e.g.
+//
+//	/system/state/hostname: spine1
+//	interface[Ethernet1]/state/mtu: 9214
+func LoadFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fixtures: %w", err)
+	}
+	var values map[string]any
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(strings.NewReader(string(data)))
+		dec.UseNumber()
+		err = dec.Decode(&values)
+	} else {
+		err = yaml.Unmarshal(data, &values)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse fixtures %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// Addr is the host:port the server listens on
+func (s *Server) Addr() string {
+	return s.lis.Addr().String()
+}
+
+// Update sets a path's value, notifying streaming subscribers
+func (s *Server) Update(path string, v any) error {
+	return s.gnmi.put(path, v)
+}
+
+// Delete removes a path and everything beneath it, notifying streaming
+// subscribers
+func (s *Server) Delete(path string) error {
+	p, err := parse(path)
+	if err != nil {
+		return err
+	}
+	s.gnmi.remove(p)
+	return nil
+}
+
+// Close stops the server, ending open subscriptions
+func (s *Server) Close() {
+	s.grpc.Stop()
+}
+
+// leaf is a value held at a path
+type leaf struct {
+	path    *gnmi.Path
+	value   any
+	updated time.Time
+}
+
+// change is a leaf set or deleted, for streaming subscriptions
+type change struct {
+	leaf    *leaf
+	deleted bool
+}
+
+type server struct {
+	gnmi.UnimplementedGNMIServer
+
+	mu       sync.Mutex
+	leaves   map[string]*leaf                // By key
+	watchers map[chan change]<-chan struct{} // To the subscriber's done
+}
+
+// parse parses a full or short path, dropping the default openconfig origin
+func parse(path string) (*gnmi.Path, error) {
+	if assertion.IsShortPath(path) {
+		path = assertion.ExpandPath(path)
+	}
+	p, err := gnmiclient.ParsePath(path)
+	if err != nil {
+		return nil, fmt.Errorf("path %s: %w", path, err)
+	}
+	if p.Origin == "openconfig" {
+		p.Origin = ""
+	}
+	return p, nil
+}
+
+// key identifies a path in the server's data
+func key(p *gnmi.Path) string {
+	if p.Origin != "" {
+		return p.Origin + ":" + gnmiclient.PathString(p)
+	}
+	return gnmiclient.PathString(p)
+}
+
+func (s *server) put(path string, v any) error {
+	p, err := parse(path)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return fmt.Errorf("path %s: no value", path)
+	}
+	s.set(p, v)
+	return nil
+}
+
+// set stores a value, notifying watchers
+func (s *server) set(p *gnmi.Path, v any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l := &leaf{path: p, value: v, updated: time.Now()}
+	s.leaves[key(p)] = l
+	s.notifyLocked(change{leaf: l})
+}
+
+// remove deletes a path and everything beneath it, notifying watchers
+func (s *server) remove(p *gnmi.Path) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, l := range s.leaves {
+		if matches(p, l.path) {
+			delete(s.leaves, k)
+			s.notifyLocked(change{leaf: l, deleted: true})
+		}
+	}
+}
+
+// notifyLocked passes a change to every watcher, waiting for each to take
+// it or go away so none is lost; callers hold mu
+func (s *server) notifyLocked(c change) {
+	for ch, done := range s.watchers {
+		select {
+		case ch <- c:
+		case <-done:
+		}
+	}
+}
+
+// matches reports whether a data path is at or beneath a request path.
+// Request keys of * or left out match any entry.
+func matches(req, data *gnmi.Path) bool {
+	if req.GetOrigin() != data.Origin && !(req.GetOrigin() == "openconfig" && data.Origin == "") {
+		return false
+	}
+	if len(req.GetElem()) > len(data.Elem) {
+		return false
+	}
+	for i, re := range req.GetElem() {
+		de := data.Elem[i]
+		if re.Name != "*" && re.Name != de.Name {
+			return false
+		}
+		for k, v := range re.Key {
+			if v != "*" && de.Key[k] != v {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matching returns the leaves at or beneath a request path, sorted by path
+func (s *server) matching(req *gnmi.Path) []*leaf {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.matchingLocked(req)
+}
+
+// matchingLocked is matching for callers that hold mu
+func (s *server) matchingLocked(req *gnmi.Path) []*leaf {
+	var found []*leaf
+	for _, l := range s.leaves {
+		if matches(req, l.path) {
+			found = append(found, l)
+		}
+	}
+	slices.SortFunc(found, func(a, b *leaf) int { return strings.Compare(key(a.path), key(b.path)) })
+	return found
+}
+
+// join prepends a request prefix to a path
+func join(prefix, p *gnmi.Path) *gnmi.Path {
+	joined := &gnmi.Path{Origin: cmp.Or(p.GetOrigin(), prefix.GetOrigin())}
+	joined.Elem = append(joined.Elem, prefix.GetElem()...)
+	joined.Elem = append(joined.Elem, p.GetElem()...)
+	return joined
+}
+
+func (s *server) Capabilities(ctx context.Context, req *gnmi.CapabilityRequest) (*gnmi.CapabilityResponse, error) {
+	return &gnmi.CapabilityResponse{
+		SupportedEncodings: []gnmi.Encoding{gnmi.Encoding_JSON, gnmi.Encoding_JSON_IETF},
+		GNMIVersion:        "0.10.0",
+	}, nil
+}
+
+// Get answers each path with the updates of the entries it matches: a
+// leaf's value, or a container's JSON
+func (s *server) Get(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	resp := &gnmi.GetResponse{}
+	for _, p := range req.Path {
+		full := join(req.Prefix, p)
+		updates, ts, err := s.entries(full, req.Encoding)
+		if err != nil {
+			return nil, err
+		}
+		if len(updates) == 0 {
+			return nil, status.Errorf(codes.NotFound, "no data at %s", key(full))
+		}
+		resp.Notification = append(resp.Notification, &gnmi.Notification{Timestamp: ts.UnixNano(), Update: updates})
+	}
+	return resp, nil
+}
+
+// entries groups the leaves beneath a request path by the entry of the
+// requested depth they belong to, an update per entry, and returns when
+// the newest of them was set
+func (s *server) entries(req *gnmi.Path, encoding gnmi.Encoding) ([]*gnmi.Update, time.Time, error) {
+	depth := len(req.Elem)
+	var order []string
+	groups := make(map[string][]*leaf)
+	for _, l := range s.matching(req) {
+		entry := &gnmi.Path{Origin: l.path.Origin, Elem: l.path.Elem[:depth]}
+		k := key(entry)
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], l)
+	}
+
+	var updates []*gnmi.Update
+	var newest time.Time
+	for _, k := range order {
+		leaves := groups[k]
+		entry := &gnmi.Path{Origin: leaves[0].path.Origin, Elem: leaves[0].path.Elem[:depth]}
+		for _, l := range leaves {
+			if l.updated.After(newest) {
+				newest = l.updated
+			}
+		}
+		if len(leaves) == 1 && len(leaves[0].path.Elem) == depth {
+			val, err := typed(leaves[0].value, encoding)
+			if err != nil {
+				return nil, newest, err
+			}
+			updates = append(updates, &gnmi.Update{Path: entry, Val: val})
+			continue
+		}
+
+		tree := make(map[string]any)
+		for _, l := range leaves {
+			insert(tree, l.path.Elem[depth:], l.value)
+		}
+		val, err := jsonValue(tree, encoding)
+		if err != nil {
+			return nil, newest, err
+		}
+		updates = append(updates, &gnmi.Update{Path: entry, Val: val})
+	}
+	return updates, newest, nil
+}
+
+// insert places a value in a JSON tree at a relative path. Elements with
+// keys are list entries carrying their keys as fields.
+func insert(tree map[string]any, elems []*gnmi.PathElem, v any) {
+	for i, e := range elems {
+		last := i == len(elems)-1
+		if len(e.Key) == 0 {
+			if last {
+				tree[e.Name] = v
+				return
+			}
+			next, ok := tree[e.Name].(map[string]any)
+			if !ok {
+				next = make(map[string]any)
+				tree[e.Name] = next
+			}
+			tree = next
+			continue
+		}
+
+		list, _ := tree[e.Name].([]any)
+		var entry map[string]any
+		for _, item := range list {
+			if m, ok := item.(map[string]any); ok && hasKeys(m, e.Key) {
+				entry = m
+				break
+			}
+		}
+		if entry == nil {
+			entry = make(map[string]any, len(e.Key))
+			for k, kv := range e.Key {
+				entry[k] = kv
+			}
+			tree[e.Name] = append(list, entry)
+		}
+		if last {
+			// A value at a list entry is its content
+			if m, ok := v.(map[string]any); ok {
+				for k, mv := range m {
+					entry[k] = mv
+				}
+			}
+			return
+		}
+		tree = entry
+	}
+}
+
+// hasKeys reports whether a list entry has the given key values
+func hasKeys(entry map[string]any, keys map[string]string) bool {
+	for k, v := range keys {
+		if entry[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// typed encodes a leaf value as gNMI would send it
+func typed(v any, encoding gnmi.Encoding) (*gnmi.TypedValue, error) {
+	switch v := v.(type) {
+	case string:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: v}}, nil
+	case bool:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_BoolVal{BoolVal: v}}, nil
+	case int:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: int64(v)}}, nil
+	case int64:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: v}}, nil
+	case uint64:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: v}}, nil
+	case float64:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_DoubleVal{DoubleVal: v}}, nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: i}}, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_DoubleVal{DoubleVal: f}}, nil
+	}
+	return jsonValue(v, encoding)
+}
+
+// jsonValue encodes a container or list as JSON, or JSON_IETF by default
+func jsonValue(v any, encoding gnmi.Encoding) (*gnmi.TypedValue, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "encode value: %v", err)
+	}
+	if encoding == gnmi.Encoding_JSON {
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{JsonVal: data}}, nil
+	}
+	return &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: data}}, nil
+}
+
+// untyped decodes a value sent with Set
+func untyped(val *gnmi.TypedValue) (any, error) {
+	switch v := val.GetValue().(type) {
+	case *gnmi.TypedValue_StringVal:
+		return v.StringVal, nil
+	case *gnmi.TypedValue_AsciiVal:
+		return v.AsciiVal, nil
+	case *gnmi.TypedValue_BoolVal:
+		return v.BoolVal, nil
+	case *gnmi.TypedValue_IntVal:
+		return v.IntVal, nil
+	case *gnmi.TypedValue_UintVal:
+		return v.UintVal, nil
+	case *gnmi.TypedValue_DoubleVal:
+		return v.DoubleVal, nil
+	case *gnmi.TypedValue_FloatVal:
+		return float64(v.FloatVal), nil
+	case *gnmi.TypedValue_JsonVal, *gnmi.TypedValue_JsonIetfVal:
+		data := val.GetJsonIetfVal()
+		if data == nil {
+			data = val.GetJsonVal()
+		}
+		dec := json.NewDecoder(strings.NewReader(string(data)))
+		dec.UseNumber()
+		var decoded any
+		if err := dec.Decode(&decoded); err != nil {
+			return nil, err
+		}
+		return decoded, nil
+	}
+	return nil, fmt.Errorf("unsupported value type %T", val.GetValue())
+}
+
+// Set applies deletes, then replaces, then updates. Replaced paths lose
+// what was beneath them; JSON values are held as given.
+func (s *server) Set(ctx context.Context, req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+	resp := &gnmi.SetResponse{Prefix: req.Prefix, Timestamp: time.Now().UnixNano()}
+	for _, p := range req.Delete {
+		s.remove(join(req.Prefix, p))
+		resp.Response = append(resp.Response, &gnmi.UpdateResult{Path: p, Op: gnmi.UpdateResult_DELETE})
+	}
+	for _, op := range []struct {
+		updates []*gnmi.Update
+		op      gnmi.UpdateResult_Operation
+	}{{req.Replace, gnmi.UpdateResult_REPLACE}, {req.Update, gnmi.UpdateResult_UPDATE}} {
+		for _, u := range op.updates {
+			v, err := untyped(u.Val)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "%s: %v", key(u.Path), err)
+			}
+			full := join(req.Prefix, u.Path)
+			if op.op == gnmi.UpdateResult_REPLACE {
+				s.remove(full)
+			}
+			s.set(full, v)
+			resp.Response = append(resp.Response, &gnmi.UpdateResult{Path: u.Path, Op: op.op})
+		}
+	}
+	return resp, nil
+}
+
+// Subscribe sends the leaves beneath each subscribed path and a sync; then
+// ONCE ends, POLL resends them on each poll, and STREAM sends changes until
+// the client goes away
+func (s *server) Subscribe(stream gnmi.GNMI_SubscribeServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	list := req.GetSubscribe()
+	if list == nil {
+		return status.Error(codes.InvalidArgument, "first request must be a subscription list")
+	}
+	var paths []*gnmi.Path
+	for _, sub := range list.Subscription {
+		paths = append(paths, join(list.Prefix, sub.Path))
+	}
+
+	var changes chan change
+	var initial []*leaf
+	if list.Mode == gnmi.SubscriptionList_STREAM {
+		// Setters wait for a full buffer to drain, so the initial leaves are
+		// taken under the lock that starts the watch, not while it blocks
+		changes = make(chan change, 256)
+		done := make(chan struct{})
+		s.mu.Lock()
+		s.watchers[changes] = done
+		initial = s.snapshotLocked(paths)
+		s.mu.Unlock()
+		defer func() {
+			s.mu.Lock()
+			delete(s.watchers, changes)
+			s.mu.Unlock()
+		}()
+		defer close(done)
+	} else {
+		initial = s.snapshot(paths)
+	}
+
+	if err := s.sendAll(stream, initial, list.Encoding); err != nil {
+		return err
+	}
+
+	switch list.Mode {
+	case gnmi.SubscriptionList_ONCE:
+		return nil
+	case gnmi.SubscriptionList_POLL:
+		for {
+			req, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if req.GetPoll() != nil {
+				if err := s.sendAll(stream, s.snapshot(paths), list.Encoding); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case c := <-changes:
+			if !slices.ContainsFunc(paths, func(p *gnmi.Path) bool { return matches(p, c.leaf.path) }) {
+				continue
+			}
+			if err := stream.Send(notification(c.leaf, c.deleted, list.Encoding)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// snapshot returns the leaves beneath each of paths
+func (s *server) snapshot(paths []*gnmi.Path) []*leaf {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshotLocked(paths)
+}
+
+// snapshotLocked is snapshot for callers that hold mu
+func (s *server) snapshotLocked(paths []*gnmi.Path) []*leaf {
+	var found []*leaf
+	for _, p := range paths {
+		found = append(found, s.matchingLocked(p)...)
+	}
+	return found
+}
+
+// sendAll sends leaves, then a sync
+func (s *server) sendAll(stream gnmi.GNMI_SubscribeServer, leaves []*leaf, encoding gnmi.Encoding) error {
+	for _, l := range leaves {
+		if err := stream.Send(notification(l, false, encoding)); err != nil {
+			return err
+		}
+	}
+	return stream.Send(&gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true}})
+}
+
+// notification reports a leaf's value, or its deletion
+func notification(l *leaf, deleted bool, encoding gnmi.Encoding) *gnmi.SubscribeResponse {
+	n := &gnmi.Notification{Timestamp: l.updated.UnixNano()}
+	if deleted {
+		n.Timestamp = time.Now().UnixNano()
+		n.Delete = []*gnmi.Path{l.path}
+	} else if val, err := typed(l.value, encoding); err == nil {
+		n.Update = []*gnmi.Update{{Path: l.path, Val: val}}
+	}
+	return &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: n}}
+}
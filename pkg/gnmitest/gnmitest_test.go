@@ -0,0 +1,188 @@
+package gnmitest
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+)
+
+func start(t *testing.T, values map[string]any) (*Server, *gnmiclient.Client) {
+	t.Helper()
+	srv, err := Start(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+	c, err := gnmiclient.NewClient(gnmiclient.Config{Address: srv.Addr(), Insecure: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return srv, c
+}
+
+func TestGet(t *testing.T) {
+	_, c := start(t, map[string]any{
+		"interface[Ethernet1]/state/oper-status":       "UP",
+		"interface[Ethernet1]/state/mtu":               9214,
+		"interface[Ethernet2]/state/oper-status":       "DOWN",
+		"/system/state/hostname":                       "spine1",
+		"eos_native:/Sysdb/hardware/archer/model/name": "DCS-7050",
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/interfaces/interface[name=Ethernet1]/state/oper-status", "UP"},
+		{"/interfaces/interface[name=Ethernet1]/state/mtu", "9214"},
+		{"/system/state/hostname", "spine1"},
+		{"eos_native:/Sysdb/hardware/archer/model/name", "DCS-7050"},
+		{"/interfaces/interface[name=Ethernet1]/state", `{"mtu":9214,"oper-status":"UP"}`},
+	}
+	for _, tt := range tests {
+		got, exists, err := c.Get(ctx, tt.path, "", "")
+		if err != nil || !exists || got != tt.want {
+			t.Errorf("Get(%s) = %q, %v, %v, want %q", tt.path, got, exists, err, tt.want)
+		}
+	}
+
+	if _, exists, err := c.Get(ctx, "/interfaces/interface[name=Ethernet9]/state", "", ""); err != nil || exists {
+		t.Errorf("Get of a missing path = %v, %v, want not found", exists, err)
+	}
+
+	// Containers hold lists with their keys
+	got, _, err := c.Get(ctx, "/interfaces", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tree map[string]any
+	if err := json.Unmarshal([]byte(got), &tree); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"interface": []any{
+		map[string]any{"name": "Ethernet1", "state": map[string]any{"mtu": float64(9214), "oper-status": "UP"}},
+		map[string]any{"name": "Ethernet2", "state": map[string]any{"oper-status": "DOWN"}},
+	}}
+	if !reflect.DeepEqual(tree, want) {
+		t.Errorf("Get(/interfaces) = %s", got)
+	}
+
+	// Wildcards match an entry each
+	updates, err := c.GetAll(ctx, "/interfaces/interface[name=*]/state/oper-status", "", "")
+	if err != nil || len(updates) != 2 {
+		t.Fatalf("GetAll() = %v, %v, want 2 updates", updates, err)
+	}
+	if updates[1].Path != "/interfaces/interface[name=Ethernet2]/state/oper-status" || updates[1].Value.String() != "DOWN" {
+		t.Errorf("GetAll()[1] = %+v", updates[1])
+	}
+}
+
+func TestSetAndSubscribe(t *testing.T) {
+	srv, c := start(t, map[string]any{"/system/config/hostname": "spine1"})
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "/system/config/hostname", "spine2", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got, _, _ := c.Get(ctx, "/system/config/hostname", "", ""); got != "spine2" {
+		t.Errorf("Get after Set = %q, want spine2", got)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	var seen []string
+	err := c.Subscribe(ctx, []string{"/system"}, gnmiclient.SubscribeOptions{Mode: gnmiclient.SubscribeStream}, "", "", func(u gnmiclient.Update) error {
+		seen = append(seen, u.Value.String())
+		if len(seen) == 1 {
+			return srv.Update("/system/config/hostname", "spine3")
+		}
+		cancel()
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(seen, []string{"spine2", "spine3"}) {
+		t.Errorf("streamed %v, want the value then its change", seen)
+	}
+}
+
+func TestSubscribeKeepsEveryChange(t *testing.T) {
+	srv, c := start(t, map[string]any{"/system/state/boot-time": 0})
+
+	// More changes than the stream buffers, made faster than they're read
+	const n = 1000
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	errs := make(chan error, 1)
+	var seen []string
+	err := c.Subscribe(ctx, []string{"/system"}, gnmiclient.SubscribeOptions{Mode: gnmiclient.SubscribeStream}, "", "", func(u gnmiclient.Update) error {
+		seen = append(seen, u.Value.String())
+		if len(seen) == 1 {
+			go func() {
+				for i := 1; i <= n; i++ {
+					if err := srv.Update("/system/state/boot-time", i); err != nil {
+						errs <- err
+						return
+					}
+				}
+				errs <- nil
+			}()
+		}
+		if len(seen) == n+1 {
+			cancel()
+		}
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != n+1 {
+		t.Fatalf("streamed %d values, want %d", len(seen), n+1)
+	}
+	for i, v := range seen {
+		if v != strconv.Itoa(i) {
+			t.Fatalf("streamed value %d = %s, want %d", i, v, i)
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	yamlFile := filepath.Join(dir, "spine1.yaml")
+	if err := os.WriteFile(yamlFile, []byte("/system/state/hostname: spine1\ninterface[Ethernet1]/state/mtu: 9214\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	jsonFile := filepath.Join(dir, "spine1.json")
+	if err := os.WriteFile(jsonFile, []byte(`{"/system/state/hostname": "spine1", "interface[Ethernet1]/state/mtu": 9214}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{yamlFile, jsonFile} {
+		srv, err := StartFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := gnmiclient.NewClient(gnmiclient.Config{Address: srv.Addr(), Insecure: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _, err := c.Get(context.Background(), "/interfaces/interface[name=Ethernet1]/state/mtu", "", ""); err != nil || got != "9214" {
+			t.Errorf("%s: Get() = %q, %v, want 9214", filepath.Base(file), got, err)
+		}
+		c.Close()
+		srv.Close()
+	}
+}
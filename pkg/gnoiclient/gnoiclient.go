@@ -0,0 +1,224 @@
+// Package gnoiclient runs gNOI operations (ping, traceroute, time, OS
+// verification) over an existing gRPC connection to a device
+package gnoiclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+	ospb "github.com/openconfig/gnoi/os"
+	"github.com/openconfig/gnoi/system"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// DefaultPingCount is the number of echo requests sent when unset
+const DefaultPingCount = 5
+
+// Client wraps the gNOI services netsert uses
+type Client struct {
+	system system.SystemClient
+	os     ospb.OSClient
+}
+
+// New creates a gNOI client sharing conn (e.g., from gnmiclient.Client.Conn)
+func New(conn grpc.ClientConnInterface) *Client {
+	return &Client{
+		system: system.NewSystemClient(conn),
+		os:     ospb.NewOSClient(conn),
+	}
+}
+
+// ClockSkew returns how far the device clock is from the local clock
+// (positive when the device is ahead), measured against the midpoint of
+// the System.Time round trip
+func (c *Client) ClockSkew(ctx context.Context, username, password string) (time.Duration, error) {
+	before := time.Now()
+	resp, err := c.system.Time(withCredentials(ctx, username, password), &system.TimeRequest{})
+	if err != nil {
+		return 0, fmt.Errorf("time: %w", gnmiclient.Classify(err))
+	}
+	after := time.Now()
+
+	local := before.Add(after.Sub(before) / 2)
+	device := time.Unix(0, int64(resp.Time))
+	return device.Sub(local), nil
+}
+
+// OSVersion returns the running OS version via OS.Verify
+func (c *Client) OSVersion(ctx context.Context, username, password string) (string, error) {
+	resp, err := c.os.Verify(withCredentials(ctx, username, password), &ospb.VerifyRequest{})
+	if err != nil {
+		return "", fmt.Errorf("os verify: %w", gnmiclient.Classify(err))
+	}
+	return resp.Version, nil
+}
+
+// PingOptions configures a gNOI System.Ping
+type PingOptions struct {
+	Destination     string
+	Source          string
+	NetworkInstance string
+	Count           int // Default: 5
+}
+
+// PingResult summarizes a ping run
+type PingResult struct {
+	Sent     int
+	Received int
+	MinRTT   time.Duration
+	AvgRTT   time.Duration
+	MaxRTT   time.Duration
+}
+
+// Loss returns the packet loss percentage
+func (r *PingResult) Loss() float64 {
+	return assertion.PingLoss(r.Sent, r.Received)
+}
+
+// Ping runs System.Ping on the device and returns the summary
+func (c *Client) Ping(ctx context.Context, opts PingOptions, username, password string) (*PingResult, error) {
+	count := opts.Count
+	if count == 0 {
+		count = DefaultPingCount
+	}
+
+	stream, err := c.system.Ping(withCredentials(ctx, username, password), &system.PingRequest{
+		Destination:     opts.Destination,
+		Source:          opts.Source,
+		NetworkInstance: opts.NetworkInstance,
+		Count:           int32(count),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ping: %w", gnmiclient.Classify(err))
+	}
+
+	// Per-packet replies are followed by a summary carrying sent/received;
+	// devices that omit the summary are tallied from the replies
+	result := &PingResult{Sent: count}
+	var replies int
+	var total time.Duration
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ping: %w", gnmiclient.Classify(err))
+		}
+
+		if resp.Sent > 0 {
+			return &PingResult{
+				Sent:     int(resp.Sent),
+				Received: int(resp.Received),
+				MinRTT:   time.Duration(resp.MinTime),
+				AvgRTT:   time.Duration(resp.AvgTime),
+				MaxRTT:   time.Duration(resp.MaxTime),
+			}, nil
+		}
+
+		rtt := time.Duration(resp.Time)
+		replies++
+		total += rtt
+		if result.MinRTT == 0 || rtt < result.MinRTT {
+			result.MinRTT = rtt
+		}
+		if rtt > result.MaxRTT {
+			result.MaxRTT = rtt
+		}
+	}
+
+	result.Received = replies
+	if replies > 0 {
+		result.AvgRTT = total / time.Duration(replies)
+	}
+	return result, nil
+}
+
+// TracerouteOptions configures a gNOI System.Traceroute
+type TracerouteOptions struct {
+	Destination     string
+	Source          string
+	NetworkInstance string
+	MaxHops         int // Default: device default (usually 30)
+}
+
+// Hop is one traceroute hop
+type Hop struct {
+	Hop     int
+	Address string
+	RTT     time.Duration
+}
+
+// TracerouteResult lists the hops towards a destination
+type TracerouteResult struct {
+	Destination string // Resolved destination address
+	Hops        []Hop
+}
+
+// Reached reports whether the last hop answered from the destination
+func (r *TracerouteResult) Reached() bool {
+	if len(r.Hops) == 0 {
+		return false
+	}
+	return r.Hops[len(r.Hops)-1].Address == r.Destination
+}
+
+// Traceroute runs System.Traceroute on the device
+func (c *Client) Traceroute(ctx context.Context, opts TracerouteOptions, username, password string) (*TracerouteResult, error) {
+	stream, err := c.system.Traceroute(withCredentials(ctx, username, password), &system.TracerouteRequest{
+		Destination:     opts.Destination,
+		Source:          opts.Source,
+		NetworkInstance: opts.NetworkInstance,
+		MaxTtl:          int32(opts.MaxHops),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("traceroute: %w", gnmiclient.Classify(err))
+	}
+
+	result := &TracerouteResult{Destination: opts.Destination}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("traceroute: %w", gnmiclient.Classify(err))
+		}
+
+		// The first response describes the destination rather than a hop
+		if resp.DestinationAddress != "" {
+			result.Destination = resp.DestinationAddress
+		}
+		if resp.Hop == 0 {
+			continue
+		}
+		// Keep the first answering probe of each hop
+		if n := len(result.Hops); n > 0 && result.Hops[n-1].Hop == int(resp.Hop) {
+			if result.Hops[n-1].Address == "" {
+				result.Hops[n-1].Address = resp.Address
+				result.Hops[n-1].RTT = time.Duration(resp.Rtt)
+			}
+			continue
+		}
+		result.Hops = append(result.Hops, Hop{
+			Hop:     int(resp.Hop),
+			Address: resp.Address,
+			RTT:     time.Duration(resp.Rtt),
+		})
+	}
+	return result, nil
+}
+
+// withCredentials attaches username/password metadata as gnmiclient does
+func withCredentials(ctx context.Context, username, password string) context.Context {
+	if username == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "username", username, "password", password)
+}
@@ -0,0 +1,184 @@
+package gnoiclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+	"github.com/openconfig/gnoi/system"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeStream replays responses, then err (io.EOF when nil)
+type fakeStream[T any] struct {
+	grpc.ClientStream
+	resps []*T
+	err   error
+}
+
+func (s *fakeStream[T]) Recv() (*T, error) {
+	if len(s.resps) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	resp := s.resps[0]
+	s.resps = s.resps[1:]
+	return resp, nil
+}
+
+// fakeSystem answers Ping and Traceroute from canned streams
+type fakeSystem struct {
+	system.SystemClient
+	ping       *fakeStream[system.PingResponse]
+	traceroute *fakeStream[system.TracerouteResponse]
+	pingReq    *system.PingRequest
+	md         metadata.MD
+}
+
+func (f *fakeSystem) Ping(ctx context.Context, in *system.PingRequest, _ ...grpc.CallOption) (grpc.ServerStreamingClient[system.PingResponse], error) {
+	f.pingReq = in
+	f.md, _ = metadata.FromOutgoingContext(ctx)
+	return f.ping, nil
+}
+
+func (f *fakeSystem) Traceroute(_ context.Context, _ *system.TracerouteRequest, _ ...grpc.CallOption) (grpc.ServerStreamingClient[system.TracerouteResponse], error) {
+	return f.traceroute, nil
+}
+
+func TestPing(t *testing.T) {
+	ms := int64(time.Millisecond)
+	tests := []struct {
+		name  string
+		resps []*system.PingResponse
+		count int
+		want  PingResult
+	}{
+		{
+			name: "summary wins",
+			resps: []*system.PingResponse{
+				{Time: ms},
+				{Sent: 3, Received: 2, MinTime: ms, AvgTime: 2 * ms, MaxTime: 3 * ms},
+			},
+			count: 3,
+			want:  PingResult{Sent: 3, Received: 2, MinRTT: time.Millisecond, AvgRTT: 2 * time.Millisecond, MaxRTT: 3 * time.Millisecond},
+		},
+		{
+			name:  "tallied from replies",
+			resps: []*system.PingResponse{{Time: 2 * ms}, {Time: 4 * ms}},
+			count: 3,
+			want:  PingResult{Sent: 3, Received: 2, MinRTT: 2 * time.Millisecond, AvgRTT: 3 * time.Millisecond, MaxRTT: 4 * time.Millisecond},
+		},
+		{
+			name: "default count",
+			want: PingResult{Sent: DefaultPingCount},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeSystem{ping: &fakeStream[system.PingResponse]{resps: tt.resps}}
+			c := &Client{system: fake}
+			got, err := c.Ping(context.Background(), PingOptions{Destination: "10.0.0.2", Count: tt.count}, "admin", "secret")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Errorf("Ping() = %+v, want %+v", *got, tt.want)
+			}
+			if tt.count == 0 && fake.pingReq.Count != DefaultPingCount {
+				t.Errorf("requested %d pings, want %d", fake.pingReq.Count, DefaultPingCount)
+			}
+			if u := fake.md.Get("username"); len(u) != 1 || u[0] != "admin" {
+				t.Errorf("username metadata = %v", u)
+			}
+		})
+	}
+}
+
+func TestPingError(t *testing.T) {
+	fake := &fakeSystem{ping: &fakeStream[system.PingResponse]{
+		resps: []*system.PingResponse{{Time: 1}},
+		err:   status.Error(codes.Unimplemented, "no ping"),
+	}}
+	c := &Client{system: fake}
+	if _, err := c.Ping(context.Background(), PingOptions{Destination: "10.0.0.2"}, "", ""); !errors.Is(err, gnmiclient.ErrUnimplemented) {
+		t.Errorf("Ping() error = %v, want ErrUnimplemented", err)
+	}
+}
+
+func TestPingResultLoss(t *testing.T) {
+	tests := []struct {
+		sent, received int
+		want           float64
+	}{
+		{5, 5, 0},
+		{5, 4, 20},
+		{4, 0, 100},
+		{0, 0, 100},
+	}
+	for _, tt := range tests {
+		r := PingResult{Sent: tt.sent, Received: tt.received}
+		if got := r.Loss(); got != tt.want {
+			t.Errorf("Loss(%d/%d) = %v, want %v", tt.received, tt.sent, got, tt.want)
+		}
+	}
+}
+
+func TestTraceroute(t *testing.T) {
+	fake := &fakeSystem{traceroute: &fakeStream[system.TracerouteResponse]{resps: []*system.TracerouteResponse{
+		{DestinationAddress: "10.2.0.5"},
+		{Hop: 1, Address: "10.0.0.1", Rtt: 1000},
+		{Hop: 2},
+		{Hop: 2, Address: "10.1.0.1", Rtt: 2000},
+		{Hop: 2, Address: "10.1.0.9", Rtt: 3000},
+		{Hop: 3, Address: "10.2.0.5", Rtt: 4000},
+	}}}
+	c := &Client{system: fake}
+	got, err := c.Traceroute(context.Background(), TracerouteOptions{Destination: "dst.example"}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &TracerouteResult{
+		Destination: "10.2.0.5",
+		Hops: []Hop{
+			{Hop: 1, Address: "10.0.0.1", RTT: time.Microsecond},
+			{Hop: 2, Address: "10.1.0.1", RTT: 2 * time.Microsecond},
+			{Hop: 3, Address: "10.2.0.5", RTT: 4 * time.Microsecond},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Traceroute() = %+v, want %+v", got, want)
+	}
+	if !got.Reached() {
+		t.Error("Reached() = false, want true")
+	}
+}
+
+func TestTracerouteReached(t *testing.T) {
+	tests := []struct {
+		name string
+		r    TracerouteResult
+		want bool
+	}{
+		{"no hops", TracerouteResult{Destination: "10.2.0.5"}, false},
+		{"last hop is destination", TracerouteResult{Destination: "10.2.0.5", Hops: []Hop{{Hop: 1, Address: "10.2.0.5"}}}, true},
+		{"stopped short", TracerouteResult{Destination: "10.2.0.5", Hops: []Hop{{Hop: 1, Address: "10.0.0.1"}, {Hop: 2}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.r.Reached(); got != tt.want {
+				t.Errorf("Reached() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+// Package history keeps a local record of runs, one JSON line each, for
+// pass-rate trends and finding the assertions that regress most often.
+package history
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/runner"
+)
+
+// DefaultFile is where runs are recorded when no other file is configured
+const DefaultFile = ".netsert-history.jsonl"
+
+// Record is one run's summary and per-assertion outcomes
+type Record struct {
+	Time     time.Time     `json:"time"`
+	File     string        `json:"file"`
+	Duration time.Duration `json:"duration"`
+	Total    int           `json:"total"`
+	Passed   int           `json:"passed"`
+	Failed   int           `json:"failed"`
+	Errors   int           `json:"errors"`
+	Outcomes []Outcome     `json:"outcomes"`
+}
+
+// Outcome is one assertion's status in a run
+type Outcome struct {
+	Target string           `json:"target"`
+	Name   string           `json:"name"`
+	Path   string           `json:"path,omitempty"`
+	Status assertion.Status `json:"status"`
+}
+
+// PassRate returns the percentage of the run's assertions that passed
+func (r Record) PassRate() float64 {
+	if r.Total == 0 {
+		return 0
+	}
+	return 100 * float64(r.Passed) / float64(r.Total)
+}
+
+// NewRecord summarizes a run of file. Skipped assertions weren't run and
+// are left out.
+func NewRecord(file string, result *runner.RunResult) Record {
+	rec := Record{
+		Time:     time.Now(),
+		File:     file,
+		Duration: result.Duration,
+		Total:    result.TotalAssertions - result.Skipped,
+		Passed:   result.Passed,
+		Failed:   result.Failed,
+		Errors:   result.Errors,
+		Outcomes: []Outcome{},
+	}
+	for _, tr := range result.Targets {
+		for _, res := range tr.Results {
+			if res.GetStatus() == assertion.StatusSkipped {
+				continue
+			}
+			rec.Outcomes = append(rec.Outcomes, Outcome{
+				Target: res.Target,
+				Name:   res.Assertion.GetName(),
+				Path:   res.Assertion.Path,
+				Status: res.GetStatus(),
+			})
+		}
+	}
+	return rec
+}
+
+// Append adds a record to the end of the history file, creating it if needed
+func Append(path string, rec Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load reads every record in a history file, oldest first. A missing file
+// is an empty history.
+func Load(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64<<20) // Large fleets write long lines
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(records, func(a, b Record) int { return a.Time.Compare(b.Time) })
+	return records, nil
+}
+
+// Trend is one assertion's record across runs
+type Trend struct {
+	Target      string
+	Name        string
+	Path        string
+	Runs        int
+	Passed      int
+	Regressions int              // Times it went from passing to not passing
+	Last        assertion.Status // Status in the latest run it was part of
+	LastFailed  time.Time        // Latest run it didn't pass in
+}
+
+// PassRate returns the percentage of runs the assertion passed
+func (t Trend) PassRate() float64 {
+	if t.Runs == 0 {
+		return 0
+	}
+	return 100 * float64(t.Passed) / float64(t.Runs)
+}
+
+// Trends collects each assertion's outcomes across records (oldest first)
+// and returns them by most regressions, then lowest pass rate
+func Trends(records []Record) []Trend {
+	type key struct{ target, name, path string }
+	byKey := make(map[key]*Trend)
+	for _, rec := range records {
+		for _, o := range rec.Outcomes {
+			k := key{o.Target, o.Name, o.Path}
+			t, ok := byKey[k]
+			if !ok {
+				t = &Trend{Target: o.Target, Name: o.Name, Path: o.Path}
+				byKey[k] = t
+			}
+			t.Runs++
+			if o.Status == assertion.StatusPass {
+				t.Passed++
+			} else {
+				if t.Last == assertion.StatusPass {
+					t.Regressions++
+				}
+				t.LastFailed = rec.Time
+			}
+			t.Last = o.Status
+		}
+	}
+
+	trends := make([]Trend, 0, len(byKey))
+	for _, t := range byKey {
+		trends = append(trends, *t)
+	}
+	slices.SortFunc(trends, func(a, b Trend) int {
+		return cmp.Or(
+			cmp.Compare(b.Regressions, a.Regressions),
+			cmp.Compare(a.PassRate(), b.PassRate()),
+			cmp.Compare(a.Target, b.Target),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Path, b.Path),
+		)
+	})
+	return trends
+}
+
+// Day is the pass rate of the runs on one day
+type Day struct {
+	Date   string // YYYY-MM-DD, local time
+	Runs   int
+	Total  int
+	Passed int
+}
+
+// PassRate returns the percentage of the day's assertions that passed
+func (d Day) PassRate() float64 {
+	if d.Total == 0 {
+		return 0
+	}
+	return 100 * float64(d.Passed) / float64(d.Total)
+}
+
+// Daily groups records (oldest first) by day
+func Daily(records []Record) []Day {
+	var days []Day
+	for _, rec := range records {
+		date := rec.Time.Local().Format(time.DateOnly)
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, Day{Date: date})
+		}
+		d := &days[len(days)-1]
+		d.Runs++
+		d.Total += rec.Total
+		d.Passed += rec.Passed
+	}
+	return days
+}
@@ -0,0 +1,81 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+)
+
+func TestAppendLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	if records, err := Load(path); err != nil || len(records) != 0 {
+		t.Fatalf("Load() of a missing file = %v, %v, want empty", records, err)
+	}
+
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, passed := range []int{3, 2} {
+		rec := Record{Time: day.Add(time.Duration(i) * time.Hour), File: "fabric.yaml", Total: 3, Passed: passed}
+		if err := Append(path, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	records, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[1].Passed != 2 {
+		t.Fatalf("Load() = %+v, want both records in order", records)
+	}
+	if rate := records[1].PassRate(); rate < 66 || rate > 67 {
+		t.Errorf("PassRate() = %v, want 66.7", rate)
+	}
+}
+
+func TestTrends(t *testing.T) {
+	run := func(hours int, statuses map[string]assertion.Status) Record {
+		rec := Record{Time: time.Date(2026, 3, 1, hours, 0, 0, 0, time.UTC)}
+		for _, name := range []string{"bgp up", "fan ok", "ntp synced"} {
+			if status, ok := statuses[name]; ok {
+				rec.Outcomes = append(rec.Outcomes, Outcome{Target: "spine1:6030", Name: name, Status: status})
+			}
+		}
+		return rec
+	}
+	records := []Record{
+		run(1, map[string]assertion.Status{"bgp up": assertion.StatusPass, "fan ok": assertion.StatusPass, "ntp synced": assertion.StatusFail}),
+		run(2, map[string]assertion.Status{"bgp up": assertion.StatusFail, "fan ok": assertion.StatusPass, "ntp synced": assertion.StatusFail}),
+		run(3, map[string]assertion.Status{"bgp up": assertion.StatusPass, "fan ok": assertion.StatusTimeout}),
+		run(4, map[string]assertion.Status{"bgp up": assertion.StatusError, "fan ok": assertion.StatusPass}),
+	}
+
+	trends := Trends(records)
+	if len(trends) != 3 {
+		t.Fatalf("Trends() = %+v, want 3", trends)
+	}
+	bgp := trends[0]
+	if bgp.Name != "bgp up" || bgp.Regressions != 2 || bgp.Runs != 4 || bgp.Passed != 2 || bgp.Last != assertion.StatusError {
+		t.Errorf("trends[0] = %+v, want bgp up with 2 regressions", bgp)
+	}
+	if !bgp.LastFailed.Equal(records[3].Time) {
+		t.Errorf("LastFailed = %v, want %v", bgp.LastFailed, records[3].Time)
+	}
+	// Never passing isn't a regression; it sorts after fan ok's one
+	if trends[1].Name != "fan ok" || trends[1].Regressions != 1 || trends[2].Name != "ntp synced" || trends[2].Regressions != 0 {
+		t.Errorf("Trends() order = %s, %s, want fan ok, ntp synced", trends[1].Name, trends[2].Name)
+	}
+}
+
+func TestDaily(t *testing.T) {
+	day1 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	records := []Record{
+		{Time: day1, Total: 10, Passed: 10},
+		{Time: day1.Add(time.Hour), Total: 10, Passed: 5},
+		{Time: day1.Add(24 * time.Hour), Total: 10, Passed: 9},
+	}
+	days := Daily(records)
+	if len(days) != 2 || days[0].Runs != 2 || days[0].PassRate() != 75 || days[1].PassRate() != 90 {
+		t.Errorf("Daily() = %+v, want 75%% then 90%%", days)
+	}
+}
@@ -0,0 +1,133 @@
+// Package hooks defines commands and gNMI Set operations run before and
+// after a suite or each target, such as clearing counters ahead of delta
+// assertions or notifying a ticketing system afterwards.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds a hook that doesn't set its own timeout, so a hung
+// command can't hang the run, even after an interrupt
+const DefaultTimeout = time.Minute
+
+// Hooks lists the hooks run at each point of a run, in order
+type Hooks struct {
+	Before       []Hook `yaml:"before,omitempty"`        // Once, before any target
+	After        []Hook `yaml:"after,omitempty"`         // Once, after every target
+	BeforeTarget []Hook `yaml:"before_target,omitempty"` // Before each target's assertions
+	AfterTarget  []Hook `yaml:"after_target,omitempty"`  // After each target's assertions
+}
+
+// Hook is one action: a local command or, for target hooks, a gNMI Set
+type Hook struct {
+	Name string `yaml:"name,omitempty"`
+	Exec string `yaml:"exec,omitempty"` // Shell command, run with sh -c
+	Set  *Set   `yaml:"set,omitempty"`
+
+	Timeout string `yaml:"timeout,omitempty"` // Max run time (e.g., "5m"; default: 1m for exec, the run's timeout for set)
+}
+
+// Set replaces the value at a path on the target
+type Set struct {
+	Path  string `yaml:"path"`
+	Value string `yaml:"value"` // Sent as JSON; values that aren't valid JSON are sent as strings
+}
+
+// GetName returns the hook's name, or a description of what it does
+func (h Hook) GetName() string {
+	switch {
+	case h.Name != "":
+		return h.Name
+	case h.Set != nil:
+		return "set " + h.Set.Path
+	default:
+		return h.Exec
+	}
+}
+
+// GetTimeout returns the hook's timeout, or fallback if it has none.
+// Validate rejects timeouts that don't parse.
+func (h Hook) GetTimeout(fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(h.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
+
+// Merge returns h's hooks followed by other's at each point
+func (h Hooks) Merge(other Hooks) Hooks {
+	return Hooks{
+		Before:       append(append([]Hook(nil), h.Before...), other.Before...),
+		After:        append(append([]Hook(nil), h.After...), other.After...),
+		BeforeTarget: append(append([]Hook(nil), h.BeforeTarget...), other.BeforeTarget...),
+		AfterTarget:  append(append([]Hook(nil), h.AfterTarget...), other.AfterTarget...),
+	}
+}
+
+// Validate checks every hook does exactly one thing, and that suite hooks,
+// which have no target, don't use set
+func (h Hooks) Validate() error {
+	points := []struct {
+		name   string
+		hooks  []Hook
+		target bool
+	}{
+		{"before", h.Before, false},
+		{"after", h.After, false},
+		{"before_target", h.BeforeTarget, true},
+		{"after_target", h.AfterTarget, true},
+	}
+	for _, p := range points {
+		for i, hook := range p.hooks {
+			switch {
+			case (hook.Exec == "") == (hook.Set == nil):
+				return fmt.Errorf("hooks.%s[%d]: set exactly one of exec or set", p.name, i)
+			case hook.Set != nil && !p.target:
+				return fmt.Errorf("hooks.%s[%d]: set needs a target; use before_target or after_target", p.name, i)
+			case hook.Set != nil && hook.Set.Path == "":
+				return fmt.Errorf("hooks.%s[%d]: set path is required", p.name, i)
+			}
+			if hook.Timeout != "" {
+				if d, err := time.ParseDuration(hook.Timeout); err != nil || d <= 0 {
+					return fmt.Errorf("hooks.%s[%d]: invalid timeout %q", p.name, i, hook.Timeout)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Exec runs a hook's command with env added to the environment, killing it
+// after the hook's timeout. A failing command's error includes its output.
+func Exec(ctx context.Context, h Hook, env map[string]string) error {
+	timeout := h.GetTimeout(DefaultTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", h.Exec)
+	cmd.WaitDelay = time.Second // Don't wait on children still holding the output
+	cmd.Env = os.Environ()
+	for k, v := range env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("hook %s: timed out after %s", h.GetName(), timeout)
+		}
+		if output := strings.TrimSpace(out.String()); output != "" {
+			return fmt.Errorf("hook %s: %w: %s", h.GetName(), err, output)
+		}
+		return fmt.Errorf("hook %s: %w", h.GetName(), err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package hooks
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		hooks   Hooks
+		wantErr string
+	}{
+		{
+			name: "valid",
+			hooks: Hooks{
+				Before:       []Hook{{Exec: "echo start"}},
+				BeforeTarget: []Hook{{Set: &Set{Path: "/interfaces/interface[name=Ethernet1]/config/description", Value: "under test"}}},
+			},
+		},
+		{
+			name:    "neither exec nor set",
+			hooks:   Hooks{After: []Hook{{Name: "notify"}}},
+			wantErr: "hooks.after[0]: set exactly one",
+		},
+		{
+			name:    "both exec and set",
+			hooks:   Hooks{AfterTarget: []Hook{{Exec: "true", Set: &Set{Path: "/x"}}}},
+			wantErr: "hooks.after_target[0]: set exactly one",
+		},
+		{
+			name:    "set on the suite",
+			hooks:   Hooks{Before: []Hook{{Set: &Set{Path: "/x"}}}},
+			wantErr: "set needs a target",
+		},
+		{
+			name:    "invalid timeout",
+			hooks:   Hooks{After: []Hook{{Exec: "true", Timeout: "soon"}}},
+			wantErr: `hooks.after[0]: invalid timeout "soon"`,
+		},
+		{
+			name:    "set without a path",
+			hooks:   Hooks{BeforeTarget: []Hook{{Set: &Set{Value: "1"}}}},
+			wantErr: "set path is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hooks.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExec(t *testing.T) {
+	env := map[string]string{"NETSERT_TARGET": "spine1:6030"}
+	if err := Exec(context.Background(), Hook{Exec: `test "$NETSERT_TARGET" = spine1:6030`}, env); err != nil {
+		t.Errorf("Exec() = %v", err)
+	}
+
+	err := Exec(context.Background(), Hook{Name: "notify", Exec: "echo ticket system down; exit 3"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "hook notify") || !strings.Contains(err.Error(), "ticket system down") {
+		t.Errorf("Exec() = %v, want the hook name and its output", err)
+	}
+}
+
+func TestExecTimeout(t *testing.T) {
+	// The timeout holds even when the caller's context can't be cancelled,
+	// as for after hooks, and a background child doesn't keep it waiting
+	ctx := context.WithoutCancel(context.Background())
+	start := time.Now()
+	err := Exec(ctx, Hook{Name: "hang", Exec: "sleep 30 & sleep 30", Timeout: "100ms"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "hook hang: timed out after 100ms") {
+		t.Errorf("Exec() = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Exec() took %s, want it cut off", elapsed)
+	}
+}
+
+func TestGetTimeout(t *testing.T) {
+	tests := []struct {
+		timeout string
+		want    time.Duration
+	}{
+		{"", DefaultTimeout},
+		{"5m", 5 * time.Minute},
+		{"soon", DefaultTimeout},
+	}
+	for _, tt := range tests {
+		if got := (Hook{Timeout: tt.timeout}).GetTimeout(DefaultTimeout); got != tt.want {
+			t.Errorf("GetTimeout(%q) = %s, want %s", tt.timeout, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DiscoveryTimeout bounds each service discovery lookup
+var DiscoveryTimeout = 10 * time.Second
+
+// Discovery populates a group from a service registry at load time
+type Discovery struct {
+	Group  string           `yaml:"group"`
+	Consul *ConsulDiscovery `yaml:"consul,omitempty"`
+	DNS    *DNSDiscovery    `yaml:"dns,omitempty"`
+}
+
+// ConsulDiscovery resolves hosts from a Consul service catalog
+type ConsulDiscovery struct {
+	Address    string `yaml:"address,omitempty"` // Default: $CONSUL_HTTP_ADDR or http://127.0.0.1:8500
+	Service    string `yaml:"service"`
+	Tag        string `yaml:"tag,omitempty"`
+	Datacenter string `yaml:"datacenter,omitempty"`
+	Token      string `yaml:"token,omitempty"` // Default: $CONSUL_HTTP_TOKEN
+}
+
+// DNSDiscovery resolves hosts from a DNS SRV record
+type DNSDiscovery struct {
+	SRV string `yaml:"srv"` // e.g., _gnmi._tcp.fabric.example.com
+}
+
+// resolveDiscovery queries each discovery source and adds the results to its group.
+// Discovered hosts are appended to any statically listed members.
+func (inv *Inventory) resolveDiscovery(ctx context.Context) error {
+	if len(inv.Discovery) == 0 {
+		return nil
+	}
+	if inv.Groups == nil {
+		inv.Groups = make(map[string][]string)
+	}
+
+	for i, d := range inv.Discovery {
+		if d.Group == "" {
+			return fmt.Errorf("discovery %d: group is required", i)
+		}
+
+		var hosts []string
+		var err error
+		switch {
+		case d.Consul != nil:
+			hosts, err = d.Consul.lookup(ctx)
+		case d.DNS != nil:
+			hosts, err = d.DNS.lookup(ctx)
+		default:
+			return fmt.Errorf("discovery %q: one of consul or dns is required", d.Group)
+		}
+		if err != nil {
+			return fmt.Errorf("discovery %q: %w", d.Group, err)
+		}
+
+		inv.Groups[d.Group] = append(inv.Groups[d.Group], hosts...)
+	}
+
+	inv.expandReferences()
+	return nil
+}
+
+// consulService is the subset of the catalog response we use
+type consulService struct {
+	Node           string `json:"Node"`
+	Address        string `json:"Address"`
+	ServiceAddress string `json:"ServiceAddress"`
+	ServicePort    int    `json:"ServicePort"`
+}
+
+func (c *ConsulDiscovery) lookup(ctx context.Context) ([]string, error) {
+	if c.Service == "" {
+		return nil, fmt.Errorf("consul: service is required")
+	}
+
+	addr := c.Address
+	if addr == "" {
+		addr = os.Getenv("CONSUL_HTTP_ADDR")
+	}
+	if addr == "" {
+		addr = "http://127.0.0.1:8500"
+	}
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+
+	query := url.Values{}
+	if c.Tag != "" {
+		query.Set("tag", c.Tag)
+	}
+	if c.Datacenter != "" {
+		query.Set("dc", c.Datacenter)
+	}
+	endpoint := strings.TrimSuffix(addr, "/") + "/v1/catalog/service/" + url.PathEscape(c.Service)
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DiscoveryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("consul: %w", err)
+	}
+	token := c.Token
+	if token == "" {
+		token = os.Getenv("CONSUL_HTTP_TOKEN")
+	}
+	if token != "" {
+		req.Header.Set("X-Consul-Token", token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("consul: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("consul: catalog returned %s", resp.Status)
+	}
+
+	var services []consulService
+	if err := json.NewDecoder(resp.Body).Decode(&services); err != nil {
+		return nil, fmt.Errorf("consul: decode catalog: %w", err)
+	}
+
+	hosts := make([]string, 0, len(services))
+	for _, s := range services {
+		// ServiceAddress is optional; Consul falls back to the node address
+		address := s.ServiceAddress
+		if address == "" {
+			address = s.Address
+		}
+		if address == "" {
+			continue
+		}
+		if s.ServicePort != 0 {
+			address = net.JoinHostPort(address, strconv.Itoa(s.ServicePort))
+		}
+		hosts = append(hosts, address)
+	}
+
+	return hosts, nil
+}
+
+func (d *DNSDiscovery) lookup(ctx context.Context) ([]string, error) {
+	if d.SRV == "" {
+		return nil, fmt.Errorf("dns: srv is required")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DiscoveryTimeout)
+	defer cancel()
+
+	_, records, err := net.DefaultResolver.LookupSRV(ctx, "", "", d.SRV)
+	if err != nil {
+		return nil, fmt.Errorf("dns: %w", err)
+	}
+
+	hosts := make([]string, 0, len(records))
+	for _, r := range records {
+		host := strings.TrimSuffix(r.Target, ".")
+		hosts = append(hosts, net.JoinHostPort(host, strconv.Itoa(int(r.Port))))
+	}
+
+	return hosts, nil
+}
@@ -0,0 +1,156 @@
+package inventory
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// consulServer answers catalog requests for the "gnmi" service with body
+func consulServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/catalog/service/gnmi" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestConsulLookup(t *testing.T) {
+	srv := consulServer(t, `[
+		{"Node": "spine1", "Address": "10.0.0.1", "ServiceAddress": "", "ServicePort": 6030},
+		{"Node": "spine2", "Address": "10.0.0.2", "ServiceAddress": "192.0.2.2", "ServicePort": 6030},
+		{"Node": "leaf1", "Address": "10.0.1.1", "ServiceAddress": "", "ServicePort": 0},
+		{"Node": "orphan", "Address": "", "ServiceAddress": "", "ServicePort": 6030}
+	]`)
+	other := consulServer(t, `[{"Node": "other", "Address": "10.9.9.9", "ServicePort": 6030}]`)
+	addr := strings.TrimPrefix(srv.URL, "http://")
+
+	tests := []struct {
+		name    string
+		consul  ConsulDiscovery
+		env     string // $CONSUL_HTTP_ADDR
+		want    []string
+		wantErr string
+	}{
+		{
+			name:   "service address preferred, node address fallback",
+			consul: ConsulDiscovery{Address: srv.URL, Service: "gnmi"},
+			want:   []string{"10.0.0.1:6030", "192.0.2.2:6030", "10.0.1.1"},
+		},
+		{
+			name:   "address without scheme",
+			consul: ConsulDiscovery{Address: addr, Service: "gnmi"},
+			want:   []string{"10.0.0.1:6030", "192.0.2.2:6030", "10.0.1.1"},
+		},
+		{
+			name:   "address from environment",
+			consul: ConsulDiscovery{Service: "gnmi"},
+			env:    other.URL,
+			want:   []string{"10.9.9.9:6030"},
+		},
+		{
+			name:   "explicit address before environment",
+			consul: ConsulDiscovery{Address: srv.URL, Service: "gnmi"},
+			env:    other.URL,
+			want:   []string{"10.0.0.1:6030", "192.0.2.2:6030", "10.0.1.1"},
+		},
+		{
+			name:    "unknown service",
+			consul:  ConsulDiscovery{Address: srv.URL, Service: "netconf"},
+			wantErr: "catalog returned 404",
+		},
+		{
+			name:    "service required",
+			consul:  ConsulDiscovery{Address: srv.URL},
+			wantErr: "service is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CONSUL_HTTP_ADDR", tt.env)
+			got, err := tt.consul.lookup(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("lookup() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lookup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsulLookupParseError(t *testing.T) {
+	srv := consulServer(t, `{"not": "a list"}`)
+	c := ConsulDiscovery{Address: srv.URL, Service: "gnmi"}
+	if _, err := c.lookup(context.Background()); err == nil || !strings.Contains(err.Error(), "decode catalog") {
+		t.Errorf("lookup() error = %v, want a decode error", err)
+	}
+}
+
+func TestResolveDiscovery(t *testing.T) {
+	srv := consulServer(t, `[{"Node": "spine2", "Address": "10.0.0.2", "ServicePort": 6030}]`)
+
+	inv := &Inventory{
+		Groups:    map[string][]string{"spines": {"spine1"}, "fabric": {"@spines"}},
+		Discovery: []Discovery{{Group: "spines", Consul: &ConsulDiscovery{Address: srv.URL, Service: "gnmi"}}},
+	}
+	if err := inv.resolveDiscovery(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"spine1", "10.0.0.2:6030"}; !reflect.DeepEqual(inv.Groups["spines"], want) {
+		t.Errorf("spines = %v, want static members then discovered ones %v", inv.Groups["spines"], want)
+	}
+	if want := []string{"spine1", "10.0.0.2:6030"}; !reflect.DeepEqual(inv.Groups["fabric"], want) {
+		t.Errorf("fabric = %v, want references expanded after discovery %v", inv.Groups["fabric"], want)
+	}
+
+	for _, d := range []Discovery{
+		{Consul: &ConsulDiscovery{Address: srv.URL, Service: "gnmi"}},
+		{Group: "spines"},
+	} {
+		inv := &Inventory{Discovery: []Discovery{d}}
+		if err := inv.resolveDiscovery(context.Background()); err == nil {
+			t.Errorf("resolveDiscovery(%+v) succeeded, want an error", d)
+		}
+	}
+}
+
+func TestLoadDiscoveryReferences(t *testing.T) {
+	srv := consulServer(t, `[{"Node": "spine2", "Address": "10.0.0.2", "ServicePort": 6030}]`)
+
+	path := filepath.Join(t.TempDir(), "inventory.yaml")
+	data := `groups:
+  spines: [spine1]
+  fabric: ["@spines", leaf1]
+discovery:
+  - group: spines
+    consul: {address: "` + srv.URL + `", service: gnmi}
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	inv, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"spine1", "10.0.0.2:6030", "leaf1"}; !reflect.DeepEqual(inv.Groups["fabric"], want) {
+		t.Errorf("fabric = %v, want the discovered spines too %v", inv.Groups["fabric"], want)
+	}
+}
@@ -3,18 +3,33 @@ package inventory
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"net"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/ndtobs/netsert/pkg/secrets"
 	"gopkg.in/yaml.v3"
 )
 
 // Inventory holds device groups, hosts, and defaults
 type Inventory struct {
-	Groups   map[string][]string `yaml:"groups"`
-	Hosts    map[string]Host     `yaml:"hosts,omitempty"`
-	Defaults Defaults            `yaml:"defaults,omitempty"`
+	Groups    map[string][]string  `yaml:"groups"`
+	GroupVars map[string]GroupVars `yaml:"group_vars,omitempty"`
+	Hosts     map[string]Host      `yaml:"hosts,omitempty"`
+	Defaults  Defaults             `yaml:"defaults,omitempty"`
+	Discovery []Discovery          `yaml:"discovery,omitempty"` // Groups resolved from Consul/DNS on each load
+}
+
+// GroupVars defines settings shared by all members of a group
+type GroupVars struct {
+	Platform string            `yaml:"platform,omitempty"`
+	Vars     map[string]string `yaml:"vars,omitempty"` // Target metadata, e.g. site or role
 }
 
 // Host defines per-host settings
@@ -24,6 +39,9 @@ type Host struct {
 	Username string `yaml:"username,omitempty"`
 	Password string `yaml:"password,omitempty"`
 	Insecure *bool  `yaml:"insecure,omitempty"`
+	Platform string `yaml:"platform,omitempty"`
+
+	Vars map[string]string `yaml:"vars,omitempty"` // Target metadata, e.g. site or role
 }
 
 // Defaults for all devices in inventory
@@ -32,6 +50,7 @@ type Defaults struct {
 	Password string `yaml:"password,omitempty"`
 	Insecure bool   `yaml:"insecure,omitempty"`
 	Port     int    `yaml:"port,omitempty"`
+	Platform string `yaml:"platform,omitempty"`
 }
 
 // DefaultPaths are the standard locations to look for inventory files
@@ -73,14 +92,27 @@ func Load(path string) (*Inventory, error) {
 		return nil, fmt.Errorf("read inventory: %w", err)
 	}
 
+	// SOPS/age-encrypted inventories are decrypted transparently
+	data, err = secrets.Decrypt(path, data)
+	if err != nil {
+		return nil, err
+	}
+
 	// Try YAML first
 	inv, err := ParseYAML(data)
+	if err == nil && len(inv.Discovery) > 0 {
+		// Dynamic groups are looked up fresh on every load (i.e., every run)
+		if err := inv.resolveDiscovery(context.Background()); err != nil {
+			return nil, err
+		}
+		return inv, nil
+	}
 	if err == nil && len(inv.Groups) > 0 {
 		return inv, nil
 	}
 
 	// Try INI/Ansible format
-	inv, err = ParseINI(path)
+	inv, err = parseINI(bytes.NewReader(data))
 	if err == nil && len(inv.Groups) > 0 {
 		return inv, nil
 	}
@@ -108,8 +140,11 @@ func ParseYAML(data []byte) (*Inventory, error) {
 		return nil, err
 	}
 
-	// Expand group references (e.g., "@spines")
-	inv.expandReferences()
+	// Expand group references (e.g., "@spines"), once discovery has filled
+	// in its groups if there is any
+	if len(inv.Discovery) == 0 {
+		inv.expandReferences()
+	}
 
 	return &inv, nil
 }
@@ -148,12 +183,18 @@ func ParseINI(path string) (*Inventory, error) {
 	}
 	defer file.Close()
 
+	return parseINI(file)
+}
+
+// parseINI parses Ansible-style INI inventory from a reader
+func parseINI(r io.Reader) (*Inventory, error) {
 	inv := &Inventory{
 		Groups: make(map[string][]string),
 	}
 
 	var currentGroup string
-	scanner := bufio.NewScanner(file)
+	var inVars bool
+	scanner := bufio.NewScanner(r)
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -167,6 +208,7 @@ func ParseINI(path string) (*Inventory, error) {
 		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
 			currentGroup = strings.Trim(line, "[]")
 			// Handle :children and :vars suffixes
+			inVars = strings.HasSuffix(currentGroup, ":vars")
 			if idx := strings.Index(currentGroup, ":"); idx != -1 {
 				currentGroup = currentGroup[:idx]
 			}
@@ -176,36 +218,75 @@ func ParseINI(path string) (*Inventory, error) {
 			continue
 		}
 
+		// Group variables become metadata for every member; Ansible
+		// connection variables (ansible_*) are left out, as for hosts
+		if inVars {
+			if k, v, ok := strings.Cut(line, "="); ok && !strings.HasPrefix(strings.TrimSpace(k), "ansible_") {
+				if inv.GroupVars == nil {
+					inv.GroupVars = make(map[string]GroupVars)
+				}
+				gv := inv.GroupVars[currentGroup]
+				if gv.Vars == nil {
+					gv.Vars = make(map[string]string)
+				}
+				gv.Vars[strings.TrimSpace(k)] = strings.TrimSpace(v)
+				inv.GroupVars[currentGroup] = gv
+			}
+			continue
+		}
+
 		// Host entry
 		if currentGroup != "" {
-			host := parseINIHost(line)
+			host, vars := parseINIHost(line)
 			if host != "" {
 				inv.Groups[currentGroup] = append(inv.Groups[currentGroup], host)
 			}
+			if len(vars) > 0 {
+				if inv.Hosts == nil {
+					inv.Hosts = make(map[string]Host)
+				}
+				h := inv.Hosts[host]
+				if h.Vars == nil {
+					h.Vars = make(map[string]string)
+				}
+				for k, v := range vars {
+					h.Vars[k] = v
+				}
+				inv.Hosts[host] = h
+			}
 		}
 	}
 
 	return inv, scanner.Err()
 }
 
-// parseINIHost extracts host address from an INI line
-func parseINIHost(line string) string {
+// parseINIHost extracts the host address and its variables from an INI
+// line. Ansible connection variables (ansible_*) aren't returned as vars.
+func parseINIHost(line string) (string, map[string]string) {
 	// Split on whitespace
 	fields := strings.Fields(line)
 	if len(fields) == 0 {
-		return ""
+		return "", nil
 	}
 
 	host := fields[0]
-
-	// Look for ansible_host variable
+	var vars map[string]string
 	for _, field := range fields[1:] {
-		if strings.HasPrefix(field, "ansible_host=") {
-			return strings.TrimPrefix(field, "ansible_host=")
+		k, v, ok := strings.Cut(field, "=")
+		switch {
+		case !ok:
+		case k == "ansible_host":
+			// Look for ansible_host variable
+			host = v
+		case !strings.HasPrefix(k, "ansible_"):
+			if vars == nil {
+				vars = make(map[string]string)
+			}
+			vars[k] = v
 		}
 	}
 
-	return host
+	return host, vars
 }
 
 // GetGroup returns all hosts in a group
@@ -231,6 +312,65 @@ func (inv *Inventory) GetAllHosts() []string {
 	return hosts
 }
 
+// GroupsFor returns the groups a host belongs to, most specific first.
+// Smaller groups are considered more specific; ties are broken by name.
+func (inv *Inventory) GroupsFor(name string) []string {
+	var groups []string
+	for group, members := range inv.Groups {
+		for _, member := range members {
+			if member == name {
+				groups = append(groups, group)
+				break
+			}
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		ni, nj := len(inv.Groups[groups[i]]), len(inv.Groups[groups[j]])
+		if ni != nj {
+			return ni < nj
+		}
+		return groups[i] < groups[j]
+	})
+	return groups
+}
+
+// GetPlatform returns the platform for a host: host setting first, then the
+// most specific group that sets one, then inventory defaults
+func (inv *Inventory) GetPlatform(name string) string {
+	if host, ok := inv.Hosts[name]; ok && host.Platform != "" {
+		return host.Platform
+	}
+	for _, group := range inv.GroupsFor(name) {
+		if vars, ok := inv.GroupVars[group]; ok && vars.Platform != "" {
+			return vars.Platform
+		}
+	}
+	return inv.Defaults.Platform
+}
+
+// VarsFor returns a host's metadata: group vars from the least to the most
+// specific group, overridden by the host's own vars. It returns nil when
+// nothing is set.
+func (inv *Inventory) VarsFor(name string) map[string]string {
+	var vars map[string]string
+	set := func(from map[string]string) {
+		for k, v := range from {
+			if vars == nil {
+				vars = make(map[string]string)
+			}
+			vars[k] = v
+		}
+	}
+
+	groups := inv.GroupsFor(name)
+	for i := len(groups) - 1; i >= 0; i-- {
+		set(inv.GroupVars[groups[i]].Vars)
+	}
+	set(inv.Hosts[name].Vars)
+	return vars
+}
+
 // ListGroups returns all group names
 func (inv *Inventory) ListGroups() []string {
 	names := make([]string, 0, len(inv.Groups))
@@ -255,11 +395,50 @@ func (inv *Inventory) ResolveHost(name string) string {
 	}
 
 	// Add port if specified and not already in address
-	if port != 0 && !strings.Contains(address, ":") {
-		return fmt.Sprintf("%s:%d", address, port)
+	return AddPort(address, port)
+}
+
+// HasPort reports whether an address already carries an explicit port.
+// Bare IPv6 literals (e.g., "2001:db8::1") are recognized as having no port.
+// gRPC target strings with a scheme (unix:///var/run/gnmi.sock, dns:///host:6030)
+// are complete as written and are never given a port.
+func HasPort(address string) bool {
+	if IsDialTarget(address) {
+		return true
 	}
+	if strings.HasPrefix(address, "[") {
+		return strings.Contains(address, "]:")
+	}
+	// More than one colon without brackets can only be an IPv6 literal
+	if strings.Count(address, ":") > 1 {
+		return false
+	}
+	return strings.Contains(address, ":")
+}
 
-	return address
+// IsDialTarget reports whether address is a full gRPC target string with a
+// scheme (e.g., "unix:///var/run/gnmi.sock", "passthrough:///10.0.0.1:6030")
+func IsDialTarget(address string) bool {
+	scheme, rest, ok := strings.Cut(address, ":")
+	if !ok || scheme == "" || !strings.HasPrefix(rest, "/") {
+		return false
+	}
+	for _, r := range scheme {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '+' || r == '-' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// AddPort appends port to address unless the address already has one or port is 0.
+// IPv6 literals are bracketed as needed.
+func AddPort(address string, port int) string {
+	if address == "" || port == 0 || HasPort(address) {
+		return address
+	}
+	host := strings.TrimSuffix(strings.TrimPrefix(address, "["), "]")
+	return net.JoinHostPort(host, strconv.Itoa(port))
 }
 
 // ResolveHosts returns resolved addresses for a list of host names
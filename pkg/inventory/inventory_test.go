@@ -0,0 +1,193 @@
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAddPort(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		port    int
+		want    string
+	}{
+		{"hostname", "spine1", 6030, "spine1:6030"},
+		{"hostname with port", "spine1:57400", 6030, "spine1:57400"},
+		{"ipv4", "10.0.0.1", 6030, "10.0.0.1:6030"},
+		{"ipv4 with port", "10.0.0.1:57400", 6030, "10.0.0.1:57400"},
+		{"ipv6 literal", "2001:db8::1", 6030, "[2001:db8::1]:6030"},
+		{"bracketed ipv6", "[2001:db8::1]", 6030, "[2001:db8::1]:6030"},
+		{"bracketed ipv6 with port", "[2001:db8::1]:57400", 6030, "[2001:db8::1]:57400"},
+		{"no default port", "spine1", 0, "spine1"},
+		{"empty address", "", 6030, ""},
+		{"unix socket", "unix:///var/run/gnmi.sock", 6030, "unix:///var/run/gnmi.sock"},
+		{"dns target", "dns:///spine1.example.com:6030", 6030, "dns:///spine1.example.com:6030"},
+		{"passthrough ipv6", "passthrough:///[2001:db8::1]:6030", 57400, "passthrough:///[2001:db8::1]:6030"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AddPort(tt.address, tt.port); got != tt.want {
+				t.Errorf("AddPort(%q, %d) = %q, want %q", tt.address, tt.port, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveHost(t *testing.T) {
+	inv := &Inventory{
+		Hosts: map[string]Host{
+			"spine1": {Address: "10.0.0.1"},
+			"spine2": {Address: "2001:db8::2", Port: 57400},
+		},
+		Defaults: Defaults{Port: 6030},
+	}
+
+	tests := []struct {
+		name string
+		host string
+		want string
+	}{
+		{"host address with default port", "spine1", "10.0.0.1:6030"},
+		{"ipv6 host with host port", "spine2", "[2001:db8::2]:57400"},
+		{"unknown host gets default port", "leaf1", "leaf1:6030"},
+		{"explicit port kept", "leaf1:6031", "leaf1:6031"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inv.ResolveHost(tt.host); got != tt.want {
+				t.Errorf("ResolveHost(%q) = %q, want %q", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPlatform(t *testing.T) {
+	inv := &Inventory{
+		Groups: map[string][]string{
+			"spines": {"spine1", "spine2"},
+			"srl":    {"spine2"},
+			"all":    {"spine1", "spine2", "leaf1"},
+		},
+		GroupVars: map[string]GroupVars{
+			"spines": {Platform: "arista_eos"},
+			"srl":    {Platform: "nokia_srlinux"},
+		},
+		Hosts: map[string]Host{
+			"leaf1": {Platform: "cisco_xr"},
+		},
+		Defaults: Defaults{Platform: "juniper"},
+	}
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"spine1", "arista_eos"},
+		{"spine2", "nokia_srlinux"}, // smaller group is more specific
+		{"leaf1", "cisco_xr"},
+		{"other", "juniper"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := inv.GetPlatform(tt.host); got != tt.want {
+				t.Errorf("GetPlatform(%q) = %q, want %q", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVarsFor(t *testing.T) {
+	inv := &Inventory{
+		Groups: map[string][]string{
+			"dc1":    {"spine1", "leaf1"},
+			"spines": {"spine1"},
+		},
+		GroupVars: map[string]GroupVars{
+			"dc1":    {Vars: map[string]string{"site": "dc1", "role": "fabric"}},
+			"spines": {Vars: map[string]string{"role": "spine"}},
+		},
+		Hosts: map[string]Host{
+			"spine1": {Vars: map[string]string{"pod": "1"}},
+		},
+	}
+
+	tests := []struct {
+		host string
+		want map[string]string
+	}{
+		{"spine1", map[string]string{"site": "dc1", "role": "spine", "pod": "1"}},
+		{"leaf1", map[string]string{"site": "dc1", "role": "fabric"}},
+		{"other", nil},
+	}
+	for _, tt := range tests {
+		if got := inv.VarsFor(tt.host); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("VarsFor(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestParseINIVars(t *testing.T) {
+	data := `
+[spines]
+spine1 ansible_host=10.0.0.1 ansible_user=admin site=dc1
+
+[spines:vars]
+role=spine
+ansible_user=admin
+ansible_password = secret
+ansible_network_os=eos
+`
+	inv, err := parseINI(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("parseINI() error = %v", err)
+	}
+	if hosts := inv.Groups["spines"]; !reflect.DeepEqual(hosts, []string{"10.0.0.1"}) {
+		t.Errorf("spines = %v", hosts)
+	}
+	want := map[string]string{"site": "dc1", "role": "spine"}
+	if got := inv.VarsFor("10.0.0.1"); !reflect.DeepEqual(got, want) {
+		t.Errorf("VarsFor() = %v, want %v", got, want)
+	}
+}
+
+func TestAutoDiscover(t *testing.T) {
+	const valid = "groups:\n  spines: [spine1]\n"
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string // Path found; empty for none
+	}{
+		{"none", nil, ""},
+		{"yaml before ini", map[string]string{"inventory.yaml": valid, "inventory.ini": "[spines]\nspine1\n"}, "inventory.yaml"},
+		{"inventory before hosts", map[string]string{"hosts.yaml": valid, "inventory": "[spines]\nspine1\n"}, "inventory"},
+		{"unparseable file skipped", map[string]string{"inventory.yaml": "- not an inventory\n", "hosts": valid}, "hosts"},
+		{"only unparseable files", map[string]string{"inventory.yaml": "- not an inventory\n"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, data := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			t.Chdir(dir)
+
+			inv, path, err := AutoDiscover()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if path != tt.want || (inv != nil) != (tt.want != "") {
+				t.Errorf("AutoDiscover() = %v, %q, want %q", inv, path, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// Package jsonschema describes netsert's YAML file formats as JSON Schema
+// documents, for editors to complete and check files with
+package jsonschema
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/config"
+	"github.com/ndtobs/netsert/pkg/inventory"
+)
+
+// Schema is a JSON Schema document or subschema
+type Schema map[string]any
+
+// formats maps each file format to its top-level type and title
+var formats = map[string]struct {
+	value any
+	title string
+}{
+	"assertions": {assertion.AssertionFile{}, "netsert assertions"},
+	"inventory":  {inventory.Inventory{}, "netsert inventory"},
+	"config":     {config.Config{}, "netsert config"},
+}
+
+// Formats lists the file formats For describes
+func Formats() []string {
+	return slices.Sorted(maps.Keys(formats))
+}
+
+// For returns the JSON Schema (draft-07, as editors support best) of a
+// file format: assertions, inventory, or config
+func For(format string) (Schema, error) {
+	f, ok := formats[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q (%s)", format, strings.Join(Formats(), ", "))
+	}
+	g := &generator{names: make(map[reflect.Type]string), definitions: make(Schema)}
+	s := g.object(reflect.TypeOf(f.value))
+	s["$schema"] = "http://json-schema.org/draft-07/schema#"
+	s["title"] = f.title
+	if len(g.definitions) > 0 {
+		s["definitions"] = g.definitions
+	}
+	return s, nil
+}
+
+// scalar matches what YAML decodes into a string: numbers and booleans are
+// taken as written
+var scalar = Schema{"type": []string{"string", "number", "boolean"}}
+
+// templateUse matches the <template>: <arguments> form of use: entries,
+// which TemplateUse decodes itself
+var templateUse = Schema{
+	"type":          "object",
+	"minProperties": 1,
+	"maxProperties": 1,
+	"additionalProperties": Schema{"oneOf": []Schema{
+		{"type": "array", "items": scalar},
+		{"type": "object", "additionalProperties": scalar},
+		{"type": "null"},
+	}},
+}
+
+// generator builds a schema from Go types and their yaml tags. Structs
+// other than the top-level one become definitions, referenced by name.
+type generator struct {
+	names       map[reflect.Type]string
+	definitions Schema
+}
+
+func (g *generator) schema(t reflect.Type) Schema {
+	if t == reflect.TypeOf(assertion.TemplateUse{}) {
+		return templateUse
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schema(t.Elem())
+	case reflect.String:
+		return scalar
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		return g.ref(t)
+	}
+	// Interfaces take any YAML value, e.g. equals_json
+	return Schema{}
+}
+
+// ref adds a struct to the definitions once and refers to it
+func (g *generator) ref(t reflect.Type) Schema {
+	name, ok := g.names[t]
+	if !ok {
+		name = t.Name()
+		// Packages reuse names, e.g. config and inventory Defaults
+		if _, taken := g.definitions[name]; taken {
+			name = t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:] + "." + name
+		}
+		g.names[t] = name
+		g.definitions[name] = nil // Reserved while recursing
+		g.definitions[name] = g.object(t)
+	}
+	return Schema{"$ref": "#/definitions/" + name}
+}
+
+// object describes a struct's yaml fields, inline ones included
+func (g *generator) object(t reflect.Type) Schema {
+	properties := make(Schema)
+	g.fields(t, properties)
+	return Schema{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func (g *generator) fields(t reflect.Type, properties Schema) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if slices.Contains(strings.Split(opts, ","), "inline") {
+			g.fields(f.Type, properties)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		properties[name] = g.schema(f.Type)
+	}
+}
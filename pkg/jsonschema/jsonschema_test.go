@@ -0,0 +1,50 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFor(t *testing.T) {
+	tests := []struct {
+		format     string
+		definition string
+		has        []string
+		lacks      []string
+	}{
+		{"assertions", "Assertion", []string{"path", "equals", "for_each", "equals_json"}, []string{"rawpath", "args", "file"}},
+		{"assertions", "Target", []string{"host", "use", "metadata"}, []string{"groups"}},
+		{"inventory", "Host", []string{"address", "vars"}, nil},
+		// Connection settings are inlined
+		{"config", "Defaults", []string{"inventory", "proxy", "ca_file"}, []string{"connection"}},
+	}
+
+	for _, tt := range tests {
+		s, err := For(tt.format)
+		if err != nil {
+			t.Fatalf("For(%q) error = %v", tt.format, err)
+		}
+		if _, err := json.Marshal(s); err != nil {
+			t.Fatalf("For(%q) doesn't marshal: %v", tt.format, err)
+		}
+		def, ok := s["definitions"].(Schema)[tt.definition].(Schema)
+		if !ok {
+			t.Fatalf("For(%q) has no definition %s", tt.format, tt.definition)
+		}
+		properties := def["properties"].(Schema)
+		for _, name := range tt.has {
+			if _, ok := properties[name]; !ok {
+				t.Errorf("%s %s lacks property %s", tt.format, tt.definition, name)
+			}
+		}
+		for _, name := range tt.lacks {
+			if _, ok := properties[name]; ok {
+				t.Errorf("%s %s has property %s", tt.format, tt.definition, name)
+			}
+		}
+	}
+
+	if _, err := For("hosts"); err == nil {
+		t.Error("For(\"hosts\") succeeded")
+	}
+}
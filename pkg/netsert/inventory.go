@@ -0,0 +1,156 @@
+package netsert
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/inventory"
+)
+
+// ExpandGroups resolves targets through the inventory: @group targets become
+// one target per host, and every target gets its address, groups, platform,
+// and metadata from the inventory. With filterGroup, only hosts in that group
+// are kept. af is left unchanged.
+func ExpandGroups(af *assertion.AssertionFile, inv *inventory.Inventory, filterGroup string) (*assertion.AssertionFile, error) {
+	var newTargets []assertion.Target
+
+	for _, target := range af.Targets {
+		// Check if this target references a group (starts with @)
+		if strings.HasPrefix(target.GetHost(), "@") {
+			groupName := strings.TrimPrefix(target.GetHost(), "@")
+			hosts, ok := inv.GetGroup(groupName)
+			if !ok {
+				// Group not found, keep as-is (will fail later with connection error)
+				newTargets = append(newTargets, target)
+				continue
+			}
+
+			// Create a target for each host in the group
+			for _, host := range hosts {
+				newTarget := target
+				newTarget.Host = inv.ResolveHost(host) // Resolve to address:port
+				newTarget.Address = ""                 // Clear deprecated field
+				newTarget.Groups = inv.GroupsFor(host)
+				newTarget.Metadata = targetMetadata(inv, host, target.Metadata)
+				if err := applyInventoryPlatform(&newTarget, inv, host); err != nil {
+					return nil, err
+				}
+				newTargets = append(newTargets, newTarget)
+			}
+		} else {
+			// Non-group target - still resolve through inventory if available
+			newTarget := target
+			newTarget.Host = inv.ResolveHost(target.GetHost())
+			newTarget.Address = ""
+			newTarget.Groups = inv.GroupsFor(target.GetHost())
+			newTarget.Metadata = targetMetadata(inv, target.GetHost(), target.Metadata)
+			if err := applyInventoryPlatform(&newTarget, inv, target.GetHost()); err != nil {
+				return nil, err
+			}
+			newTargets = append(newTargets, newTarget)
+		}
+	}
+
+	// Filter by group if specified
+	if filterGroup != "" {
+		hosts, ok := inv.GetGroup(filterGroup)
+		if ok {
+			// Build set of resolved addresses for hosts in the filter group
+			hostSet := make(map[string]bool)
+			for _, h := range hosts {
+				hostSet[inv.ResolveHost(h)] = true
+			}
+
+			var filtered []assertion.Target
+			for _, t := range newTargets {
+				if hostSet[t.GetHost()] {
+					filtered = append(filtered, t)
+				}
+			}
+			newTargets = filtered
+		}
+	}
+
+	expanded := *af
+	expanded.Targets = newTargets
+	return &expanded, nil
+}
+
+// targetMetadata merges a host's inventory vars under the metadata set in the
+// assertion file, returning a new map per host
+func targetMetadata(inv *inventory.Inventory, host string, inline map[string]string) map[string]string {
+	metadata := inv.VarsFor(host)
+	if metadata == nil && len(inline) > 0 {
+		metadata = make(map[string]string)
+	}
+	maps.Copy(metadata, inline)
+	return metadata
+}
+
+// applyInventoryPlatform sets the target's platform from inventory (unless the
+// assertion file set one) and re-expands short paths for that platform
+func applyInventoryPlatform(target *assertion.Target, inv *inventory.Inventory, host string) error {
+	if target.Platform != "" {
+		return nil
+	}
+	platform, err := assertion.ParsePlatform(inv.GetPlatform(host))
+	if err != nil {
+		return fmt.Errorf("inventory host %s: %w", host, err)
+	}
+	if platform == assertion.PlatformGeneric {
+		return nil
+	}
+	target.Platform = string(platform)
+	target.ExpandPaths()
+	return nil
+}
+
+// CheckInventory reports what the inventory can't resolve before a run
+// would: errors for @group targets and a group filter naming groups the
+// inventory lacks, and warnings for empty groups, groups: sections no
+// inventory group matches, and targets the group filter would drop.
+func CheckInventory(af *assertion.AssertionFile, inv *inventory.Inventory, filterGroup string) (errs, warnings []string) {
+	filterGroup = strings.TrimPrefix(filterGroup, "@")
+	var filter map[string]bool
+	if filterGroup != "" {
+		hosts, ok := inv.GetGroup(filterGroup)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("group %s (from -g) is not in the inventory", filterGroup))
+		} else {
+			filter = make(map[string]bool, len(hosts))
+			for _, h := range hosts {
+				filter[inv.ResolveHost(h)] = true
+			}
+		}
+	}
+
+	for _, t := range af.Targets {
+		name, isGroup := strings.CutPrefix(t.GetHost(), "@")
+		if !isGroup {
+			if filter != nil && !filter[inv.ResolveHost(name)] {
+				warnings = append(warnings, fmt.Sprintf("host %s is not in group %s; -g skips it", name, filterGroup))
+			}
+			continue
+		}
+
+		hosts, ok := inv.GetGroup(name)
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Sprintf("target @%s: group %s is not in the inventory", name, name))
+		case len(hosts) == 0:
+			warnings = append(warnings, fmt.Sprintf("target @%s: group %s has no hosts", name, name))
+		case filter != nil && !slices.ContainsFunc(hosts, func(h string) bool { return filter[inv.ResolveHost(h)] }):
+			warnings = append(warnings, fmt.Sprintf("target @%s: no host is also in group %s; -g skips it", name, filterGroup))
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(af.Groups)) {
+		if _, ok := inv.GetGroup(name); !ok {
+			warnings = append(warnings, fmt.Sprintf("groups: %s is not in the inventory, so its assertions apply to no target", name))
+		}
+	}
+	return errs, warnings
+}
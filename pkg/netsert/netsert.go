@@ -0,0 +1,189 @@
+// Package netsert runs netsert assertions from Go: load an assertion file,
+// prepare it against an inventory, and run it with the same behavior as
+// netsert run, receiving typed results.
+//
+//	af, err := netsert.LoadFile("fabric.yaml")
+//	if err != nil {
+//		return err
+//	}
+//	report, err := netsert.Run(ctx, af, netsert.Options{
+//		Config:   cfg,
+//		OnResult: func(res *netsert.Result) { log.Println(res.Assertion.GetName(), res.GetStatus()) },
+//	})
+package netsert
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/config"
+	"github.com/ndtobs/netsert/pkg/inventory"
+	"github.com/ndtobs/netsert/pkg/runner"
+)
+
+// Types from the packages netsert is built on, so embedders need only this one
+type (
+	File         = assertion.AssertionFile
+	Target       = assertion.Target
+	Assertion    = assertion.Assertion
+	Result       = assertion.Result
+	Status       = assertion.Status
+	Report       = runner.RunResult
+	TargetReport = runner.TargetResult
+	Config       = config.Config
+	Inventory    = inventory.Inventory
+	OperatorFunc = assertion.OperatorFunc
+)
+
+// Result statuses
+const (
+	StatusPass            = assertion.StatusPass
+	StatusFail            = assertion.StatusFail
+	StatusError           = assertion.StatusError
+	StatusTimeout         = assertion.StatusTimeout
+	StatusUnauthenticated = assertion.StatusUnauthenticated
+	StatusUnsupported     = assertion.StatusUnsupported
+	StatusSkipped         = assertion.StatusSkipped
+	StatusBlocked         = assertion.StatusBlocked
+)
+
+// RegisterOperator adds a custom operator for assertions to use as
+// operator: <name>, with an optional arg:. Register before loading files.
+func RegisterOperator(name string, fn OperatorFunc) error {
+	return assertion.RegisterOperator(name, fn)
+}
+
+// SetPathPrefix adds a short path prefix of your own, as the config's
+// short_paths do for the CLI. Set prefixes before loading files using them.
+func SetPathPrefix(name, template string) error {
+	return assertion.SetPathPrefix(name, template)
+}
+
+// LoadFile loads and validates an assertion file
+func LoadFile(path string) (*File, error) {
+	return assertion.LoadFile(path)
+}
+
+// LoadFiles loads files and directories of them as one suite, merging
+// targets listed in several
+func LoadFiles(paths ...string) (*File, error) {
+	return assertion.LoadFiles(paths...)
+}
+
+// Parse parses and validates assertion YAML
+func Parse(data []byte) (*File, error) {
+	return assertion.Parse(data)
+}
+
+// LoadConfig loads the netsert config from its standard locations, or
+// returns an empty config if there is none
+func LoadConfig() (*Config, error) {
+	return config.Load()
+}
+
+// LoadConfigFile loads the netsert config from path
+func LoadConfigFile(path string) (*Config, error) {
+	return config.LoadFile(path)
+}
+
+// LoadInventory loads a YAML or INI inventory
+func LoadInventory(path string) (*Inventory, error) {
+	return inventory.Load(path)
+}
+
+// Options configure Run. The zero value runs with netsert's defaults.
+type Options struct {
+	Config    *Config    // Credentials and connection settings (default: none)
+	Inventory *Inventory // Resolves @group targets, platforms, and metadata
+	Group     string     // Run only hosts in this inventory group
+
+	Timeout  time.Duration // Per assertion (default: 30s)
+	Workers  int           // Concurrent targets (default: 10)
+	Parallel int           // Concurrent assertions per target (default: 5)
+	FailFast bool          // Stop at the first assertion that doesn't pass
+
+	// Requests per second to each target and across all of them (default: unlimited)
+	RateLimit       float64
+	GlobalRateLimit float64
+
+	// OnResult, if set, is called with each result as it completes. Calls
+	// are serialized.
+	OnResult func(*Result)
+
+	// Output, if set, gets a line per result as netsert run prints them;
+	// Verbose adds timings and failure details. CompactPaths names results
+	// by short path (e.g., interface[Ethernet1]/...) where one exists.
+	Output       io.Writer
+	Verbose      bool
+	CompactPaths bool
+}
+
+// Prepare readies a loaded file for running: targets are resolved through
+// the inventory if there is one (see ExpandGroups), then shared
+// assertions, metadata, templates, and when: conditions are applied to a
+// copy, leaving af as loaded, so it can be prepared and run again.
+func Prepare(af *File, inv *Inventory, group string) (*File, error) {
+	af = af.Clone()
+	group = strings.TrimPrefix(group, "@")
+	if inv != nil {
+		expanded, err := ExpandGroups(af, inv, group)
+		if err != nil {
+			return nil, err
+		}
+
+		// Check if filtering resulted in no targets
+		if len(expanded.Targets) == 0 {
+			if group != "" {
+				return nil, fmt.Errorf("no targets match group %q - check that assertion file uses @group syntax or hosts are in the group", group)
+			}
+			return nil, fmt.Errorf("no targets found after expanding inventory groups")
+		}
+		af = expanded
+	}
+
+	af.ApplyShared()
+	for i := range af.Targets {
+		if err := af.Targets[i].ApplyMetadata(); err != nil {
+			return nil, fmt.Errorf("target %s: %w", af.Targets[i].GetHost(), err)
+		}
+	}
+	return af, nil
+}
+
+// NewRunner returns a runner configured from opts, for callers that run
+// prepared files themselves (e.g., repeatedly, sharing a connection pool)
+func NewRunner(opts Options) *runner.Runner {
+	r := runner.NewRunner(opts.Output)
+	if opts.Timeout > 0 {
+		r.Timeout = opts.Timeout
+	}
+	if opts.Workers > 0 {
+		r.Workers = opts.Workers
+	}
+	if opts.Parallel > 0 {
+		r.Parallel = opts.Parallel
+	}
+	r.Verbose = opts.Verbose
+	r.CompactPaths = opts.CompactPaths
+	r.FailFast = opts.FailFast
+	r.Config = opts.Config
+	r.RateLimit = opts.RateLimit
+	r.GlobalRateLimit = opts.GlobalRateLimit
+	r.OnResult = opts.OnResult
+	return r
+}
+
+// Run prepares af (see Prepare) and runs it. A target that can't be
+// reached fails its own assertions; the error return is for files that
+// can't be prepared and failed before hooks.
+func Run(ctx context.Context, af *File, opts Options) (*Report, error) {
+	af, err := Prepare(af, opts.Inventory, opts.Group)
+	if err != nil {
+		return nil, err
+	}
+	return NewRunner(opts).Run(ctx, af)
+}
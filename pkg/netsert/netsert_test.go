@@ -0,0 +1,191 @@
+package netsert
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ndtobs/netsert/pkg/gnmitest"
+	"github.com/ndtobs/netsert/pkg/inventory"
+)
+
+const fabric = `
+all:
+  - name: "{{ .host }} has a hostname"
+    path: system/state/hostname
+    exists: true
+targets:
+  - host: "@spines"
+    assertions:
+      - name: BGP AS
+        path: bgp[default]/global/state/as
+        equals: "65000"
+        when: {role: spine}
+`
+
+func TestPrepare(t *testing.T) {
+	af, err := Parse([]byte(fabric))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv := &inventory.Inventory{
+		Groups: map[string][]string{"spines": {"spine1", "spine2"}, "leaves": {"leaf1"}},
+		Hosts: map[string]inventory.Host{
+			"spine1": {Address: "10.0.0.1:6030", Vars: map[string]string{"role": "spine"}},
+			"spine2": {Address: "10.0.0.2:6030", Platform: "nokia_srlinux"},
+		},
+	}
+
+	prepared, err := Prepare(af, inv, "@spines")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prepared.Targets) != 2 {
+		t.Fatalf("Targets = %+v, want one per spine", prepared.Targets)
+	}
+	spine1, spine2 := prepared.Targets[0], prepared.Targets[1]
+	if spine1.Host != "10.0.0.1:6030" || len(spine1.Assertions) != 2 || spine1.Assertions[1].Name != "10.0.0.1:6030 has a hostname" {
+		t.Errorf("spine1 = %+v, want its address, BGP AS, and the rendered all: assertion", spine1)
+	}
+	// spine2 lacks role: spine, so only the shared assertion applies
+	if spine2.Platform != "nokia_srlinux" || len(spine2.Assertions) != 1 {
+		t.Errorf("spine2 = %+v, want the inventory platform and one assertion", spine2)
+	}
+
+	if _, err := Prepare(af, inv, "leaves"); err == nil {
+		t.Error("Prepare() with a group matching no targets succeeded")
+	}
+}
+
+func TestPrepareTwice(t *testing.T) {
+	// Without an inventory, targets are prepared in place of a copy
+	af, err := Parse([]byte(`
+all:
+  - name: "{{ .host }} has a hostname"
+    path: system/state/hostname
+    exists: true
+targets:
+  - host: spine1:6030
+    assertions:
+      - name: BGP AS
+        path: bgp[default]/global/state/as
+        equals: "65000"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for run := range 2 {
+		prepared, err := Prepare(af, nil, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := prepared.Targets[0].Assertions; len(got) != 2 || got[1].Name != "spine1:6030 has a hostname" {
+			t.Errorf("run %d: assertions = %+v, want BGP AS and the rendered all: assertion", run+1, got)
+		}
+	}
+	if got := af.Targets[0].Assertions; len(got) != 1 || af.All[0].Name != "{{ .host }} has a hostname" {
+		t.Errorf("loaded file changed: assertions %+v, all %+v", got, af.All)
+	}
+}
+
+func TestRunTwice(t *testing.T) {
+	srv, err := gnmitest.Start(map[string]any{"/system/state/hostname": "spine1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	af, err := Parse([]byte(`
+all:
+  - path: /system/state/hostname
+    equals: spine1
+targets:
+  - host: ` + srv.Addr() + `
+    insecure: true
+    assertions:
+      - path: /system/state/hostname
+        exists: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for run := range 2 {
+		report, err := Run(context.Background(), af, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.TotalAssertions != 2 || report.Passed != 2 {
+			t.Errorf("run %d: Run() = %d assertions, %+v; want the same 2 passing each run", run+1, report.TotalAssertions, report.Counts)
+		}
+	}
+}
+
+func TestCheckInventory(t *testing.T) {
+	af, err := Parse([]byte(`
+groups:
+  borders:
+    - path: /system/state/hostname
+      exists: true
+targets:
+  - host: "@spines"
+    assertions: [{path: /system/state/hostname, exists: true}]
+  - host: "@leaves"
+    assertions: [{path: /system/state/hostname, exists: true}]
+  - host: "@cores"
+    assertions: [{path: /system/state/hostname, exists: true}]
+  - host: router9:6030
+    assertions: [{path: /system/state/hostname, exists: true}]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv := &inventory.Inventory{
+		Groups: map[string][]string{"spines": {"spine1", "spine2"}, "leaves": {}},
+	}
+
+	tests := []struct {
+		group        string
+		errs, warned int
+	}{
+		// @cores is missing; @leaves is empty and borders unused
+		{"", 1, 2},
+		// router9 isn't a spine either
+		{"spines", 1, 3},
+		{"@spines", 1, 3},
+		{"wan", 2, 2},
+	}
+	for _, tt := range tests {
+		errs, warnings := CheckInventory(af, inv, tt.group)
+		if len(errs) != tt.errs || len(warnings) != tt.warned {
+			t.Errorf("CheckInventory(%q) = %q, %q; want %d errors and %d warnings", tt.group, errs, warnings, tt.errs, tt.warned)
+		}
+	}
+}
+
+func TestRunStatuses(t *testing.T) {
+	// Hosts without an inventory are run as written; a bad connection
+	// setting fails the target's assertions without failing the run
+	af, err := Parse([]byte(`
+targets:
+  - host: spine1:6030
+    assertions:
+      - path: /system/state/hostname
+        exists: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{}
+	cfg.Defaults.Compression = "lz4"
+
+	var streamed []Status
+	report, err := Run(context.Background(), af, Options{
+		Config:   cfg,
+		OnResult: func(res *Result) { streamed = append(streamed, res.GetStatus()) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Errors != 1 || len(streamed) != 1 || streamed[0] != StatusError {
+		t.Errorf("Run() = %+v, streamed %v, want one error", report.Counts, streamed)
+	}
+}
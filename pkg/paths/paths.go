@@ -0,0 +1,111 @@
+// Package paths provides a searchable index of common OpenConfig paths
+package paths
+
+import (
+	_ "embed"
+	"sort"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+)
+
+//go:embed index.tsv
+var indexData string
+
+// Entry is one indexed path
+type Entry struct {
+	Path        string // Full path with example key values
+	Type        string // YANG leaf type (string, bool, uint64, decimal, ...)
+	Description string
+}
+
+// Index returns every indexed path
+func Index() []Entry {
+	var entries []Entry
+	for _, line := range strings.Split(indexData, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		entries = append(entries, Entry{Path: fields[0], Type: fields[1], Description: fields[2]})
+	}
+	return entries
+}
+
+// Search returns entries matching every word of query (in the path or
+// description), best matches first
+func Search(query string) []Entry {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil
+	}
+
+	type scored struct {
+		entry Entry
+		score int
+	}
+	var matches []scored
+	for _, e := range Index() {
+		path := strings.ToLower(e.Path)
+		desc := strings.ToLower(e.Description)
+		score := 0
+		for _, w := range words {
+			inDesc, inPath := strings.Contains(desc, w), strings.Contains(path, w)
+			if !inDesc && !inPath {
+				score = -1
+				break
+			}
+			// Leaf name hits rank highest, then description, then elsewhere in the path
+			if strings.Contains(leafName(path), w) {
+				score += 3
+			}
+			if inDesc {
+				score += 2
+			}
+			if inPath {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, scored{e, score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return len(matches[i].entry.Path) < len(matches[j].entry.Path)
+	})
+
+	entries := make([]Entry, len(matches))
+	for i, m := range matches {
+		entries[i] = m.entry
+	}
+	return entries
+}
+
+// Stub returns a ready-to-edit assertion for the entry, with an operator
+// suited to the leaf type
+func (e Entry) Stub() assertion.Assertion {
+	a := assertion.Assertion{Path: assertion.CompactPath(e.Path)}
+	switch e.Type {
+	case "bool":
+		v := "true"
+		a.Equals = &v
+	case "string":
+		v := ""
+		a.Equals = &v
+	default:
+		v := "0"
+		a.GTE = &v
+	}
+	return a
+}
+
+func leafName(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
@@ -0,0 +1,59 @@
+package paths
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIndex(t *testing.T) {
+	entries := Index()
+	if len(entries) < 50 {
+		t.Fatalf("Index() returned %d entries, want at least 50", len(entries))
+	}
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Path, "/") || e.Type == "" || e.Description == "" {
+			t.Errorf("malformed entry %+v", e)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantTop string // Suffix of the best match; empty for no results
+	}{
+		{"transceiver rx power", "/input-power/instant"},
+		{"bgp session", "/state/session-state"},
+		{"HOSTNAME", "/system/state/hostname"},
+		{"in errors", "/counters/in-errors"},
+		{"no-such-thing", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := Search(tt.query)
+			if tt.wantTop == "" {
+				if len(got) != 0 {
+					t.Errorf("Search(%q) = %d results, want none", tt.query, len(got))
+				}
+				return
+			}
+			if len(got) == 0 || !strings.HasSuffix(got[0].Path, tt.wantTop) {
+				t.Errorf("Search(%q) top = %+v, want suffix %q", tt.query, got, tt.wantTop)
+			}
+		})
+	}
+}
+
+func TestStub(t *testing.T) {
+	e := Entry{Path: "/interfaces/interface[name=Ethernet1]/state/counters/in-errors", Type: "uint64"}
+	a := e.Stub()
+	if a.GTE == nil || a.Equals != nil {
+		t.Errorf("Stub() for uint64 = %+v, want gte", a)
+	}
+	e.Type = "string"
+	if a := e.Stub(); a.Equals == nil {
+		t.Errorf("Stub() for string = %+v, want equals", a)
+	}
+}
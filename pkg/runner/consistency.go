@@ -0,0 +1,102 @@
+package runner
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+)
+
+// checkConsistency compares the values of consistent: assertions across
+// targets, failing those that break the rule, and reports them as other
+// results are reported when they finish. Results that errored or weren't
+// run were reported already and aren't compared.
+func (r *Runner) checkConsistency(targets []*TargetResult, ff *failFast) {
+	type key struct{ name, path string }
+	var order []key
+	byKey := make(map[key][]*assertion.Result)
+	for _, tr := range targets {
+		for _, res := range tr.Results {
+			status := res.GetStatus()
+			if res.Assertion.Consistent == "" || (status != assertion.StatusPass && status != assertion.StatusFail) {
+				continue
+			}
+			k := key{res.Assertion.GetName(), res.Assertion.Path}
+			if _, ok := byKey[k]; !ok {
+				order = append(order, k)
+			}
+			byKey[k] = append(byKey[k], res)
+		}
+	}
+
+	for _, k := range order {
+		results := byKey[k]
+		compare(results)
+		for _, res := range results {
+			res.Status = ""
+			res.Status = res.GetStatus()
+			ff.record(res)
+			r.printResult(res)
+			r.emit(res)
+		}
+	}
+}
+
+// compare applies a consistent: rule to one assertion's results across
+// targets. Results that already failed their own checks keep failing but
+// their values still count.
+func compare(results []*assertion.Result) {
+	byValue := make(map[string][]string)
+	for _, res := range results {
+		byValue[res.ActualValue] = append(byValue[res.ActualValue], res.Target)
+	}
+
+	switch results[0].Assertion.Consistent {
+	case "identical":
+		if len(byValue) == 1 {
+			return
+		}
+		// The value most targets have is taken as right; with no single
+		// such value every target fails
+		var common string
+		most, tied := 0, false
+		for v, targets := range byValue {
+			switch {
+			case len(targets) > most:
+				common, most, tied = v, len(targets), false
+			case len(targets) == most:
+				tied = true
+			}
+		}
+		for _, res := range results {
+			if !tied && res.ActualValue == common {
+				continue
+			}
+			res.Passed = false
+			if tied {
+				res.Diff = "targets disagree: " + describeValues(byValue)
+			} else {
+				res.Diff = fmt.Sprintf("%d of %d targets have %s", most, len(results), common)
+			}
+		}
+	case "unique":
+		for _, res := range results {
+			others := slices.DeleteFunc(slices.Clone(byValue[res.ActualValue]), func(t string) bool { return t == res.Target })
+			if len(others) > 0 {
+				res.Passed = false
+				res.Diff = "same as " + strings.Join(others, ", ")
+			}
+		}
+	}
+}
+
+// describeValues lists each value with the targets that have it
+func describeValues(byValue map[string][]string) string {
+	var parts []string
+	for _, v := range slices.Sorted(maps.Keys(byValue)) {
+		parts = append(parts, fmt.Sprintf("%s on %s", v, strings.Join(byValue[v], ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+)
+
+func TestCheckConsistency(t *testing.T) {
+	version := assertion.Assertion{Name: "same version", Path: "/system/state/software-version", Consistent: "identical"}
+	routerID := assertion.Assertion{Name: "unique router-id", Path: "/bgp/global/state/router-id", Consistent: "unique"}
+	split := assertion.Assertion{Name: "same domain", Path: "/system/config/domain-name", Consistent: "identical"}
+	result := func(target string, a assertion.Assertion, actual string) *assertion.Result {
+		return &assertion.Result{Target: target, Assertion: a, ActualValue: actual, Passed: true, Status: assertion.StatusPass}
+	}
+
+	targets := []*TargetResult{
+		{Target: "spine1", Results: []*assertion.Result{
+			result("spine1", version, "4.30.1F"), result("spine1", routerID, "10.0.0.1"), result("spine1", split, "a.example.com"),
+		}},
+		{Target: "spine2", Results: []*assertion.Result{
+			result("spine2", version, "4.30.1F"), result("spine2", routerID, "10.0.0.1"), result("spine2", split, "b.example.com"),
+		}},
+		{Target: "spine3", Results: []*assertion.Result{
+			result("spine3", version, "4.29.2F"), result("spine3", routerID, "10.0.0.3"),
+			// Errors were reported when they happened
+			{Target: "spine3", Assertion: split, Error: errors.New("path does not exist"), Status: assertion.StatusError},
+		}},
+	}
+	r := NewRunner(nil)
+	var emitted int
+	r.OnResult = func(*assertion.Result) { emitted++ }
+	r.checkConsistency(targets, &failFast{})
+
+	want := map[string][]assertion.Status{
+		"spine1": {assertion.StatusPass, assertion.StatusFail, assertion.StatusFail},
+		"spine2": {assertion.StatusPass, assertion.StatusFail, assertion.StatusFail},
+		"spine3": {assertion.StatusFail, assertion.StatusPass, assertion.StatusError},
+	}
+	for _, tr := range targets {
+		for i, res := range tr.Results {
+			if got := res.GetStatus(); got != want[tr.Target][i] {
+				t.Errorf("%s %s = %s (%s), want %s", tr.Target, res.Assertion.Name, got, res.Diff, want[tr.Target][i])
+			}
+		}
+	}
+	if diff := targets[2].Results[0].Diff; diff != "2 of 3 targets have 4.30.1F" {
+		t.Errorf("odd one out diff = %q", diff)
+	}
+	if diff := targets[0].Results[1].Diff; diff != "same as spine2" {
+		t.Errorf("duplicate diff = %q", diff)
+	}
+	if emitted != 8 {
+		t.Errorf("emitted %d results, want the 8 compared", emitted)
+	}
+}
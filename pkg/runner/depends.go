@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+)
+
+// dependencies resolves each assertion's depends_on names to the indexes of
+// the target's assertions with those names. Assertions naming an unknown
+// assertion, or caught in a dependency cycle, get an error instead of
+// dependencies, so nothing waits on them forever.
+func dependencies(assertions []assertion.Assertion) ([][]int, []error) {
+	byName := make(map[string][]int)
+	for i, a := range assertions {
+		byName[a.GetName()] = append(byName[a.GetName()], i)
+	}
+
+	deps := make([][]int, len(assertions))
+	errs := make([]error, len(assertions))
+	for i, a := range assertions {
+		for _, name := range a.DependsOn {
+			idx, ok := byName[name]
+			if !ok {
+				errs[i] = fmt.Errorf("depends_on: no assertion named %q", name)
+				deps[i] = nil
+				break
+			}
+			deps[i] = append(deps[i], idx...)
+		}
+	}
+
+	// Peel off assertions whose dependencies are all resolved; what's left
+	// is a cycle or waits on one
+	dependents := make([][]int, len(assertions))
+	pending := make([]int, len(assertions))
+	var ready []int
+	for i, d := range deps {
+		pending[i] = len(d)
+		for _, j := range d {
+			dependents[j] = append(dependents[j], i)
+		}
+		if len(d) == 0 {
+			ready = append(ready, i)
+		}
+	}
+	for len(ready) > 0 {
+		i := ready[0]
+		ready = ready[1:]
+		for _, j := range dependents[i] {
+			if pending[j]--; pending[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+	}
+	for i := range assertions {
+		if pending[i] > 0 {
+			errs[i] = fmt.Errorf("depends_on: cycle through %q", strings.Join(assertions[i].DependsOn, `", "`))
+			deps[i] = nil
+		}
+	}
+	return deps, errs
+}
+
+// blocked returns why an assertion can't run if one of its dependencies
+// didn't pass
+func blocked(deps []int, results []*assertion.Result) error {
+	for _, d := range deps {
+		if dep := results[d]; dep.GetStatus() != assertion.StatusPass {
+			return fmt.Errorf("depends on %q, which didn't pass (%s)", dep.Assertion.GetName(), dep.GetStatus())
+		}
+	}
+	return nil
+}
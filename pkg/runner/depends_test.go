@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+)
+
+func TestDependencies(t *testing.T) {
+	assertions := []assertion.Assertion{
+		{Name: "session up"},
+		{Name: "prefixes received", DependsOn: []string{"session up"}},
+		{Name: "default route", DependsOn: []string{"prefixes received", "session up"}},
+		{Name: "typo", DependsOn: []string{"sesion up"}},
+		{Name: "a", DependsOn: []string{"b"}},
+		{Name: "b", DependsOn: []string{"a"}},
+		{Name: "after cycle", DependsOn: []string{"a"}},
+	}
+	deps, errs := dependencies(assertions)
+
+	tests := []struct {
+		name    string
+		deps    []int
+		wantErr bool
+	}{
+		{"session up", nil, false},
+		{"prefixes received", []int{0}, false},
+		{"default route", []int{1, 0}, false},
+		{"typo", nil, true},
+		{"a", nil, true},
+		{"b", nil, true},
+		{"after cycle", nil, true},
+	}
+	for i, tt := range tests {
+		if !slices.Equal(deps[i], tt.deps) {
+			t.Errorf("%s: deps = %v, want %v", tt.name, deps[i], tt.deps)
+		}
+		if (errs[i] != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, errs[i], tt.wantErr)
+		}
+	}
+}
+
+func TestBlocked(t *testing.T) {
+	results := []*assertion.Result{
+		{Assertion: assertion.Assertion{Name: "session up"}, Passed: true},
+		{Assertion: assertion.Assertion{Name: "session down"}, Status: assertion.StatusFail},
+	}
+	if err := blocked([]int{0}, results); err != nil {
+		t.Errorf("blocked on a passing dependency: %v", err)
+	}
+	if err := blocked([]int{0, 1}, results); err == nil {
+		t.Error("not blocked on a failing dependency")
+	}
+}
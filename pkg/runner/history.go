@@ -0,0 +1,100 @@
+package runner
+
+import (
+	"github.com/ndtobs/netsert/pkg/assertion"
+)
+
+// AssertionHistory is one assertion's outcomes across repeated runs of the
+// same file
+type AssertionHistory struct {
+	Target string
+	Name   string
+	Path   string
+	Runs   int // Runs the assertion took part in
+	Passed int
+
+	Worst       assertion.Status // Most severe status seen
+	Transitions int              // Times the status changed from one run to the next
+
+	last assertion.Status
+}
+
+// FailureRate returns the percentage of runs the assertion didn't pass
+func (h AssertionHistory) FailureRate() float64 {
+	if h.Runs == 0 {
+		return 0
+	}
+	return 100 * float64(h.Runs-h.Passed) / float64(h.Runs)
+}
+
+// Flaky reports whether the assertion passed in some runs and not others
+func (h AssertionHistory) Flaky() bool {
+	return h.Passed > 0 && h.Passed < h.Runs
+}
+
+// severity orders statuses for Worst: failing to answer is worse than
+// answering wrong
+var severity = map[assertion.Status]int{
+	assertion.StatusPass:            0,
+	assertion.StatusBlocked:         1,
+	assertion.StatusFail:            2,
+	assertion.StatusUnsupported:     3,
+	assertion.StatusTimeout:         4,
+	assertion.StatusError:           5,
+	assertion.StatusUnauthenticated: 6,
+}
+
+// History collects each assertion's outcomes across runs, in the order the
+// assertions first appear. Skipped results aren't outcomes and are ignored.
+func History(runs []*RunResult) []AssertionHistory {
+	type key struct{ target, name, path string }
+	var order []key
+	seen := make(map[key]*AssertionHistory)
+
+	for _, run := range runs {
+		for _, tr := range run.Targets {
+			for _, res := range tr.Results {
+				status := res.GetStatus()
+				if status == assertion.StatusSkipped {
+					continue
+				}
+				k := key{res.Target, res.Assertion.GetName(), res.Assertion.Path}
+				h, ok := seen[k]
+				if !ok {
+					h = &AssertionHistory{Target: k.target, Name: k.name, Path: k.path, Worst: status}
+					seen[k] = h
+					order = append(order, k)
+				}
+				h.Runs++
+				if status == assertion.StatusPass {
+					h.Passed++
+				}
+				if severity[status] > severity[h.Worst] {
+					h.Worst = status
+				}
+				if h.last != "" && status != h.last {
+					h.Transitions++
+				}
+				h.last = status
+			}
+		}
+	}
+
+	history := make([]AssertionHistory, len(order))
+	for i, k := range order {
+		history[i] = *seen[k]
+	}
+	return history
+}
+
+// Flaky compares repeated runs of one file and returns the assertions with
+// inconsistent outcomes, in the order they first appear
+func Flaky(runs []*RunResult) []AssertionHistory {
+	var flaky []AssertionHistory
+	for _, h := range History(runs) {
+		if h.Flaky() {
+			flaky = append(flaky, h)
+		}
+	}
+	return flaky
+}
@@ -0,0 +1,58 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+)
+
+func TestFlaky(t *testing.T) {
+	result := func(name string, status assertion.Status) *assertion.Result {
+		return &assertion.Result{
+			Target:    "spine1:6030",
+			Assertion: assertion.Assertion{Name: name, Path: "/" + name},
+			Passed:    status == assertion.StatusPass,
+			Status:    status,
+		}
+	}
+	run := func(results ...*assertion.Result) *RunResult {
+		return &RunResult{Targets: []*TargetResult{{Target: "spine1:6030", Results: results}}}
+	}
+
+	runs := []*RunResult{
+		run(result("stable", assertion.StatusPass), result("churn", assertion.StatusPass), result("down", assertion.StatusFail)),
+		run(result("stable", assertion.StatusPass), result("churn", assertion.StatusFail), result("down", assertion.StatusFail)),
+		run(result("stable", assertion.StatusPass), result("churn", assertion.StatusTimeout), result("down", assertion.StatusSkipped)),
+		run(result("stable", assertion.StatusPass), result("churn", assertion.StatusPass), result("down", assertion.StatusFail)),
+	}
+
+	flaky := Flaky(runs)
+	if len(flaky) != 1 {
+		t.Fatalf("Flaky() = %+v, want only churn", flaky)
+	}
+	f := flaky[0]
+	if f.Name != "churn" || f.Runs != 4 || f.Passed != 2 {
+		t.Errorf("Flaky() = %+v, want churn passing 2 of 4", f)
+	}
+	if rate := f.FailureRate(); rate != 50 {
+		t.Errorf("FailureRate() = %v, want 50", rate)
+	}
+
+	history := History(runs)
+	if len(history) != 3 {
+		t.Fatalf("History() = %d assertions, want 3", len(history))
+	}
+	want := []struct {
+		worst       assertion.Status
+		transitions int
+	}{
+		{assertion.StatusPass, 0},
+		{assertion.StatusTimeout, 3},
+		{assertion.StatusFail, 0},
+	}
+	for i, h := range history {
+		if h.Worst != want[i].worst || h.Transitions != want[i].transitions {
+			t.Errorf("%s: worst %s, %d transitions; want %s, %d", h.Name, h.Worst, h.Transitions, want[i].worst, want[i].transitions)
+		}
+	}
+}
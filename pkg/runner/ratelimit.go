@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket allowing rate requests per second, in
+// bursts of up to one second's worth
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns a limiter for rate requests per second, or nil
+// (no limit) if rate isn't positive
+func newRateLimiter(rate float64) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	burst := math.Max(rate, 1)
+	return &rateLimiter{rate: rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// wait blocks until a request may be sent or ctx is done. A nil limiter
+// never blocks.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	// Take a token now, going into debt if there are none; the debt is
+	// how long this request waits
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// waitAll waits on each limiter in turn
+func waitAll(ctx context.Context, limiters ...*rateLimiter) error {
+	for _, l := range limiters {
+		if err := l.wait(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hostLimiter returns the limiter shared by every target at addr, in this
+// run and later ones, created for rate on first use
+func (r *Runner) hostLimiter(addr string, rate float64) *rateLimiter {
+	r.limitersMu.Lock()
+	defer r.limitersMu.Unlock()
+	if l, ok := r.limiters[addr]; ok {
+		return l
+	}
+	if r.limiters == nil {
+		r.limiters = make(map[string]*rateLimiter)
+	}
+	l := newRateLimiter(rate)
+	r.limiters[addr] = l
+	return l
+}
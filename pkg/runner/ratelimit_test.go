@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/gnmitest"
+)
+
+func TestRateLimiter(t *testing.T) {
+	if l := newRateLimiter(0); l != nil {
+		t.Fatalf("newRateLimiter(0) = %v, want nil", l)
+	}
+	var unlimited *rateLimiter
+	if err := unlimited.wait(context.Background()); err != nil {
+		t.Fatalf("nil limiter wait: %v", err)
+	}
+
+	// A full bucket of 50 goes at once, then 10 more take 200ms
+	l := newRateLimiter(50)
+	start := time.Now()
+	for range 60 {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond || elapsed > time.Second {
+		t.Errorf("60 requests at 50/s took %s, want about 200ms", elapsed)
+	}
+
+	// Waiting gives up when the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.wait(ctx); err == nil {
+		t.Error("wait with a canceled context and an empty bucket succeeded")
+	}
+}
+
+func TestRunRateLimitPerAddress(t *testing.T) {
+	srv, err := gnmitest.Start(map[string]any{"/system/state/hostname": "spine1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	// Two targets on one device share its limit: the first 10 RPCs empty
+	// the bucket and the next 10 wait a second, where separate buckets
+	// wouldn't wait at all
+	host := "spine1"
+	var assertions []assertion.Assertion
+	for range 10 {
+		assertions = append(assertions, assertion.Assertion{Path: "/system/state/hostname", Equals: &host})
+	}
+	target := assertion.Target{Host: srv.Addr(), Insecure: true, Assertions: assertions}
+	af := &assertion.AssertionFile{Targets: []assertion.Target{target, target}}
+
+	r := NewRunner(io.Discard)
+	r.RateLimit = 10
+	start := time.Now()
+	result, err := r.Run(context.Background(), af)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Passed != 20 {
+		t.Fatalf("Run() = %+v, want 20 passed", result.Counts)
+	}
+	if elapsed := time.Since(start); elapsed < 800*time.Millisecond {
+		t.Errorf("20 RPCs at 10/s to one address took %s, want about 1s", elapsed)
+	}
+	if l := r.hostLimiter(srv.Addr(), 0); l == nil || l.rate != 10 {
+		t.Errorf("hostLimiter(%s) = %+v, want the one the run created", srv.Addr(), l)
+	}
+}
@@ -2,40 +2,148 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ndtobs/netsert/pkg/assertion"
 	"github.com/ndtobs/netsert/pkg/config"
 	"github.com/ndtobs/netsert/pkg/gnmiclient"
+	"github.com/ndtobs/netsert/pkg/gnoiclient"
+	"github.com/ndtobs/netsert/pkg/hooks"
+	"github.com/ndtobs/netsert/pkg/inventory"
 )
 
 // Default concurrency settings
 const (
 	DefaultWorkers  = 10 // Concurrent targets
 	DefaultParallel = 5  // Concurrent assertions per target
+	DefaultRetries  = 2  // Retries for transient gNMI errors
 )
 
 // Runner executes assertions against targets
 type Runner struct {
 	Output   io.Writer
 	Timeout  time.Duration
-	Workers  int  // Concurrent targets
-	Parallel int  // Concurrent assertions per target
+	Workers  int // Concurrent targets
+	Parallel int // Concurrent assertions per target
 	Verbose  bool
+	FailFast bool // Stop at the first assertion that doesn't pass
 	Config   *config.Config
+	Pool     *gnmiclient.Pool // Optional; reuses connections across runs
+
+	// Fixtures, if set, record every target's gNMI responses, or replay
+	// recorded ones without connecting to the targets
+	Fixtures *gnmiclient.Fixtures
+
+	// CompactPaths shows paths in short form (see assertion.CompactPathFor)
+	// in results; verbose output and reports keep the full path too
+	CompactPaths bool
+
+	// RPC rate limits, in requests per second; zero is unlimited.
+	// RateLimit applies to each target address and overrides the
+	// configured rate_limit; GlobalRateLimit applies across all targets.
+	RateLimit       float64
+	GlobalRateLimit float64
+
+	// Capture, if set, records the value of every path read. Against, if
+	// set, replaces path assertions' operators with a comparison to the
+	// value it recorded, so any change fails.
+	Capture *Snapshot
+	Against *Snapshot
+
+	// OnResult, if set, is called with each result as it completes,
+	// including skipped assertions. Calls are serialized.
+	OnResult func(*assertion.Result)
+	resultMu sync.Mutex
+
+	limiters   map[string]*rateLimiter // By target address; see hostLimiter
+	limitersMu sync.Mutex
+
+	stopping atomic.Bool // See Stop
+}
+
+// Counts tallies results by outcome
+type Counts struct {
+	Passed  int
+	Failed  int
+	Errors  int
+	Skipped int // Not run because of FailFast or Stop
+	Blocked int // Not run because a dependency didn't pass
+}
+
+// add counts one result
+func (c *Counts) add(res *assertion.Result) {
+	switch {
+	case res.GetStatus() == assertion.StatusSkipped:
+		c.Skipped++
+	case res.GetStatus() == assertion.StatusBlocked:
+		c.Blocked++
+	case res.Error != nil:
+		c.Errors++
+	case res.Passed:
+		c.Passed++
+	default:
+		c.Failed++
+	}
 }
 
 // RunResult contains the results of a run
 type RunResult struct {
 	TotalAssertions int
-	Passed          int
-	Failed          int
-	Errors          int
-	Results         []*assertion.Result
-	Duration        time.Duration
+	Counts
+	Results  []*assertion.Result // Every result, in file order; OnResult sees them as they complete
+	Targets  []*TargetResult     // Per-target breakdown, in file order
+	Duration time.Duration
+
+	// HookErrors are failures of after and after_target hooks, which
+	// don't change any result
+	HookErrors []error
+}
+
+// TargetResult is the outcome of one target's assertions
+type TargetResult struct {
+	Target   string
+	Metadata map[string]string
+
+	// ConnectError is set when the target couldn't be reached; each of its
+	// assertions then reports the error
+	ConnectError error
+
+	Counts
+	Results  []*assertion.Result // In the order of the target's assertions
+	Duration time.Duration
+	RPCs     gnmiclient.RPCStats // gNMI requests made over the target's connection while it ran, and their latency
+
+	hookErrors []error // after_target hook failures, gathered into RunResult
+}
+
+// Connected reports whether the target was reached
+func (t *TargetResult) Connected() bool {
+	return t.ConnectError == nil
+}
+
+// OK reports whether the target was reached and none of its assertions
+// failed or errored
+func (t *TargetResult) OK() bool {
+	return t.Connected() && t.Failed == 0 && t.Errors == 0
+}
+
+// FailedTargets returns the targets that aren't OK, in file order
+func (r *RunResult) FailedTargets() []string {
+	var failed []string
+	for _, tr := range r.Targets {
+		if !tr.OK() {
+			failed = append(failed, tr.Target)
+		}
+	}
+	return failed
 }
 
 // NewRunner creates a new runner with defaults
@@ -48,89 +156,227 @@ func NewRunner(output io.Writer) *Runner {
 	}
 }
 
+// Stop asks the runner to wind down, e.g. on an interrupt: assertions in
+// flight finish, and the rest, in this run and any later one, are reported
+// as skipped. Cancel the run's context to abandon those in flight too.
+func (r *Runner) Stop() {
+	r.stopping.Store(true)
+}
+
+// Stopped reports whether Stop has been called
+func (r *Runner) Stopped() bool {
+	return r.stopping.Load()
+}
+
+// failFast stops a run at its first failure when enabled: it cancels the
+// run context so in-flight requests return, and nothing new is dispatched.
+// A runner's Stop halts dispatch the same way, without cancelling. Blocked
+// results don't halt a run: they follow from a dependency that didn't
+// pass, which already did.
+type failFast struct {
+	enabled     bool
+	halted      atomic.Bool
+	cancel      context.CancelFunc
+	interrupted *atomic.Bool // The runner's stopping flag
+}
+
+// record halts the run if res is the first failure
+func (f *failFast) record(res *assertion.Result) {
+	switch res.GetStatus() {
+	case assertion.StatusPass, assertion.StatusSkipped, assertion.StatusBlocked:
+		return
+	}
+	if f.enabled && f.halted.CompareAndSwap(false, true) {
+		f.cancel()
+	}
+}
+
+// stopped reports whether the run has halted or been stopped
+func (f *failFast) stopped() bool {
+	return f.halted.Load() || f.interrupted.Load()
+}
+
+// skip returns the results for assertions a halted run didn't get to
+func (r *Runner) skip(target assertion.Target, assertions []assertion.Assertion) []*assertion.Result {
+	results := make([]*assertion.Result, len(assertions))
+	for i, a := range assertions {
+		results[i] = &assertion.Result{
+			Target:    target.GetHost(),
+			Metadata:  target.Metadata,
+			Assertion: a,
+			Status:    assertion.StatusSkipped,
+		}
+		r.compact(results[i], target)
+		r.emit(results[i])
+	}
+	return results
+}
+
+// compact sets a result's short path, with CompactPaths, when its path has one
+func (r *Runner) compact(res *assertion.Result, target assertion.Target) {
+	if !r.CompactPaths || res.Assertion.Path == "" {
+		return
+	}
+	if short := assertion.CompactPathFor(res.Assertion.Path, assertion.Platform(target.Platform)); short != res.Assertion.Path {
+		res.ShortPath = short
+	}
+}
+
+// emit passes a completed result to OnResult
+func (r *Runner) emit(res *assertion.Result) {
+	if r.OnResult == nil {
+		return
+	}
+	r.resultMu.Lock()
+	defer r.resultMu.Unlock()
+	r.OnResult(res)
+}
+
 // Run executes all assertions in the file
 func (r *Runner) Run(ctx context.Context, af *assertion.AssertionFile) (*RunResult, error) {
 	start := time.Now()
 	result := &RunResult{}
 
-	var allResults []*assertion.Result
-	var mu sync.Mutex
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ff := &failFast{enabled: r.FailFast, cancel: cancel, interrupted: &r.stopping}
+	global := newRateLimiter(r.GlobalRateLimit)
+
+	hs := af.Hooks
+	if r.Config != nil {
+		hs = r.Config.Hooks.Merge(af.Hooks)
+	}
+	if err := r.runHooks(ctx, hs.Before, nil, assertion.Target{}, nil); err != nil {
+		return nil, fmt.Errorf("before hook: %w", err)
+	}
+
 	var wg sync.WaitGroup
 
 	// Semaphore for target-level concurrency
 	workers := max(r.Workers, 1)
 	sem := make(chan struct{}, workers)
 
-	// Process targets concurrently
-	errChan := make(chan error, len(af.Targets))
+	// Targets on the same host share a connection: through the runner's
+	// pool if it has one, or one kept for this run
+	pool := r.Pool
+	if pool == nil {
+		pool = gnmiclient.NewPool(0)
+		defer pool.Close()
+	}
 
-	for _, target := range af.Targets {
+	// Apply config credentials if not specified in assertion file
+	targets := make([]*TargetResult, len(af.Targets))
+	configured := make([]assertion.Target, len(af.Targets))
+	for i, target := range af.Targets {
+		configured[i] = r.applyConfig(target)
+	}
+	shareCredentials(configured)
+
+	// Process targets concurrently
+	for i, target := range configured {
 		wg.Add(1)
-		target := target // capture
 
 		go func() {
 			defer wg.Done()
 			sem <- struct{}{}
 			defer func() { <-sem }()
+			targetStart := time.Now()
 
-			// Apply config credentials if not specified in assertion file
-			target = r.applyConfig(target)
-
-			targetResults, err := r.runTarget(ctx, target)
-			if err != nil {
-				errChan <- fmt.Errorf("target %s: %w", target.GetHost(), err)
-				return
+			tr := &TargetResult{}
+			if ff.stopped() {
+				tr.Results = r.skip(target, target.Assertions)
+			} else {
+				tr = r.runTarget(ctx, target, pool, ff, global, hs)
 			}
 
-			mu.Lock()
-			allResults = append(allResults, targetResults...)
-			mu.Unlock()
+			tr.Target = target.GetHost()
+			tr.Metadata = target.Metadata
+			tr.Duration = time.Since(targetStart)
+			targets[i] = tr
 		}()
 	}
 
 	wg.Wait()
-	close(errChan)
+	r.checkConsistency(targets, ff)
 
-	// Check for errors
-	for err := range errChan {
-		if err != nil {
-			return nil, err
+	// Tally results
+	result.Targets = targets
+	for _, tr := range targets {
+		result.Results = append(result.Results, tr.Results...)
+		result.HookErrors = append(result.HookErrors, tr.hookErrors...)
+		for _, res := range tr.Results {
+			tr.add(res)
+			result.TotalAssertions++
+			result.add(res)
 		}
 	}
 
-	result.Results = allResults
-
-	// Tally results
-	for _, res := range result.Results {
-		result.TotalAssertions++
-		if res.Error != nil {
-			result.Errors++
-		} else if res.Passed {
-			result.Passed++
-		} else {
-			result.Failed++
-		}
+	// After hooks run even if the run was cut short
+	if err := r.runHooks(context.WithoutCancel(ctx), hs.After, nil, assertion.Target{}, countsEnv(result.Counts)); err != nil {
+		result.HookErrors = append(result.HookErrors, fmt.Errorf("after hook: %w", err))
 	}
 
 	result.Duration = time.Since(start)
 	return result, nil
 }
 
+// runHooks runs hooks in order, stopping at the first failure. Commands
+// get env along with NETSERT_TARGET and NETSERT_PLATFORM for target hooks;
+// sets go to the target through client.
+func (r *Runner) runHooks(ctx context.Context, hs []hooks.Hook, client *gnmiclient.Client, target assertion.Target, env map[string]string) error {
+	if target.GetHost() != "" {
+		env = maps.Clone(env)
+		if env == nil {
+			env = make(map[string]string)
+		}
+		env["NETSERT_TARGET"] = target.GetHost()
+		env["NETSERT_PLATFORM"] = target.Platform
+	}
+
+	for _, h := range hs {
+		if h.Set == nil {
+			if err := hooks.Exec(ctx, h, env); err != nil {
+				return err
+			}
+			continue
+		}
+		path := assertion.ExpandPathFor(h.Set.Path, assertion.Platform(target.Platform))
+		setCtx, cancel := context.WithTimeout(ctx, h.GetTimeout(r.Timeout))
+		err := client.Set(setCtx, path, h.Set.Value, target.Username, target.Password)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("hook %s: %w", h.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// countsEnv exposes result counts to after hooks
+func countsEnv(c Counts) map[string]string {
+	return map[string]string{
+		"NETSERT_PASSED":  strconv.Itoa(c.Passed),
+		"NETSERT_FAILED":  strconv.Itoa(c.Failed),
+		"NETSERT_ERRORS":  strconv.Itoa(c.Errors),
+		"NETSERT_SKIPPED": strconv.Itoa(c.Skipped),
+		"NETSERT_BLOCKED": strconv.Itoa(c.Blocked),
+	}
+}
+
 // applyConfig merges config settings into target (assertion file takes precedence)
 func (r *Runner) applyConfig(target assertion.Target) assertion.Target {
 	if r.Config == nil {
 		return target
 	}
 
-	username, password, insecure := r.Config.GetCredentials(target.GetHost())
-
-	// Only apply if not already set in assertion file
-	if target.Username == "" {
-		target.Username = username
-	}
-	if target.Password == "" {
-		target.Password = password
+	// Append the configured default port to hosts that lack one
+	if host := inventory.AddPort(target.GetHost(), r.Config.Defaults.Port); host != target.GetHost() {
+		target.Host = host
+		target.Address = ""
 	}
+
+	// Credentials set in the assertion file are kept
+	username, password, insecure := r.Config.Credentials(target.GetHost(), target.Username, target.Password, target.Groups...)
+	target.Username, target.Password = username, password
 	if !target.Insecure {
 		target.Insecure = insecure
 	}
@@ -138,57 +384,232 @@ func (r *Runner) applyConfig(target assertion.Target) assertion.Target {
 	return target
 }
 
-func (r *Runner) runTarget(ctx context.Context, target assertion.Target) ([]*assertion.Result, error) {
-	// Connect to target
-	client, err := gnmiclient.NewClient(gnmiclient.Config{
+// ClientConfig applies configured connection settings to a client config
+func ClientConfig(base gnmiclient.Config, conn config.Connection) (gnmiclient.Config, error) {
+	interval, timeout, err := conn.KeepaliveDurations()
+	if err != nil {
+		return base, err
+	}
+	retryBackoff, err := conn.RetryBackoffDuration()
+	if err != nil {
+		return base, err
+	}
+
+	base.Retries = DefaultRetries
+	if conn.Retries != nil {
+		base.Retries = *conn.Retries
+	}
+	base.RetryBackoff = retryBackoff
+	base.Proxy = conn.Proxy
+	base.KeepaliveTime = interval
+	base.KeepaliveTimeout = timeout
+	base.MaxMsgSize = conn.MaxMsgSize
+	base.Authority = conn.Authority
+	base.Compression = conn.Compression
+	base.ServerName = conn.ServerName
+	base.CAFile = conn.CAFile
+	base.TargetName = conn.TargetName
+	base.Prefix = conn.Prefix
+	return base, nil
+}
+
+// shareCredentials gives targets on the same host without credentials
+// those of the first one that has them, e.g. a host both listed explicitly
+// with credentials and expanded from an inventory group, so they can share
+// a connection
+func shareCredentials(targets []assertion.Target) {
+	credentials := make(map[string]assertion.Target)
+	for _, t := range targets {
+		if _, ok := credentials[t.GetHost()]; !ok && t.Username != "" {
+			credentials[t.GetHost()] = t
+		}
+	}
+	for i := range targets {
+		t := &targets[i]
+		if from, ok := credentials[t.GetHost()]; ok && t.Username == "" {
+			t.Username, t.Password = from.Username, from.Password
+		}
+	}
+}
+
+// connect returns a pooled client for the target
+func (r *Runner) connect(target assertion.Target, pool *gnmiclient.Pool, conn config.Connection) (*gnmiclient.Client, error) {
+	clientCfg, err := ClientConfig(gnmiclient.Config{
 		Address:  target.GetHost(),
 		Username: target.Username,
 		Password: target.Password,
 		Insecure: target.Insecure,
 		Timeout:  r.Timeout,
-	})
+		Origin:   assertion.Platform(target.Platform).Origin(),
+
+		PathMapper: assertion.Platform(target.Platform),
+	}, conn)
 	if err != nil {
-		return nil, fmt.Errorf("connect: %w", err)
+		return nil, err
 	}
-	defer client.Close()
+	clientCfg.Fixtures = r.Fixtures
+	return pool.Get(clientCfg)
+}
 
-	var results []*assertion.Result
-	var mu sync.Mutex
+// runTarget runs a target's assertions. A target that can't be reached, or
+// whose connection settings are invalid, gets its ConnectError set and
+// every assertion fails with it; other targets carry on. RPCs wait for
+// both the address's rate limit and the global one. Once connected,
+// before_target hooks run first (if one fails, every assertion fails with
+// it), and after_target hooks last.
+func (r *Runner) runTarget(ctx context.Context, target assertion.Target, pool *gnmiclient.Pool, ff *failFast, global *rateLimiter, hs hooks.Hooks) *TargetResult {
+	var conn config.Connection
+	if r.Config != nil {
+		conn = r.Config.GetConnection(target.GetHost(), target.Groups...)
+	}
+
+	client, err := r.connect(target, pool, conn)
+	if err == nil {
+		// Dialing is lazy; find out once, rather than per assertion,
+		// whether the device is there
+		readyCtx, cancel := context.WithTimeout(ctx, r.Timeout)
+		err = client.WaitReady(readyCtx)
+		cancel()
+	}
+	if err != nil {
+		if ff.stopped() {
+			// Connecting was cut short by the halt
+			return &TargetResult{Results: r.skip(target, target.Assertions)}
+		}
+		err = fmt.Errorf("connect: %w", err)
+		if r.Output != nil {
+			fmt.Fprintf(r.Output, "✗ [ERROR] %v @ %s\n", err, target.GetHost())
+		}
+		results := make([]*assertion.Result, len(target.Assertions))
+		for i, a := range target.Assertions {
+			res := &assertion.Result{Target: target.GetHost(), Metadata: target.Metadata, Assertion: a, Error: err}
+			r.compact(res, target)
+			res.Status = resultStatus(res)
+			ff.record(res)
+			r.emit(res)
+			results[i] = res
+		}
+		return &TargetResult{ConnectError: err, Results: results}
+	}
+	statsBefore := client.Stats()
+
+	rate := conn.RateLimit
+	if r.RateLimit > 0 {
+		rate = r.RateLimit
+	}
+	limiter := r.hostLimiter(target.GetHost(), rate)
+	ctx = gnmiclient.WithThrottle(ctx, func(ctx context.Context) error {
+		return waitAll(ctx, limiter, global)
+	})
+
+	hookErr := r.runHooks(ctx, hs.BeforeTarget, client, target, nil)
+	if hookErr != nil {
+		hookErr = fmt.Errorf("before_target hook: %w", hookErr)
+	}
+
+	// Results are kept by index, in the order of the assertions; those that
+	// depend on others wait for done[i], closed once final[i] is set
+	deps, depErrs := dependencies(target.Assertions)
+	final := make([]*assertion.Result, len(target.Assertions))
+	done := make([]chan struct{}, len(target.Assertions))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	finish := func(i int, res *assertion.Result) {
+		final[i] = res
+		close(done[i])
+	}
+
+	// Rejected credentials fail every remaining assertion the same way;
+	// don't keep retrying them (and risk locking out the account)
+	var authErr atomic.Pointer[error]
 
 	// Run assertions with parallelism
 	parallel := max(r.Parallel, 1)
 	sem := make(chan struct{}, parallel)
 	var wg sync.WaitGroup
 
-	for _, a := range target.Assertions {
+	for i, a := range target.Assertions {
 		wg.Add(1)
-		a := a // capture
 
 		go func() {
 			defer wg.Done()
+			for _, d := range deps[i] {
+				<-done[d]
+			}
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			res := r.runAssertion(ctx, client, target, a)
-			res.Target = target.GetHost()
+			// Don't dispatch once the run has halted
+			if ff.stopped() {
+				finish(i, r.skip(target, []assertion.Assertion{a})[0])
+				return
+			}
 
-			mu.Lock()
-			results = append(results, res)
-			mu.Unlock()
+			var res *assertion.Result
+			if hookErr != nil {
+				res = &assertion.Result{Assertion: a, Error: hookErr}
+			} else if err := depErrs[i]; err != nil {
+				res = &assertion.Result{Assertion: a, Error: err}
+			} else if err := blocked(deps[i], final); err != nil {
+				res = &assertion.Result{Assertion: a, Error: err, Status: assertion.StatusBlocked}
+			} else if err := authErr.Load(); err != nil {
+				res = &assertion.Result{Assertion: a, Error: *err}
+			} else {
+				start := time.Now()
+				res = r.runAssertion(ctx, client, target, a)
+				res.Duration = time.Since(start)
+				if errors.Is(res.Error, gnmiclient.ErrUnauthenticated) {
+					authErr.CompareAndSwap(nil, &res.Error)
+				}
+			}
+			if res.Error != nil && ff.stopped() && ctx.Err() != nil {
+				// In flight when the run was cancelled; its error is the cancellation
+				finish(i, r.skip(target, []assertion.Assertion{a})[0])
+				return
+			}
+			res.Target = target.GetHost()
+			res.Metadata = target.Metadata
+			r.compact(res, target)
+			if res.Status == "" {
+				res.Status = resultStatus(res)
+			}
+			if a.Consistent != "" && res.Error == nil {
+				// Reported once every target's value is in; see checkConsistency
+				finish(i, res)
+				return
+			}
+			ff.record(res)
+			finish(i, res)
 
 			r.printResult(res)
+			r.emit(res)
 		}()
 	}
 
 	wg.Wait()
-	return results, nil
+
+	tr := &TargetResult{Results: final}
+	var counts Counts
+	for _, res := range final {
+		counts.add(res)
+	}
+	if err := r.runHooks(context.WithoutCancel(ctx), hs.AfterTarget, client, target, countsEnv(counts)); err != nil {
+		tr.hookErrors = append(tr.hookErrors, fmt.Errorf("%s: after_target hook: %w", target.GetHost(), err))
+	}
+	tr.RPCs = client.Stats().Sub(statsBefore)
+	return tr
 }
 
 func (r *Runner) runAssertion(ctx context.Context, client *gnmiclient.Client, target assertion.Target, a assertion.Assertion) *assertion.Result {
 	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
 	defer cancel()
 
-	value, exists, err := client.Get(ctx, a.Path, target.Username, target.Password)
+	if a.IsGNOI() {
+		return r.runGNOIAssertion(ctx, client, target, a)
+	}
+
+	update, exists, err := client.GetUpdate(ctx, a.Path, target.Username, target.Password)
 	if err != nil {
 		return &assertion.Result{
 			Assertion: a,
@@ -196,39 +617,144 @@ func (r *Runner) runAssertion(ctx context.Context, client *gnmiclient.Client, ta
 		}
 	}
 
-	return a.Validate(value, exists)
+	live := SnapshotValue{Target: target.GetHost(), Path: a.Path, Exists: exists}
+	if exists {
+		live.Value = update.Value.String()
+	}
+	if r.Capture != nil {
+		r.Capture.record(live)
+	}
+	if r.Against != nil {
+		stored, ok := r.Against.lookup(live.Target, live.Path)
+		if !ok {
+			return &assertion.Result{Assertion: a, ActualValue: live.Value, Error: fmt.Errorf("path not in snapshot")}
+		}
+		return compareSnapshot(a, live, stored)
+	}
+
+	return a.ValidateUpdate(update.Value, update.Timestamp, exists)
+}
+
+// runGNOIAssertion executes gNOI assertions over the target's connection
+func (r *Runner) runGNOIAssertion(ctx context.Context, client *gnmiclient.Client, target assertion.Target, a assertion.Assertion) *assertion.Result {
+	if client.Conn() == nil {
+		return &assertion.Result{Assertion: a, Error: fmt.Errorf("gNOI isn't replayed from fixtures: %w", gnmiclient.ErrUnimplemented)}
+	}
+	gnoi := gnoiclient.New(client.Conn())
+
+	switch {
+	case a.Ping != nil:
+		res, err := gnoi.Ping(ctx, gnoiclient.PingOptions{
+			Destination:     a.Ping.Destination,
+			Source:          a.Ping.Source,
+			NetworkInstance: a.Ping.NetworkInstance,
+			Count:           a.Ping.Count,
+		}, target.Username, target.Password)
+		if err != nil {
+			return &assertion.Result{Assertion: a, Error: err}
+		}
+		return a.ValidatePing(res.Sent, res.Received, res.MaxRTT)
+
+	case a.MaxClockSkew != nil:
+		skew, err := gnoi.ClockSkew(ctx, target.Username, target.Password)
+		if err != nil {
+			return &assertion.Result{Assertion: a, Error: err}
+		}
+		return a.ValidateClockSkew(skew)
+
+	case a.OSVersion != nil:
+		version, err := gnoi.OSVersion(ctx, target.Username, target.Password)
+		if err != nil {
+			return &assertion.Result{Assertion: a, Error: err}
+		}
+		return a.ValidateOSVersion(version)
+
+	default:
+		res, err := gnoi.Traceroute(ctx, gnoiclient.TracerouteOptions{
+			Destination:     a.Traceroute.Destination,
+			Source:          a.Traceroute.Source,
+			NetworkInstance: a.Traceroute.NetworkInstance,
+			MaxHops:         a.Traceroute.MaxHops,
+		}, target.Username, target.Password)
+		if err != nil {
+			return &assertion.Result{Assertion: a, Error: err}
+		}
+		hops := make([]string, len(res.Hops))
+		for i, hop := range res.Hops {
+			hops[i] = hop.Address
+		}
+		return a.ValidateTraceroute(hops, res.Reached())
+	}
+}
+
+// resultStatus maps a result's error kind to a distinct status
+func resultStatus(res *assertion.Result) assertion.Status {
+	switch {
+	case res.Error == nil:
+		return res.GetStatus()
+	case errors.Is(res.Error, gnmiclient.ErrDeadline):
+		return assertion.StatusTimeout
+	case errors.Is(res.Error, gnmiclient.ErrUnauthenticated):
+		return assertion.StatusUnauthenticated
+	case errors.Is(res.Error, gnmiclient.ErrUnimplemented), errors.Is(res.Error, assertion.ErrUnsupportedPath):
+		return assertion.StatusUnsupported
+	default:
+		return assertion.StatusError
+	}
 }
 
 func (r *Runner) printResult(res *assertion.Result) {
-	if r.Output == nil {
+	// Skipped assertions are only counted in the summary
+	if r.Output == nil || res.GetStatus() == assertion.StatusSkipped {
 		return
 	}
 
-	icon := "✓"
-	status := "PASS"
-	if res.Error != nil {
-		icon = "✗"
-		status = "ERROR"
-	} else if !res.Passed {
-		icon = "✗"
-		status = "FAIL"
+	icon := "✗"
+	status := strings.ToUpper(string(res.GetStatus()))
+	switch res.GetStatus() {
+	case assertion.StatusPass:
+		icon = "✓"
+	case assertion.StatusBlocked:
+		icon = "○"
 	}
 
-	name := res.Assertion.GetName()
+	name := res.DisplayName()
 	if len(name) > 60 {
 		name = name[:57] + "..."
 	}
 
-	fmt.Fprintf(r.Output, "%s [%s] %s @ %s\n", icon, status, name, res.Target)
+	if r.Verbose {
+		fmt.Fprintf(r.Output, "%s [%s] %s @ %s (%s)\n", icon, status, name, res.Target, res.Duration.Round(time.Millisecond))
+	} else {
+		fmt.Fprintf(r.Output, "%s [%s] %s @ %s\n", icon, status, name, res.Target)
+		if res.GetStatus() == assertion.StatusBlocked {
+			fmt.Fprintf(r.Output, "    %v\n", res.Error)
+		}
+	}
+	if res.Assertion.File != "" && res.GetStatus() != assertion.StatusPass {
+		fmt.Fprintf(r.Output, "    in %s\n", res.Assertion.File)
+	}
 
 	if r.Verbose && (res.Error != nil || !res.Passed) {
+		if res.ShortPath != "" {
+			fmt.Fprintf(r.Output, "    path: %s\n", res.Assertion.Path)
+		}
 		if res.Error != nil {
 			fmt.Fprintf(r.Output, "    error: %v\n", res.Error)
 		}
-		if res.ActualValue != "" {
+		if res.Diff != "" {
+			fmt.Fprintf(r.Output, "    diff: %s\n", res.Diff)
+		} else if res.ActualValue != "" {
 			fmt.Fprintf(r.Output, "    actual: %s\n", res.ActualValue)
 		}
-		if res.Assertion.Equals != nil {
+		if res.Assertion.FreshWithin != nil && !res.Timestamp.IsZero() {
+			fmt.Fprintf(r.Output, "    reported: %s ago\n", time.Since(res.Timestamp).Round(time.Second))
+		}
+		if res.Assertion.EqualsFile != "" {
+			fmt.Fprintf(r.Output, "    expected: contents of %s\n", res.Assertion.EqualsFile)
+		} else if res.Assertion.Equals != nil && res.Assertion.Not {
+			fmt.Fprintf(r.Output, "    expected: not %s\n", *res.Assertion.Equals)
+		} else if res.Assertion.Equals != nil {
 			fmt.Fprintf(r.Output, "    expected: %s\n", *res.Assertion.Equals)
 		}
 	}
@@ -0,0 +1,337 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+	"github.com/ndtobs/netsert/pkg/config"
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+	"github.com/ndtobs/netsert/pkg/gnmitest"
+)
+
+func TestFailedTargets(t *testing.T) {
+	result := &RunResult{Targets: []*TargetResult{
+		{Target: "spine1:6030", Counts: Counts{Passed: 3}},
+		{Target: "spine2:6030", Counts: Counts{Passed: 2, Failed: 1}},
+		{Target: "leaf1:6030", ConnectError: errors.New("connection refused"), Counts: Counts{Errors: 3}},
+		{Target: "leaf2:6030", Counts: Counts{Passed: 1, Skipped: 2}},
+	}}
+	want := []string{"spine2:6030", "leaf1:6030"}
+	if got := result.FailedTargets(); !slices.Equal(got, want) {
+		t.Errorf("FailedTargets() = %v, want %v", got, want)
+	}
+}
+
+func TestRunInvalidConnectionSettings(t *testing.T) {
+	// One target's bad settings fail its assertions, not the whole run
+	cfg := &config.Config{Targets: map[string]config.Target{
+		"spine1:6030": {Connection: config.Connection{Keepalive: "often"}},
+	}}
+	af := &assertion.AssertionFile{Targets: []assertion.Target{{
+		Host:       "spine1:6030",
+		Assertions: []assertion.Assertion{{Name: "hostname", Path: "/system/state/hostname"}},
+	}}}
+
+	r := NewRunner(io.Discard)
+	r.Config = cfg
+	result, err := r.Run(context.Background(), af)
+	if err != nil {
+		t.Fatalf("Run() error = %v, want per-target failure", err)
+	}
+	if len(result.Targets) != 1 || result.Targets[0].Connected() || result.Errors != 1 {
+		t.Errorf("Run() = %+v, want one unreachable target with an error result", result)
+	}
+}
+
+func TestRunUnreachable(t *testing.T) {
+	// A port nothing listens on
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+
+	af := &assertion.AssertionFile{Targets: []assertion.Target{{
+		Host:     addr,
+		Insecure: true,
+		Assertions: []assertion.Assertion{
+			{Name: "hostname", Path: "/system/state/hostname"},
+			{Name: "version", Path: "/system/state/software-version"},
+		},
+	}}}
+
+	r := NewRunner(io.Discard)
+	start := time.Now()
+	result, err := r.Run(context.Background(), af)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > r.Timeout/2 {
+		t.Errorf("Run() took %v, want the retries spent well within the timeout", elapsed)
+	}
+	tr := result.Targets[0]
+	if tr.Connected() || !errors.Is(tr.ConnectError, gnmiclient.ErrUnavailable) {
+		t.Fatalf("ConnectError = %v, want the target unreachable", tr.ConnectError)
+	}
+	if result.Errors != 2 || tr.RPCs.Count != 0 {
+		t.Errorf("Run() = %+v with %d RPCs, want both assertions failed without RPCs", result.Counts, tr.RPCs.Count)
+	}
+	for _, res := range tr.Results {
+		if res.Error != tr.ConnectError {
+			t.Errorf("%s error = %v, want the connect error", res.Assertion.Name, res.Error)
+		}
+	}
+	if tr.Target != addr {
+		t.Errorf("Target = %s, want %s", tr.Target, addr)
+	}
+}
+
+func TestRunCompactPaths(t *testing.T) {
+	cfg := &config.Config{Targets: map[string]config.Target{
+		"spine1:6030": {Connection: config.Connection{Keepalive: "often"}},
+	}}
+	af := &assertion.AssertionFile{Targets: []assertion.Target{{
+		Host: "spine1:6030",
+		Assertions: []assertion.Assertion{
+			{Path: "/interfaces/interface[name=Ethernet1]/state/oper-status"},
+			{Name: "hostname", Path: "/system/state/hostname"},
+		},
+	}}}
+
+	r := NewRunner(io.Discard)
+	r.Config = cfg
+	r.CompactPaths = true
+	result, err := r.Run(context.Background(), af)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.Results[0].DisplayName(); got != "interface[Ethernet1]/state/oper-status" {
+		t.Errorf("DisplayName() = %q, want the short path", got)
+	}
+	if got := result.Results[1].DisplayName(); got != "hostname" {
+		t.Errorf("DisplayName() = %q, want the assertion's own name", got)
+	}
+}
+
+func TestShareCredentials(t *testing.T) {
+	targets := []assertion.Target{
+		{Host: "spine1:6030"},
+		{Host: "spine1:6030", Username: "admin", Password: "secret"},
+		{Host: "spine1:6030", Username: "readonly", Password: "ro"},
+		{Host: "leaf1:6030"},
+	}
+	shareCredentials(targets)
+
+	want := []string{"admin", "admin", "readonly", ""}
+	for i, target := range targets {
+		if target.Username != want[i] {
+			t.Errorf("targets[%d].Username = %q, want %q", i, target.Username, want[i])
+		}
+	}
+	if targets[0].Password != "secret" {
+		t.Errorf("targets[0].Password = %q, want the password shared with the username", targets[0].Password)
+	}
+}
+
+func TestRunStopped(t *testing.T) {
+	af := &assertion.AssertionFile{Targets: []assertion.Target{{
+		Host:       "spine1:6030",
+		Assertions: []assertion.Assertion{{Name: "hostname", Path: "/system/state/hostname"}, {Name: "uptime", Path: "/system/state/boot-time"}},
+	}}}
+
+	r := NewRunner(io.Discard)
+	r.Stop()
+	result, err := r.Run(context.Background(), af)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TotalAssertions != 2 || result.Skipped != 2 {
+		t.Errorf("Run() after Stop() = %+v, want every assertion skipped", result.Counts)
+	}
+}
+
+func TestRunAgainstServer(t *testing.T) {
+	srv, err := gnmitest.Start(map[string]any{
+		"interface[Ethernet1]/state/oper-status": "UP",
+		"interface[Ethernet1]/state/mtu":         9214,
+		"/system/state/hostname":                 "spine1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	up, mtu, host := "UP", "9000", "spine1"
+	exists, absent := true, true
+	af := &assertion.AssertionFile{Targets: []assertion.Target{{
+		Host:     srv.Addr(),
+		Insecure: true,
+		Assertions: []assertion.Assertion{
+			{Name: "Ethernet1 up", Path: "interface[Ethernet1]/state/oper-status", Equals: &up},
+			{Name: "jumbo frames", Path: "interface[Ethernet1]/state/mtu", GTE: &mtu},
+			{Name: "hostname", Path: "/system/state/hostname", Equals: &host},
+			{Name: "Ethernet1 exists", Path: "interface[Ethernet1]/state", Exists: &exists},
+			{Name: "no Ethernet9", Path: "interface[Ethernet9]/state", Absent: &absent},
+			{Name: "Ethernet2 up", Path: "interface[Ethernet2]/state/oper-status", Equals: &up},
+		},
+	}}}
+	af.Targets[0].ExpandPaths()
+
+	r := NewRunner(io.Discard)
+	r.Parallel = 6
+	result, err := r.Run(context.Background(), af)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Passed != 5 || result.Errors != 1 {
+		t.Errorf("Run() = %+v, want 5 passed and Ethernet2's missing path an error", result.Counts)
+	}
+	for i, res := range result.Results {
+		if want := af.Targets[0].Assertions[i].Name; res.Assertion.Name != want {
+			t.Errorf("Results[%d] = %q, want %q: results follow the file", i, res.Assertion.Name, want)
+		}
+	}
+}
+
+func TestRunFailFast(t *testing.T) {
+	srv, err := gnmitest.Start(map[string]any{"/system/state/hostname": "spine1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	host, wrong := "spine1", "spine2"
+	af := &assertion.AssertionFile{Targets: []assertion.Target{{
+		Host:     srv.Addr(),
+		Insecure: true,
+		Assertions: []assertion.Assertion{
+			{Name: "a", Path: "/system/state/hostname", Equals: &host},
+			{Name: "b", Path: "/system/state/hostname", Equals: &wrong},
+			{Name: "c", Path: "/system/state/hostname", Equals: &host},
+			{Name: "d", Path: "/system/state/hostname", Equals: &host, DependsOn: []string{"b"}},
+			{Name: "e", Path: "/system/state/hostname", Equals: &host},
+		},
+	}}}
+
+	r := NewRunner(io.Discard)
+	r.FailFast = true
+	r.Parallel = 1
+	var order []*assertion.Result
+	r.OnResult = func(res *assertion.Result) { order = append(order, res) }
+	result, err := r.Run(context.Background(), af)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing runs after the failure; what's left, including b's
+	// dependent, is skipped rather than blocked
+	if result.Failed != 1 || result.Blocked != 0 || result.Passed+result.Failed+result.Skipped != 5 {
+		t.Errorf("Run() = %+v, want one failure and the rest passed before it or skipped after", result.Counts)
+	}
+	failed := false
+	for _, res := range order {
+		switch status := res.GetStatus(); {
+		case status == assertion.StatusFail:
+			failed = true
+		case failed && status != assertion.StatusSkipped:
+			t.Errorf("%s = %s after the failure, want skipped", res.Assertion.Name, status)
+		case !failed && status != assertion.StatusPass:
+			t.Errorf("%s = %s before the failure, want pass", res.Assertion.Name, status)
+		}
+	}
+}
+
+// gatedProxy forwards connections to target once open is closed
+func gatedProxy(t *testing.T, target string, open <-chan struct{}) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				<-open
+				upstream, err := net.Dial("tcp", target)
+				if err != nil {
+					return
+				}
+				defer upstream.Close()
+				go io.Copy(upstream, conn)
+				io.Copy(conn, upstream)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestRunFailFastCancelsInFlight(t *testing.T) {
+	srv, err := gnmitest.Start(map[string]any{"/system/state/hostname": "spine1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	// A device that accepts connections and never answers; the failure
+	// on the other target is let through once a request to it is pending
+	silent, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+	pending := make(chan struct{})
+	go func() {
+		conn, err := silent.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		close(pending)
+		io.Copy(io.Discard, conn)
+	}()
+
+	wrong := "spine2"
+	af := &assertion.AssertionFile{Targets: []assertion.Target{
+		{
+			Host:       gatedProxy(t, srv.Addr(), pending),
+			Insecure:   true,
+			Assertions: []assertion.Assertion{{Name: "hostname", Path: "/system/state/hostname", Equals: &wrong}},
+		},
+		{
+			Host:       silent.Addr().String(),
+			Insecure:   true,
+			Assertions: []assertion.Assertion{{Name: "stuck", Path: "/system/state/hostname", Equals: &wrong}},
+		},
+	}}
+
+	r := NewRunner(io.Discard)
+	r.FailFast = true
+	r.Timeout = time.Minute
+	start := time.Now()
+	result, err := r.Run(context.Background(), af)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Second {
+		t.Errorf("Run() took %v, want the pending request cancelled", elapsed)
+	}
+	if result.Failed != 1 || result.Skipped != 1 {
+		t.Errorf("Run() = %+v, want the failure and the cancelled request skipped", result.Counts)
+	}
+	if status := result.Targets[1].Results[0].GetStatus(); status != assertion.StatusSkipped {
+		t.Errorf("pending assertion = %s, want skipped", status)
+	}
+}
@@ -0,0 +1,134 @@
+package runner
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+)
+
+// Snapshot holds the values of assertion paths read in one run, so a later
+// run can report drift from them (see Runner.Against)
+type Snapshot struct {
+	Taken  time.Time       `json:"taken"`
+	Values []SnapshotValue `json:"values"`
+
+	mu    sync.Mutex
+	index map[snapshotKey]int // Position in Values
+}
+
+// SnapshotValue is one path's value on one target
+type SnapshotValue struct {
+	Target string `json:"target"`
+	Path   string `json:"path"`
+	Value  string `json:"value,omitempty"`
+	Exists bool   `json:"exists"`
+}
+
+type snapshotKey struct{ target, path string }
+
+// NewSnapshot returns an empty snapshot to record a run into with
+// Runner.Capture
+func NewSnapshot() *Snapshot {
+	return &Snapshot{Taken: time.Now(), Values: []SnapshotValue{}}
+}
+
+// LoadSnapshot reads a snapshot saved with Save
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot: %w", err)
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse snapshot %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Save writes the snapshot as JSON, sorted by target and path
+func (s *Snapshot) Save(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	slices.SortFunc(s.Values, func(a, b SnapshotValue) int {
+		return cmp.Or(cmp.Compare(a.Target, b.Target), cmp.Compare(a.Path, b.Path))
+	})
+	s.index = nil
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// record stores a path's value, replacing any earlier one
+func (s *Snapshot) record(v SnapshotValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buildIndex()
+	k := snapshotKey{v.Target, v.Path}
+	if i, ok := s.index[k]; ok {
+		s.Values[i] = v
+		return
+	}
+	s.index[k] = len(s.Values)
+	s.Values = append(s.Values, v)
+}
+
+// lookup returns the value recorded for a path on a target
+func (s *Snapshot) lookup(target, path string) (SnapshotValue, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buildIndex()
+	i, ok := s.index[snapshotKey{target, path}]
+	if !ok {
+		return SnapshotValue{}, false
+	}
+	return s.Values[i], true
+}
+
+// buildIndex indexes Values if it hasn't been; callers hold mu
+func (s *Snapshot) buildIndex() {
+	if s.index != nil {
+		return
+	}
+	s.index = make(map[snapshotKey]int, len(s.Values))
+	for i, v := range s.Values {
+		s.index[snapshotKey{v.Target, v.Path}] = i
+	}
+}
+
+// compareSnapshot checks a live value against the snapshot instead of the
+// assertion's own operators, passing if nothing changed. Containers are
+// compared as JSON, as a device may order their keys and list entries
+// differently from one Get to the next; scalars must match exactly.
+func compareSnapshot(a assertion.Assertion, live, stored SnapshotValue) *assertion.Result {
+	res := &assertion.Result{Assertion: a, ActualValue: live.Value}
+	res.Passed = live.Exists == stored.Exists &&
+		(live.Value == stored.Value || isContainer(live.Value) && isContainer(stored.Value) && assertion.SameJSON(live.Value, stored.Value))
+	if !res.Passed {
+		res.Diff = fmt.Sprintf("%s -> %s", stored.describe(), live.describe())
+	}
+	return res
+}
+
+// isContainer reports whether a value is a JSON object or list
+func isContainer(v string) bool {
+	v = strings.TrimSpace(v)
+	return strings.HasPrefix(v, "{") || strings.HasPrefix(v, "[")
+}
+
+// describe shows a value for drift reports
+func (v SnapshotValue) describe() string {
+	if !v.Exists {
+		return "(absent)"
+	}
+	return fmt.Sprintf("%q", v.Value)
+}
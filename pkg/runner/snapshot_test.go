@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ndtobs/netsert/pkg/assertion"
+)
+
+func TestSnapshot(t *testing.T) {
+	s := NewSnapshot()
+	s.record(SnapshotValue{Target: "spine1:6030", Path: "/system/state/hostname", Value: "spine1", Exists: true})
+	s.record(SnapshotValue{Target: "leaf1:6030", Path: "/system/state/hostname", Value: "old", Exists: true})
+	s.record(SnapshotValue{Target: "leaf1:6030", Path: "/system/state/hostname", Value: "leaf1", Exists: true})
+	s.record(SnapshotValue{Target: "leaf1:6030", Path: "/lldp/state/enabled"})
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Values) != 3 || loaded.Values[0].Target != "leaf1:6030" || loaded.Values[0].Path != "/lldp/state/enabled" {
+		t.Fatalf("Values = %+v, want 3 sorted by target and path", loaded.Values)
+	}
+
+	stored, ok := loaded.lookup("leaf1:6030", "/system/state/hostname")
+	if !ok || stored.Value != "leaf1" {
+		t.Fatalf("lookup() = %+v, %v, want the latest value", stored, ok)
+	}
+	if _, ok := loaded.lookup("leaf2:6030", "/system/state/hostname"); ok {
+		t.Error("lookup() found a target that wasn't recorded")
+	}
+}
+
+func TestCompareSnapshot(t *testing.T) {
+	a := assertion.Assertion{Name: "hostname", Path: "/system/state/hostname"}
+	leaf1 := SnapshotValue{Value: "leaf1", Exists: true}
+	neighbors := SnapshotValue{Value: `{"neighbor": [{"address": "10.0.0.1", "state": "UP"}, {"address": "10.0.0.2", "state": "UP"}]}`, Exists: true}
+
+	tests := []struct {
+		name     string
+		stored   SnapshotValue
+		live     SnapshotValue
+		wantPass bool
+		wantDiff string
+	}{
+		{"unchanged", leaf1, SnapshotValue{Value: "leaf1", Exists: true}, true, ""},
+		{"changed", leaf1, SnapshotValue{Value: "leaf9", Exists: true}, false, `"leaf1" -> "leaf9"`},
+		{"gone", leaf1, SnapshotValue{}, false, `"leaf1" -> (absent)`},
+		{"scalars exactly", SnapshotValue{Value: "1", Exists: true}, SnapshotValue{Value: "1.0", Exists: true}, false, `"1" -> "1.0"`},
+		{"container reordered", neighbors, SnapshotValue{Value: `{"neighbor": [{"state": "UP", "address": "10.0.0.2"}, {"address": "10.0.0.1", "state": "UP"}]}`, Exists: true}, true, ""},
+		{"container changed", neighbors, SnapshotValue{Value: `{"neighbor": [{"address": "10.0.0.1", "state": "UP"}, {"address": "10.0.0.2", "state": "DOWN"}]}`, Exists: true}, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := compareSnapshot(a, tt.live, tt.stored)
+			if res.Passed != tt.wantPass || (tt.wantDiff != "" && res.Diff != tt.wantDiff) {
+				t.Errorf("compareSnapshot() = passed %v, diff %q; want %v, %q", res.Passed, res.Diff, tt.wantPass, tt.wantDiff)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Package schema checks assertion paths against YANG models
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ndtobs/netsert/pkg/gnmiclient"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// Schema is a merged view of the data trees of a set of YANG modules
+type Schema struct {
+	root *yang.Entry // Synthetic root whose Dir holds every top-level node
+}
+
+// Load parses every .yang file under dir (recursively). Imports are
+// resolved from the same tree.
+func Load(dir string) (*Schema, error) {
+	ms := yang.NewModules()
+
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			ms.AddPath(path)
+			return nil
+		}
+		if strings.HasSuffix(path, ".yang") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read models: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .yang files found in %s", dir)
+	}
+
+	for _, f := range files {
+		if err := ms.Read(f); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", f, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		return nil, fmt.Errorf("process models: %w", errs[0])
+	}
+
+	root := &yang.Entry{Name: "/", Dir: make(map[string]*yang.Entry)}
+	for _, m := range ms.Modules {
+		for name, child := range yang.ToEntry(m).Dir {
+			root.Dir[name] = child
+		}
+	}
+	return &Schema{root: root}, nil
+}
+
+// Check validates an expanded path (e.g., /interfaces/interface[name=Ethernet1]/state/oper-status)
+// and returns a descriptive error for unknown nodes, wrong key names, or
+// leaves looked up under the wrong config/state container. Paths under a
+// native origin (e.g., eos_native:/Sysdb/...) aren't OpenConfig and pass.
+func (s *Schema) Check(path string) error {
+	p, err := gnmiclient.ParsePath(path)
+	if err != nil {
+		return err
+	}
+	if p.Origin != "" && p.Origin != "openconfig" {
+		return nil
+	}
+
+	entry := s.root
+	walked := ""
+	for _, elem := range p.Elem {
+		name := stripPrefix(elem.Name)
+		child := lookup(entry, name)
+		if child == nil {
+			return fmt.Errorf("unknown node %q under %s%s", name, displayPath(walked), suggestion(entry, name))
+		}
+		walked += "/" + name
+
+		if len(elem.Key) > 0 {
+			if !child.IsList() {
+				return fmt.Errorf("%s is not a list but has keys", walked)
+			}
+			keys := strings.Fields(child.Key)
+			for k := range elem.Key {
+				if !slices.Contains(keys, k) {
+					return fmt.Errorf("%s has no key %q (keys: %s)", walked, k, strings.Join(keys, ", "))
+				}
+			}
+		}
+		entry = child
+	}
+	return nil
+}
+
+// lookup finds a data child by name, looking through choice/case nodes
+func lookup(e *yang.Entry, name string) *yang.Entry {
+	if child, ok := e.Dir[name]; ok && !child.IsChoice() && !child.IsCase() {
+		return child
+	}
+	for _, child := range e.Dir {
+		if child.IsChoice() || child.IsCase() {
+			if found := lookup(child, name); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// suggestion proposes a fix for an unknown child name
+func suggestion(parent *yang.Entry, name string) string {
+	// config vs state: the leaf exists in the sibling container
+	if parent.Name == "config" || parent.Name == "state" {
+		sibling := "state"
+		if parent.Name == "state" {
+			sibling = "config"
+		}
+		if parent.Parent != nil {
+			if other := lookup(parent.Parent, sibling); other != nil && lookup(other, name) != nil {
+				return fmt.Sprintf(" (found under %s/ instead)", sibling)
+			}
+		}
+	}
+
+	best, bestDist := "", 3 // Only suggest close matches
+	names := make([]string, 0, len(parent.Dir))
+	for n := range parent.Dir {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if d := levenshtein(name, n); d < bestDist {
+			best, bestDist = n, d
+		}
+	}
+	if best != "" {
+		return fmt.Sprintf(" (did you mean %q?)", best)
+	}
+	return ""
+}
+
+func displayPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	return p
+}
+
+// stripPrefix removes a module prefix ("oc-if:interfaces" -> "interfaces")
+func stripPrefix(name string) string {
+	if i := strings.Index(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,44 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	s, err := Load("testdata")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{"valid state leaf", "/interfaces/interface[name=Ethernet1]/state/oper-status", ""},
+		{"module prefix", "/tif:interfaces/interface[name=Ethernet1]/config/mtu", ""},
+		{"typo", "/interfaces/interface[name=Ethernet1]/state/oper-staus", `did you mean "oper-status"`},
+		{"wrong key", "/interfaces/interface[id=1]/state/mtu", `no key "id"`},
+		{"config vs state", "/interfaces/interface[name=Ethernet1]/config/oper-status", "found under state/"},
+		{"unknown root", "/bogus", "unknown node"},
+		{"openconfig origin", "openconfig:/interfaces/interface[name=Ethernet1]/state/oper-staus", `did you mean "oper-status"`},
+		{"EOS native", "eos_native:/Sysdb/interface/status/eth/phy/slice/1/intfStatus/Ethernet1", ""},
+		{"SR Linux native", "srl_nokia:/interface[name=ethernet-1/1]/oper-state", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Check(tt.path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Check() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Check() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// Package secrets transparently decrypts SOPS- and age-encrypted files
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"gopkg.in/yaml.v3"
+)
+
+// ageHeader starts every binary age file
+const ageHeader = "age-encryption.org/v1"
+
+// Decrypt returns the plaintext of a file read from path.
+// Data that is neither SOPS- nor age-encrypted is returned unchanged.
+func Decrypt(path string, data []byte) ([]byte, error) {
+	switch {
+	case IsAge(data):
+		plain, err := decryptAge(data)
+		if err != nil {
+			return nil, fmt.Errorf("age decrypt %s: %w", path, err)
+		}
+		return plain, nil
+	case IsSOPS(data):
+		plain, err := decryptSOPS(path)
+		if err != nil {
+			return nil, fmt.Errorf("sops decrypt %s: %w", path, err)
+		}
+		return plain, nil
+	}
+	return data, nil
+}
+
+// IsAge reports whether data is an age-encrypted file (binary or armored)
+func IsAge(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return bytes.HasPrefix(trimmed, []byte(ageHeader)) || bytes.HasPrefix(trimmed, []byte(armor.Header))
+}
+
+// IsSOPS reports whether data is a SOPS-encrypted YAML document,
+// detected by the top-level sops metadata block
+func IsSOPS(data []byte) bool {
+	var doc struct {
+		Sops map[string]interface{} `yaml:"sops"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil || doc.Sops == nil {
+		return false
+	}
+	_, hasMAC := doc.Sops["mac"]
+	_, hasVersion := doc.Sops["version"]
+	return hasMAC || hasVersion
+}
+
+// decryptSOPS shells out to the sops binary, which handles every key
+// backend (age, PGP, cloud KMS) with the user's existing setup
+func decryptSOPS(path string) ([]byte, error) {
+	bin, err := exec.LookPath("sops")
+	if err != nil {
+		return nil, fmt.Errorf("sops binary not found in PATH")
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(bin, "--decrypt", "--input-type", "yaml", "--output-type", "yaml", path)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+func decryptAge(data []byte) ([]byte, error) {
+	identities, err := ageIdentities()
+	if err != nil {
+		return nil, err
+	}
+
+	var src io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+		src = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+	}
+
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// ageIdentities loads age identities, following the same conventions as sops:
+// SOPS_AGE_KEY (inline keys), SOPS_AGE_KEY_FILE, then <config dir>/sops/age/keys.txt
+func ageIdentities() ([]age.Identity, error) {
+	if keys := os.Getenv("SOPS_AGE_KEY"); keys != "" {
+		return age.ParseIdentities(strings.NewReader(keys))
+	}
+
+	path := os.Getenv("SOPS_AGE_KEY_FILE")
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("no age identity: set SOPS_AGE_KEY_FILE")
+		}
+		path = filepath.Join(dir, "sops", "age", "keys.txt")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open age identity: %w", err)
+	}
+	defer f.Close()
+
+	return age.ParseIdentities(bufio.NewReader(f))
+}
@@ -0,0 +1,82 @@
+package secrets
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+func encryptAge(t *testing.T, recipient age.Recipient, plain string, armored bool) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var out io.Writer = &buf
+	var aw io.WriteCloser
+	if armored {
+		aw = armor.NewWriter(&buf)
+		out = aw
+	}
+	w, err := age.Encrypt(out, recipient)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	io.WriteString(w, plain)
+	w.Close()
+	if aw != nil {
+		aw.Close()
+	}
+	return buf.Bytes()
+}
+
+func TestDecrypt_Age(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SOPS_AGE_KEY", identity.String())
+
+	plain := "defaults:\n  password: secret\n"
+	for _, armored := range []bool{false, true} {
+		data := encryptAge(t, identity.Recipient(), plain, armored)
+		got, err := Decrypt("netsert.yaml", data)
+		if err != nil {
+			t.Fatalf("Decrypt(armored=%v) error = %v", armored, err)
+		}
+		if string(got) != plain {
+			t.Errorf("Decrypt(armored=%v) = %q, want %q", armored, got, plain)
+		}
+	}
+}
+
+func TestDecrypt_Plaintext(t *testing.T) {
+	plain := []byte("groups:\n  spines:\n    - spine1\n")
+	got, err := Decrypt("inventory.yaml", plain)
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if !bytes.Equal(got, plain) {
+		t.Errorf("Decrypt() modified plaintext: %q", got)
+	}
+}
+
+func TestIsSOPS(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want bool
+	}{
+		{"sops metadata", "defaults:\n  password: ENC[AES256_GCM,data:abc]\nsops:\n  mac: ENC[...]\n  version: 3.8.1\n", true},
+		{"plain yaml", "defaults:\n  password: secret\n", false},
+		{"ini inventory", "[spines]\nspine1\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSOPS([]byte(tt.data)); got != tt.want {
+				t.Errorf("IsSOPS() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
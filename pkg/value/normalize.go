@@ -0,0 +1,76 @@
+package value
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Normalize unwraps JSON-encoded scalars so leaf values compare the same
+// across vendors:
+//
+//	"UP"                     -> UP (string)
+//	42, 1.5, true            -> int, float, bool
+//	{"oper-status":"UP"}     -> UP (single-key wrapper around a scalar)
+//
+// Objects with several members, lists, and non-JSON values are returned
+// unchanged. Module prefixes on wrapper keys don't matter.
+func Normalize(v Value) Value {
+	if v.Kind != KindJSON {
+		return v
+	}
+
+	decoded, ok := decodeJSON(v.str)
+	if !ok {
+		return v
+	}
+
+	// Single-key wrapper (e.g., {"openconfig-interfaces:oper-status": "UP"})
+	if obj, isObj := decoded.(map[string]any); isObj && len(obj) == 1 {
+		for _, inner := range obj {
+			decoded = inner
+		}
+	}
+
+	if scalar, ok := scalarValue(decoded); ok {
+		return scalar
+	}
+	return v
+}
+
+func decodeJSON(s string) (any, bool) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+
+	var decoded any
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, false
+	}
+	// Trailing content means this wasn't a single JSON document
+	if dec.More() {
+		return nil, false
+	}
+	return decoded, true
+}
+
+func scalarValue(decoded any) (Value, bool) {
+	switch x := decoded.(type) {
+	case string:
+		return String(x), true
+	case bool:
+		return Bool(x), true
+	case json.Number:
+		n, err := parseNumber(x.String())
+		if err != nil {
+			return Value{}, false
+		}
+		switch n.kind {
+		case KindInt:
+			return Int(n.i), true
+		case KindUint:
+			return Uint(n.u), true
+		default:
+			return Float(n.f), true
+		}
+	}
+	return Value{}, false
+}
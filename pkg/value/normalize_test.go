@@ -0,0 +1,34 @@
+package value
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       Value
+		wantKind Kind
+		want     string
+	}{
+		{"quoted string", JSON([]byte(`"UP"`)), KindString, "UP"},
+		{"bare number", JSON([]byte(`42`)), KindInt, "42"},
+		{"float", JSON([]byte(`1.5`)), KindFloat, "1.5"},
+		{"uint64 number", JSON([]byte(`18446744073709551615`)), KindUint, "18446744073709551615"},
+		{"bool", JSON([]byte(`true`)), KindBool, "true"},
+		{"single-key wrapper", JSON([]byte(`{"oper-status":"UP"}`)), KindString, "UP"},
+		{"module-prefixed wrapper", JSON([]byte(`{"openconfig-interfaces:mtu":9214}`)), KindInt, "9214"},
+		{"multi-key object", JSON([]byte(`{"a":1,"b":2}`)), KindJSON, `{"a":1,"b":2}`},
+		{"single-key object wrapper", JSON([]byte(`{"state":{"a":1}}`)), KindJSON, `{"state":{"a":1}}`},
+		{"list", JSON([]byte(`["a","b"]`)), KindJSON, `["a","b"]`},
+		{"not json", JSON([]byte(`UP`)), KindJSON, "UP"},
+		{"plain string untouched", String(`"quoted"`), KindString, `"quoted"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Normalize(tt.in)
+			if got.Kind != tt.wantKind || got.String() != tt.want {
+				t.Errorf("Normalize() = %s %q, want %s %q", got.Kind, got.String(), tt.wantKind, tt.want)
+			}
+		})
+	}
+}
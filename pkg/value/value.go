@@ -0,0 +1,214 @@
+// Package value holds typed gNMI leaf values so comparisons don't depend on
+// how a value happens to be formatted as a string
+package value
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Kind is the type of a Value
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindUint
+	KindFloat
+	KindBool
+	KindJSON  // Undecoded JSON / JSON_IETF document
+	KindBytes // Opaque bytes (e.g., bytes_val, proto_bytes)
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindInt:
+		return "int"
+	case KindUint:
+		return "uint"
+	case KindFloat:
+		return "float"
+	case KindBool:
+		return "bool"
+	case KindJSON:
+		return "json"
+	case KindBytes:
+		return "bytes"
+	default:
+		return "string"
+	}
+}
+
+// Value is a typed leaf value
+type Value struct {
+	Kind Kind
+
+	str string // String, JSON text, or bytes
+	i   int64
+	u   uint64
+	f   float64
+	b   bool
+}
+
+// String returns a string value
+func String(s string) Value { return Value{Kind: KindString, str: s} }
+
+// Int returns a signed integer value
+func Int(i int64) Value { return Value{Kind: KindInt, i: i} }
+
+// Uint returns an unsigned integer value
+func Uint(u uint64) Value { return Value{Kind: KindUint, u: u} }
+
+// Float returns a floating point value
+func Float(f float64) Value { return Value{Kind: KindFloat, f: f} }
+
+// Bool returns a boolean value
+func Bool(b bool) Value { return Value{Kind: KindBool, b: b} }
+
+// JSON returns an undecoded JSON value
+func JSON(raw []byte) Value { return Value{Kind: KindJSON, str: string(raw)} }
+
+// Bytes returns an opaque bytes value
+func Bytes(b []byte) Value { return Value{Kind: KindBytes, str: string(b)} }
+
+// String formats the value for display and string operators
+func (v Value) String() string {
+	switch v.Kind {
+	case KindInt:
+		return strconv.FormatInt(v.i, 10)
+	case KindUint:
+		return strconv.FormatUint(v.u, 10)
+	case KindFloat:
+		return strconv.FormatFloat(v.f, 'f', -1, 64)
+	case KindBool:
+		return strconv.FormatBool(v.b)
+	default:
+		return v.str
+	}
+}
+
+// Equal reports whether the value equals expected, comparing numbers and
+// booleans by value (so "1.50" equals 1.5 and "True" equals true)
+func (v Value) Equal(expected string) bool {
+	switch v.Kind {
+	case KindInt, KindUint, KindFloat:
+		c, err := v.Compare(expected)
+		return err == nil && c == 0
+	case KindBool:
+		b, err := strconv.ParseBool(expected)
+		return err == nil && b == v.b
+	default:
+		// SR Linux identityrefs carry a module prefix the expected value
+		// may omit, e.g. srl_nokia-common:ipv4-unicast for ipv4-unicast
+		return v.str == expected || (!strings.Contains(expected, ":") && trimSRLPrefix(v.str) == expected)
+	}
+}
+
+// trimSRLPrefix removes a leading SR Linux module name ("srl_nokia-...:")
+// from an identityref. Other values containing a colon, such as IPv6
+// addresses or OpenConfig identityrefs, are returned unchanged.
+func trimSRLPrefix(s string) string {
+	module, rest, ok := strings.Cut(s, ":")
+	if !ok || !strings.HasPrefix(module, "srl_nokia-") {
+		return s
+	}
+	for _, r := range module {
+		if !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.') {
+			return s
+		}
+	}
+	return rest
+}
+
+// Compare compares the value numerically with threshold, returning -1, 0,
+// or +1. Integers are compared exactly, so large uint64 counters don't lose
+// precision through float conversion.
+func (v Value) Compare(threshold string) (int, error) {
+	actual, err := v.number()
+	if err != nil {
+		return 0, fmt.Errorf("value is not numeric: %w", err)
+	}
+	t, err := parseNumber(strings.TrimSpace(threshold))
+	if err != nil {
+		return 0, fmt.Errorf("invalid threshold %q: %w", threshold, err)
+	}
+	return compareNumbers(actual, t), nil
+}
+
+// number is an exact numeric representation
+type number struct {
+	kind Kind // KindInt, KindUint, or KindFloat
+	i    int64
+	u    uint64
+	f    float64
+}
+
+func (v Value) number() (number, error) {
+	switch v.Kind {
+	case KindInt:
+		return number{kind: KindInt, i: v.i}, nil
+	case KindUint:
+		return number{kind: KindUint, u: v.u}, nil
+	case KindFloat:
+		return number{kind: KindFloat, f: v.f}, nil
+	case KindBool:
+		return number{}, fmt.Errorf("%s is a boolean", v.String())
+	case KindJSON:
+		// JSON_IETF encodes 64-bit integers as quoted strings
+		return parseNumber(strings.Trim(strings.TrimSpace(v.str), `"`))
+	default:
+		return parseNumber(strings.TrimSpace(v.str))
+	}
+}
+
+func parseNumber(s string) (number, error) {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return number{kind: KindInt, i: i}, nil
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return number{kind: KindUint, u: u}, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return number{}, err
+	}
+	return number{kind: KindFloat, f: f}, nil
+}
+
+func (n number) float() float64 {
+	switch n.kind {
+	case KindInt:
+		return float64(n.i)
+	case KindUint:
+		return float64(n.u)
+	default:
+		return n.f
+	}
+}
+
+func compareNumbers(a, b number) int {
+	if a.kind == KindFloat || b.kind == KindFloat {
+		return cmp.Compare(a.float(), b.float())
+	}
+
+	// Both integers: negative ints sort below every uint
+	switch {
+	case a.kind == KindInt && b.kind == KindInt:
+		return cmp.Compare(a.i, b.i)
+	case a.kind == KindUint && b.kind == KindUint:
+		return cmp.Compare(a.u, b.u)
+	case a.kind == KindInt:
+		if a.i < 0 {
+			return -1
+		}
+		return cmp.Compare(uint64(a.i), b.u)
+	default:
+		if b.i < 0 {
+			return 1
+		}
+		return cmp.Compare(a.u, uint64(b.i))
+	}
+}
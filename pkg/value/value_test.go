@@ -0,0 +1,71 @@
+package value
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    Value
+		expected string
+		want     bool
+	}{
+		{"string", String("UP"), "UP", true},
+		{"string mismatch", String("UP"), "up", false},
+		{"float formatting", Float(1.5), "1.50", true},
+		{"int", Int(-3), "-3", true},
+		{"uint64 max", Uint(math.MaxUint64), "18446744073709551615", true},
+		{"uint64 near max", Uint(math.MaxUint64 - 1), "18446744073709551615", false},
+		{"bool", Bool(true), "True", true},
+		{"bool mismatch", Bool(false), "true", false},
+		{"not a number", Int(1), "one", false},
+		{"srl identityref prefix", String("srl_nokia-common:ipv4-unicast"), "ipv4-unicast", true},
+		{"identityref prefix expected", String("openconfig-bgp-types:IPV4_UNICAST"), "openconfig-bgp-types:IPV4_UNICAST", true},
+		{"identityref other module", String("openconfig-bgp-types:IPV4_UNICAST"), "oc:IPV4_UNICAST", false},
+		{"openconfig prefix kept", String("openconfig-bgp-types:IPV4_UNICAST"), "IPV4_UNICAST", false},
+		{"plain colon string", String("vlan:100"), "100", false},
+		{"description with colon", String("uplink: spine1"), " spine1", false},
+		{"ipv6 not a prefix", String("2001:db8::1"), "db8::1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.value.Equal(tt.expected); got != tt.want {
+				t.Errorf("Equal(%q) = %v, want %v", tt.expected, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     Value
+		threshold string
+		want      int
+		wantErr   bool
+	}{
+		{"int less", Int(5), "10", -1, false},
+		{"uint beyond float precision", Uint(9007199254740993), "9007199254740992", 1, false},
+		{"negative threshold vs uint", Uint(0), "-1", 1, false},
+		{"float", Float(0.25), "0.5", -1, false},
+		{"numeric string", String("42"), "42", 0, false},
+		{"json quoted uint64", JSON([]byte(`"18446744073709551615"`)), "1", 1, false},
+		{"bool not numeric", Bool(true), "1", 0, true},
+		{"bad threshold", Int(1), "abc", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.value.Compare(tt.threshold)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Compare() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Compare(%q) = %d, want %d", tt.threshold, got, tt.want)
+			}
+		})
+	}
+}